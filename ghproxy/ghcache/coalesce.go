@@ -24,8 +24,6 @@ import (
 	"sync"
 
 	"github.com/sirupsen/logrus"
-
-	"k8s.io/test-infra/ghproxy/ghmetrics"
 )
 
 // requestCoalescer allows concurrent requests for the same URI to share a
@@ -35,6 +33,7 @@ type requestCoalescer struct {
 	keys map[string]*responseWaiter
 
 	delegate http.RoundTripper
+	upstream Upstream
 }
 
 type responseWaiter struct {
@@ -129,7 +128,7 @@ func (r *requestCoalescer) RoundTrip(req *http.Request) (*http.Response, error)
 		return resp, nil
 	}()
 
-	ghmetrics.CollectCacheRequestMetrics(string(cacheMode), req.URL.Path, req.Header.Get("User-Agent"))
+	r.upstream.collectCacheRequestMetrics(string(cacheMode), req.URL.Path, req.Header.Get("User-Agent"))
 	if resp != nil {
 		resp.Header.Set(CacheModeHeader, string(cacheMode))
 	}
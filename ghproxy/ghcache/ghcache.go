@@ -15,7 +15,8 @@ limitations under the License.
 */
 
 // Package ghcache implements an HTTP cache optimized for caching responses
-// from the GitHub API (https://api.github.com).
+// from the GitHub API (https://api.github.com) or the Gitee API
+// (https://gitee.com/api/v5).
 //
 // Specifically, it enforces a cache policy that revalidates every cache hit
 // with a conditional request to upstream regardless of cache entry freshness
@@ -43,7 +44,6 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/semaphore"
-	"k8s.io/test-infra/ghproxy/ghmetrics"
 )
 
 type CacheResponseMode string
@@ -101,7 +101,10 @@ func cacheResponseMode(headers http.Header) CacheResponseMode {
 	if strings.Contains(headers.Get("Cache-Control"), "no-store") {
 		return ModeNoStore
 	}
-	if strings.Contains(headers.Get("Status"), "304 Not Modified") {
+	// GitHub echoes the status of the revalidation in a header, which is
+	// copied to the cached response. Gitee doesn't, but every response served
+	// from the cache has been revalidated because of the cache policy.
+	if strings.Contains(headers.Get("Status"), "304 Not Modified") || headers.Get(httpcache.XFromCache) != "" {
 		return ModeRevalidated
 	}
 	if headers.Get("X-Conditional-Request") != "" {
@@ -144,6 +147,7 @@ func (c *throttlingTransport) RoundTrip(req *http.Request) (*http.Response, erro
 //    Cache-Control: no-cache
 // This instructs the cache to store the response, but always consider it stale.
 type upstreamTransport struct {
+	upstream Upstream
 	delegate http.RoundTripper
 }
 
@@ -170,8 +174,8 @@ func (u upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	// Don't modify request, just pass to delegate.
 	resp, err := u.delegate.RoundTrip(req)
 	if err != nil {
-		ghmetrics.CollectRequestTimeoutMetrics(authHeaderHash, req.URL.Path, req.Header.Get("User-Agent"), reqStartTime, time.Now())
-		logrus.WithField("cache-key", req.URL.String()).WithError(err).Warn("Error from upstream (GitHub).")
+		u.upstream.collectRequestTimeoutMetrics(authHeaderHash, req.URL.Path, req.Header.Get("User-Agent"), reqStartTime, time.Now())
+		logrus.WithField("cache-key", req.URL.String()).WithError(err).Warnf("Error from upstream (%s).", u.upstream)
 		return nil, err
 	}
 	responseTime := time.Now()
@@ -187,22 +191,20 @@ func (u upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		resp.Header.Set("X-Conditional-Request", etag)
 	}
 
-	apiVersion := "v3"
-	if strings.HasPrefix(req.URL.Path, "graphql") || strings.HasPrefix(req.URL.Path, "/graphql") {
+	if u.upstream == GitHub && isGraphQL(req) {
 		resp.Header.Set("Cache-Control", "no-store")
-		apiVersion = "v4"
 	}
 
-	ghmetrics.CollectGitHubTokenMetrics(authHeaderHash, apiVersion, resp.Header, reqStartTime, responseTime)
-	ghmetrics.CollectGitHubRequestMetrics(authHeaderHash, req.URL.Path, strconv.Itoa(resp.StatusCode), req.Header.Get("User-Agent"), roundTripTime.Seconds())
+	u.upstream.collectTokenMetrics(authHeaderHash, req, resp.Header, reqStartTime, responseTime)
+	u.upstream.collectRequestMetrics(authHeaderHash, req.URL.Path, strconv.Itoa(resp.StatusCode), req.Header.Get("User-Agent"), roundTripTime.Seconds())
 
 	return resp, nil
 }
 
-// NewDiskCache creates a GitHub cache RoundTripper that is backed by a disk
-// cache.
-func NewDiskCache(delegate http.RoundTripper, cacheDir string, cacheSizeGB, maxConcurrency int) http.RoundTripper {
-	return NewFromCache(delegate, diskcache.NewWithDiskv(
+// NewDiskCache creates a cache RoundTripper for the upstream API that is
+// backed by a disk cache.
+func NewDiskCache(upstream Upstream, delegate http.RoundTripper, cacheDir string, cacheSizeGB, maxConcurrency int) http.RoundTripper {
+	return NewFromCache(upstream, delegate, diskcache.NewWithDiskv(
 		diskv.New(diskv.Options{
 			BasePath:     path.Join(cacheDir, "data"),
 			TempDir:      path.Join(cacheDir, "temp"),
@@ -212,29 +214,34 @@ func NewDiskCache(delegate http.RoundTripper, cacheDir string, cacheSizeGB, maxC
 	)
 }
 
-// NewMemCache creates a GitHub cache RoundTripper that is backed by a memory
-// cache.
-func NewMemCache(delegate http.RoundTripper, maxConcurrency int) http.RoundTripper {
-	return NewFromCache(delegate, httpcache.NewMemoryCache(), maxConcurrency)
+// NewMemCache creates a cache RoundTripper for the upstream API that is
+// backed by a memory cache.
+func NewMemCache(upstream Upstream, delegate http.RoundTripper, maxConcurrency int) http.RoundTripper {
+	return NewFromCache(upstream, delegate, httpcache.NewMemoryCache(), maxConcurrency)
 }
 
-// NewFromCache creates a GitHub cache RoundTripper that is backed by the
-// specified httpcache.Cache implementation.
-func NewFromCache(delegate http.RoundTripper, cache httpcache.Cache, maxConcurrency int) http.RoundTripper {
+// NewFromCache creates a cache RoundTripper for the upstream API that is
+// backed by the specified httpcache.Cache implementation.
+func NewFromCache(upstream Upstream, delegate http.RoundTripper, cache httpcache.Cache, maxConcurrency int) http.RoundTripper {
 	cacheTransport := httpcache.NewTransport(cache)
-	cacheTransport.Transport = newThrottlingTransport(maxConcurrency, upstreamTransport{delegate: delegate})
-	return &requestCoalescer{
+	cacheTransport.Transport = newThrottlingTransport(maxConcurrency, upstreamTransport{upstream: upstream, delegate: delegate})
+	var rt http.RoundTripper = &requestCoalescer{
 		keys:     make(map[string]*responseWaiter),
 		delegate: cacheTransport,
+		upstream: upstream,
 	}
+	if upstream == Gitee {
+		rt = giteeTokenTransport{delegate: rt}
+	}
+	return rt
 }
 
-// NewRedisCache creates a GitHub cache RoundTripper that is backed by a Redis
-// cache.
-func NewRedisCache(delegate http.RoundTripper, redisAddress string, maxConcurrency int) http.RoundTripper {
+// NewRedisCache creates a cache RoundTripper for the upstream API that is
+// backed by a Redis cache.
+func NewRedisCache(upstream Upstream, delegate http.RoundTripper, redisAddress string, maxConcurrency int) http.RoundTripper {
 	conn, err := redis.Dial("tcp", redisAddress)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error connecting to Redis")
 	}
-	return NewFromCache(delegate, rediscache.NewWithClient(conn), maxConcurrency)
+	return NewFromCache(upstream, delegate, rediscache.NewWithClient(conn), maxConcurrency)
 }
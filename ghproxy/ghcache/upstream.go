@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/test-infra/ghproxy/ghmetrics"
+)
+
+// Upstream identifies the API whose responses are cached. It decides how the
+// requests are instrumented and how the API tokens are passed.
+type Upstream string
+
+const (
+	// GitHub is the GitHub API, e.g. https://api.github.com.
+	GitHub Upstream = "github"
+	// Gitee is the Gitee API served under /api/v5, e.g. https://gitee.com.
+	Gitee Upstream = "gitee"
+)
+
+// ParseUpstream returns the Upstream of the name.
+func ParseUpstream(name string) (Upstream, error) {
+	switch u := Upstream(strings.ToLower(name)); u {
+	case GitHub, Gitee:
+		return u, nil
+	}
+	return "", fmt.Errorf("unknown upstream %q, must be one of %q and %q", name, GitHub, Gitee)
+}
+
+func (u Upstream) collectTokenMetrics(tokenHash string, req *http.Request, headers http.Header, reqStartTime, responseTime time.Time) {
+	switch u {
+	case Gitee:
+		ghmetrics.CollectGiteeTokenMetrics(tokenHash, headers, reqStartTime, responseTime)
+	default:
+		ghmetrics.CollectGitHubTokenMetrics(tokenHash, apiVersion(req), headers, reqStartTime, responseTime)
+	}
+}
+
+func (u Upstream) collectRequestMetrics(tokenHash, path, statusCode, userAgent string, roundTripTime float64) {
+	switch u {
+	case Gitee:
+		ghmetrics.CollectGiteeRequestMetrics(tokenHash, path, statusCode, userAgent, roundTripTime)
+	default:
+		ghmetrics.CollectGitHubRequestMetrics(tokenHash, path, statusCode, userAgent, roundTripTime)
+	}
+}
+
+func (u Upstream) collectRequestTimeoutMetrics(tokenHash, path, userAgent string, reqStartTime, responseTime time.Time) {
+	switch u {
+	case Gitee:
+		ghmetrics.CollectGiteeRequestTimeoutMetrics(tokenHash, path, userAgent, reqStartTime, responseTime)
+	default:
+		ghmetrics.CollectRequestTimeoutMetrics(tokenHash, path, userAgent, reqStartTime, responseTime)
+	}
+}
+
+func (u Upstream) collectCacheRequestMetrics(mode, path, userAgent string) {
+	switch u {
+	case Gitee:
+		ghmetrics.CollectGiteeCacheRequestMetrics(mode, path, userAgent)
+	default:
+		ghmetrics.CollectCacheRequestMetrics(mode, path, userAgent)
+	}
+}
+
+// isGraphQL returns true for the requests to the GitHub v4 API. Their
+// responses can't be cached.
+func isGraphQL(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "graphql") || strings.HasPrefix(req.URL.Path, "/graphql")
+}
+
+func apiVersion(req *http.Request) string {
+	if isGraphQL(req) {
+		return "v4"
+	}
+	return "v3"
+}
+
+// giteeTokenTransport moves the access token of the Gitee requests from the
+// access_token query parameter to the Authorization header. The cache keys
+// are the request URLs, so this keeps the tokens out of the cache and lets
+// the clients which pass the token either way share the cached responses.
+type giteeTokenTransport struct {
+	delegate http.RoundTripper
+}
+
+func (g giteeTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	token := query.Get("access_token")
+	if token == "" {
+		return g.delegate.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	query.Del("access_token")
+	r.URL.RawQuery = query.Encode()
+	if r.Header.Get("Authorization") == "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return g.delegate.RoundTrip(r)
+}
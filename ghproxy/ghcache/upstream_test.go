@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghcache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/gregjones/httpcache"
+)
+
+type recordingDelegate struct {
+	requests []*http.Request
+	header   http.Header
+}
+
+func (r *recordingDelegate) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req)
+	header := r.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("Response")),
+		Header:     header,
+	}, nil
+}
+
+func TestParseUpstream(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected Upstream
+		err      bool
+	}{
+		{name: "github", expected: GitHub},
+		{name: "Gitee", expected: Gitee},
+		{name: "gitlab", err: true},
+	}
+	for _, tc := range testCases {
+		u, err := ParseUpstream(tc.name)
+		if tc.err != (err != nil) {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.err, err)
+		}
+		if u != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, u)
+		}
+	}
+}
+
+func TestGiteeTokenTransport(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		authorization string
+
+		expectedURL           string
+		expectedAuthorization string
+	}{
+		{
+			name:        "request without token is unchanged",
+			url:         "https://gitee.com/api/v5/repos/org/repo/pulls?state=open",
+			expectedURL: "https://gitee.com/api/v5/repos/org/repo/pulls?state=open",
+		},
+		{
+			name:                  "token is moved to the header",
+			url:                   "https://gitee.com/api/v5/repos/org/repo/pulls?access_token=secret&state=open",
+			expectedURL:           "https://gitee.com/api/v5/repos/org/repo/pulls?state=open",
+			expectedAuthorization: "Bearer secret",
+		},
+		{
+			name:                  "authorization header is kept",
+			url:                   "https://gitee.com/api/v5/user?access_token=secret",
+			authorization:         "Bearer other",
+			expectedURL:           "https://gitee.com/api/v5/user",
+			expectedAuthorization: "Bearer other",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delegate := &recordingDelegate{}
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatalf("failed to create the request: %v", err)
+			}
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			if _, err := (giteeTokenTransport{delegate: delegate}).RoundTrip(req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sent := delegate.requests[0]
+			if sent.URL.String() != tc.expectedURL {
+				t.Errorf("expected the URL %q, got %q", tc.expectedURL, sent.URL.String())
+			}
+			if auth := sent.Header.Get("Authorization"); auth != tc.expectedAuthorization {
+				t.Errorf("expected the Authorization %q, got %q", tc.expectedAuthorization, auth)
+			}
+		})
+	}
+}
+
+func TestCacheModeRevalidatedFromCache(t *testing.T) {
+	header := http.Header{}
+	header.Set(httpcache.XFromCache, "1")
+	coalesce := &requestCoalescer{
+		keys:     make(map[string]*responseWaiter),
+		delegate: &recordingDelegate{header: header},
+		upstream: Gitee,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://gitee.com/api/v5/user", nil)
+	if err != nil {
+		t.Fatalf("failed to create the request: %v", err)
+	}
+	resp, err := coalesce.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode := CacheResponseMode(resp.Header.Get(CacheModeHeader)); mode != ModeRevalidated {
+		t.Errorf("expected the cache mode %s, got %s", ModeRevalidated, mode)
+	}
+}
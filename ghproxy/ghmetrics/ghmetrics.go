@@ -93,23 +93,10 @@ func init() {
 // CollectGitHubTokenMetrics publishes the rate limits of the github api to
 // `github_token_usage` as well as `github_token_reset` on prometheus.
 func CollectGitHubTokenMetrics(tokenHash, apiVersion string, headers http.Header, reqStartTime, responseTime time.Time) {
-	remaining := headers.Get("X-RateLimit-Remaining")
-	if remaining == "" {
+	remainingFloat, durationUntilReset, ok := parseRateLimit(headers, reqStartTime)
+	if !ok {
 		return
 	}
-	timeUntilReset := timestampStringToTime(headers.Get("X-RateLimit-Reset"))
-	durationUntilReset := timeUntilReset.Sub(reqStartTime)
-
-	remainingFloat, err := strconv.ParseFloat(remaining, 64)
-	if err != nil {
-		logrus.WithError(err).Infof("Couldn't convert number of remaining token requests into gauge value (float)")
-	}
-	if remainingFloat == 0 {
-		logrus.WithFields(logrus.Fields{
-			"header":     remaining,
-			"user-agent": headers.Get("User-Agent"),
-		}).Debug("Parsed GitHub header as indicating no remaining rate-limit.")
-	}
 
 	muxTokenUsage.Lock()
 	isAfter := lastGitHubResponse.After(responseTime)
@@ -131,6 +118,30 @@ func CollectGitHubRequestMetrics(tokenHash, path, statusCode, userAgent string,
 	ghRequestDurationHistVec.With(prometheus.Labels{"token_hash": tokenHash, "path": simplifier.Simplify(path), "status": statusCode, "user_agent": userAgentWithoutVersion(userAgent)}).Observe(roundTripTime)
 }
 
+// parseRateLimit returns the number of remaining requests and the duration
+// until the reset of the rate limit from the X-RateLimit headers. The bool
+// is false when the response carries no rate limit.
+func parseRateLimit(headers http.Header, reqStartTime time.Time) (float64, time.Duration, bool) {
+	remaining := headers.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return 0, 0, false
+	}
+	timeUntilReset := timestampStringToTime(headers.Get("X-RateLimit-Reset"))
+	durationUntilReset := timeUntilReset.Sub(reqStartTime)
+
+	remainingFloat, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		logrus.WithError(err).Infof("Couldn't convert number of remaining token requests into gauge value (float)")
+	}
+	if remainingFloat == 0 {
+		logrus.WithFields(logrus.Fields{
+			"header":     remaining,
+			"user-agent": headers.Get("User-Agent"),
+		}).Debug("Parsed rate-limit header as indicating no remaining rate-limit.")
+	}
+	return remainingFloat, durationUntilReset, true
+}
+
 // timestampStringToTime takes a unix timestamp and returns a `time.Time`
 // from the given time.
 func timestampStringToTime(tstamp string) time.Time {
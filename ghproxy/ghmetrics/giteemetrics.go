@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghmetrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// giteeTokenUntilResetGaugeVec provides the 'gitee_token_reset' gauge that
+// enables keeping track of Gitee reset times.
+var giteeTokenUntilResetGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "gitee_token_reset",
+		Help: "Last reported Gitee token reset time.",
+	},
+	[]string{"token_hash"},
+)
+
+// giteeTokenUsageGaugeVec provides the 'gitee_token_usage' gauge that
+// enables keeping track of Gitee calls and quotas.
+var giteeTokenUsageGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "gitee_token_usage",
+		Help: "How many Gitee token requests are remaining for the current period.",
+	},
+	[]string{"token_hash"},
+)
+
+// giteeRequestDurationHistVec provides the 'gitee_request_duration' histogram
+// that keeps track of the duration of Gitee requests by API path.
+var giteeRequestDurationHistVec = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "gitee_request_duration",
+		Help:    "Gitee request duration by API path.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	},
+	[]string{"token_hash", "path", "status", "user_agent"},
+)
+
+// giteeTimeoutDuration provides the 'gitee_request_timeouts' histogram that
+// keeps track of the timeouts of Gitee requests by API path.
+var giteeTimeoutDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "gitee_request_timeouts",
+		Help:    "Gitee request timeout by API path.",
+		Buckets: []float64{45, 60, 90, 120, 300},
+	},
+	[]string{"token_hash", "path", "user_agent"},
+)
+
+var muxGiteeTokenUsage sync.Mutex
+var lastGiteeResponse time.Time
+
+func init() {
+	prometheus.MustRegister(giteeTokenUntilResetGaugeVec)
+	prometheus.MustRegister(giteeTokenUsageGaugeVec)
+	prometheus.MustRegister(giteeRequestDurationHistVec)
+	prometheus.MustRegister(giteeTimeoutDuration)
+}
+
+// CollectGiteeTokenMetrics publishes the rate limits of the gitee api to
+// `gitee_token_usage` as well as `gitee_token_reset` on prometheus.
+func CollectGiteeTokenMetrics(tokenHash string, headers http.Header, reqStartTime, responseTime time.Time) {
+	remaining, durationUntilReset, ok := parseRateLimit(headers, reqStartTime)
+	if !ok {
+		return
+	}
+
+	muxGiteeTokenUsage.Lock()
+	isAfter := lastGiteeResponse.After(responseTime)
+	if !isAfter {
+		lastGiteeResponse = responseTime
+	}
+	muxGiteeTokenUsage.Unlock()
+	if isAfter {
+		logrus.WithField("last-gitee-response", lastGiteeResponse).WithField("response-time", responseTime).Debug("Previously pushed metrics of a newer response, skipping old metrics")
+		return
+	}
+	giteeTokenUntilResetGaugeVec.With(prometheus.Labels{"token_hash": tokenHash}).Set(float64(durationUntilReset.Nanoseconds()))
+	giteeTokenUsageGaugeVec.With(prometheus.Labels{"token_hash": tokenHash}).Set(remaining)
+}
+
+// CollectGiteeRequestMetrics publishes the duration of the requests by API
+// path to `gitee_request_duration` on prometheus.
+func CollectGiteeRequestMetrics(tokenHash, path, statusCode, userAgent string, roundTripTime float64) {
+	giteeRequestDurationHistVec.With(prometheus.Labels{"token_hash": tokenHash, "path": giteeSimplifier.Simplify(path), "status": statusCode, "user_agent": userAgentWithoutVersion(userAgent)}).Observe(roundTripTime)
+}
+
+// CollectGiteeCacheRequestMetrics records a cache outcome for a specific
+// Gitee path.
+func CollectGiteeCacheRequestMetrics(mode, path, userAgent string) {
+	cacheCounter.With(prometheus.Labels{"mode": mode, "path": giteeSimplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent)}).Inc()
+}
+
+// CollectGiteeRequestTimeoutMetrics publishes the duration of timed-out
+// requests by API path to 'gitee_request_timeouts' on prometheus.
+func CollectGiteeRequestTimeoutMetrics(tokenHash, path, userAgent string, reqStartTime, responseTime time.Time) {
+	giteeTimeoutDuration.With(prometheus.Labels{"token_hash": tokenHash, "path": giteeSimplifier.Simplify(path), "user_agent": userAgentWithoutVersion(userAgent)}).Observe(float64(responseTime.Sub(reqStartTime).Seconds()))
+}
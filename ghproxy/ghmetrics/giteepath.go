@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghmetrics
+
+import (
+	"k8s.io/test-infra/prow/simplifypath"
+)
+
+func giteeRepositoryTree() []simplifypath.Node {
+	return []simplifypath.Node{
+		l("pulls",
+			l("comments", v("commentId")),
+			v("number",
+				l("assignees"),
+				l("comments"),
+				l("commits"),
+				l("files"),
+				l("labels", v("label")),
+				l("merge"))),
+		l("issues",
+			l("comments", v("commentId")),
+			v("number",
+				l("comments"),
+				l("labels", v("label")))),
+		l("branches", v("branch", l("protection"))),
+		l("collaborators", v("username", l("permission"))),
+		l("commits", v("sha")),
+		l("contents", v("path")),
+		l("git",
+			l("trees", v("sha"))),
+		l("labels", v("label")),
+		l("hooks", v("hookId")),
+		l("milestones", v("milestone")),
+	}
+}
+
+// The Gitee API is served under /api/v5, the base path of the SDK.
+var giteeSimplifier = simplifypath.NewSimplifier(l("", // shadow element mimicing the root
+	l("api",
+		l("v5",
+			l("repos",
+				v("owner",
+					v("repo",
+						giteeRepositoryTree()...),
+					l("issues", v("number")))),
+			l("orgs",
+				v("org",
+					l("repos"),
+					l("members", v("username")),
+					l("memberships", v("username")))),
+			l("user",
+				l("repos"),
+				l("orgs"),
+				l("memberships", l("orgs", v("org")))),
+			l("users", v("username")),
+			l("enterprises",
+				v("enterprise",
+					l("members", v("username"))))))))
+
+// SimplifyGiteePath returns a variable-free Gitee API path that can be used
+// as label for prometheus metrics.
+func SimplifyGiteePath(path string) string {
+	return giteeSimplifier.Simplify(path)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghmetrics
+
+import "testing"
+
+func TestGiteeSimplifiedPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "path not in tree", path: "/unknown", want: "unmatched"},
+		{name: "github path", path: "/repos/org/repo/pulls/1", want: "unmatched"},
+
+		{name: "user", path: "/api/v5/user", want: "/api/v5/user"},
+		{name: "org repos", path: "/api/v5/orgs/testOrg/repos", want: "/api/v5/orgs/:org/repos"},
+		{name: "org membership", path: "/api/v5/orgs/testOrg/memberships/testUser", want: "/api/v5/orgs/:org/memberships/:username"},
+
+		{name: "pull requests", path: "/api/v5/repos/testOwner/testRepo/pulls", want: "/api/v5/repos/:owner/:repo/pulls"},
+		{name: "pull request comments", path: "/api/v5/repos/testOwner/testRepo/pulls/1/comments", want: "/api/v5/repos/:owner/:repo/pulls/:number/comments"},
+		{name: "pull request comment by id", path: "/api/v5/repos/testOwner/testRepo/pulls/comments/12", want: "/api/v5/repos/:owner/:repo/pulls/comments/:commentId"},
+		{name: "pull request label", path: "/api/v5/repos/testOwner/testRepo/pulls/1/labels/lgtm", want: "/api/v5/repos/:owner/:repo/pulls/:number/labels/:label"},
+		{name: "pull request merge", path: "/api/v5/repos/testOwner/testRepo/pulls/1/merge", want: "/api/v5/repos/:owner/:repo/pulls/:number/merge"},
+
+		{name: "issue of the owner", path: "/api/v5/repos/testOwner/issues/I1ABCD", want: "/api/v5/repos/:owner/issues/:number"},
+		{name: "issue comments", path: "/api/v5/repos/testOwner/testRepo/issues/I1ABCD/comments", want: "/api/v5/repos/:owner/:repo/issues/:number/comments"},
+
+		{name: "branch", path: "/api/v5/repos/testOwner/testRepo/branches/master", want: "/api/v5/repos/:owner/:repo/branches/:branch"},
+		{name: "collaborator", path: "/api/v5/repos/testOwner/testRepo/collaborators/testUser", want: "/api/v5/repos/:owner/:repo/collaborators/:username"},
+		{name: "commit", path: "/api/v5/repos/testOwner/testRepo/commits/abcde", want: "/api/v5/repos/:owner/:repo/commits/:sha"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := giteeSimplifier.Simplify(tt.path); got != tt.want {
+				t.Errorf("Simplify(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
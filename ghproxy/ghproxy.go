@@ -61,15 +61,23 @@ func init() {
 	prometheus.MustRegister(diskTotal)
 }
 
-// GitHub reverse proxy HTTP cache RoundTripper stack:
+// GitHub (or Gitee) reverse proxy HTTP cache RoundTripper stack:
 //  v -   <Client(s)>
 //  v ^ reverse proxy
+//  v ^ ghcache: giteeTokenTransport (Gitee only, token out of the cache keys)
 //  v ^ ghcache: downstreamTransport (coalescing, instrumentation)
 //  v ^ ghcache: httpcache layer
 //  v ^ ghcache: upstreamTransport (cache-control, instrumentation)
 //  v ^ http.DefaultTransport
 //  > ^   <Upstream>
 
+// defaultUpstreams are the upstreams of the APIs. The Gitee clients send their
+// requests to the /api/v5 path of the proxy, e.g. --gitee-endpoint=http://ghproxy/api/v5.
+var defaultUpstreams = map[ghcache.Upstream]string{
+	ghcache.GitHub: "https://api.github.com",
+	ghcache.Gitee:  "https://gitee.com",
+}
+
 type options struct {
 	dir    string
 	sizeGB int
@@ -79,6 +87,8 @@ type options struct {
 	port           int
 	upstream       string
 	upstreamParsed *url.URL
+	upstreamAPI    string
+	upstreamKind   ghcache.Upstream
 
 	maxConcurrency int
 
@@ -101,6 +111,14 @@ func (o *options) validate() error {
 	if (o.dir == "") != (o.sizeGB == 0) {
 		return errors.New("--cache-dir and --cache-sizeGB must be specified together to enable the disk cache (otherwise a memory cache is used)")
 	}
+	upstreamKind, err := ghcache.ParseUpstream(o.upstreamAPI)
+	if err != nil {
+		return fmt.Errorf("invalid --upstream-api: %v", err)
+	}
+	o.upstreamKind = upstreamKind
+	if o.upstream == "" {
+		o.upstream = defaultUpstreams[upstreamKind]
+	}
 	upstreamURL, err := url.Parse(o.upstream)
 	if err != nil {
 		return fmt.Errorf("failed to parse upstream URL: %v", err)
@@ -115,7 +133,8 @@ func flagOptions() *options {
 	flag.IntVar(&o.sizeGB, "cache-sizeGB", 0, "Cache size in GB if using a disk cache.")
 	flag.StringVar(&o.redisAddress, "redis-address", "", "Redis address if using a redis cache e.g. localhost:6379.")
 	flag.IntVar(&o.port, "port", 8888, "Port to listen on.")
-	flag.StringVar(&o.upstream, "upstream", "https://api.github.com", "Scheme, host, and base path of reverse proxy upstream.")
+	flag.StringVar(&o.upstream, "upstream", "", fmt.Sprintf("Scheme, host, and base path of reverse proxy upstream. Defaults to %q for GitHub and %q for Gitee.", defaultUpstreams[ghcache.GitHub], defaultUpstreams[ghcache.Gitee]))
+	flag.StringVar(&o.upstreamAPI, "upstream-api", string(ghcache.GitHub), fmt.Sprintf("The API of the upstream, %q or %q.", ghcache.GitHub, ghcache.Gitee))
 	flag.IntVar(&o.maxConcurrency, "concurrency", 25, "Maximum number of concurrent in-flight requests to GitHub.")
 	flag.StringVar(&o.pushGateway, "push-gateway", "", "If specified, push prometheus metrics to this endpoint.")
 	flag.DurationVar(&o.pushGatewayInterval, "push-gateway-interval", time.Minute, "Interval at which prometheus metrics are pushed.")
@@ -135,11 +154,11 @@ func main() {
 
 	var cache http.RoundTripper
 	if o.redisAddress != "" {
-		cache = ghcache.NewRedisCache(http.DefaultTransport, o.redisAddress, o.maxConcurrency)
+		cache = ghcache.NewRedisCache(o.upstreamKind, http.DefaultTransport, o.redisAddress, o.maxConcurrency)
 	} else if o.dir == "" {
-		cache = ghcache.NewMemCache(http.DefaultTransport, o.maxConcurrency)
+		cache = ghcache.NewMemCache(o.upstreamKind, http.DefaultTransport, o.maxConcurrency)
 	} else {
-		cache = ghcache.NewDiskCache(http.DefaultTransport, o.dir, o.sizeGB, o.maxConcurrency)
+		cache = ghcache.NewDiskCache(o.upstreamKind, http.DefaultTransport, o.dir, o.sizeGB, o.maxConcurrency)
 		go diskMonitor(o.pushGatewayInterval, o.dir)
 	}
 
@@ -33,9 +33,16 @@ fi
 label_sync=$PWD/$1
 cd "$BUILD_WORKSPACE_DIRECTORY"
 out=${2:-label_sync/labels.md}
+gitee_out=${3:-label_sync/labels_gitee.md}
 
 "$label_sync" \
     --config=label_sync/labels.yaml \
     --action=docs \
     --docs-template=label_sync/labels.md.tmpl \
     "--docs-output=$out"
+
+"$label_sync" \
+    --config=label_sync/labels.yaml \
+    --action=docs \
+    --docs-template=label_sync/labels_gitee.md.tmpl \
+    "--docs-output=$gitee_out"
@@ -31,15 +31,18 @@ else
 fi
 
 out=$TEST_TMPDIR/labels.md.expected
-BUILD_WORKSPACE_DIRECTORY="$PWD" "$@" "$out"
+gitee_out=$TEST_TMPDIR/labels_gitee.md.expected
+BUILD_WORKSPACE_DIRECTORY="$PWD" "$@" "$out" "$gitee_out"
 
 
-DIFF=$(diff label_sync/labels.md "$out" || true)
-if [[ -n "$DIFF" ]]; then
-    echo "< unexpected" >&2
-    echo "> missing" >&2
-    echo "${DIFF}" >&2
-    echo "" >&2
-    echo "ERROR: labels.md out of date. Fix with hack/update-labels.sh" >&2
-    exit 1
-fi
+for pair in "label_sync/labels.md:$out" "label_sync/labels_gitee.md:$gitee_out"; do
+  DIFF=$(diff "${pair%%:*}" "${pair#*:}" || true)
+  if [[ -n "$DIFF" ]]; then
+      echo "< unexpected" >&2
+      echo "> missing" >&2
+      echo "${DIFF}" >&2
+      echo "" >&2
+      echo "ERROR: $(basename "${pair%%:*}") out of date. Fix with hack/update-labels.sh" >&2
+      exit 1
+  fi
+done
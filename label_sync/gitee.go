@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/config/secret"
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+// giteeClient syncs the labels of Gitee repos. Gitee labels have no
+// description, the descriptions of the config only end up in the docs.
+type giteeClient struct {
+	gc gitee.Client
+}
+
+func newGiteeClient(o *flagutil.GiteeOptions, tokens, tokenBurst int, dryRun bool) (client, error) {
+	if err := o.Validate(dryRun); err != nil {
+		return nil, err
+	}
+	if tokens > 0 && tokenBurst >= tokens {
+		return nil, fmt.Errorf("--tokens=%d must exceed --token-burst=%d", tokens, tokenBurst)
+	}
+
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.TokenPath}); err != nil {
+		return nil, err
+	}
+	c, err := o.GiteeClient(secretAgent, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if tokens > 0 && o.ThrottleHourlyTokens == 0 {
+		c.Throttle(tokens, tokenBurst)
+	}
+	return giteeClient{gc: c}, nil
+}
+
+func (c giteeClient) AddRepoLabel(org, repo, name, description, color string) error {
+	return c.gc.AddRepoLabel(org, repo, name, color)
+}
+
+func (c giteeClient) UpdateRepoLabel(org, repo, currentName, newName, description, color string) error {
+	return c.gc.UpdateRepoLabel(org, repo, currentName, newName, color)
+}
+
+func (c giteeClient) DeleteRepoLabel(org, repo, label string) error {
+	return c.gc.DeleteRepoLabel(org, repo, label)
+}
+
+func (c giteeClient) GetRepos(org string, isUser bool) ([]github.Repo, error) {
+	if isUser {
+		return nil, fmt.Errorf("cannot sync the repos of the user %s on Gitee", org)
+	}
+	projects, err := c.gc.GetRepos(org)
+	if err != nil {
+		return nil, err
+	}
+	var repos []github.Repo
+	for _, p := range projects {
+		repos = append(repos, github.Repo{
+			Name:     p.Path,
+			FullName: p.FullName,
+			Private:  p.Private,
+		})
+	}
+	return repos, nil
+}
+
+func (c giteeClient) GetRepoLabels(org, repo string) ([]github.Label, error) {
+	ls, err := c.gc.GetRepoLabels(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	var labels []github.Label
+	for _, l := range ls {
+		labels = append(labels, github.Label{
+			Name:  l.Name,
+			Color: strings.TrimPrefix(l.Color, "#"),
+		})
+	}
+	return labels, nil
+}
+
+// MigrateLabel moves the open PRs and issues from the current label to the
+// wanted one. Like on GitHub, items which already carry both labels are left
+// alone, and the current label is deleted once no item needs migrating.
+func (c giteeClient) MigrateLabel(org, repo, current, wanted string) error {
+	prs, err := c.gc.GetPullRequests(org, repo, "open", "", "")
+	if err != nil {
+		return err
+	}
+	var prsToMigrate []int
+	for _, pr := range prs {
+		ls, err := c.gc.GetPRLabels(org, repo, int(pr.Number))
+		if err != nil {
+			return err
+		}
+		names := sets.NewString()
+		for _, l := range ls {
+			names.Insert(l.Name)
+		}
+		if names.Has(current) && !names.Has(wanted) {
+			prsToMigrate = append(prsToMigrate, int(pr.Number))
+		}
+	}
+
+	issues, err := c.gc.ListIssues(org, repo, "open", current)
+	if err != nil {
+		return err
+	}
+	issuesToMigrate := map[string][]string{}
+	for _, i := range issues {
+		var labels []string
+		names := sets.NewString()
+		for _, l := range i.Labels {
+			names.Insert(l.Name)
+			if l.Name != current {
+				labels = append(labels, l.Name)
+			}
+		}
+		if names.Has(current) && !names.Has(wanted) {
+			issuesToMigrate[i.Number] = append(labels, wanted)
+		}
+	}
+
+	if len(prsToMigrate) == 0 && len(issuesToMigrate) == 0 {
+		return c.gc.DeleteRepoLabel(org, repo, current)
+	}
+
+	var errs []error
+	for _, n := range prsToMigrate {
+		if err := c.gc.AddPRLabel(org, repo, n, wanted); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := c.gc.RemovePRLabel(org, repo, n, current); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for n, labels := range issuesToMigrate {
+		if err := c.gc.UpdateIssueLabels(org, repo, n, labels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to migrate %d PRs and issues: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// withoutDescriptions returns a copy of the configuration whose labels have no
+// description, so that labels synced to Gitee are not updated on every run.
+func (c Configuration) withoutDescriptions() Configuration {
+	r := Configuration{
+		Default: RepoConfig{Labels: labelsWithoutDescriptions(c.Default.Labels)},
+	}
+	if c.Repos != nil {
+		r.Repos = map[string]RepoConfig{}
+		for name, repo := range c.Repos {
+			r.Repos[name] = RepoConfig{Labels: labelsWithoutDescriptions(repo.Labels)}
+		}
+	}
+	return r
+}
+
+func labelsWithoutDescriptions(labels []Label) []Label {
+	if labels == nil {
+		return nil
+	}
+	r := make([]Label, 0, len(labels))
+	for _, l := range labels {
+		l.Description = ""
+		l.Previously = labelsWithoutDescriptions(l.Previously)
+		r = append(r, l)
+	}
+	return r
+}
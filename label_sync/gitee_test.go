@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+)
+
+func TestGiteeMigrateLabel(t *testing.T) {
+	cases := []struct {
+		name       string
+		repoLabels []string
+		prLabels   []string
+		issues     []sdk.Issue
+
+		expectedRepoLabels []string
+		expectedPRAdded    []string
+		expectedPRRemoved  []string
+		expectedIssues     []sdk.Issue
+	}{
+		{
+			name:               "unused label is deleted",
+			repoLabels:         []string{"org/repo:old", "org/repo:new"},
+			expectedRepoLabels: []string{"org/repo:new"},
+		},
+		{
+			name:       "PRs and issues are migrated",
+			repoLabels: []string{"org/repo:old", "org/repo:new"},
+			prLabels:   []string{"org/repo#1:old", "org/repo#2:old", "org/repo#2:new"},
+			issues: []sdk.Issue{
+				{Number: "I1", State: "open", Labels: []sdk.Label{{Name: "kind/bug"}, {Name: "old"}}},
+				{Number: "I2", State: "open", Labels: []sdk.Label{{Name: "old"}, {Name: "new"}}},
+				{Number: "I3", State: "closed", Labels: []sdk.Label{{Name: "old"}}},
+			},
+			expectedRepoLabels: []string{"org/repo:old", "org/repo:new"},
+			expectedPRAdded:    []string{"org/repo#1:new"},
+			expectedPRRemoved:  []string{"org/repo#1:old"},
+			expectedIssues: []sdk.Issue{
+				{Number: "I1", State: "open", Labels: []sdk.Label{{Name: "kind/bug"}, {Name: "new"}}},
+				{Number: "I2", State: "open", Labels: []sdk.Label{{Name: "old"}, {Name: "new"}}},
+				{Number: "I3", State: "closed", Labels: []sdk.Label{{Name: "old"}}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.RepoLabelsExisting = tc.repoLabels
+			fc.PRLabelsExisting = tc.prLabels
+			fc.PullRequests[1] = &sdk.PullRequest{Number: 1, State: "open"}
+			fc.PullRequests[2] = &sdk.PullRequest{Number: 2, State: "open"}
+			fc.Issues["org/repo"] = tc.issues
+
+			if err := (giteeClient{gc: fc}).MigrateLabel("org", "repo", "old", "new"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			sort.Strings(fc.RepoLabelsExisting)
+			sort.Strings(tc.expectedRepoLabels)
+			if !reflect.DeepEqual(fc.RepoLabelsExisting, tc.expectedRepoLabels) {
+				t.Errorf("Expected the repo labels %v, got %v", tc.expectedRepoLabels, fc.RepoLabelsExisting)
+			}
+			if !reflect.DeepEqual(fc.PRLabelsAdded, tc.expectedPRAdded) {
+				t.Errorf("Expected the added PR labels %v, got %v", tc.expectedPRAdded, fc.PRLabelsAdded)
+			}
+			if !reflect.DeepEqual(fc.PRLabelsRemoved, tc.expectedPRRemoved) {
+				t.Errorf("Expected the removed PR labels %v, got %v", tc.expectedPRRemoved, fc.PRLabelsRemoved)
+			}
+			if !reflect.DeepEqual(fc.Issues["org/repo"], tc.expectedIssues) {
+				t.Errorf("Expected the issues %+v, got %+v", tc.expectedIssues, fc.Issues["org/repo"])
+			}
+		})
+	}
+}
+
+func TestWithoutDescriptions(t *testing.T) {
+	config := Configuration{
+		Default: RepoConfig{Labels: []Label{
+			{Name: "lgtm", Color: "15dd18", Description: "Looks good to me.", Previously: []Label{{Name: "LGTM", Description: "Old."}}},
+		}},
+		Repos: map[string]RepoConfig{
+			"org/repo": {Labels: []Label{{Name: "area/infra", Color: "0052cc", Description: "Infra."}}},
+		},
+	}
+	expected := Configuration{
+		Default: RepoConfig{Labels: []Label{
+			{Name: "lgtm", Color: "15dd18", Previously: []Label{{Name: "LGTM"}}},
+		}},
+		Repos: map[string]RepoConfig{
+			"org/repo": {Labels: []Label{{Name: "area/infra", Color: "0052cc"}}},
+		},
+	}
+
+	if actual := config.withoutDescriptions(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+	if config.Default.Labels[0].Description == "" || config.Default.Labels[0].Previously[0].Description == "" {
+		t.Error("The original configuration was modified")
+	}
+}
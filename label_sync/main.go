@@ -115,6 +115,11 @@ const (
 	defaultBurst  = 100
 )
 
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
+)
+
 // TODO(fejta): rewrite this to use an option struct which we can unit test, like everything else.
 var (
 	debug           = flag.Bool("debug", false, "Turn on debug to be more verbose")
@@ -133,10 +138,13 @@ var (
 	docsOutput      = flag.String("docs-output", "", "Path to output file for docs")
 	tokens          = flag.Int("tokens", defaultTokens, "Throttle hourly token consumption (0 to disable)")
 	tokenBurst      = flag.Int("token-burst", defaultBurst, "Allow consuming a subset of hourly tokens in a short burst")
+	provider        = flag.String("provider", providerGitHub, fmt.Sprintf("The code hosting platform of the orgs, %q or %q", providerGitHub, providerGitee))
+	giteeOptions    = flagutil.NewGiteeOptions()
 )
 
 func init() {
 	flag.Var(&endpoint, "endpoint", "GitHub's API endpoint")
+	giteeOptions.AddFlags(flag.CommandLine)
 }
 
 func pathExists(path string) bool {
@@ -597,24 +605,10 @@ func (ru RepoUpdates) DoUpdates(org string, gc client) error {
 						errChan <- err
 					}
 				case "migrate":
-					issues, err := gc.FindIssues(fmt.Sprintf("is:open repo:%s/%s label:\"%s\" -label:\"%s\"", org, repo, update.Current.Name, update.Wanted.Name), "", false)
+					err := gc.MigrateLabel(org, repo, update.Current.Name, update.Wanted.Name)
 					if err != nil {
 						errChan <- err
 					}
-					if len(issues) == 0 {
-						if err = gc.DeleteRepoLabel(org, repo, update.Current.Name); err != nil {
-							errChan <- err
-						}
-					}
-					for _, i := range issues {
-						if err = gc.AddLabel(org, repo, i.Number, update.Wanted.Name); err != nil {
-							errChan <- err
-							continue
-						}
-						if err = gc.RemoveLabel(org, repo, i.Number, update.Current.Name); err != nil {
-							errChan <- err
-						}
-					}
 				default:
 					errChan <- errors.New("unknown label operation: " + update.Why)
 				}
@@ -641,13 +635,43 @@ type client interface {
 	AddRepoLabel(org, repo, name, description, color string) error
 	UpdateRepoLabel(org, repo, currentName, newName, description, color string) error
 	DeleteRepoLabel(org, repo, label string) error
-	AddLabel(org, repo string, number int, label string) error
-	RemoveLabel(org, repo string, number int, label string) error
-	FindIssues(query, order string, ascending bool) ([]github.Issue, error)
+	// MigrateLabel moves the open issues and PRs from the current label to
+	// the wanted one, and deletes the current label once none is left.
+	MigrateLabel(org, repo, current, wanted string) error
 	GetRepos(org string, isUser bool) ([]github.Repo, error)
 	GetRepoLabels(string, string) ([]github.Label, error)
 }
 
+// githubClient syncs the labels of GitHub repos.
+type githubClient struct {
+	github.Client
+}
+
+func (gc githubClient) MigrateLabel(org, repo, current, wanted string) error {
+	issues, err := gc.FindIssues(fmt.Sprintf("is:open repo:%s/%s label:\"%s\" -label:\"%s\"", org, repo, current, wanted), "", false)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		return gc.DeleteRepoLabel(org, repo, current)
+	}
+
+	var errs []error
+	for _, i := range issues {
+		if err = gc.AddLabel(org, repo, i.Number, wanted); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = gc.RemoveLabel(org, repo, i.Number, current); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to migrate %d issues: %v", len(errs), errs)
+	}
+	return nil
+}
+
 func newClient(tokenPath string, tokens, tokenBurst int, dryRun bool, graphqlEndpoint string, hosts ...string) (client, error) {
 	if tokenPath == "" {
 		return nil, errors.New("--token unset")
@@ -659,7 +683,7 @@ func newClient(tokenPath string, tokens, tokenBurst int, dryRun bool, graphqlEnd
 	}
 
 	if dryRun {
-		return github.NewDryRunClient(secretAgent.GetTokenGenerator(tokenPath), secretAgent.Censor, graphqlEndpoint, hosts...), nil
+		return githubClient{github.NewDryRunClient(secretAgent.GetTokenGenerator(tokenPath), secretAgent.Censor, graphqlEndpoint, hosts...)}, nil
 	}
 	c := github.NewClient(secretAgent.GetTokenGenerator(tokenPath), secretAgent.Censor, graphqlEndpoint, hosts...)
 	if tokens > 0 && tokenBurst >= tokens {
@@ -668,7 +692,7 @@ func newClient(tokenPath string, tokens, tokenBurst int, dryRun bool, graphqlEnd
 	if tokens > 0 {
 		c.Throttle(tokens, tokenBurst) // 300 hourly tokens, bursts of 100
 	}
-	return c, nil
+	return githubClient{c}, nil
 }
 
 // Main function
@@ -710,7 +734,16 @@ func main() {
 			logrus.WithError(err).Fatalf("failed to write css file using css-template %s to css-output %s", *cssTemplate, *cssOutput)
 		}
 	case *action == "sync":
-		githubClient, err := newClient(*token, *tokens, *tokenBurst, !*confirm, *graphqlEndpoint, endpoint.Strings()...)
+		var labelClient client
+		switch *provider {
+		case providerGitHub:
+			labelClient, err = newClient(*token, *tokens, *tokenBurst, !*confirm, *graphqlEndpoint, endpoint.Strings()...)
+		case providerGitee:
+			labelClient, err = newGiteeClient(giteeOptions, *tokens, *tokenBurst, !*confirm)
+			*config = config.withoutDescriptions()
+		default:
+			err = fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, *provider)
+		}
 		if err != nil {
 			logrus.WithError(err).Fatal("failed to create client")
 		}
@@ -725,7 +758,7 @@ func main() {
 				logrus.WithError(err).Fatal("invalid value for --only")
 			}
 			for org := range reposToSync {
-				if err = syncOrg(org, githubClient, *config, reposToSync[org]); err != nil {
+				if err = syncOrg(org, labelClient, *config, reposToSync[org]); err != nil {
 					logrus.WithError(err).Fatalf("failed to update %s", org)
 				}
 			}
@@ -745,14 +778,14 @@ func main() {
 			org = strings.TrimSpace(org)
 			logger := logrus.WithField("org", org)
 			logger.Info("Reading repos")
-			repos, err := loadRepos(org, githubClient)
+			repos, err := loadRepos(org, labelClient)
 			if err != nil {
 				logger.WithError(err).Fatalf("failed to read repos")
 			}
 			if skipped, exist := skippedRepos[org]; exist {
 				repos = sets.NewString(repos...).Difference(sets.NewString(skipped...)).UnsortedList()
 			}
-			if err = syncOrg(org, githubClient, *config, repos); err != nil {
+			if err = syncOrg(org, labelClient, *config, repos); err != nil {
 				logrus.WithError(err).Fatalf("failed to update %s", org)
 			}
 		}
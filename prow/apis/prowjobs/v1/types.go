@@ -188,6 +188,15 @@ type RerunAuthConfig struct {
 	GitHubUsers []string `json:"github_users,omitempty"`
 	// GitHubOrgs contains names of GitHub organizations whose members can rerun the job
 	GitHubOrgs []string `json:"github_orgs,omitempty"`
+	// GiteeUsers contains logins of individual Gitee users who can rerun the job
+	GiteeUsers []string `json:"gitee_users,omitempty"`
+	// GiteeOrgs contains names of Gitee organizations whose members can rerun the job
+	GiteeOrgs []string `json:"gitee_orgs,omitempty"`
+}
+
+// GiteeRerunClient checks the membership of the Gitee orgs in GiteeOrgs.
+type GiteeRerunClient interface {
+	IsMember(org, login string) (bool, error)
 }
 
 // IsSpecifiedUser returns true if AllowAnyone is set to true or if the given user is
@@ -242,13 +251,45 @@ func (rac *RerunAuthConfig) IsAuthorized(user string, cli prowgithub.RerunClient
 	return false, nil
 }
 
+// IsAuthorizedGitee returns true if AllowAnyone is set to true or if the given
+// Gitee user is specified as a permitted GiteeUser or is a member of one of
+// the GiteeOrgs. The GitHub users and teams never match a Gitee user.
+func (rac *RerunAuthConfig) IsAuthorizedGitee(user string, cli GiteeRerunClient) (bool, error) {
+	if rac == nil {
+		return false, nil
+	}
+	if rac.AllowAnyone {
+		return true, nil
+	}
+	for _, u := range rac.GiteeUsers {
+		if prowgithub.NormLogin(u) == prowgithub.NormLogin(user) {
+			return true, nil
+		}
+	}
+	// if there is no client, no token was provided, so we cannot access the orgs
+	if cli == nil {
+		return false, nil
+	}
+	for _, org := range rac.GiteeOrgs {
+		isOrgMember, err := cli.IsMember(org, user)
+		if err != nil {
+			return false, fmt.Errorf("Gitee failed to fetch members of org %v: %v", org, err)
+		}
+		if isOrgMember {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Validate validates the RerunAuthConfig fields.
 func (rac *RerunAuthConfig) Validate() error {
 	if rac == nil {
 		return nil
 	}
 
-	hasWhiteList := len(rac.GitHubUsers) > 0 || len(rac.GitHubTeamIDs) > 0 || len(rac.GitHubTeamSlugs) > 0 || len(rac.GitHubOrgs) > 0
+	hasWhiteList := len(rac.GitHubUsers) > 0 || len(rac.GitHubTeamIDs) > 0 || len(rac.GitHubTeamSlugs) > 0 || len(rac.GitHubOrgs) > 0 ||
+		len(rac.GiteeUsers) > 0 || len(rac.GiteeOrgs) > 0
 
 	// If a whitelist is specified, the user probably does not intend for anyone to be able to rerun any job.
 	if rac.AllowAnyone && hasWhiteList {
@@ -638,6 +679,14 @@ type ProwJobStatus struct {
 	// the ProwJob.ObjectMeta.Name field.
 	PodName string `json:"pod_name,omitempty"`
 
+	// QueuePosition applies only to triggered ProwJobs
+	// fulfilled by plank. It is the position of the job
+	// in the queue of the jobs waiting for max_concurrency,
+	// starting at 1, and is unset once the job starts. It
+	// is only updated once it moved by a tenth beyond the
+	// first 10 positions.
+	QueuePosition int `json:"queue_position,omitempty"`
+
 	// BuildID is the build identifier vended either by tot
 	// or the snowflake library for this job and used as an
 	// identifier for grouping artifacts in GCS for views in
@@ -292,6 +292,16 @@ func TestRerunAuthConfigValidate(t *testing.T) {
 			config:      &RerunAuthConfig{AllowAnyone: true, GitHubOrgs: []string{"istio"}},
 			errExpected: true,
 		},
+		{
+			name:        "restrict Gitee orgs and users",
+			config:      &RerunAuthConfig{GiteeOrgs: []string{"openeuler"}, GiteeUsers: []string{"gumby"}},
+			errExpected: false,
+		},
+		{
+			name:        "allow any and has Gitee restriction",
+			config:      &RerunAuthConfig{AllowAnyone: true, GiteeUsers: []string{"gumby"}},
+			errExpected: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -347,6 +357,81 @@ func TestRerunAuthConfigIsAuthorized(t *testing.T) {
 	}
 }
 
+type fakeGiteeRerunClient map[string][]string
+
+func (c fakeGiteeRerunClient) IsMember(org, login string) (bool, error) {
+	for _, m := range c[org] {
+		if m == login {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRerunAuthConfigIsAuthorizedGitee(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		user       string
+		config     *RerunAuthConfig
+		cli        GiteeRerunClient
+		authorized bool
+	}{
+		{
+			name:       "authorized - AllowAnyone is true",
+			user:       "gumby",
+			config:     &RerunAuthConfig{AllowAnyone: true},
+			authorized: true,
+		},
+		{
+			name:       "authorized - user in GiteeUsers",
+			user:       "Gumby",
+			config:     &RerunAuthConfig{GiteeUsers: []string{"gumby"}},
+			authorized: true,
+		},
+		{
+			name:       "authorized - user in GiteeOrgs",
+			user:       "gumby",
+			config:     &RerunAuthConfig{GiteeOrgs: []string{"openeuler"}},
+			cli:        fakeGiteeRerunClient{"openeuler": {"gumby"}},
+			authorized: true,
+		},
+		{
+			name:       "unauthorized - user only in GitHubUsers",
+			user:       "gumby",
+			config:     &RerunAuthConfig{GitHubUsers: []string{"gumby"}},
+			authorized: false,
+		},
+		{
+			name:       "unauthorized - user not in GiteeOrgs",
+			user:       "gumby",
+			config:     &RerunAuthConfig{GiteeOrgs: []string{"openeuler"}},
+			cli:        fakeGiteeRerunClient{"openeuler": {"pokey"}},
+			authorized: false,
+		},
+		{
+			name:       "unauthorized - RerunAuthConfig is nil",
+			user:       "gumby",
+			config:     nil,
+			authorized: false,
+		},
+		{
+			name:       "unauthorized - cli is nil",
+			user:       "gumby",
+			config:     &RerunAuthConfig{GiteeOrgs: []string{"openeuler"}},
+			authorized: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			if actual, _ := tc.config.IsAuthorizedGitee(tc.user, tc.cli); actual != tc.authorized {
+				t.Errorf("Expected %v, got %v", tc.authorized, actual)
+			}
+		})
+	}
+}
+
 func TestRerunAuthConfigIsAllowAnyone(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -406,6 +406,16 @@ func (in *RerunAuthConfig) DeepCopyInto(out *RerunAuthConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.GiteeUsers != nil {
+		in, out := &in.GiteeUsers, &out.GiteeUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GiteeOrgs != nil {
+		in, out := &in.GiteeOrgs, &out.GiteeOrgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
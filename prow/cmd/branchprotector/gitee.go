@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+// giteeClient applies the branch protection policies to Gitee repos.
+//
+// Gitee only knows whether a branch is protected, who can push to and merge
+// into a protected branch and, per repo, how many reviewers must approve a
+// PR. The other settings of a policy are reported as unsupported.
+type giteeClient struct {
+	gc gitee.Client
+
+	lock sync.Mutex
+	// org/repo -> number of required approvals applied to the repo
+	approvals map[string]int
+}
+
+func newGiteeClient(gc gitee.Client) *giteeClient {
+	return &giteeClient{
+		gc:        gc,
+		approvals: map[string]int{},
+	}
+}
+
+// GetBranchProtection returns a protection with empty restrictions for
+// protected branches and nil otherwise, as Gitee does not return the
+// settings of a protected branch. Policies with or without restrictions, or
+// with reviews, are thus applied on every run, which resets the pushers and
+// mergers of the branches whose policy has no restrictions.
+func (c *giteeClient) GetBranchProtection(org, repo, branch string) (*github.BranchProtection, error) {
+	// The branch is escaped for the GitHub API
+	name, err := url.QueryUnescape(branch)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := c.gc.GetBranches(org, repo, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		if b.Name == name {
+			return &github.BranchProtection{Restrictions: &github.Restrictions{}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *giteeClient) RemoveBranchProtection(org, repo, branch string) error {
+	return c.gc.UnprotectBranch(org, repo, branch)
+}
+
+func (c *giteeClient) UpdateBranchProtection(org, repo, branch string, config github.BranchProtectionRequest) error {
+	if unsupported := unsupportedByGitee(config); len(unsupported) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"org":    org,
+			"repo":   repo,
+			"branch": branch,
+		}).Warnf("Gitee cannot express %s, ignoring them", strings.Join(unsupported, ", "))
+	}
+
+	if config.RequiredPullRequestReviews != nil {
+		if err := c.updateApprovals(org, repo, config.RequiredPullRequestReviews.RequiredApprovingReviewCount); err != nil {
+			return err
+		}
+	}
+
+	if err := c.gc.ProtectBranch(org, repo, branch); err != nil {
+		return err
+	}
+
+	// Like on GitHub, admins can always push and merge. Without
+	// restrictions, the ones set by an earlier run are reset.
+	allowed := "admin"
+	if config.Restrictions != nil && config.Restrictions.Users != nil && len(*config.Restrictions.Users) > 0 {
+		allowed = strings.Join(*config.Restrictions.Users, ";")
+	}
+	return c.gc.UpdateBranchSetting(org, repo, branch, sdk.BranchProtectionPutParam{
+		Pusher: allowed,
+		Merger: allowed,
+	})
+}
+
+// updateApprovals sets the number of required approvals of the repo, which
+// Gitee cannot set per branch. Gitee replaces all the code review settings
+// of the repo at once, so the reviewers and testers are read and sent back
+// unchanged.
+func (c *giteeClient) updateApprovals(org, repo string, approvals int) error {
+	key := org + "/" + repo
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if current, ok := c.approvals[key]; ok {
+		if current != approvals {
+			return fmt.Errorf("the branches of %s must require the same number of approvals on Gitee, got %d and %d", key, current, approvals)
+		}
+		return nil
+	}
+
+	p, err := c.gc.GetRepo(org, repo)
+	if err != nil {
+		return err
+	}
+	if int(p.AssigneesNumber) != approvals {
+		reviewer := sdk.ProjectReviewerPutParam{
+			Assignees:       logins(p.Assignee),
+			Testers:         logins(p.Testers),
+			AssigneesNumber: int32(approvals),
+			TestersNumber:   p.TestersNumber,
+		}
+		if err := c.gc.UpdateRepoReviewer(org, repo, reviewer); err != nil {
+			return err
+		}
+	}
+	c.approvals[key] = approvals
+	return nil
+}
+
+// logins returns the logins of the users separated by commas, the format of
+// the reviewers and testers Gitee expects.
+func logins(users []sdk.UserBasic) string {
+	r := make([]string, 0, len(users))
+	for _, u := range users {
+		r = append(r, u.Login)
+	}
+	return strings.Join(r, ",")
+}
+
+// unsupportedByGitee lists the settings of the request which Gitee cannot
+// express.
+func unsupportedByGitee(config github.BranchProtectionRequest) []string {
+	var r []string
+	if config.RequiredStatusChecks != nil {
+		r = append(r, "required_status_checks")
+	}
+	if config.EnforceAdmins != nil && *config.EnforceAdmins {
+		r = append(r, "enforce_admins")
+	}
+	if rp := config.RequiredPullRequestReviews; rp != nil {
+		if rp.DismissStaleReviews {
+			r = append(r, "dismiss_stale_reviews")
+		}
+		if rp.RequireCodeOwnerReviews {
+			r = append(r, "require_code_owner_reviews")
+		}
+		if !emptyRestrictions(&rp.DismissalRestrictions) {
+			r = append(r, "dismissal_restrictions")
+		}
+	}
+	if config.Restrictions != nil && config.Restrictions.Teams != nil && len(*config.Restrictions.Teams) > 0 {
+		r = append(r, "restrictions.teams")
+	}
+	if config.RequiredLinearHistory {
+		r = append(r, "required_linear_history")
+	}
+	if config.AllowForcePushes {
+		r = append(r, "allow_force_pushes")
+	}
+	if config.AllowDeletions {
+		r = append(r, "allow_deletions")
+	}
+	return r
+}
+
+func emptyRestrictions(r *github.RestrictionsRequest) bool {
+	return (r.Users == nil || len(*r.Users) == 0) && (r.Teams == nil || len(*r.Teams) == 0)
+}
+
+func (c *giteeClient) GetBranches(org, repo string, onlyProtected bool) ([]github.Branch, error) {
+	bs, err := c.gc.GetBranches(org, repo, onlyProtected)
+	if err != nil {
+		return nil, err
+	}
+	var r []github.Branch
+	for _, b := range bs {
+		r = append(r, github.Branch{Name: b.Name, Protected: b.Protected})
+	}
+	return r, nil
+}
+
+func (c *giteeClient) GetRepo(owner, name string) (github.FullRepo, error) {
+	p, err := c.gc.GetRepo(owner, name)
+	if err != nil {
+		return github.FullRepo{}, err
+	}
+	return github.FullRepo{Repo: giteeRepo(p)}, nil
+}
+
+func (c *giteeClient) GetRepos(org string, user bool) ([]github.Repo, error) {
+	if user {
+		return nil, fmt.Errorf("cannot protect the repos of the user %s on Gitee", org)
+	}
+	ps, err := c.gc.GetRepos(org)
+	if err != nil {
+		return nil, err
+	}
+	var r []github.Repo
+	for _, p := range ps {
+		r = append(r, giteeRepo(p))
+	}
+	return r, nil
+}
+
+func giteeRepo(p sdk.Project) github.Repo {
+	return github.Repo{
+		Name:          p.Path,
+		FullName:      p.FullName,
+		Private:       p.Private,
+		DefaultBranch: p.DefaultBranch,
+	}
+}
+
+// ListCollaborators returns the collaborators of the repo with their
+// permissions, which Gitee does not list.
+func (c *giteeClient) ListCollaborators(org, repo string) ([]github.User, error) {
+	users, err := c.gc.ListCollaborators(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range users {
+		permission, err := c.gc.GetRepoCollaboratorPermission(org, repo, u.Login)
+		if err != nil {
+			return nil, err
+		}
+		users[i].Permissions = github.RepoPermissions{
+			Pull:  true,
+			Push:  permission == "push" || permission == "admin",
+			Admin: permission == "admin",
+		}
+	}
+	return users, nil
+}
+
+// ListRepoTeams returns no teams, Gitee has none.
+func (c *giteeClient) ListRepoTeams(org, repo string) ([]github.Team, error) {
+	return nil, nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestGiteeProtect(t *testing.T) {
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(`
+branch-protection:
+  allow_disabled_policies: true
+  orgs:
+    org:
+      protect: true
+      required_pull_request_reviews:
+        required_approving_review_count: 2
+      restrictions:
+        users: ["alice", "bob"]
+      repos:
+        repo:
+          branches:
+            legacy:
+              protect: false
+`), &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	fc := fakegitee.NewFakeClient()
+	fc.Repos["org"] = []sdk.Project{{
+		Name:            "repo",
+		Path:            "repo",
+		FullName:        "org/repo",
+		AssigneesNumber: 1,
+		TestersNumber:   1,
+		Assignee:        []sdk.UserBasic{{Login: "alice"}, {Login: "bob"}},
+		Testers:         []sdk.UserBasic{{Login: "carol"}},
+	}}
+	fc.Branches["org/repo"] = []sdk.Branch{
+		{Name: "master"},
+		{Name: "release", Protected: true},
+		{Name: "legacy", Protected: true},
+	}
+	fc.BranchSettings["org/repo=legacy"] = sdk.BranchProtectionPutParam{Wildcard: "legacy", Pusher: "admin", Merger: "admin"}
+
+	p := protector{
+		client:         newGiteeClient(fc),
+		cfg:            &cfg,
+		errors:         Errors{},
+		updates:        make(chan requirements),
+		done:           make(chan []error),
+		completedRepos: make(map[string]bool),
+	}
+	go func() {
+		p.protect()
+		close(p.updates)
+	}()
+	var updates []requirements
+	for r := range p.updates {
+		updates = append(updates, r)
+	}
+	if errs := p.errors.errs; len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	// Apply the updates once the branches are read, the fake is not thread safe
+	p.updates = make(chan requirements)
+	go p.configureBranches()
+	for _, r := range updates {
+		p.updates <- r
+	}
+	close(p.updates)
+	if errs := <-p.done; len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	expectedBranches := []sdk.Branch{
+		{Name: "master", Protected: true},
+		{Name: "release", Protected: true},
+		{Name: "legacy"},
+	}
+	if !reflect.DeepEqual(fc.Branches["org/repo"], expectedBranches) {
+		t.Errorf("Expected the branches %+v, got %+v", expectedBranches, fc.Branches["org/repo"])
+	}
+	expectedSettings := map[string]sdk.BranchProtectionPutParam{
+		"org/repo=master":  {Wildcard: "master", Pusher: "alice;bob", Merger: "alice;bob"},
+		"org/repo=release": {Wildcard: "release", Pusher: "alice;bob", Merger: "alice;bob"},
+	}
+	if !reflect.DeepEqual(fc.BranchSettings, expectedSettings) {
+		t.Errorf("Expected the branch settings %+v, got %+v", expectedSettings, fc.BranchSettings)
+	}
+	expectedReviewers := map[string]sdk.ProjectReviewerPutParam{
+		"org/repo": {Assignees: "alice,bob", Testers: "carol", AssigneesNumber: 2, TestersNumber: 1},
+	}
+	if !reflect.DeepEqual(fc.RepoReviewers, expectedReviewers) {
+		t.Errorf("Expected the reviewers %+v, got %+v", expectedReviewers, fc.RepoReviewers)
+	}
+}
+
+func TestGiteeResetsRestrictions(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Branches["org/repo"] = []sdk.Branch{{Name: "master", Protected: true}}
+	fc.BranchSettings["org/repo=master"] = sdk.BranchProtectionPutParam{Wildcard: "master", Pusher: "alice", Merger: "alice"}
+	c := newGiteeClient(fc)
+
+	request := &github.BranchProtectionRequest{}
+	current, err := c.GetBranchProtection("org", "repo", "master")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if equalBranchProtections(current, request) {
+		t.Fatal("Expected a policy without restrictions to be applied to a protected branch")
+	}
+	if err := c.UpdateBranchProtection("org", "repo", "master", *request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := sdk.BranchProtectionPutParam{Wildcard: "master", Pusher: "admin", Merger: "admin"}
+	if actual := fc.BranchSettings["org/repo=master"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected the restrictions to be reset to %+v, got %+v", expected, actual)
+	}
+}
+
+func TestGiteeApprovalsPerRepo(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Repos["org"] = []sdk.Project{{Name: "repo", Path: "repo", FullName: "org/repo"}}
+	fc.Branches["org/repo"] = []sdk.Branch{{Name: "master"}, {Name: "release"}}
+	c := newGiteeClient(fc)
+
+	request := func(approvals int) github.BranchProtectionRequest {
+		return github.BranchProtectionRequest{
+			RequiredPullRequestReviews: &github.RequiredPullRequestReviewsRequest{RequiredApprovingReviewCount: approvals},
+		}
+	}
+	if err := c.UpdateBranchProtection("org", "repo", "master", request(1)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.UpdateBranchProtection("org", "repo", "release", request(2)); err == nil {
+		t.Error("Expected an error for different approvals in the same repo")
+	}
+	if fc.Branches["org/repo"][1].Protected {
+		t.Error("The branch with the conflicting approvals was protected")
+	}
+}
+
+func TestGiteeApprovalsUnchanged(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Repos["org"] = []sdk.Project{{Name: "repo", Path: "repo", FullName: "org/repo", AssigneesNumber: 2}}
+	fc.Branches["org/repo"] = []sdk.Branch{{Name: "master"}}
+	c := newGiteeClient(fc)
+
+	request := github.BranchProtectionRequest{
+		RequiredPullRequestReviews: &github.RequiredPullRequestReviewsRequest{RequiredApprovingReviewCount: 2},
+	}
+	if err := c.UpdateBranchProtection("org", "repo", "master", request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(fc.RepoReviewers) > 0 {
+		t.Errorf("Expected the code review settings to be left alone, got %+v", fc.RepoReviewers)
+	}
+}
+
+func TestUnsupportedByGitee(t *testing.T) {
+	yes := true
+	users := []string{"alice"}
+	teams := []string{"team"}
+
+	cases := []struct {
+		name     string
+		request  github.BranchProtectionRequest
+		expected []string
+	}{
+		{
+			name: "supported settings",
+			request: github.BranchProtectionRequest{
+				EnforceAdmins:              makeAdmins(nil),
+				RequiredPullRequestReviews: &github.RequiredPullRequestReviewsRequest{RequiredApprovingReviewCount: 1},
+				Restrictions:               &github.RestrictionsRequest{Users: &users, Teams: &[]string{}},
+			},
+		},
+		{
+			name: "unsupported settings",
+			request: github.BranchProtectionRequest{
+				RequiredStatusChecks: &github.RequiredStatusChecks{Contexts: []string{"test"}},
+				EnforceAdmins:        &yes,
+				RequiredPullRequestReviews: &github.RequiredPullRequestReviewsRequest{
+					DismissStaleReviews:     true,
+					RequireCodeOwnerReviews: true,
+					DismissalRestrictions:   github.RestrictionsRequest{Users: &users},
+				},
+				Restrictions:          &github.RestrictionsRequest{Teams: &teams},
+				RequiredLinearHistory: true,
+				AllowForcePushes:      true,
+				AllowDeletions:        true,
+			},
+			expected: []string{
+				"required_status_checks",
+				"enforce_admins",
+				"dismiss_stale_reviews",
+				"require_code_owner_reviews",
+				"dismissal_restrictions",
+				"restrictions.teams",
+				"required_linear_history",
+				"allow_force_pushes",
+				"allow_deletions",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := unsupportedByGitee(tc.request); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
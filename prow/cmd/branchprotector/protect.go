@@ -43,6 +43,11 @@ const (
 	defaultBurst  = 100
 )
 
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
+)
+
 type options struct {
 	config             string
 	jobConfig          string
@@ -51,11 +56,22 @@ type options struct {
 	tokens             int
 	tokenBurst         int
 	github             flagutil.GitHubOptions
+	gitee              flagutil.GiteeOptions
+	provider           string
 }
 
 func (o *options) Validate() error {
-	if err := o.github.Validate(!o.confirm); err != nil {
-		return err
+	switch o.provider {
+	case providerGitHub:
+		if err := o.github.Validate(!o.confirm); err != nil {
+			return err
+		}
+	case providerGitee:
+		if err := o.gitee.Validate(!o.confirm); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, o.provider)
 	}
 
 	if o.config == "" {
@@ -74,7 +90,9 @@ func gatherOptions() options {
 	fs.BoolVar(&o.verifyRestrictions, "verify-restrictions", false, "Verify the restrictions section of the request for authorized collaborators/teams")
 	fs.IntVar(&o.tokens, "tokens", defaultTokens, "Throttle hourly token consumption (0 to disable)")
 	fs.IntVar(&o.tokenBurst, "token-burst", defaultBurst, "Allow consuming a subset of hourly tokens in a short burst")
+	fs.StringVar(&o.provider, "provider", providerGitHub, fmt.Sprintf("The code hosting platform of the orgs, %q or %q.", providerGitHub, providerGitee))
 	o.github.AddFlags(fs)
+	o.gitee.AddFlags(fs)
 	fs.Parse(os.Args[1:])
 	return o
 }
@@ -113,19 +131,16 @@ func main() {
 	}
 	cfg.BranchProtectionWarnings(logrus.NewEntry(logrus.StandardLogger()), cfg.PresubmitsStatic)
 
-	secretAgent := &secret.Agent{}
-	if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
-		logrus.WithError(err).Fatal("Error starting secrets agent.")
-	}
-
-	githubClient, err := o.github.GitHubClient(secretAgent, !o.confirm)
-	if err != nil {
-		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	var c client
+	switch o.provider {
+	case providerGitee:
+		c = newGiteeProtectorClient(o)
+	default:
+		c = newGitHubProtectorClient(o)
 	}
-	githubClient.Throttle(o.tokens, o.tokenBurst)
 
 	p := protector{
-		client:             githubClient,
+		client:             c,
 		cfg:                cfg,
 		updates:            make(chan requirements),
 		errors:             Errors{},
@@ -146,6 +161,34 @@ func main() {
 	}
 }
 
+func newGitHubProtectorClient(o options) client {
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	githubClient, err := o.github.GitHubClient(secretAgent, !o.confirm)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting GitHub client.")
+	}
+	githubClient.Throttle(o.tokens, o.tokenBurst)
+	return githubClient
+}
+
+func newGiteeProtectorClient(o options) client {
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.gitee.TokenPath}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	giteeClient, err := o.gitee.GiteeClient(secretAgent, !o.confirm)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Gitee client.")
+	}
+	giteeClient.Throttle(o.tokens, o.tokenBurst)
+	return newGiteeClient(giteeClient)
+}
+
 type client interface {
 	GetBranchProtection(org, repo, branch string) (*github.BranchProtection, error)
 	RemoveBranchProtection(org, repo, branch string) error
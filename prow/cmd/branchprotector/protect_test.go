@@ -41,23 +41,26 @@ func TestOptions_Validate(t *testing.T) {
 		{
 			name: "all ok",
 			opt: options{
-				config: "dummy",
-				github: flagutil.GitHubOptions{TokenPath: "fake"},
+				config:   "dummy",
+				github:   flagutil.GitHubOptions{TokenPath: "fake"},
+				provider: providerGitHub,
 			},
 			expectedErr: false,
 		},
 		{
 			name: "no config",
 			opt: options{
-				config: "",
-				github: flagutil.GitHubOptions{TokenPath: "fake"},
+				config:   "",
+				github:   flagutil.GitHubOptions{TokenPath: "fake"},
+				provider: providerGitHub,
 			},
 			expectedErr: true,
 		},
 		{
 			name: "no token, allow",
 			opt: options{
-				config: "dummy",
+				config:   "dummy",
+				provider: providerGitHub,
 			},
 			expectedErr: false,
 		},
@@ -67,9 +70,36 @@ func TestOptions_Validate(t *testing.T) {
 				config:     "dummy",
 				tokens:     5000,
 				tokenBurst: 200,
+				provider:   providerGitHub,
 			},
 			expectedErr: false,
 		},
+		{
+			name: "gitee ok",
+			opt: options{
+				config:   "dummy",
+				gitee:    *flagutil.NewGiteeOptions(),
+				provider: providerGitee,
+			},
+			expectedErr: false,
+		},
+		{
+			name: "gitee with invalid throttle",
+			opt: options{
+				config:   "dummy",
+				gitee:    flagutil.GiteeOptions{ThrottleHourlyTokens: 10, ThrottleAllowBurst: 20},
+				provider: providerGitee,
+			},
+			expectedErr: true,
+		},
+		{
+			name: "unknown provider",
+			opt: options{
+				config:   "dummy",
+				provider: "gitlab",
+			},
+			expectedErr: true,
+		},
 	}
 
 	for _, testCase := range testCases {
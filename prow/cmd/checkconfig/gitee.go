@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	giteeplugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee-plugins/approve"
+	"k8s.io/test-infra/prow/gitee-plugins/assign"
+	"k8s.io/test-infra/prow/gitee-plugins/blunderbuss"
+	"k8s.io/test-infra/prow/gitee-plugins/hold"
+	"k8s.io/test-infra/prow/gitee-plugins/label"
+	"k8s.io/test-infra/prow/gitee-plugins/lgtm"
+	"k8s.io/test-infra/prow/gitee-plugins/lifecycle"
+	"k8s.io/test-infra/prow/gitee-plugins/trigger"
+	"k8s.io/test-infra/prow/gitee-plugins/wip"
+)
+
+// newGiteePluginAgent returns a config agent of the Gitee plugins which
+// knows the configurations of the built-in plugins, and the help providers
+// of those plugins by name. The plugins are built without clients since
+// they are only used to validate the configuration.
+func newGiteePluginAgent() (*giteeplugins.ConfigAgent, map[string]giteeplugins.HelpProvider) {
+	agent := giteeplugins.NewConfigAgent()
+	gpc := func(name, org, repo string) giteeplugins.PluginConfig {
+		return agent.Config().PluginConfigFor(name, org, repo)
+	}
+
+	var v []giteeplugins.Plugin
+	v = append(v, approve.NewApprove(gpc, nil, nil))
+	v = append(v, assign.NewAssign(gpc, nil))
+	v = append(v, blunderbuss.NewBlunderbuss(gpc, nil, nil))
+	v = append(v, hold.NewHold(gpc, nil))
+	v = append(v, label.NewLabel(gpc, nil))
+	v = append(v, lgtm.NewLGTM(gpc, nil, nil, nil))
+	v = append(v, lifecycle.NewLifecycle(gpc, nil))
+	v = append(v, trigger.NewTrigger(gpc, nil, nil, nil, nil))
+	v = append(v, wip.NewWIP(gpc, nil))
+
+	helpProviders := make(map[string]giteeplugins.HelpProvider, len(v))
+	for _, i := range v {
+		name := i.PluginName()
+
+		helpProviders[name] = i.HelpProvider
+		agent.RegisterPluginConfigBuilder(name, i.NewPluginConfig)
+	}
+	return agent, helpProviders
+}
@@ -37,6 +37,7 @@ import (
 	"k8s.io/test-infra/prow/config/secret"
 	needsrebase "k8s.io/test-infra/prow/external-plugins/needs-rebase/plugin"
 	"k8s.io/test-infra/prow/flagutil"
+	giteeplugins "k8s.io/test-infra/prow/gitee-plugins"
 	"k8s.io/test-infra/prow/github"
 	_ "k8s.io/test-infra/prow/hook/plugin-imports"
 	"k8s.io/test-infra/prow/labels"
@@ -61,6 +62,8 @@ type options struct {
 	jobConfigPath string
 	pluginConfig  string
 
+	giteePluginConfig string
+
 	prowYAMLRepoName string
 	prowYAMLPath     string
 
@@ -168,6 +171,7 @@ func (o *options) gatherOptions(flag *flag.FlagSet, args []string) error {
 	flag.StringVar(&o.configPath, "config-path", "", "Path to config.yaml.")
 	flag.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to prow job configs.")
 	flag.StringVar(&o.pluginConfig, "plugin-config", "", "Path to plugin config file.")
+	flag.StringVar(&o.giteePluginConfig, "gitee-plugin-config", "", "Path to the Gitee plugin config file.")
 	flag.StringVar(&o.prowYAMLRepoName, "prow-yaml-repo-name", "", "Name of the repo whose .prow.yaml should be checked.")
 	flag.StringVar(&o.prowYAMLPath, "prow-yaml-path", "", "Path to the .prow.yaml file to check. Requires --prow-yaml-repo-name to be set. Defaults to `/home/prow/go/src/github.com/<< prow-yaml-repo-name >>/.prow.yaml`")
 	flag.Var(&o.warnings, "warnings", "Warnings to validate. Use repeatedly to provide a list of warnings")
@@ -222,6 +226,15 @@ func main() {
 		pcfg = pluginAgent.Config()
 	}
 
+	var giteePluginAgent *giteeplugins.ConfigAgent
+	if o.giteePluginConfig != "" {
+		agent, helpProviders := newGiteePluginAgent()
+		if err := agent.Load(o.giteePluginConfig, true, helpProviders); err != nil {
+			logrus.WithError(err).Fatal("Error loading Gitee plugin config.")
+		}
+		giteePluginAgent = agent
+	}
+
 	// the following checks are useful in finding user errors but their
 	// presence won't lead to strictly incorrect behavior, so we can
 	// detect them here but don't necessarily want to stop config re-load
@@ -312,6 +325,11 @@ func main() {
 			errs = append(errs, err)
 		}
 	}
+	if giteePluginAgent != nil && o.warningEnabled(unknownFieldsWarning) {
+		if err := giteePluginAgent.CheckUnknownFields(o.giteePluginConfig); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if o.warningEnabled(tideStrictBranchWarning) {
 		if err := validateStrictBranches(cfg.ProwConfig); err != nil {
 			errs = append(errs, err)
@@ -36,6 +36,7 @@ import (
 	gcsreporter "k8s.io/test-infra/prow/crier/reporters/gcs"
 	k8sgcsreporter "k8s.io/test-infra/prow/crier/reporters/gcs/kubernetes"
 	gerritreporter "k8s.io/test-infra/prow/crier/reporters/gerrit"
+	giteereporter "k8s.io/test-infra/prow/crier/reporters/gitee"
 	githubreporter "k8s.io/test-infra/prow/crier/reporters/github"
 	pubsubreporter "k8s.io/test-infra/prow/crier/reporters/pubsub"
 	slackreporter "k8s.io/test-infra/prow/crier/reporters/slack"
@@ -58,16 +59,19 @@ type options struct {
 	cookiefilePath string
 	gerritProjects gerritclient.ProjectsFlag
 	github         prowflagutil.GitHubOptions
+	gitee          prowflagutil.GiteeOptions
 
 	configPath    string
 	jobConfigPath string
 
-	gerritWorkers int
-	pubsubWorkers int
-	githubWorkers int
-	slackWorkers  int
-	gcsWorkers    int
-	k8sGCSWorkers int
+	gerritWorkers           int
+	gerritMaxInlineComments int
+	pubsubWorkers           int
+	githubWorkers           int
+	giteeWorkers            int
+	slackWorkers            int
+	gcsWorkers              int
+	k8sGCSWorkers           int
 
 	slackTokenFile string
 
@@ -91,7 +95,7 @@ func (o *options) validate() error {
 		o.gerritWorkers = 1
 	}
 
-	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.slackWorkers+o.gcsWorkers+o.k8sGCSWorkers <= 0 {
+	if o.gerritWorkers+o.pubsubWorkers+o.githubWorkers+o.giteeWorkers+o.slackWorkers+o.gcsWorkers+o.k8sGCSWorkers <= 0 {
 		return errors.New("crier need to have at least one report worker to start")
 	}
 
@@ -109,12 +113,22 @@ func (o *options) validate() error {
 		}
 	}
 
+	if o.gerritMaxInlineComments < 0 {
+		return errors.New("--gerrit-max-inline-comments must not be negative")
+	}
+
 	if o.githubWorkers > 0 {
 		if err := o.github.Validate(o.dryrun); err != nil {
 			return err
 		}
 	}
 
+	if o.giteeWorkers > 0 {
+		if err := o.gitee.Validate(o.dryrun); err != nil {
+			return err
+		}
+	}
+
 	if o.slackWorkers > 0 {
 		if o.slackTokenFile == "" {
 			return errors.New("--slack-token-file must be set")
@@ -135,23 +149,26 @@ func (o *options) parseArgs(fs *flag.FlagSet, args []string) error {
 	fs.StringVar(&o.cookiefilePath, "cookiefile", "", "Path to git http.cookiefile, leave empty for anonymous")
 	fs.Var(&o.gerritProjects, "gerrit-projects", "Set of gerrit repos to monitor on a host example: --gerrit-host=https://android.googlesource.com=platform/build,toolchain/llvm, repeat flag for each host")
 	fs.IntVar(&o.gerritWorkers, "gerrit-workers", 0, "Number of gerrit report workers (0 means disabled)")
+	fs.IntVar(&o.gerritMaxInlineComments, "gerrit-max-inline-comments", 0, "Maximum number of inline comments posted by the gerrit reporter from the JUnit failures of a revision (0 means disabled)")
 	fs.IntVar(&o.pubsubWorkers, "pubsub-workers", 0, "Number of pubsub report workers (0 means disabled)")
 	fs.IntVar(&o.githubWorkers, "github-workers", 0, "Number of github report workers (0 means disabled)")
+	fs.IntVar(&o.giteeWorkers, "gitee-workers", 0, "Number of gitee report workers (0 means disabled)")
 	fs.IntVar(&o.slackWorkers, "slack-workers", 0, "Number of Slack report workers (0 means disabled)")
 	fs.IntVar(&o.gcsWorkers, "gcs-workers", 0, "Number of GCS report workers (0 means disabled)")
 	fs.IntVar(&o.k8sGCSWorkers, "kubernetes-gcs-workers", 0, "Number of Kubernetes-specific GCS report workers (0 means disabled)")
 	fs.Float64Var(&o.k8sReportFraction, "kubernetes-report-fraction", 1.0, "Approximate portion of jobs to report pod information for, if kubernetes-gcs-workers are enabled (0 - > none, 1.0 -> all)")
-	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "Location of the GCS credentials file, if gcs-workers is non-zero")
+	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "Location of the GCS credentials file, if gcs-workers or gerrit-max-inline-comments is non-zero")
 	fs.StringVar(&o.slackTokenFile, "slack-token-file", "", "Path to a Slack token file")
-	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report specified agent - empty means report to all agents (effective for github and Slack only)")
+	fs.StringVar(&o.reportAgent, "report-agent", "", "Only report specified agent - empty means report to all agents (effective for github, gitee and Slack only)")
 
 	fs.StringVar(&o.configPath, "config-path", "", "Path to config.yaml.")
 	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to prow job configs.")
 
 	// TODO(krzyzacy): implement dryrun for gerrit/pubsub
-	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github and Slack only)")
+	fs.BoolVar(&o.dryrun, "dry-run", false, "Run in dry-run mode, not doing actual report (effective for github, gitee and Slack only)")
 
 	o.github.AddFlags(fs)
+	o.gitee.AddFlags(fs)
 	o.client.AddFlags(fs)
 
 	fs.Parse(args)
@@ -169,6 +186,15 @@ func parseOptions() options {
 	return o
 }
 
+// storageClient returns a GCS client using --gcs-credentials-file if set.
+func (o *options) storageClient() (*storage.Client, error) {
+	var opts []option.ClientOption
+	if o.gcsCredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(o.gcsCredentialsFile))
+	}
+	return storage.NewClient(context.Background(), opts...)
+}
+
 func main() {
 	logrusutil.ComponentInit()
 
@@ -216,7 +242,14 @@ func main() {
 
 	if o.gerritWorkers > 0 {
 		informer := prowjobInformerFactory.Prow().V1().ProwJobs()
-		gerritReporter, err := gerritreporter.NewReporter(o.cookiefilePath, o.gerritProjects, informer.Lister())
+		var s *storage.Client
+		if o.gerritMaxInlineComments > 0 {
+			s, err = o.storageClient()
+			if err != nil {
+				logrus.WithError(err).Fatal("Error creating storage client for gerrit workers.")
+			}
+		}
+		gerritReporter, err := gerritreporter.NewReporter(o.cookiefilePath, o.gerritProjects, informer.Lister(), s, o.gerritMaxInlineComments)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error starting gerrit reporter")
 		}
@@ -267,14 +300,32 @@ func main() {
 				o.githubWorkers))
 	}
 
-	if o.gcsWorkers > 0 || o.k8sGCSWorkers > 0 {
-		var s *storage.Client
-		var opts []option.ClientOption
-		if o.gcsCredentialsFile != "" {
-			opts = append(opts, option.WithCredentialsFile(o.gcsCredentialsFile))
+	if o.giteeWorkers > 0 {
+		secretAgent := &secret.Agent{}
+		if o.gitee.TokenPath != "" {
+			if err := secretAgent.Start([]string{o.gitee.TokenPath}); err != nil {
+				logrus.WithError(err).Fatal("Error starting secrets agent")
+			}
 		}
-		s, err = storage.NewClient(context.Background(), opts...)
 
+		giteeClient, err := o.gitee.GiteeClient(secretAgent, o.dryrun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error getting Gitee client.")
+		}
+
+		giteeReporter := giteereporter.NewReporter(giteeClient, cfg, v1.ProwJobAgent(o.reportAgent))
+		controllers = append(
+			controllers,
+			crier.NewController(
+				prowjobClientset,
+				kube.RateLimiter(giteeReporter.GetName()),
+				prowjobInformerFactory.Prow().V1().ProwJobs(),
+				giteeReporter,
+				o.giteeWorkers))
+	}
+
+	if o.gcsWorkers > 0 || o.k8sGCSWorkers > 0 {
+		s, err := o.storageClient()
 		if err != nil {
 			logrus.WithError(err).Fatal("Error creating storage client for gcs workers.")
 		}
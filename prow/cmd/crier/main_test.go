@@ -29,6 +29,9 @@ func TestOptions(t *testing.T) {
 	var defaultGitHubOptions flagutil.GitHubOptions
 	defaultGitHubOptions.AddFlags(flag.NewFlagSet("", flag.ContinueOnError))
 
+	var defaultGiteeOptions flagutil.GiteeOptions
+	defaultGiteeOptions.AddFlags(flag.NewFlagSet("", flag.ContinueOnError))
+
 	defaultGerritProjects := make(map[string][]string, 0)
 
 	cases := []struct {
@@ -57,6 +60,7 @@ func TestOptions(t *testing.T) {
 				},
 				configPath:        "foo",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				k8sReportFraction: 1.0,
 			},
 		},
@@ -74,9 +78,29 @@ func TestOptions(t *testing.T) {
 				},
 				configPath:        "foo",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				k8sReportFraction: 1.0,
 			},
 		},
+		{
+			name: "gerrit with inline comments",
+			args: []string{"--gerrit-workers=1", "--gerrit-projects=foo=bar", "--gerrit-max-inline-comments=20", "--config-path=foo"},
+			expected: &options{
+				gerritWorkers:           1,
+				gerritMaxInlineComments: 20,
+				gerritProjects: map[string][]string{
+					"foo": {"bar"},
+				},
+				configPath:        "foo",
+				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
+				k8sReportFraction: 1.0,
+			},
+		},
+		{
+			name: "gerrit with negative inline comments, reject",
+			args: []string{"--gerrit-workers=1", "--gerrit-projects=foo=bar", "--gerrit-max-inline-comments=-1", "--config-path=foo"},
+		},
 		//PubSub Reporter
 		{
 			name: "pubsub workers, sets workers",
@@ -85,6 +109,7 @@ func TestOptions(t *testing.T) {
 				pubsubWorkers:     7,
 				configPath:        "baz",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				gerritProjects:    defaultGerritProjects,
 				k8sReportFraction: 1.0,
 			},
@@ -102,6 +127,7 @@ func TestOptions(t *testing.T) {
 				slackTokenFile:    "/bar/baz",
 				configPath:        "foo",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				gerritProjects:    defaultGerritProjects,
 				k8sReportFraction: 1.0,
 			},
@@ -122,6 +148,7 @@ func TestOptions(t *testing.T) {
 					DeckURI: "http://www.example.com",
 				},
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				gerritProjects:    defaultGerritProjects,
 				k8sReportFraction: 1.0,
 			},
@@ -137,6 +164,7 @@ func TestOptions(t *testing.T) {
 				k8sGCSWorkers:     3,
 				configPath:        "foo",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				gerritProjects:    defaultGerritProjects,
 				k8sReportFraction: 1.0,
 			},
@@ -148,6 +176,7 @@ func TestOptions(t *testing.T) {
 				k8sGCSWorkers:     3,
 				configPath:        "foo",
 				github:            defaultGitHubOptions,
+				gitee:             defaultGiteeOptions,
 				gerritProjects:    defaultGerritProjects,
 				k8sReportFraction: 0.5,
 			},
@@ -60,6 +60,8 @@ import (
 	"k8s.io/test-infra/prow/deck/jobs"
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/giteeoauth"
 	prowgithub "k8s.io/test-infra/prow/github"
 	"k8s.io/test-infra/prow/githuboauth"
 	"k8s.io/test-infra/prow/interrupts"
@@ -103,10 +105,13 @@ type options struct {
 	buildCluster          string
 	kubernetes            prowflagutil.KubernetesOptions
 	github                prowflagutil.GitHubOptions
+	gitee                 prowflagutil.GiteeOptions
 	tideURL               string
 	hookURL               string
+	giteeHookURL          string
 	oauthURL              string
 	githubOAuthConfigFile string
+	giteeOAuthConfigFile  string
 	cookieSecretFile      string
 	redirectHTTPTo        string
 	hiddenOnly            bool
@@ -132,6 +137,9 @@ func (o *options) Validate() error {
 	if err := o.github.Validate(o.dryRun); err != nil {
 		return err
 	}
+	if err := o.gitee.Validate(o.dryRun); err != nil {
+		return err
+	}
 
 	if o.configPath == "" {
 		return errors.New("required flag --config-path was unset")
@@ -156,6 +164,9 @@ func (o *options) Validate() error {
 			return errors.New("an OAuth URL was provided but required flag --cookie-secret was unset")
 		}
 	}
+	if o.giteeOAuthConfigFile != "" && o.cookieSecretFile == "" {
+		return errors.New("--gitee-oauth-config-file was provided but required flag --cookie-secret was unset")
+	}
 
 	if o.hiddenOnly && o.showHidden {
 		return errors.New("'--hidden-only' and '--show-hidden' are mutually exclusive, the first one shows only hidden job, the second one shows both hidden and non-hidden jobs")
@@ -173,8 +184,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to prow job configs.")
 	fs.StringVar(&o.tideURL, "tide-url", "", "Path to tide. If empty, do not serve tide data.")
 	fs.StringVar(&o.hookURL, "hook-url", "", "Path to hook plugin help endpoint.")
+	fs.StringVar(&o.giteeHookURL, "gitee-hook-url", "", "Path to gitee-hook plugin help endpoint. Its help is merged with the help of hook if both are set.")
 	fs.StringVar(&o.oauthURL, "oauth-url", "", "Path to deck user dashboard endpoint.")
 	fs.StringVar(&o.githubOAuthConfigFile, "github-oauth-config-file", "/etc/github/secret", "Path to the file containing the GitHub App Client secret.")
+	fs.StringVar(&o.giteeOAuthConfigFile, "gitee-oauth-config-file", "", "Path to the file containing the Gitee OAuth application secret. If set, users can log in with Gitee to rerun jobs and see their PRs.")
 	fs.StringVar(&o.cookieSecretFile, "cookie-secret", "", "Path to the file containing the cookie secret key.")
 	// use when behind a load balancer
 	fs.StringVar(&o.redirectHTTPTo, "redirect-http-to", "", "Host to redirect http->https to based on x-forwarded-proto == http.")
@@ -195,6 +208,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.pluginConfig, "plugin-config", "", "Path to plugin config file, probably /etc/plugins/plugins.yaml")
 	o.kubernetes.AddFlags(fs)
 	o.github.AddFlagsWithoutDefaultGitHubTokenPath(fs)
+	o.gitee.AddFlagsWithoutDefaultGiteeTokenPath(fs)
 	fs.Parse(args)
 	o.configPath = config.ConfigPath(o.configPath)
 	return o
@@ -223,6 +237,8 @@ var simplifier = simplifypath.NewSimplifier(l("", // shadow element mimicing the
 	l("config"),
 	l("data.js"),
 	l("favicon.ico"),
+	l("gitee-login",
+		l("redirect")),
 	l("github-login",
 		l("redirect")),
 	l("job-history",
@@ -551,10 +567,18 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 	var githubClient deckGitHubClient
 	var gitClient git.ClientFactory
 	secretAgent := &secret.Agent{}
-	if o.github.TokenPath != "" {
-		if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+	var tokenPaths []string
+	for _, path := range []string{o.github.TokenPath, o.gitee.TokenPath} {
+		if path != "" {
+			tokenPaths = append(tokenPaths, path)
+		}
+	}
+	if len(tokenPaths) > 0 {
+		if err := secretAgent.Start(tokenPaths); err != nil {
 			logrus.WithError(err).Fatal("Error starting secrets agent.")
 		}
+	}
+	if o.github.TokenPath != "" {
 		githubClient, err = o.github.GitHubClient(secretAgent, o.dryRun)
 		if err != nil {
 			logrus.WithError(err).Fatal("Error getting GitHub client.")
@@ -574,9 +598,15 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 		initSpyglass(cfg, o, mux, ja, githubClient, gitClient)
 	}
 
-	if o.hookURL != "" {
+	var hookURLs []string
+	for _, u := range []string{o.hookURL, o.giteeHookURL} {
+		if u != "" {
+			hookURLs = append(hookURLs, u)
+		}
+	}
+	if len(hookURLs) > 0 {
 		mux.Handle("/plugin-help.js",
-			gziphandler.GzipHandler(handlePluginHelp(newHelpAgent(o.hookURL), logrus.WithField("handler", "/plugin-help.js"))))
+			gziphandler.GzipHandler(handlePluginHelp(newHelpAgent(hookURLs...), logrus.WithField("handler", "/plugin-help.js"))))
 	}
 
 	if o.tideURL != "" {
@@ -599,34 +629,10 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 
 	// Enable Git OAuth feature if oauthURL is provided.
 	var goa *githuboauth.Agent
+	var prStatusHandler http.HandlerFunc
 	if o.oauthURL != "" {
-		githubOAuthConfigRaw, err := loadToken(o.githubOAuthConfigFile)
-		if err != nil {
-			logrus.WithError(err).Fatal("Could not read github oauth config file.")
-		}
-
-		cookieSecretRaw, err := loadToken(o.cookieSecretFile)
-		if err != nil {
-			logrus.WithError(err).Fatal("Could not read cookie secret file.")
-		}
-
-		var githubOAuthConfig githuboauth.Config
-		if err := yaml.Unmarshal(githubOAuthConfigRaw, &githubOAuthConfig); err != nil {
-			logrus.WithError(err).Fatal("Error unmarshalling github oauth config")
-		}
-		if !isValidatedGitOAuthConfig(&githubOAuthConfig) {
-			logrus.Fatal("Error invalid github oauth config")
-		}
-
-		decodedSecret, err := base64.StdEncoding.DecodeString(string(cookieSecretRaw))
-		if err != nil {
-			logrus.WithError(err).Fatal("Error decoding cookie secret")
-		}
-		if len(decodedSecret) == 0 {
-			logrus.Fatal("Cookie secret should not be empty")
-		}
-		cookie := sessions.NewCookieStore(decodedSecret)
-		githubOAuthConfig.InitGitHubOAuthConfig(cookie)
+		githubOAuthConfig := loadOAuthConfig(o.githubOAuthConfigFile, "github")
+		githubOAuthConfig.InitGitHubOAuthConfig(loadCookieStore(o.cookieSecretFile))
 
 		goa = githuboauth.NewAgent(&githubOAuthConfig, logrus.WithField("client", "githuboauth"))
 		oauthClient := githuboauth.NewClient(&oauth2.Config{
@@ -650,15 +656,59 @@ func prodOnlyMain(cfg config.Getter, pluginAgent *plugins.ConfigAgent, authCfgGe
 
 		secure := !o.allowInsecure
 
-		mux.Handle("/pr-data.js", handleNotCached(
-			prStatusAgent.HandlePrStatus(prStatusAgent)))
+		prStatusHandler = prStatusAgent.HandlePrStatus(prStatusAgent)
 		// Handles login request.
 		mux.Handle("/github-login", goa.HandleLogin(oauthClient, secure))
 		// Handles redirect from GitHub OAuth server.
 		mux.Handle("/github-login/redirect", goa.HandleRedirect(oauthClient, githuboauth.NewAuthenticatedUserIdentifier(&o.github), secure))
 	}
 
-	mux.Handle("/rerun", gziphandler.GzipHandler(handleRerun(prowJobClient, o.rerunCreatesJob, authCfgGetter, goa, githuboauth.NewAuthenticatedUserIdentifier(&o.github), githubClient, pluginAgent, logrus.WithField("handler", "/rerun"))))
+	// Enable Gitee OAuth feature if its config is provided.
+	var giteeAuth *giteeRerunAuth
+	if o.giteeOAuthConfigFile != "" {
+		giteeOAuthConfig := loadOAuthConfig(o.giteeOAuthConfigFile, "gitee")
+		giteeOAuthConfig.InitGitHubOAuthConfig(loadCookieStore(o.cookieSecretFile))
+
+		giteeAuth = &giteeRerunAuth{
+			agent:      giteeoauth.NewAgent(&giteeOAuthConfig, logrus.WithField("client", "giteeoauth")),
+			identifier: giteeoauth.NewAuthenticatedUserIdentifier(&o.gitee),
+		}
+		// We use the Gitee client to resolve Gitee orgs when determining who is permitted to rerun a job.
+		if o.gitee.TokenPath != "" {
+			giteeClient, err := o.gitee.GiteeClient(secretAgent, o.dryRun)
+			if err != nil {
+				logrus.WithError(err).Fatal("Error getting Gitee client.")
+			}
+			giteeAuth.client = giteeClient
+		}
+		oauthClient := githuboauth.NewClient(&oauth2.Config{
+			ClientID:     giteeOAuthConfig.ClientID,
+			ClientSecret: giteeOAuthConfig.ClientSecret,
+			RedirectURL:  giteeOAuthConfig.RedirectURL,
+			Scopes:       giteeOAuthConfig.Scopes,
+			Endpoint:     giteeoauth.Endpoint,
+		})
+
+		giteePRStatusAgent := prstatus.NewGiteeDashboardAgent(
+			func() []string { return giteeRepos(cfg(), ja.ProwJobs()) },
+			&giteeOAuthConfig,
+			&o.gitee,
+			logrus.WithField("client", "gitee-pr-status"))
+
+		secure := !o.allowInsecure
+
+		prStatusHandler = handleGiteePrStatus(prStatusHandler, giteePRStatusAgent)
+		// Handles login request.
+		mux.Handle("/gitee-login", giteeAuth.agent.HandleLogin(oauthClient, secure))
+		// Handles redirect from Gitee OAuth server.
+		mux.Handle("/gitee-login/redirect", giteeAuth.agent.HandleRedirect(oauthClient, giteeAuth.identifier, secure))
+	}
+
+	if prStatusHandler != nil {
+		mux.Handle("/pr-data.js", handleNotCached(prStatusHandler))
+	}
+
+	mux.Handle("/rerun", gziphandler.GzipHandler(handleRerun(prowJobClient, o.rerunCreatesJob, authCfgGetter, goa, githuboauth.NewAuthenticatedUserIdentifier(&o.github), githubClient, giteeAuth, pluginAgent, logrus.WithField("handler", "/rerun"))))
 
 	// optionally inject http->https redirect handler when behind loadbalancer
 	if o.redirectHTTPTo != "" {
@@ -1252,6 +1302,8 @@ func handlePluginHelp(ha *helpAgent, log *logrus.Entry) http.HandlerFunc {
 		help, err := ha.getHelp()
 		if err != nil {
 			log.WithError(err).Error("Getting plugin help from hook.")
+		}
+		if help == nil {
 			help = &pluginhelp.Help{}
 		}
 		b, err := json.Marshal(*help)
@@ -1378,10 +1430,29 @@ func canTriggerJob(user string, pj prowapi.ProwJob, cfg *prowapi.RerunAuthConfig
 	return false, nil
 }
 
+// canTriggerJobGitee determines whether the given Gitee user can trigger the job.
+func canTriggerJobGitee(user string, pj prowapi.ProwJob, cfg *prowapi.RerunAuthConfig, cli prowapi.GiteeRerunClient) (bool, error) {
+	// Check config-level rerun auth config.
+	if auth, err := cfg.IsAuthorizedGitee(user, cli); err != nil || auth {
+		return auth, err
+	}
+
+	// Check job-level rerun auth config.
+	return pj.Spec.RerunAuthConfig.IsAuthorizedGitee(user, cli)
+}
+
+// giteeRerunAuth identifies the users logged in with Gitee who rerun jobs.
+type giteeRerunAuth struct {
+	agent      *githuboauth.Agent
+	identifier githuboauth.AuthenticatedUserIdentifier
+	// client resolves the Gitee orgs, it is nil without a Gitee token.
+	client prowapi.GiteeRerunClient
+}
+
 // handleRerun triggers a rerun of the given job if that features is enabled, it receives a
 // POST request, and the user has the necessary permissions. Otherwise, it writes the config
 // for a new job but does not trigger it.
-func handleRerun(prowJobClient prowv1.ProwJobInterface, createProwJob bool, cfg authCfgGetter, goa *githuboauth.Agent, ghc githuboauth.AuthenticatedUserIdentifier, cli prowgithub.RerunClient, pluginAgent *plugins.ConfigAgent, log *logrus.Entry) http.HandlerFunc {
+func handleRerun(prowJobClient prowv1.ProwJobInterface, createProwJob bool, cfg authCfgGetter, goa *githuboauth.Agent, ghc githuboauth.AuthenticatedUserIdentifier, cli prowgithub.RerunClient, gitee *giteeRerunAuth, pluginAgent *plugins.ConfigAgent, log *logrus.Entry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		name := r.URL.Query().Get("prowjob")
 		l := log.WithField("prowjob", name)
@@ -1415,20 +1486,40 @@ func handleRerun(prowJobClient prowv1.ProwJobInterface, createProwJob bool, cfg
 				// jobs so that GH oauth doesn't need to be set up for private Prows.
 				allowed = true
 			} else {
-				if goa == nil {
-					msg := "GitHub oauth must be configured to rerun jobs unless 'allow_anyone: true' is specified."
+				if goa == nil && gitee == nil {
+					msg := "GitHub or Gitee oauth must be configured to rerun jobs unless 'allow_anyone: true' is specified."
 					http.Error(w, msg, http.StatusInternalServerError)
 					l.Error(msg)
 					return
 				}
-				login, err := goa.GetLogin(r, ghc)
+				var login, giteeLogin string
+				err = errors.New("GitHub oauth is not configured")
+				if goa != nil {
+					login, err = goa.GetLogin(r, ghc)
+				}
+				// Users logged in with Gitee only are identified by their Gitee login.
+				if err != nil && gitee != nil {
+					giteeLogin, err = gitee.agent.GetLogin(r, gitee.identifier)
+				}
 				if err != nil {
-					l.WithError(err).Errorf("Error retrieving GitHub login")
-					http.Error(w, "Error retrieving GitHub login", http.StatusUnauthorized)
+					msg := "Error retrieving GitHub login"
+					if gitee != nil {
+						msg = "Error retrieving GitHub or Gitee login"
+						if goa == nil {
+							msg = "Error retrieving Gitee login"
+						}
+					}
+					l.WithError(err).Error(msg)
+					http.Error(w, msg, http.StatusUnauthorized)
 					return
 				}
-				l = l.WithField("user", login)
-				allowed, err = canTriggerJob(login, newPJ, authConfig, cli, pluginAgent, l)
+				if giteeLogin != "" {
+					l = l.WithField("gitee-user", giteeLogin)
+					allowed, err = canTriggerJobGitee(giteeLogin, newPJ, authConfig, gitee.client)
+				} else {
+					l = l.WithField("user", login)
+					allowed, err = canTriggerJob(login, newPJ, authConfig, cli, pluginAgent, l)
+				}
 				if err != nil {
 					http.Error(w, fmt.Sprintf("Error checking if user can trigger job: %v", err), http.StatusInternalServerError)
 					l.WithError(err).Errorf("Error checking if user can trigger job")
@@ -1512,6 +1603,65 @@ func handleFavicon(staticFilesLocation string, cfg config.Getter) http.HandlerFu
 	}
 }
 
+// loadOAuthConfig reads the OAuth config of the provider, github or gitee,
+// and exits if it is invalid.
+func loadOAuthConfig(path, provider string) githuboauth.Config {
+	raw, err := loadToken(path)
+	if err != nil {
+		logrus.WithError(err).Fatalf("Could not read %s oauth config file.", provider)
+	}
+	var oauthConfig githuboauth.Config
+	if err := yaml.Unmarshal(raw, &oauthConfig); err != nil {
+		logrus.WithError(err).Fatalf("Error unmarshalling %s oauth config", provider)
+	}
+	if !isValidatedGitOAuthConfig(&oauthConfig) {
+		logrus.Fatalf("Error invalid %s oauth config", provider)
+	}
+	return oauthConfig
+}
+
+// loadCookieStore returns a cookie store keyed with the cookie secret, and
+// exits if the secret cannot be read.
+func loadCookieStore(path string) *sessions.CookieStore {
+	cookieSecretRaw, err := loadToken(path)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not read cookie secret file.")
+	}
+	decodedSecret, err := base64.StdEncoding.DecodeString(string(cookieSecretRaw))
+	if err != nil {
+		logrus.WithError(err).Fatal("Error decoding cookie secret")
+	}
+	if len(decodedSecret) == 0 {
+		logrus.Fatal("Cookie secret should not be empty")
+	}
+	return sessions.NewCookieStore(decodedSecret)
+}
+
+// giteeRepos returns the configured repos which are hosted on Gitee, as told
+// by their ProwJobs.
+func giteeRepos(cfg *config.Config, pjs []prowapi.ProwJob) []string {
+	repos := sets.NewString()
+	for i := range pjs {
+		if gitee.IsGiteeJob(&pjs[i]) {
+			repos.Insert(pjs[i].Spec.Refs.Org + "/" + pjs[i].Spec.Refs.Repo)
+		}
+	}
+	return repos.Intersection(cfg.AllRepos).List()
+}
+
+// handleGiteePrStatus serves the PRs of the users logged in with Gitee, and
+// of the users logged in with GitHub if it is configured too.
+func handleGiteePrStatus(github http.HandlerFunc, agent *prstatus.GiteeDashboardAgent) http.HandlerFunc {
+	gitee := agent.HandlePrStatus()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if github == nil || agent.HasSession(r) {
+			gitee(w, r)
+			return
+		}
+		github(w, r)
+	}
+}
+
 func isValidatedGitOAuthConfig(githubOAuthConfig *githuboauth.Config) bool {
 	return githubOAuthConfig.ClientID != "" && githubOAuthConfig.ClientSecret != "" &&
 		githubOAuthConfig.RedirectURL != ""
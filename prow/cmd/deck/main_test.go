@@ -38,6 +38,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 
+	"k8s.io/test-infra/prow/giteeoauth"
 	"k8s.io/test-infra/prow/github/fakegithub"
 	"k8s.io/test-infra/prow/githuboauth"
 	"k8s.io/test-infra/prow/plugins"
@@ -430,7 +431,7 @@ func TestRerun(t *testing.T) {
 			ghc := &fakeAuthenticatedUserIdentifier{login: tc.login}
 			rc := &fakegithub.FakeClient{OrgMembers: map[string][]string{"org": {"org-member"}}}
 			pca := plugins.NewFakeConfigAgent()
-			handler := handleRerun(fakeProwJobClient.ProwV1().ProwJobs("prowjobs"), tc.rerunCreatesJob, authCfgGetter, goa, ghc, rc, &pca, logrus.WithField("handler", "/rerun"))
+			handler := handleRerun(fakeProwJobClient.ProwV1().ProwJobs("prowjobs"), tc.rerunCreatesJob, authCfgGetter, goa, ghc, rc, nil, &pca, logrus.WithField("handler", "/rerun"))
 			handler.ServeHTTP(rr, req)
 			if rr.Code != tc.httpCode {
 				t.Fatalf("Bad error code: %d", rr.Code)
@@ -467,6 +468,145 @@ func TestRerun(t *testing.T) {
 	}
 }
 
+type fakeGiteeRerunClient map[string][]string
+
+func (c fakeGiteeRerunClient) IsMember(org, login string) (bool, error) {
+	return sets.NewString(c[org]...).Has(login), nil
+}
+
+func TestRerunGitee(t *testing.T) {
+	testCases := []struct {
+		name                string
+		login               string
+		githubLogin         bool
+		noGitee             bool
+		shouldCreateProwJob bool
+		httpCode            int
+	}{
+		{
+			name:                "Gitee user permitted by the config",
+			login:               "gitee-user",
+			shouldCreateProwJob: true,
+			httpCode:            http.StatusOK,
+		},
+		{
+			name:                "Gitee org member permitted on specific job",
+			login:               "gitee-org-member",
+			shouldCreateProwJob: true,
+			httpCode:            http.StatusOK,
+		},
+		{
+			name:     "GitHub user is not a Gitee user",
+			login:    "github-user",
+			httpCode: http.StatusOK,
+		},
+		{
+			name:                "GitHub login is preferred",
+			login:               "github-user",
+			githubLogin:         true,
+			shouldCreateProwJob: true,
+			httpCode:            http.StatusOK,
+		},
+		{
+			name:     "Gitee oauth is not configured",
+			login:    "gitee-user",
+			noGitee:  true,
+			httpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeProwJobClient := fake.NewSimpleClientset(&prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "wowsuch",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					Job:  "whoa",
+					Type: prowapi.PeriodicJob,
+					RerunAuthConfig: &prowapi.RerunAuthConfig{
+						GiteeOrgs: []string{"gitee-org"},
+					},
+				},
+			})
+			authCfgGetter := func(refs *prowapi.Refs) *prowapi.RerunAuthConfig {
+				return &prowapi.RerunAuthConfig{
+					GitHubUsers: []string{"github-user"},
+					GiteeUsers:  []string{"gitee-user"},
+				}
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/rerun?prowjob=wowsuch", nil)
+			if err != nil {
+				t.Fatalf("Error making request: %v", err)
+			}
+			mockCookieStore := sessions.NewCookieStore([]byte("secret-key"))
+			sessionName := "gitee-access-token-session"
+			if tc.githubLogin {
+				sessionName = "access-token-session"
+			}
+			session, err := sessions.GetRegistry(req).Get(mockCookieStore, sessionName)
+			if err != nil {
+				t.Fatalf("Error making access token session: %v", err)
+			}
+			session.Values["access-token"] = &oauth2.Token{AccessToken: "validtoken"}
+
+			rr := httptest.NewRecorder()
+			mockConfig := &githuboauth.Config{
+				CookieStore: mockCookieStore,
+			}
+			goa := githuboauth.NewAgent(mockConfig, &logrus.Entry{})
+			ghc := &fakeAuthenticatedUserIdentifier{login: tc.login}
+			var gitee *giteeRerunAuth
+			if !tc.noGitee {
+				gitee = &giteeRerunAuth{
+					agent:      giteeoauth.NewAgent(mockConfig, &logrus.Entry{}),
+					identifier: &fakeAuthenticatedUserIdentifier{login: tc.login},
+					client:     fakeGiteeRerunClient{"gitee-org": {"gitee-org-member"}},
+				}
+			}
+			handler := handleRerun(fakeProwJobClient.ProwV1().ProwJobs("prowjobs"), true, authCfgGetter, goa, ghc, nil, gitee, nil, logrus.WithField("handler", "/rerun"))
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tc.httpCode {
+				t.Fatalf("Bad error code: %d", rr.Code)
+			}
+
+			pjs, err := fakeProwJobClient.ProwV1().ProwJobs("prowjobs").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed to list prowjobs: %v", err)
+			}
+			expected := 1
+			if tc.shouldCreateProwJob {
+				expected = 2
+			}
+			if numPJs := len(pjs.Items); numPJs != expected {
+				t.Errorf("expected to get %d prowjobs, got %d", expected, numPJs)
+			}
+		})
+	}
+}
+
+func TestGiteeRepos(t *testing.T) {
+	job := func(org, repo, link string) prowapi.ProwJob {
+		return prowapi.ProwJob{Spec: prowapi.ProwJobSpec{
+			Refs: &prowapi.Refs{Org: org, Repo: repo, RepoLink: link},
+		}}
+	}
+	pjs := []prowapi.ProwJob{
+		job("org", "gitee", "https://gitee.com/org/gitee"),
+		job("org", "gitee", "https://gitee.com/org/gitee"),
+		job("org", "github", "https://github.com/org/github"),
+		job("org", "removed", "https://gitee.com/org/removed"),
+		{Spec: prowapi.ProwJobSpec{Type: prowapi.PeriodicJob}},
+	}
+	cfg := &config.Config{JobConfig: config.JobConfig{AllRepos: sets.NewString("org/gitee", "org/github")}}
+
+	if repos := giteeRepos(cfg, pjs); !reflect.DeepEqual(repos, []string{"org/gitee"}) {
+		t.Errorf("expected the Gitee repos [org/gitee], got %v", repos)
+	}
+}
+
 func TestTide(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		pools := []tide.Pool{
@@ -611,7 +751,7 @@ func TestHelp(t *testing.T) {
 		fmt.Fprintf(w, string(b))
 	}))
 	ha := &helpAgent{
-		path: s.URL,
+		paths: []string{s.URL},
 	}
 	handler := handlePluginHelp(ha, logrus.WithField("handler", "/plugin-help.js"))
 	handleAndCheck := func() {
@@ -645,6 +785,74 @@ func TestHelp(t *testing.T) {
 	handleAndCheck()
 }
 
+func TestHelpMergesHooks(t *testing.T) {
+	serve := func(help pluginhelp.Help) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := json.Marshal(help)
+			if err != nil {
+				t.Fatalf("Marshaling: %v", err)
+			}
+			fmt.Fprint(w, string(b))
+		}))
+	}
+	hook := serve(pluginhelp.Help{
+		AllRepos:    []string{"org/repo"},
+		RepoPlugins: map[string][]string{"": {"lgtm", "size"}, "org": {"lgtm", "size"}},
+		PluginHelp: map[string]pluginhelp.PluginHelp{
+			"lgtm": {Description: "lgtm", Config: map[string]string{"org/repo": "github"}, Events: []string{"issue_comment"}},
+			"size": {Description: "size"},
+		},
+	})
+	defer hook.Close()
+	giteeHook := serve(pluginhelp.Help{
+		AllRepos:    []string{"openeuler/community"},
+		RepoPlugins: map[string][]string{"": {"lgtm", "wip"}, "openeuler": {"lgtm", "wip"}},
+		PluginHelp: map[string]pluginhelp.PluginHelp{
+			"lgtm": {Description: "lgtm", Config: map[string]string{"openeuler/community": "gitee"}, Events: []string{"Note Hook"}},
+			"wip":  {Description: "wip"},
+		},
+	})
+	defer giteeHook.Close()
+	down := serve(pluginhelp.Help{})
+	down.Close()
+
+	expected := &pluginhelp.Help{
+		AllRepos: []string{"openeuler/community", "org/repo"},
+		RepoPlugins: map[string][]string{
+			"":          {"lgtm", "size", "wip"},
+			"org":       {"lgtm", "size"},
+			"openeuler": {"lgtm", "wip"},
+		},
+		RepoExternalPlugins: map[string][]string{},
+		PluginHelp: map[string]pluginhelp.PluginHelp{
+			"lgtm": {
+				Description: "lgtm",
+				Config:      map[string]string{"org/repo": "github", "openeuler/community": "gitee"},
+				Events:      []string{"issue_comment", "Note Hook"},
+			},
+			"size": {Description: "size"},
+			"wip":  {Description: "wip"},
+		},
+		ExternalPluginHelp: map[string]pluginhelp.PluginHelp{},
+	}
+
+	help, err := newHelpAgent(hook.URL, giteeHook.URL).getHelp()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(help, expected) {
+		t.Errorf("Invalid merged plugin help. Got %v, expected %v", help, expected)
+	}
+
+	help, err = newHelpAgent(hook.URL, down.URL).getHelp()
+	if err == nil {
+		t.Error("expected an error when a hook is down")
+	}
+	if help == nil || len(help.PluginHelp) != 2 {
+		t.Errorf("expected the help of the hook which is up, got %v", help)
+	}
+}
+
 func TestListProwJobs(t *testing.T) {
 	templateJob := &prowapi.ProwJob{
 		ObjectMeta: metav1.ObjectMeta{
@@ -954,6 +1162,8 @@ func Test_gatherOptions(t *testing.T) {
 		fs := flag.NewFlagSet("fake-flags", flag.PanicOnError)
 		ghoptions := flagutil.GitHubOptions{}
 		ghoptions.AddFlagsWithoutDefaultGitHubTokenPath(fs)
+		giteeoptions := flagutil.GiteeOptions{}
+		giteeoptions.AddFlagsWithoutDefaultGiteeTokenPath(fs)
 		t.Run(tc.name, func(t *testing.T) {
 			expected := &options{
 				configPath:            "yo",
@@ -964,6 +1174,7 @@ func Test_gatherOptions(t *testing.T) {
 				spyglassFilesLocation: "/lenses",
 				kubernetes:            flagutil.KubernetesOptions{},
 				github:                ghoptions,
+				gitee:                 giteeoptions,
 			}
 			if tc.expected != nil {
 				tc.expected(expected)
@@ -23,6 +23,8 @@ import (
 	"sync"
 	"time"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/pluginhelp"
 )
 
@@ -31,16 +33,18 @@ import (
 const cacheLife = time.Minute
 
 type helpAgent struct {
-	path string
+	// paths are the plugin help endpoints of the hook deployments, e.g.
+	// hook and gitee-hook. Their help is merged.
+	paths []string
 
 	sync.Mutex
 	help   *pluginhelp.Help
 	expiry time.Time
 }
 
-func newHelpAgent(path string) *helpAgent {
+func newHelpAgent(paths ...string) *helpAgent {
 	return &helpAgent{
-		path: path,
+		paths: paths,
 	}
 }
 
@@ -51,20 +55,107 @@ func (ha *helpAgent) getHelp() (*pluginhelp.Help, error) {
 		return ha.help, nil
 	}
 
+	var helps []pluginhelp.Help
+	var errs []error
+	for _, path := range ha.paths {
+		help, err := fetchHelp(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		helps = append(helps, *help)
+	}
+	if len(helps) == 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	help := mergeHelp(helps)
+	if len(errs) > 0 {
+		// Serve what we have but don't cache it, the failed hook may be back soon.
+		return help, utilerrors.NewAggregate(errs)
+	}
+	ha.help = help
+	ha.expiry = time.Now().Add(cacheLife)
+	return help, nil
+}
+
+func fetchHelp(path string) (*pluginhelp.Help, error) {
 	var help pluginhelp.Help
-	resp, err := http.Get(ha.path)
+	resp, err := http.Get(path)
 	if err != nil {
 		return nil, fmt.Errorf("error Getting plugin help: %v", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, fmt.Errorf("response has status code %d", resp.StatusCode)
+		return nil, fmt.Errorf("response from %s has status code %d", path, resp.StatusCode)
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&help); err != nil {
 		return nil, fmt.Errorf("error decoding json plugin help: %v", err)
 	}
-
-	ha.help = &help
-	ha.expiry = time.Now().Add(cacheLife)
 	return &help, nil
 }
+
+// mergeHelp merges the help of several hook deployments. A plugin served by
+// more than one of them, e.g. lgtm for GitHub and Gitee repos, gets the
+// configurations of all its repos and the events of all the deployments.
+func mergeHelp(helps []pluginhelp.Help) *pluginhelp.Help {
+	if len(helps) == 1 {
+		return &helps[0]
+	}
+
+	merged := &pluginhelp.Help{
+		RepoPlugins:         map[string][]string{},
+		RepoExternalPlugins: map[string][]string{},
+		PluginHelp:          map[string]pluginhelp.PluginHelp{},
+		ExternalPluginHelp:  map[string]pluginhelp.PluginHelp{},
+	}
+	allRepos := sets.NewString()
+	for _, help := range helps {
+		allRepos.Insert(help.AllRepos...)
+		mergeRepoPlugins(merged.RepoPlugins, help.RepoPlugins)
+		mergeRepoPlugins(merged.RepoExternalPlugins, help.RepoExternalPlugins)
+		mergePluginHelp(merged.PluginHelp, help.PluginHelp)
+		mergePluginHelp(merged.ExternalPluginHelp, help.ExternalPluginHelp)
+	}
+	merged.AllRepos = allRepos.List()
+	return merged
+}
+
+func mergeRepoPlugins(dst, src map[string][]string) {
+	for repo, plugins := range src {
+		existing := sets.NewString(dst[repo]...)
+		for _, p := range plugins {
+			if !existing.Has(p) {
+				existing.Insert(p)
+				dst[repo] = append(dst[repo], p)
+			}
+		}
+	}
+}
+
+func mergePluginHelp(dst, src map[string]pluginhelp.PluginHelp) {
+	for name, help := range src {
+		existing, ok := dst[name]
+		if !ok {
+			dst[name] = help
+			continue
+		}
+		for repo, c := range help.Config {
+			if _, ok := existing.Config[repo]; ok {
+				continue
+			}
+			if existing.Config == nil {
+				existing.Config = map[string]string{}
+			}
+			existing.Config[repo] = c
+		}
+		events := sets.NewString(existing.Events...)
+		for _, e := range help.Events {
+			if !events.Has(e) {
+				events.Insert(e)
+				existing.Events = append(existing.Events, e)
+			}
+		}
+		dst[name] = existing
+	}
+}
@@ -118,6 +118,7 @@ export interface ProwJobStatus {
   description?: string;
   url?: string;
   pod_name?: string;
+  queue_position?: number;
   build_id?: string;
   jenkins_build_id?: string;
   prev_report_states?: { [key: string]: ProwJobState };
@@ -43,6 +43,8 @@ export interface TidePool {
   Org: string;
   Repo: string;
   Branch: string;
+  // RepoLink is only set for repositories which are not on GitHub.
+  RepoLink?: string;
 
   SuccessPRs: PullRequest[];
   PendingPRs: PullRequest[];
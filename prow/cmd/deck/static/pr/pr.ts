@@ -10,6 +10,7 @@ import {relativeURL} from "../common/urls";
 declare const tideData: TideData;
 declare const allBuilds: ProwJobList;
 declare const csrfToken: string;
+declare const loginPath: string;
 
 type UnifiedState = ProwJobState | "expected";
 
@@ -153,7 +154,7 @@ function redraw(prData: UserData): void {
     if (prData && prData.Login) {
         loadPrStatus(prData);
     } else {
-        forceGitHubLogin();
+        forceLogin();
     }
 }
 
@@ -238,7 +239,7 @@ window.onload = () => {
     // Check URL, if the search is empty, adds search query by default format
     // ?is:pr state:open query="author:<user_login>"
     if (window.location.search === "") {
-        const login = getCookieByName("github_login");
+        const login = userLogin();
         const searchQuery = "is:pr state:open author:" + login;
         window.location.search = "?query=" + encodeURIComponent(searchQuery);
     }
@@ -289,7 +290,7 @@ function createSearchCard(): HTMLElement {
     }, true);
     const userBtn = createIcon("person", "Show my open pull requests", ["search-button"], true);
     userBtn.addEventListener("click", () => {
-        const login = getCookieByName("github_login");
+        const login = userLogin();
         const searchQuery = "is:pr state:open author:" + login;
         window.location.search = "?query=" + encodeURIComponent(searchQuery);
     });
@@ -298,7 +299,7 @@ function createSearchCard(): HTMLElement {
     actionCtn.id = "search-action";
     actionCtn.appendChild(userBtn);
     actionCtn.appendChild(refBtn);
-    actionCtn.appendChild(tidehistory.authorIcon(getCookieByName("github_login")));
+    actionCtn.appendChild(tidehistory.authorIcon(userLogin()));
 
     const inputContainer = document.createElement("div");
     inputContainer.id = "search-input-ctn";
@@ -1167,10 +1168,17 @@ function createPRCard(pr: PullRequest, builds: UnifiedContext[] = [], queries: P
 }
 
 /**
- * Redirect to initiate github login flow.
+ * Redirect to initiate github or gitee login flow.
  */
-function forceGitHubLogin(): void {
-    window.location.href = window.location.origin + `/github-login?dest=${relativeURL()}`;
+function forceLogin(): void {
+    window.location.href = window.location.origin + `${loginPath}?dest=${relativeURL()}`;
+}
+
+/**
+ * Returns the login of the user logged in with github or gitee.
+ */
+function userLogin(): string {
+    return getCookieByName("github_login") || getCookieByName("gitee_login");
 }
 
 type VagueState = "succeeded" | "failed" | "pending" | "unknown";
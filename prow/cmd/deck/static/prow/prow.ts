@@ -9,6 +9,7 @@ declare const allBuilds: ProwJobList;
 declare const spyglass: boolean;
 declare const rerunCreatesJob: boolean;
 declare const csrfToken: string;
+declare const loginPath: string;
 
 function genShortRefKey(baseRef: string, pulls: Pull[] = []) {
     return [baseRef, ...pulls.map((p) => p.number)].filter((n) => n).join(",");
@@ -501,7 +502,7 @@ function redraw(fz: FuzzySearch): void {
                 agent = "",
                 refs: {org = "", repo = "", repo_link = "", base_sha = "", base_link = "", pulls = [], base_ref = ""} = {},
             },
-            status: {startTime, completionTime = "", state = "", pod_name, build_id = "", url = ""},
+            status: {startTime, completionTime = "", state = "", pod_name, queue_position = 0, build_id = "", url = ""},
         } = build;
 
         if (!equalSelected(typeSel, type)) {
@@ -568,7 +569,15 @@ function redraw(fz: FuzzySearch): void {
         }
         displayedJob++;
         const r = document.createElement("tr");
-        r.appendChild(cell.state(state));
+        const stateCell = cell.state(state);
+        if (state === "triggered" && queue_position > 0) {
+            stateCell.title += ` (#${queue_position} in the queue)`;
+            const position = document.createElement("span");
+            position.classList.add("queue-position");
+            position.innerText = `#${queue_position}`;
+            stateCell.appendChild(position);
+        }
+        r.appendChild(stateCell);
         if ((agent === "kubernetes" && pod_name) || agent !== "kubernetes") {
             const logIcon = icon.create("description", "Build log");
             logIcon.href = `log?job=${job}&id=${build_id}`;
@@ -715,7 +724,7 @@ function createRerunCell(modal: HTMLElement, rerunElement: HTMLElement, prowjob:
                 });
                 const data = await result.text();
                 if (result.status === 401) {
-                    window.location.href = window.location.origin + `/github-login?dest=${relativeURL({rerun: "gh_redirect"})}`;
+                    window.location.href = window.location.origin + `${loginPath}?dest=${relativeURL({rerun: "gh_redirect"})}`;
                 } else {
                     rerunElement.innerHTML = data;
                 }
@@ -235,9 +235,24 @@ function createHistoryCell(pool: TidePool): HTMLTableDataCellElement {
     return td;
 }
 
+// repoLink returns the link of the pool's repository, which is on GitHub unless
+// the pool says otherwise.
+function repoLink(pool: TidePool): string {
+    return pool.RepoLink || `https://github.com/${pool.Org}/${pool.Repo}`;
+}
+
+// prLink returns the link of a PR in the pool's repository. Gitee serves the
+// PRs under "pulls" rather than "pull".
+function prLink(pool: TidePool, pr: PullRequest): string {
+    if (pool.RepoLink) {
+        return `${pool.RepoLink}/pulls/${pr.Number}`;
+    }
+    return `${repoLink(pool)}/pull/${pr.Number}`;
+}
+
 function createRepoCell(pool: TidePool): HTMLTableDataCellElement {
     const deckLink = `/?repo=` + encodeURIComponent(`${pool.Org}/${pool.Repo}`);
-    const branchLink = `https://github.com/${pool.Org}/${pool.Repo}/tree/${pool.Branch}`;
+    const branchLink = `${repoLink(pool)}/tree/${pool.Branch}`;
     const linksTD = document.createElement("td");
     linksTD.appendChild(createLink(deckLink, `${pool.Org}/${pool.Repo}`));
     linksTD.appendChild(document.createTextNode(" "));
@@ -299,12 +314,12 @@ function createBatchCell(pool: TidePool): HTMLTableDataCellElement {
     return td;
 }
 
-// addPRsToElem adds a space separated list of PR numbers that link to the corresponding PR on github.
+// addPRsToElem adds a space separated list of PR numbers that link to the corresponding PR.
 function addPRsToElem(elem: HTMLElement, pool: TidePool, prs?: PullRequest[]): void {
     if (prs) {
         for (let i = 0; i < prs.length; i++) {
             const a = document.createElement("a");
-            a.href = `https://github.com/${pool.Org}/${pool.Repo}/pull/${prs[i].Number}`;
+            a.href = prLink(pool, prs[i]);
             a.appendChild(document.createTextNode("#" + prs[i].Number));
             a.id = `pr-${pool.Org}-${pool.Repo}-${prs[i].Number}-${nextID()}`;
             if (prs[i].Title) {
@@ -55,12 +55,21 @@ type baseTemplateSections struct {
 func getConcreteSectionFunction(o options) func() baseTemplateSections {
 	return func() baseTemplateSections {
 		return baseTemplateSections{
-			PR:   o.oauthURL != "" || o.pregeneratedData != "",
+			PR:   o.oauthURL != "" || o.giteeOAuthConfigFile != "" || o.pregeneratedData != "",
 			Tide: o.tideURL != "" || o.pregeneratedData != "",
 		}
 	}
 }
 
+// loginPath returns the path logging users in, with Gitee only when deck
+// has no GitHub login.
+func loginPath(o options) string {
+	if o.oauthURL == "" && o.giteeOAuthConfigFile != "" {
+		return "/gitee-login"
+	}
+	return "/github-login"
+}
+
 func prepareBaseTemplate(o options, cfg config.Getter, csrfToken string, t *template.Template) (*template.Template, error) {
 	return t.Funcs(map[string]interface{}{
 		"settings":         makeBaseTemplateSettings,
@@ -73,6 +82,7 @@ func prepareBaseTemplate(o options, cfg config.Getter, csrfToken string, t *temp
 		"deckVersion":      func() string { return version.Version },
 		"googleAnalytics":  func() string { return cfg().Deck.GoogleAnalytics },
 		"csrfToken":        func() string { return csrfToken },
+		"loginPath":        func() string { return loginPath(o) },
 	}).ParseFiles(path.Join(o.templateFilesLocation, "base.html"))
 }
 
@@ -23,7 +23,9 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -36,6 +38,7 @@ import (
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	"k8s.io/test-infra/prow/gerrit/adapter"
 	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/gerrit/tide"
 	"k8s.io/test-infra/prow/interrupts"
 	"k8s.io/test-infra/prow/logrusutil"
 	"k8s.io/test-infra/prow/pjutil"
@@ -49,9 +52,19 @@ type options struct {
 	// lastSyncFallback is the path to sync the latest timestamp
 	// Can be /local/path, gs://path/to/object or s3://path/to/object.
 	lastSyncFallback string
-	dryRun           bool
-	kubernetes       prowflagutil.KubernetesOptions
-	storage          prowflagutil.StorageClientOptions
+	// streamEvents triggers the jobs from the events of the gerrit instances
+	// instead of polling their changes.
+	streamEvents bool
+	// lastEventFallback is the path to sync the time of the last event
+	// processed with --stream-events.
+	lastEventFallback string
+	// submit submits the changes once their required presubmits pass.
+	submit bool
+	// statusPort is the port of the status page of the submitted changes.
+	statusPort int
+	dryRun     bool
+	kubernetes prowflagutil.KubernetesOptions
+	storage    prowflagutil.StorageClientOptions
 }
 
 func (o *options) Validate() error {
@@ -71,6 +84,10 @@ func (o *options) Validate() error {
 		return errors.New("--last-sync-fallback must be set")
 	}
 
+	if o.streamEvents && o.lastEventFallback == "" {
+		return errors.New("--last-event-fallback must be set with --stream-events")
+	}
+
 	if strings.HasPrefix(o.lastSyncFallback, "gs://") && !o.storage.HasGCSCredentials() {
 		logrus.WithField("last-sync-fallback", o.lastSyncFallback).Warn("--gcs-credentials-file unset, will try and access with a default service account")
 	}
@@ -88,6 +105,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.cookiefilePath, "cookiefile", "", "Path to git http.cookiefile, leave empty for anonymous")
 	fs.Var(&o.projects, "gerrit-projects", "Set of gerrit repos to monitor on a host example: --gerrit-host=https://android.googlesource.com=platform/build,toolchain/llvm, repeat fs for each host")
 	fs.StringVar(&o.lastSyncFallback, "last-sync-fallback", "", "The /local/path, gs://path/to/object or s3://path/to/object to sync the latest timestamp")
+	fs.BoolVar(&o.streamEvents, "stream-events", false, "Trigger the jobs from the events-log plugin of the gerrit instances instead of polling their changes, which is only done to catch up after reconnecting")
+	fs.StringVar(&o.lastEventFallback, "last-event-fallback", "", "The /local/path, gs://path/to/object or s3://path/to/object to sync the time of the last event processed with --stream-events")
+	fs.BoolVar(&o.submit, "submit", false, "Submit the changes matching gerrit.submit.query once their required presubmits pass on the tip of their branch")
+	fs.IntVar(&o.statusPort, "status-port", 8888, "Port of the status page of the changes to submit, served with --submit")
 	fs.BoolVar(&o.dryRun, "dry-run", false, "Run in dry-run mode, performing no modifying actions.")
 	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.storage} {
 		group.AddFlags(fs)
@@ -220,6 +241,36 @@ func (st *syncTime) Update(newState client.LastSyncState) error {
 	return nil
 }
 
+// eventTime tracks the time of the last event processed from each gerrit
+// instance, which syncTime persists under lastEventKey.
+type eventTime struct {
+	st *syncTime
+}
+
+const lastEventKey = "events"
+
+func (et *eventTime) init(instances []string) error {
+	hostProjects := client.ProjectsFlag{}
+	for _, instance := range instances {
+		hostProjects[instance] = []string{lastEventKey}
+	}
+	return et.st.init(hostProjects)
+}
+
+func (et *eventTime) Current() map[string]time.Time {
+	result := map[string]time.Time{}
+	for instance, lastEvents := range et.st.Current() {
+		if lastEvent, ok := lastEvents[lastEventKey]; ok {
+			result[instance] = lastEvent
+		}
+	}
+	return result
+}
+
+func (et *eventTime) Update(instance string, lastEvent time.Time) error {
+	return et.st.Update(client.LastSyncState{instance: {lastEventKey: lastEvent}})
+}
+
 func main() {
 	logrusutil.ComponentInit()
 
@@ -261,6 +312,54 @@ func main() {
 		logrus.WithError(err).Fatal("Error creating gerrit client.")
 	}
 
+	if o.submit {
+		tc, err := tide.NewController(o.cookiefilePath, o.projects, prowJobClient, cfg, o.dryRun)
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating gerrit tide controller.")
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/", tc.StatusPage())
+		mux.Handle("/pools", tc)
+		server := &http.Server{Addr: ":" + strconv.Itoa(o.statusPort), Handler: mux}
+		interrupts.ListenAndServe(server, 5*time.Second)
+
+		logrus.Infof("Starting gerrit tide")
+		interrupts.Tick(func() {
+			start := time.Now()
+			if err := tc.Sync(); err != nil {
+				logrus.WithError(err).Error("Error submitting changes.")
+			}
+			logrus.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Info("Synced submittable changes")
+		}, func() time.Duration {
+			return cfg().Gerrit.Submit.SyncPeriod.Duration
+		})
+	}
+
+	if o.streamEvents {
+		var instances []string
+		for instance := range o.projects {
+			instances = append(instances, instance)
+		}
+
+		et := eventTime{st: &syncTime{
+			path:   o.lastEventFallback,
+			ctx:    ctx,
+			opener: op,
+		}}
+		if err := et.init(instances); err != nil {
+			logrus.WithError(err).Fatal("Error initializing lastEventFallback.")
+		}
+
+		logrus.Infof("Starting gerrit events stream")
+		interrupts.Run(func(ctx context.Context) {
+			c.StreamEvents(ctx, &et, instances, func() time.Duration {
+				return cfg().Gerrit.EventsInterval.Duration
+			})
+		})
+		return
+	}
+
 	logrus.Infof("Starting gerrit fetcher")
 
 	interrupts.Tick(func() {
@@ -96,6 +96,35 @@ func TestFlags(t *testing.T) {
 				o.storage.S3CredentialsFile = "/creds"
 			},
 		},
+		{
+			name: "stream events",
+			args: map[string]string{
+				"--stream-events":       "true",
+				"--last-event-fallback": "gs://events",
+			},
+			expected: func(o *options) {
+				o.streamEvents = true
+				o.lastEventFallback = "gs://events"
+			},
+		},
+		{
+			name: "submit",
+			args: map[string]string{
+				"--submit":      "true",
+				"--status-port": "8080",
+			},
+			expected: func(o *options) {
+				o.submit = true
+				o.statusPort = 8080
+			},
+		},
+		{
+			name: "stream events requires --last-event-fallback",
+			args: map[string]string{
+				"--stream-events": "true",
+			},
+			err: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -104,6 +133,7 @@ func TestFlags(t *testing.T) {
 				projects:         client.ProjectsFlag{},
 				lastSyncFallback: "gs://path",
 				configPath:       "yo",
+				statusPort:       8888,
 				dryRun:           false,
 			}
 			expected.projects.Set("foo=bar")
@@ -253,3 +283,53 @@ func TestNewProjectAddition(t *testing.T) {
 		t.Error("expected tracker to initialize a new entry for qwe/qux, but did not")
 	}
 }
+
+func TestEventTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fake-gerrit-value")
+	if err != nil {
+		t.Fatalf("Could not create temp file: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.txt")
+	var noCreds string
+	ctx := context.Background()
+	open, err := io.NewOpener(ctx, noCreds, noCreds)
+	if err != nil {
+		t.Fatalf("Failed to create opener: %v", err)
+	}
+
+	et := eventTime{st: &syncTime{
+		path:   path,
+		opener: open,
+		ctx:    ctx,
+	}}
+	if err := et.init([]string{"foo"}); err != nil {
+		t.Fatalf("Failed init: %v", err)
+	}
+	if _, ok := et.Current()["foo"]; !ok {
+		t.Fatal("expected init to start the events of foo from now")
+	}
+
+	now := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := et.Update("foo", now); err != nil {
+		t.Fatalf("Failed update: %v", err)
+	}
+	if err := et.Update("foo", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed update: %v", err)
+	}
+	if actual := et.Current()["foo"]; !actual.Equal(now) {
+		t.Errorf("Update should not have reduced the last event from %v, got %v", now, actual)
+	}
+
+	et = eventTime{st: &syncTime{
+		path:   path,
+		opener: open,
+		ctx:    ctx,
+	}}
+	if err := et.init([]string{"foo"}); err != nil {
+		t.Fatalf("Failed init: %v", err)
+	}
+	if actual := et.Current()["foo"]; !actual.Equal(now) {
+		t.Errorf("init() failed to reload %v, got %v", now, actual)
+	}
+}
@@ -18,8 +18,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"k8s.io/test-infra/prow/interrupts"
 
 	"k8s.io/test-infra/pkg/flagutil"
+	prowv1 "k8s.io/test-infra/prow/client/clientset/versioned/typed/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/config/secret"
 	prowflagutil "k8s.io/test-infra/prow/flagutil"
@@ -55,17 +58,41 @@ type options struct {
 	bugzilla    prowflagutil.BugzillaOptions
 	gitee       prowflagutil.GiteeOptions
 
-	webhookSecretFile string
-	slackTokenFile    string
+	webhookSecretFile      string
+	webhookTimestampWindow time.Duration
+	slackTokenFile         string
+
+	pluginWorkers int
+
+	eventQueueDir     string
+	eventMaxRetries   int
+	eventRetryBackoff time.Duration
+	adminPort         int
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.bugzilla, &o.gitee} {
 		if err := group.Validate(o.dryRun); err != nil {
 			return err
 		}
 	}
 
+	if o.pluginWorkers <= 0 {
+		return fmt.Errorf("--plugin-workers must be positive")
+	}
+
+	if o.eventQueueDir != "" {
+		if o.eventMaxRetries < 0 {
+			return fmt.Errorf("--event-max-retries can't be negative")
+		}
+		if o.eventRetryBackoff <= 0 {
+			return fmt.Errorf("--event-retry-backoff must be positive")
+		}
+		if o.adminPort == o.port {
+			return fmt.Errorf("--admin-port must be different from --port")
+		}
+	}
+
 	return nil
 }
 
@@ -83,8 +110,14 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 		group.AddFlags(fs)
 	}
 
-	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
+	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the Gitee webhook secret, either a single token or the per-org YAML tokens of hook.")
+	fs.DurationVar(&o.webhookTimestampWindow, "webhook-timestamp-window", 5*time.Minute, "Reject the webhooks whose X-Gitee-Timestamp is further than this from now, and the replayed ones. Zero disables these checks.")
 	fs.StringVar(&o.slackTokenFile, "slack-token-file", "", "Path to the file containing the Slack token to use.")
+	fs.IntVar(&o.pluginWorkers, "plugin-workers", 20, "Maximum number of plugins handling events at once. The events of a PR or an issue are handled in order.")
+	fs.StringVar(&o.eventQueueDir, "event-queue-dir", "", "Path to the directory where the events are persisted until the plugins handled them. The queue is disabled if empty.")
+	fs.IntVar(&o.eventMaxRetries, "event-max-retries", 3, "Number of times the plugins which failed to handle a queued event are retried before it is moved to the dead letters.")
+	fs.DurationVar(&o.eventRetryBackoff, "event-retry-backoff", 10*time.Second, "Time to wait before retrying the plugins which failed to handle a queued event, doubled after each retry.")
+	fs.IntVar(&o.adminPort, "admin-port", 8889, "Port serving the dead letters of the event queue on /dead-letters, which replays them on POST. It must not be exposed publicly.")
 	fs.Parse(args)
 	o.configPath = config.ConfigPath(o.configPath)
 	return o
@@ -148,10 +181,20 @@ func main() {
 	metrics.ExposeMetrics("gitee-hook", configAgent.Config().PushGateway)
 	pjutil.ServePProf()
 
-	vf := func(w http.ResponseWriter, r *http.Request) (string, string, []byte, bool, int) {
-		return gitee.ValidateWebhook(w, r, secretAgent.GetTokenGenerator(o.webhookSecretFile))
+	var dispatcher hook.Dispatcher
+	var queue *hook.Queue
+	if o.eventQueueDir != "" {
+		queue, err = newQueue(&o, plugins.NewEventHandler(pluginAgent, pm, o.pluginWorkers))
+		if err != nil {
+			logrus.WithError(err).Fatal("Error creating the event queue.")
+		}
+		dispatcher = queue
+	} else {
+		dispatcher = plugins.NewDispatcher(pluginAgent, pm, o.pluginWorkers)
 	}
-	server := hook.NewServer(originh.NewMetrics(), vf, plugins.NewDispatcher(pluginAgent, pm))
+
+	validator := gitee.NewWebhookValidator(secretAgent.GetTokenGenerator(o.webhookSecretFile), o.webhookTimestampWindow)
+	server := hook.NewServer(originh.NewMetrics(), validator.Validate, dispatcher)
 
 	interrupts.OnInterrupt(func() {
 		server.GracefulShutdown()
@@ -169,21 +212,46 @@ func main() {
 
 	// For /hook, handle a webhook normally.
 	http.Handle("/gitee-hook", server)
-	// Serve plugin help information from /plugin-help.
-	// TODO(zengchen1024) pluginhelp use the original plugins.Configuration
-	//http.Handle("/gitee-plugin-help", pluginhelp.NewHelpAgent(pluginAgent, githubClient))
+	// Serve plugin help information from /gitee-plugin-help.
+	http.Handle("/gitee-plugin-help", plugins.NewHelpAgent(pluginAgent, pm, cs.giteeClient))
 
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port)}
 
+	if queue != nil {
+		if err := queue.Resume(); err != nil {
+			logrus.WithError(err).Fatal("Error resuming the pending events.")
+		}
+
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/dead-letters", queue)
+		interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.adminPort), Handler: adminMux}, o.gracePeriod)
+	}
+
 	health.ServeReady()
 
 	interrupts.ListenAndServe(httpServer, o.gracePeriod)
 }
 
+// newQueue returns the queue persisting the events in the pending and dead
+// directories of --event-queue-dir.
+func newQueue(o *options, h hook.EventHandler) (*hook.Queue, error) {
+	pending, err := hook.NewDiskStore(filepath.Join(o.eventQueueDir, "pending"))
+	if err != nil {
+		return nil, err
+	}
+	dead, err := hook.NewDiskStore(filepath.Join(o.eventQueueDir, "dead"))
+	if err != nil {
+		return nil, err
+	}
+	return hook.NewQueue(h, pending, dead, o.eventMaxRetries, o.eventRetryBackoff), nil
+}
+
 type clients struct {
 	giteeClient    gitee.Client
 	giteeGitClient git.ClientFactory
 	ownersClient   repoowners.Interface
+	prowJobClient  prowv1.ProwJobInterface
+	configAgent    *config.Agent
 }
 
 func buildClients(o *options, secretAgent *secret.Agent, pluginAgent *plugins.ConfigAgent, configAgent *config.Agent) (*clients, error) {
@@ -207,10 +275,17 @@ func buildClients(o *options, secretAgent *secret.Agent, pluginAgent *plugins.Co
 	}
 	ownersClient := repoowners.NewClient(giteeGitClient, giteeClient, mdYAMLEnabled, skipCollaborators, ownersDirBlacklist)
 
+	prowJobClient, err := o.kubernetes.ProwJobClient(configAgent.Config().ProwJobNamespace, o.dryRun)
+	if err != nil {
+		return nil, err
+	}
+
 	cs := &clients{
 		giteeClient:    giteeClient,
 		giteeGitClient: giteeGitClient,
 		ownersClient:   ownersClient,
+		prowJobClient:  prowJobClient,
+		configAgent:    configAgent,
 	}
 	return cs, nil
 }
@@ -4,18 +4,30 @@ import (
 	plugins "k8s.io/test-infra/prow/gitee-plugins"
 	"k8s.io/test-infra/prow/gitee-plugins/approve"
 	"k8s.io/test-infra/prow/gitee-plugins/assign"
+	"k8s.io/test-infra/prow/gitee-plugins/blunderbuss"
+	"k8s.io/test-infra/prow/gitee-plugins/hold"
+	"k8s.io/test-infra/prow/gitee-plugins/label"
 	"k8s.io/test-infra/prow/gitee-plugins/lgtm"
+	"k8s.io/test-infra/prow/gitee-plugins/lifecycle"
+	"k8s.io/test-infra/prow/gitee-plugins/trigger"
+	"k8s.io/test-infra/prow/gitee-plugins/wip"
 )
 
 func initPlugins(agent *plugins.ConfigAgent, pm plugins.Plugins, cs *clients) {
-	gpc := func(name string) plugins.PluginConfig {
-		return agent.Config().GetPluginConfig(name)
+	gpc := func(name, org, repo string) plugins.PluginConfig {
+		return agent.Config().PluginConfigFor(name, org, repo)
 	}
 
 	var v []plugins.Plugin
 	v = append(v, approve.NewApprove(gpc, cs.giteeClient, cs.ownersClient))
 	v = append(v, assign.NewAssign(gpc, cs.giteeClient))
+	v = append(v, blunderbuss.NewBlunderbuss(gpc, cs.giteeClient, cs.ownersClient))
+	v = append(v, hold.NewHold(gpc, cs.giteeClient))
+	v = append(v, label.NewLabel(gpc, cs.giteeClient))
 	v = append(v, lgtm.NewLGTM(gpc, agent.Config, cs.giteeClient, cs.ownersClient))
+	v = append(v, lifecycle.NewLifecycle(gpc, cs.giteeClient))
+	v = append(v, trigger.NewTrigger(gpc, cs.configAgent.Config, cs.giteeClient, cs.prowJobClient, cs.giteeGitClient))
+	v = append(v, wip.NewWIP(gpc, cs.giteeClient))
 
 	for _, i := range v {
 		name := i.PluginName()
@@ -0,0 +1,396 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/config/org"
+	"k8s.io/test-infra/prow/config/secret"
+	"k8s.io/test-infra/prow/github"
+)
+
+// giteePermissions maps the permission levels of the config to the Gitee
+// repository permissions.
+var giteePermissions = map[github.RepoPermissionLevel]string{
+	github.Read:  "pull",
+	github.Write: "push",
+	github.Admin: "admin",
+}
+
+// validateGitee validates the options of the Gitee backend, which manages
+// the org members and the repos but neither the org metadata nor the teams.
+func (o *options) validateGitee() error {
+	if err := o.gitee.Validate(!o.confirm); err != nil {
+		return err
+	}
+	if o.dump != "" {
+		return fmt.Errorf("--dump is not supported with --provider=%s", providerGitee)
+	}
+	if o.fixOrg {
+		return fmt.Errorf("--fix-org is not supported with --provider=%s", providerGitee)
+	}
+	if o.fixTeams {
+		return fmt.Errorf("--fix-teams is not supported with --provider=%s", providerGitee)
+	}
+	return nil
+}
+
+func syncGitee(o options) {
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.gitee.TokenPath}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	client, err := o.gitee.GiteeClient(secretAgent, !o.confirm)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Gitee client.")
+	}
+	if o.tokensPerHour > 0 {
+		client.Throttle(o.tokensPerHour, o.tokenBurst)
+	}
+
+	cfg, err := loadOrgConfig(o.config)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load configuration")
+	}
+
+	for name, orgcfg := range cfg.Orgs {
+		if err := configureGiteeOrg(o, client, name, orgcfg); err != nil {
+			logrus.Fatalf("Configuration failed: %v", err)
+		}
+	}
+	logrus.Info("Finished syncing configuration.")
+}
+
+type giteeClient interface {
+	giteeOrgClient
+	giteeRepoClient
+}
+
+func configureGiteeOrg(opt options, client giteeClient, orgName string, orgConfig org.Config) error {
+	if len(orgConfig.Teams) > 0 {
+		logrus.Warnf("Ignoring the teams of %s, they are not managed on Gitee", orgName)
+	}
+
+	// Add/remove/update members of the org.
+	if !opt.fixOrgMembers {
+		logrus.Infof("Skipping org member configuration")
+	} else if err := configureGiteeOrgMembers(opt, client, orgName, orgConfig); err != nil {
+		return fmt.Errorf("failed to configure %s members: %v", orgName, err)
+	}
+
+	// Create/update repositories and their collaborators in the org.
+	if !opt.fixRepos {
+		logrus.Info("Skipping org repositories configuration")
+	} else if err := configureGiteeRepos(opt, client, orgName, orgConfig); err != nil {
+		return fmt.Errorf("failed to configure %s repos: %v", orgName, err)
+	}
+	return nil
+}
+
+type giteeOrgClient interface {
+	BotName() (string, error)
+	ListOrgMembers(org, role string) ([]sdk.UserBasic, error)
+	UpdateOrgMembership(org, login string, admin bool) error
+	RemoveOrgMembership(org, login string) error
+}
+
+func configureGiteeOrgMembers(opt options, client giteeOrgClient, orgName string, orgConfig org.Config) error {
+	// Get desired state
+	wantAdmins := sets.NewString(orgConfig.Admins...)
+	wantMembers := sets.NewString(orgConfig.Members...)
+
+	// Sanity desired state
+	if err := validateAdmins(opt, client.BotName, opt.gitee.TokenPath, orgName, wantAdmins); err != nil {
+		return err
+	}
+
+	// Get current state
+	haveAdmins := sets.String{}
+	haveMembers := sets.String{}
+	ms, err := client.ListOrgMembers(orgName, github.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to list %s admins: %v", orgName, err)
+	}
+	for _, m := range ms {
+		haveAdmins.Insert(m.Login)
+	}
+	if ms, err = client.ListOrgMembers(orgName, github.RoleMember); err != nil {
+		return fmt.Errorf("failed to list %s members: %v", orgName, err)
+	}
+	for _, m := range ms {
+		haveMembers.Insert(m.Login)
+	}
+
+	have := memberships{members: haveMembers, super: haveAdmins}
+	want := memberships{members: wantMembers, super: wantAdmins}
+	have.normalize()
+	want.normalize()
+	// Sanity check changes
+	if err := validateRemovalDelta(opt, orgName, have, want); err != nil {
+		return err
+	}
+
+	adder := func(user string, super bool) error {
+		role := github.RoleMember
+		if super {
+			role = github.RoleAdmin
+		}
+		logrus.Infof("Setting %s as a %s of %s", user, role, orgName)
+		err := client.UpdateOrgMembership(orgName, user, super)
+		if err != nil {
+			logrus.WithError(err).Warnf("UpdateOrgMembership(%s, %s, %t) failed", orgName, user, super)
+		}
+		return err
+	}
+
+	remover := func(user string) error {
+		logrus.Infof("Removing %s from %s", user, orgName)
+		err := client.RemoveOrgMembership(orgName, user)
+		if err != nil {
+			logrus.WithError(err).Warnf("RemoveOrgMembership(%s, %s) failed", orgName, user)
+		}
+		return err
+	}
+
+	// Gitee adds the members without an invitation.
+	return configureMembers(have, want, sets.String{}, adder, remover)
+}
+
+// validateGiteeRepos ensures the repos only declare what the Gitee backend
+// is able to configure.
+func validateGiteeRepos(repos map[string]org.Repo) error {
+	if err := validateRepos(repos); err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, repo := range repos {
+		if len(repo.Previously) > 0 {
+			errs = append(errs, fmt.Errorf("%s: renaming repos is not supported on Gitee", name))
+		}
+		if repo.Archived != nil && *repo.Archived {
+			errs = append(errs, fmt.Errorf("%s: archiving repos is not supported on Gitee", name))
+		}
+		for login, level := range repo.Collaborators {
+			if _, ok := giteePermissions[level]; !ok {
+				errs = append(errs, fmt.Errorf("%s: collaborator %s has the permission %q, Gitee only supports %q, %q and %q", name, login, level, github.Read, github.Write, github.Admin))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type giteeRepoClient interface {
+	GetRepos(org string) ([]sdk.Project, error)
+	CreateRepo(org string, repo sdk.RepositoryPostParam) (sdk.Project, error)
+	UpdateRepo(org, repo string, patch sdk.RepoPatchParam) (sdk.Project, error)
+	giteeCollaboratorClient
+}
+
+func newGiteeRepoPostParam(name string, definition org.Repo) sdk.RepositoryPostParam {
+	repo := sdk.RepositoryPostParam{
+		Name: name,
+		Path: name,
+	}
+	if definition.Description != nil {
+		repo.Description = *definition.Description
+	}
+	if definition.HomePage != nil {
+		repo.Homepage = *definition.HomePage
+	}
+	if definition.Private != nil {
+		repo.Private = *definition.Private
+	}
+	if o := definition.OnCreate; o != nil {
+		if o.AutoInit != nil {
+			repo.AutoInit = *o.AutoInit
+		}
+		if o.GitignoreTemplate != nil {
+			repo.GitignoreTemplate = *o.GitignoreTemplate
+		}
+		if o.LicenseTemplate != nil {
+			repo.LicenseTemplate = *o.LicenseTemplate
+		}
+	}
+	return repo
+}
+
+// newGiteeRepoPatch returns the patch updating the current repo into the
+// target state, along with the fields it changes. Gitee requires every patch
+// to carry the name of the repo, so the patch starts from the current values.
+func newGiteeRepoPatch(current sdk.Project, repo org.Repo) (sdk.RepoPatchParam, []string) {
+	patch := sdk.RepoPatchParam{
+		Name:          current.Name,
+		Description:   current.Description,
+		Homepage:      current.Homepage,
+		Private:       current.Private,
+		DefaultBranch: current.DefaultBranch,
+	}
+
+	var changes []string
+	setString := func(field string, have *string, want *string) {
+		if want != nil && *want != *have {
+			*have = *want
+			changes = append(changes, field)
+		}
+	}
+	setString("description", &patch.Description, repo.Description)
+	setString("homepage", &patch.Homepage, repo.HomePage)
+	setString("default_branch", &patch.DefaultBranch, repo.DefaultBranch)
+	if repo.Private != nil && *repo.Private != patch.Private {
+		patch.Private = *repo.Private
+		changes = append(changes, "private")
+	}
+	return patch, changes
+}
+
+func configureGiteeRepos(opt options, client giteeRepoClient, orgName string, orgConfig org.Config) error {
+	if err := validateGiteeRepos(orgConfig.Repos); err != nil {
+		return err
+	}
+
+	repoList, err := client.GetRepos(orgName)
+	if err != nil {
+		return fmt.Errorf("failed to get repos: %v", err)
+	}
+	logrus.Debugf("Found %d repositories", len(repoList))
+	byName := make(map[string]sdk.Project, len(repoList))
+	for _, repo := range repoList {
+		byName[strings.ToLower(repo.Path)] = repo
+	}
+
+	admins := normalize(sets.NewString(orgConfig.Admins...))
+	var allErrors []error
+	for wantName, wantRepo := range orgConfig.Repos {
+		repoLogger := logrus.WithField("repo", wantName)
+		current, exists := byName[strings.ToLower(wantName)]
+		if !exists {
+			repoLogger.Info("repo does not exist, creating")
+			created, err := client.CreateRepo(orgName, newGiteeRepoPostParam(wantName, wantRepo))
+			if err != nil {
+				allErrors = append(allErrors, err)
+				continue
+			}
+			current = created
+		}
+
+		if wantRepo.Private != nil && !*wantRepo.Private && current.Private && !opt.allowRepoPublish {
+			err := errors.New("asked to publish a private repo but this is not allowed by default (see --allow-repo-publish)")
+			repoLogger.WithError(err).Error("requested repo change is not allowed, removing from delta")
+			allErrors = append(allErrors, err)
+			wantRepo.Private = nil
+		}
+		if patch, changes := newGiteeRepoPatch(current, wantRepo); len(changes) > 0 {
+			repoLogger.WithField("changes", changes).Info("repo differs from desired state, updating")
+			if _, err := client.UpdateRepo(orgName, current.Path, patch); err != nil {
+				allErrors = append(allErrors, err)
+			}
+		}
+
+		if wantRepo.Collaborators == nil {
+			continue
+		}
+		// A dry run doesn't create the repo, so it has no collaborators to list.
+		planned := !exists && !opt.confirm
+		if err := configureGiteeCollaborators(opt, client, orgName, current.Path, wantRepo.Collaborators, admins, planned); err != nil {
+			allErrors = append(allErrors, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(allErrors)
+}
+
+type giteeCollaboratorClient interface {
+	ListCollaborators(org, repo string) ([]github.User, error)
+	GetRepoCollaboratorPermission(org, repo, login string) (string, error)
+	AddRepoCollaborator(org, repo, login, permission string) error
+	RemoveRepoCollaborator(org, repo, login string) error
+}
+
+// configureGiteeCollaborators gives the wanted collaborators their permission
+// on the repo and removes the others. The org admins are left alone, they
+// have admin access to every repo of the org. A planned repo, which a dry run
+// didn't create, has no collaborators yet, so all of them are added.
+func configureGiteeCollaborators(opt options, client giteeCollaboratorClient, orgName, repoName string, collaborators map[string]github.RepoPermissionLevel, admins sets.String, planned bool) error {
+	repoLogger := logrus.WithField("repo", repoName)
+
+	var users []github.User
+	if !planned {
+		var err error
+		users, err = client.ListCollaborators(orgName, repoName)
+		if err != nil {
+			return fmt.Errorf("failed to list %s/%s collaborators: %v", orgName, repoName, err)
+		}
+	}
+	have := map[string]string{}
+	for _, u := range users {
+		if login := github.NormLogin(u.Login); !admins.Has(login) {
+			have[login] = u.Login
+		}
+	}
+
+	want := sets.String{}
+	for login := range collaborators {
+		want.Insert(github.NormLogin(login))
+	}
+	var remove []string
+	for login, user := range have {
+		if !want.Has(login) {
+			remove = append(remove, user)
+		}
+	}
+
+	// Sanity check changes
+	if d := float64(len(remove)) / float64(len(have)); d > opt.maximumDelta {
+		return fmt.Errorf("cannot remove %d collaborators or %.3f of %s/%s (exceeds limit of %.3f)", len(remove), d, orgName, repoName, opt.maximumDelta)
+	}
+
+	var errs []error
+	for login, level := range collaborators {
+		permission := giteePermissions[level]
+		if user, ok := have[github.NormLogin(login)]; ok {
+			current, err := client.GetRepoCollaboratorPermission(orgName, repoName, user)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get the permission of %s on %s/%s: %v", user, orgName, repoName, err))
+				continue
+			}
+			if current == permission {
+				continue
+			}
+		}
+		repoLogger.Infof("Setting %s as a collaborator with the %s permission", login, permission)
+		if err := client.AddRepoCollaborator(orgName, repoName, login, permission); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, user := range remove {
+		repoLogger.Infof("Removing the collaborator %s", user)
+		if err := client.RemoveRepoCollaborator(orgName, repoName, user); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
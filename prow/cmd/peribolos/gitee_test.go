@@ -0,0 +1,373 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+
+	"k8s.io/test-infra/prow/config/org"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+const giteeOrg = "gitee-org"
+
+func TestConfigureGiteeOrgMembers(t *testing.T) {
+	cases := []struct {
+		name    string
+		opt     options
+		config  org.Config
+		admins  []string
+		members []string
+
+		err             bool
+		expectedAdmins  []string
+		expectedMembers []string
+	}{
+		{
+			name: "too few admins",
+			opt: options{
+				minAdmins: 2,
+			},
+			config: org.Config{
+				Admins: []string{"joe"},
+			},
+			err: true,
+		},
+		{
+			name: "forgot to add self",
+			opt: options{
+				requireSelf: true,
+			},
+			config: org.Config{
+				Admins: []string{"other"},
+			},
+			err: true,
+		},
+		{
+			name: "remove too many members",
+			opt: options{
+				maximumDelta: 0.3,
+			},
+			config: org.Config{
+				Admins: []string{"keep"},
+			},
+			admins:  []string{"keep"},
+			members: []string{"a", "b"},
+			err:     true,
+		},
+		{
+			name: "add, promote, demote and remove",
+			opt: options{
+				maximumDelta: 0.5,
+				requireSelf:  true,
+			},
+			config: org.Config{
+				Admins:  []string{fakegitee.Bot, "promoted", "new-admin"},
+				Members: []string{"demoted", "keep-member", "new-member"},
+			},
+			admins:          []string{fakegitee.Bot, "demoted", "drop-admin"},
+			members:         []string{"promoted", "keep-member"},
+			expectedAdmins:  []string{fakegitee.Bot, "new-admin", "promoted"},
+			expectedMembers: []string{"demoted", "keep-member", "new-member"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.OrgAdmins[giteeOrg] = tc.admins
+			fc.OrgMembers[giteeOrg] = tc.members
+
+			err := configureGiteeOrgMembers(tc.opt, fc, giteeOrg, tc.config)
+			switch {
+			case err != nil:
+				if !tc.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			case tc.err:
+				t.Errorf("Failed to receive error")
+			default:
+				admins, _ := fc.ListOrgMembers(giteeOrg, github.RoleAdmin)
+				if err := cmpLists(tc.expectedAdmins, logins(admins)); err != nil {
+					t.Errorf("Wrong admins: %v", err)
+				}
+				members, _ := fc.ListOrgMembers(giteeOrg, github.RoleMember)
+				if err := cmpLists(tc.expectedMembers, logins(members)); err != nil {
+					t.Errorf("Wrong members: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func logins(users []sdk.UserBasic) []string {
+	var r []string
+	for _, u := range users {
+		r = append(r, u.Login)
+	}
+	return r
+}
+
+func TestConfigureGiteeRepos(t *testing.T) {
+	yes := true
+	no := false
+	updated := "UPDATED"
+	dev := "dev"
+
+	existing := sdk.Project{
+		Name:          "existing",
+		Path:          "existing",
+		FullName:      giteeOrg + "/existing",
+		Description:   "An existing repository",
+		Private:       true,
+		DefaultBranch: "master",
+	}
+
+	cases := []struct {
+		name          string
+		opt           options
+		config        org.Config
+		repos         []sdk.Project
+		collaborators map[string]string
+
+		err                   bool
+		expectedRepos         []sdk.Project
+		expectedCollaborators map[string]string
+	}{
+		{
+			name: "repo is created",
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"new": {Description: &updated, Private: &yes},
+				},
+			},
+			expectedRepos: []sdk.Project{
+				{Name: "new", Path: "new", FullName: giteeOrg + "/new", Description: updated, Private: true, DefaultBranch: "master"},
+			},
+		},
+		{
+			name: "repo is updated",
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {Description: &updated, DefaultBranch: &dev},
+				},
+			},
+			repos: []sdk.Project{existing},
+			expectedRepos: []sdk.Project{
+				{Name: "existing", Path: "existing", FullName: giteeOrg + "/existing", Description: updated, Private: true, DefaultBranch: dev},
+			},
+		},
+		{
+			name: "private repo is not published by default",
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {Private: &no},
+				},
+			},
+			repos:         []sdk.Project{existing},
+			err:           true,
+			expectedRepos: []sdk.Project{existing},
+		},
+		{
+			name: "private repo is published with the flag",
+			opt: options{
+				allowRepoPublish: true,
+			},
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {Private: &no},
+				},
+			},
+			repos: []sdk.Project{existing},
+			expectedRepos: []sdk.Project{
+				{Name: "existing", Path: "existing", FullName: giteeOrg + "/existing", Description: "An existing repository", DefaultBranch: "master"},
+			},
+		},
+		{
+			name: "archiving is rejected",
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {Archived: &yes},
+				},
+			},
+			repos:         []sdk.Project{existing},
+			err:           true,
+			expectedRepos: []sdk.Project{existing},
+		},
+		{
+			name: "collaborators are added, updated and removed",
+			opt: options{
+				maximumDelta: 0.5,
+			},
+			config: org.Config{
+				Admins: []string{"org-admin"},
+				Repos: map[string]org.Repo{
+					"existing": {
+						Collaborators: map[string]github.RepoPermissionLevel{
+							"new":     github.Read,
+							"updated": github.Admin,
+							"kept":    github.Write,
+						},
+					},
+				},
+			},
+			repos: []sdk.Project{existing},
+			collaborators: map[string]string{
+				giteeOrg + "/existing:updated":   "push",
+				giteeOrg + "/existing:kept":      "push",
+				giteeOrg + "/existing:removed":   "pull",
+				giteeOrg + "/existing:org-admin": "admin",
+			},
+			expectedRepos: []sdk.Project{existing},
+			expectedCollaborators: map[string]string{
+				giteeOrg + "/existing:new":       "pull",
+				giteeOrg + "/existing:updated":   "admin",
+				giteeOrg + "/existing:kept":      "push",
+				giteeOrg + "/existing:org-admin": "admin",
+			},
+		},
+		{
+			name: "removing too many collaborators is rejected",
+			opt: options{
+				maximumDelta: 0.25,
+			},
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {
+						Collaborators: map[string]github.RepoPermissionLevel{
+							"kept": github.Write,
+						},
+					},
+				},
+			},
+			repos: []sdk.Project{existing},
+			collaborators: map[string]string{
+				giteeOrg + "/existing:kept":    "push",
+				giteeOrg + "/existing:removed": "pull",
+			},
+			err:           true,
+			expectedRepos: []sdk.Project{existing},
+			expectedCollaborators: map[string]string{
+				giteeOrg + "/existing:kept":    "push",
+				giteeOrg + "/existing:removed": "pull",
+			},
+		},
+		{
+			name: "unsupported permission is rejected",
+			config: org.Config{
+				Repos: map[string]org.Repo{
+					"existing": {
+						Collaborators: map[string]github.RepoPermissionLevel{
+							"nobody": github.None,
+						},
+					},
+				},
+			},
+			repos:         []sdk.Project{existing},
+			err:           true,
+			expectedRepos: []sdk.Project{existing},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.Repos[giteeOrg] = append([]sdk.Project{}, tc.repos...)
+			for k, v := range tc.collaborators {
+				fc.RepoCollaborators[k] = v
+			}
+
+			err := configureGiteeRepos(tc.opt, fc, giteeOrg, tc.config)
+			if err != nil && !tc.err {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if err == nil && tc.err {
+				t.Errorf("Failed to receive error")
+			}
+
+			if !reflect.DeepEqual(fc.Repos[giteeOrg], tc.expectedRepos) {
+				t.Errorf("Expected the repos %+v, got %+v", tc.expectedRepos, fc.Repos[giteeOrg])
+			}
+			expectedCollaborators := tc.expectedCollaborators
+			if expectedCollaborators == nil {
+				expectedCollaborators = map[string]string{}
+			}
+			if !reflect.DeepEqual(fc.RepoCollaborators, expectedCollaborators) {
+				t.Errorf("Expected the collaborators %v, got %v", expectedCollaborators, fc.RepoCollaborators)
+			}
+		})
+	}
+}
+
+// dryRunRepoClient doesn't create the repos, like the client of a dry run,
+// and fails to read the collaborators of the repos which don't exist.
+type dryRunRepoClient struct {
+	*fakegitee.FakeClient
+}
+
+func (c *dryRunRepoClient) CreateRepo(org string, repo sdk.RepositoryPostParam) (sdk.Project, error) {
+	return sdk.Project{Name: repo.Name, Path: repo.Path, FullName: org + "/" + repo.Path, Private: repo.Private}, nil
+}
+
+func (c *dryRunRepoClient) exists(org, repo string) error {
+	for _, p := range c.Repos[org] {
+		if p.Path == repo {
+			return nil
+		}
+	}
+	return fmt.Errorf("404 Not Found: %s/%s", org, repo)
+}
+
+func (c *dryRunRepoClient) ListCollaborators(org, repo string) ([]github.User, error) {
+	if err := c.exists(org, repo); err != nil {
+		return nil, err
+	}
+	return c.FakeClient.ListCollaborators(org, repo)
+}
+
+func (c *dryRunRepoClient) GetRepoCollaboratorPermission(org, repo, login string) (string, error) {
+	if err := c.exists(org, repo); err != nil {
+		return "", err
+	}
+	return c.FakeClient.GetRepoCollaboratorPermission(org, repo, login)
+}
+
+func TestConfigureGiteeReposDryRunOfANewRepo(t *testing.T) {
+	fc := &dryRunRepoClient{FakeClient: fakegitee.NewFakeClient()}
+	config := org.Config{
+		Repos: map[string]org.Repo{
+			"new": {
+				Collaborators: map[string]github.RepoPermissionLevel{
+					"someone": github.Write,
+				},
+			},
+		},
+	}
+
+	if err := configureGiteeRepos(options{}, fc, giteeOrg, config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{giteeOrg + "/new:someone": "push"}
+	if !reflect.DeepEqual(fc.RepoCollaborators, expected) {
+		t.Errorf("Expected the collaborators %v to be planned, got %v", expected, fc.RepoCollaborators)
+	}
+}
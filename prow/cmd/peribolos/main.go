@@ -43,6 +43,11 @@ const (
 	defaultBurst     = 100
 )
 
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
+)
+
 type options struct {
 	config            string
 	confirm           bool
@@ -62,6 +67,8 @@ type options struct {
 	allowRepoArchival bool
 	allowRepoPublish  bool
 	github            flagutil.GitHubOptions
+	gitee             flagutil.GiteeOptions
+	provider          string
 	tokenBurst        int
 	tokensPerHour     int
 	logLevel          string
@@ -97,12 +104,23 @@ func (o *options) parseArgs(flags *flag.FlagSet, args []string) error {
 	flags.BoolVar(&o.allowRepoArchival, "allow-repo-archival", false, "If set, archiving repos is allowed while updating repos")
 	flags.BoolVar(&o.allowRepoPublish, "allow-repo-publish", false, "If set, making private repos public is allowed while updating repos")
 	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), fmt.Sprintf("Logging level, one of %v", logrus.AllLevels))
+	flags.StringVar(&o.provider, "provider", providerGitHub, fmt.Sprintf("The code hosting platform of the orgs, %q or %q.", providerGitHub, providerGitee))
 	o.github.AddFlags(flags)
+	o.gitee.AddFlags(flags)
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
-	if err := o.github.Validate(!o.confirm); err != nil {
-		return err
+	switch o.provider {
+	case providerGitHub:
+		if err := o.github.Validate(!o.confirm); err != nil {
+			return err
+		}
+	case providerGitee:
+		if err := o.validateGitee(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, o.provider)
 	}
 	if o.tokensPerHour > 0 && o.tokenBurst >= o.tokensPerHour {
 		return fmt.Errorf("--tokens=%d must exceed --token-burst=%d", o.tokensPerHour, o.tokenBurst)
@@ -151,6 +169,11 @@ func main() {
 
 	o := parseOptions()
 
+	if o.provider == providerGitee {
+		syncGitee(o)
+		return
+	}
+
 	secretAgent := &secret.Agent{}
 	if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
@@ -186,13 +209,8 @@ func main() {
 		return
 	}
 
-	raw, err := ioutil.ReadFile(o.config)
+	cfg, err := loadOrgConfig(o.config)
 	if err != nil {
-		logrus.WithError(err).Fatal("Could not read --config-path file")
-	}
-
-	var cfg org.FullConfig
-	if err := yaml.Unmarshal(raw, &cfg); err != nil {
 		logrus.WithError(err).Fatal("Failed to load configuration")
 	}
 
@@ -204,6 +222,19 @@ func main() {
 	logrus.Info("Finished syncing configuration.")
 }
 
+func loadOrgConfig(path string) (*org.FullConfig, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read --config-path file: %v", err)
+	}
+
+	var cfg org.FullConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
 type dumpClient interface {
 	GetOrg(name string) (*github.Organization, error)
 	ListOrgMembers(org, role string) ([]github.TeamMember, error)
@@ -394,24 +425,8 @@ func configureOrgMembers(opt options, client orgClient, orgName string, orgConfi
 	wantMembers := sets.NewString(orgConfig.Members...)
 
 	// Sanity desired state
-	if n := len(wantAdmins); n < opt.minAdmins {
-		return fmt.Errorf("%s must specify at least %d admins, only found %d", orgName, opt.minAdmins, n)
-	}
-	var missing []string
-	for _, r := range opt.requiredAdmins.Strings() {
-		if !wantAdmins.Has(r) {
-			missing = append(missing, r)
-		}
-	}
-	if len(missing) > 0 {
-		return fmt.Errorf("%s must specify %v as admins, missing %v", orgName, opt.requiredAdmins, missing)
-	}
-	if opt.requireSelf {
-		if me, err := client.BotName(); err != nil {
-			return fmt.Errorf("cannot determine user making requests for %s: %v", opt.github.TokenPath, err)
-		} else if !wantAdmins.Has(me) {
-			return fmt.Errorf("authenticated user %s is not an admin of %s", me, orgName)
-		}
+	if err := validateAdmins(opt, client.BotName, opt.github.TokenPath, orgName, wantAdmins); err != nil {
+		return err
 	}
 
 	// Get current state
@@ -435,12 +450,9 @@ func configureOrgMembers(opt options, client orgClient, orgName string, orgConfi
 	want := memberships{members: wantMembers, super: wantAdmins}
 	have.normalize()
 	want.normalize()
-	// Figure out who to remove
-	remove := have.all().Difference(want.all())
-
 	// Sanity check changes
-	if d := float64(len(remove)) / float64(len(have.all())); d > opt.maximumDelta {
-		return fmt.Errorf("cannot delete %d memberships or %.3f of %s (exceeds limit of %.3f)", len(remove), d, orgName, opt.maximumDelta)
+	if err := validateRemovalDelta(opt, orgName, have, want); err != nil {
+		return err
 	}
 
 	teamMembers := sets.String{}
@@ -506,6 +518,42 @@ func configureOrgMembers(opt options, client orgClient, orgName string, orgConfi
 	return configureMembers(have, want, invitees, adder, remover)
 }
 
+// validateAdmins ensures the wanted admins of the org include the required
+// admins and, if asked, the user making the requests with the token.
+func validateAdmins(opt options, botName func() (string, error), tokenPath, orgName string, wantAdmins sets.String) error {
+	if n := len(wantAdmins); n < opt.minAdmins {
+		return fmt.Errorf("%s must specify at least %d admins, only found %d", orgName, opt.minAdmins, n)
+	}
+	var missing []string
+	for _, r := range opt.requiredAdmins.Strings() {
+		if !wantAdmins.Has(r) {
+			missing = append(missing, r)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s must specify %v as admins, missing %v", orgName, opt.requiredAdmins, missing)
+	}
+	if opt.requireSelf {
+		if me, err := botName(); err != nil {
+			return fmt.Errorf("cannot determine user making requests for %s: %v", tokenPath, err)
+		} else if !wantAdmins.Has(me) {
+			return fmt.Errorf("authenticated user %s is not an admin of %s", me, orgName)
+		}
+	}
+	return nil
+}
+
+// validateRemovalDelta ensures the memberships to remove, which are the
+// normalized memberships we have but don't want, don't exceed the maximum
+// delta.
+func validateRemovalDelta(opt options, orgName string, have, want memberships) error {
+	remove := have.all().Difference(want.all())
+	if d := float64(len(remove)) / float64(len(have.all())); d > opt.maximumDelta {
+		return fmt.Errorf("cannot delete %d memberships or %.3f of %s (exceeds limit of %.3f)", len(remove), d, orgName, opt.maximumDelta)
+	}
+	return nil
+}
+
 type memberships struct {
 	members sets.String
 	super   sets.String
@@ -74,6 +74,7 @@ func TestOptions(t *testing.T) {
 				tokensPerHour: defaultTokens,
 				tokenBurst:    defaultBurst,
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -87,6 +88,7 @@ func TestOptions(t *testing.T) {
 				tokensPerHour: defaultTokens,
 				tokenBurst:    defaultBurst,
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -100,6 +102,7 @@ func TestOptions(t *testing.T) {
 				tokensPerHour: defaultTokens,
 				tokenBurst:    defaultBurst,
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -129,6 +132,7 @@ func TestOptions(t *testing.T) {
 				tokensPerHour: 0,
 				tokenBurst:    defaultBurst,
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -142,6 +146,7 @@ func TestOptions(t *testing.T) {
 				tokenBurst:    defaultBurst,
 				dump:          "frogger",
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -155,6 +160,7 @@ func TestOptions(t *testing.T) {
 				tokensPerHour: defaultTokens,
 				tokenBurst:    defaultBurst,
 				logLevel:      "info",
+				provider:      providerGitHub,
 			},
 		},
 		{
@@ -173,8 +179,37 @@ func TestOptions(t *testing.T) {
 				fixTeams:       true,
 				fixTeamMembers: true,
 				logLevel:       "debug",
+				provider:       providerGitHub,
 			},
 		},
+		{
+			name: "gitee",
+			args: []string{"--config-path=foo", "--provider=gitee", "--fix-org-members", "--fix-repos"},
+			expected: &options{
+				config:        "foo",
+				minAdmins:     defaultMinAdmins,
+				requireSelf:   true,
+				maximumDelta:  defaultDelta,
+				tokensPerHour: defaultTokens,
+				tokenBurst:    defaultBurst,
+				fixOrgMembers: true,
+				fixRepos:      true,
+				logLevel:      "info",
+				provider:      providerGitee,
+			},
+		},
+		{
+			name: "reject --fix-teams with gitee",
+			args: []string{"--config-path=foo", "--provider=gitee", "--fix-teams"},
+		},
+		{
+			name: "reject --dump with gitee",
+			args: []string{"--dump=frogger", "--provider=gitee"},
+		},
+		{
+			name: "reject unknown --provider",
+			args: []string{"--config-path=foo", "--provider=gitlab"},
+		},
 	}
 
 	for _, tc := range cases {
@@ -182,6 +217,7 @@ func TestOptions(t *testing.T) {
 		var actual options
 		err := actual.parseArgs(flags, tc.args)
 		actual.github = flagutil.GitHubOptions{}
+		actual.gitee = flagutil.GiteeOptions{}
 		switch {
 		case err == nil && tc.expected == nil:
 			t.Errorf("%s: failed to return an error", tc.name)
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
@@ -37,6 +38,12 @@ import (
 	"k8s.io/test-infra/prow/metrics"
 	"k8s.io/test-infra/prow/pjutil"
 	"k8s.io/test-infra/prow/tide"
+	tidegitee "k8s.io/test-infra/prow/tide/gitee"
+)
+
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
 )
 
 type options struct {
@@ -52,8 +59,12 @@ type options struct {
 	runOnce    bool
 	kubernetes prowflagutil.KubernetesOptions
 	github     prowflagutil.GitHubOptions
+	gitee      prowflagutil.GiteeOptions
 	storage    prowflagutil.StorageClientOptions
 
+	// provider is the code hosting platform of the repositories Tide merges.
+	provider string
+
 	maxRecordsPerPool int
 	// historyURI where Tide should store its action history.
 	// Can be /local/path, gs://path/to/object or s3://path/to/object.
@@ -71,12 +82,16 @@ type options struct {
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.storage} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.gitee, &o.storage} {
 		if err := group.Validate(o.dryRun); err != nil {
 			return err
 		}
 	}
 
+	if o.provider != providerGitHub && o.provider != providerGitee {
+		return fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, o.provider)
+	}
+
 	return nil
 }
 
@@ -87,9 +102,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.jobConfigPath, "job-config-path", "", "Path to prow job configs.")
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Whether to mutate any real-world state.")
 	fs.BoolVar(&o.runOnce, "run-once", false, "If true, run only once then quit.")
-	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.storage} {
+	for _, group := range []flagutil.OptionGroup{&o.kubernetes, &o.github, &o.gitee, &o.storage} {
 		group.AddFlags(fs)
 	}
+	fs.StringVar(&o.provider, "provider", providerGitHub, fmt.Sprintf("The code hosting platform of the repositories to merge, %q or %q.", providerGitHub, providerGitee))
 	fs.IntVar(&o.syncThrottle, "sync-hourly-tokens", 800, "The maximum number of tokens per hour to be used by the sync controller.")
 	fs.IntVar(&o.statusThrottle, "status-hourly-tokens", 400, "The maximum number of tokens per hour to be used by the status controller.")
 	fs.IntVar(&o.maxRecordsPerPool, "max-records-per-pool", 1000, "The maximum number of history records stored for an individual Tide pool.")
@@ -124,35 +140,6 @@ func main() {
 	}
 	cfg := configAgent.Config
 
-	secretAgent := &secret.Agent{}
-	if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
-		logrus.WithError(err).Fatal("Error starting secrets agent.")
-	}
-
-	githubSync, err := o.github.GitHubClientWithLogFields(secretAgent, o.dryRun, logrus.Fields{"controller": "sync"})
-	if err != nil {
-		logrus.WithError(err).Fatal("Error getting GitHub client for sync.")
-	}
-
-	githubStatus, err := o.github.GitHubClientWithLogFields(secretAgent, o.dryRun, logrus.Fields{"controller": "status-update"})
-	if err != nil {
-		logrus.WithError(err).Fatal("Error getting GitHub client for status.")
-	}
-
-	// The sync loop should be allowed more tokens than the status loop because
-	// it has to list all PRs in the pool every loop while the status loop only
-	// has to list changed PRs every loop.
-	// The sync loop should have a much lower burst allowance than the status
-	// loop which may need to update many statuses upon restarting Tide after
-	// changing the context format or starting Tide on a new repo.
-	githubSync.Throttle(o.syncThrottle, 3*tokensPerIteration(o.syncThrottle, cfg().Tide.SyncPeriod.Duration))
-	githubStatus.Throttle(o.statusThrottle, o.statusThrottle/2)
-
-	gitClient, err := o.github.GitClient(secretAgent, o.dryRun)
-	if err != nil {
-		logrus.WithError(err).Fatal("Error getting Git client.")
-	}
-
 	kubeCfg, err := o.kubernetes.InfrastructureClusterConfig(o.dryRun)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting kubeconfig.")
@@ -163,7 +150,29 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Error constructing mgr.")
 	}
-	c, err := tide.NewController(githubSync, githubStatus, mgr, cfg, git.ClientFactoryFrom(gitClient), o.maxRecordsPerPool, opener, o.historyURI, o.statusURI, nil)
+
+	secretAgent := &secret.Agent{}
+	var providerSync, providerStatus tide.Provider
+	var gitClient git.ClientFactory
+	switch o.provider {
+	case providerGitee:
+		if err := secretAgent.Start([]string{o.gitee.TokenPath}); err != nil {
+			logrus.WithError(err).Fatal("Error starting secrets agent.")
+		}
+
+		providerSync, providerStatus, gitClient, err = giteeProviders(&o, secretAgent, mgr, cfg)
+	default:
+		if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+			logrus.WithError(err).Fatal("Error starting secrets agent.")
+		}
+
+		providerSync, providerStatus, gitClient, err = githubProviders(&o, secretAgent, cfg)
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting clients.")
+	}
+
+	c, err := tide.NewController(providerSync, providerStatus, mgr, cfg, gitClient, o.maxRecordsPerPool, opener, o.historyURI, o.statusURI, nil)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error creating Tide controller.")
 	}
@@ -209,6 +218,57 @@ func main() {
 	})
 }
 
+func githubProviders(o *options, secretAgent *secret.Agent, cfg config.Getter) (tide.Provider, tide.Provider, git.ClientFactory, error) {
+	githubSync, err := o.github.GitHubClientWithLogFields(secretAgent, o.dryRun, logrus.Fields{"controller": "sync"})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting GitHub client for sync: %v", err)
+	}
+
+	githubStatus, err := o.github.GitHubClientWithLogFields(secretAgent, o.dryRun, logrus.Fields{"controller": "status-update"})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting GitHub client for status: %v", err)
+	}
+
+	// The sync loop should be allowed more tokens than the status loop because
+	// it has to list all PRs in the pool every loop while the status loop only
+	// has to list changed PRs every loop.
+	// The sync loop should have a much lower burst allowance than the status
+	// loop which may need to update many statuses upon restarting Tide after
+	// changing the context format or starting Tide on a new repo.
+	githubSync.Throttle(o.syncThrottle, 3*tokensPerIteration(o.syncThrottle, cfg().Tide.SyncPeriod.Duration))
+	githubStatus.Throttle(o.statusThrottle, o.statusThrottle/2)
+
+	gitClient, err := o.github.GitClient(secretAgent, o.dryRun)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting Git client: %v", err)
+	}
+
+	return tide.NewGitHubProvider(githubSync), tide.NewGitHubProvider(githubStatus), git.ClientFactoryFrom(gitClient), nil
+}
+
+func giteeProviders(o *options, secretAgent *secret.Agent, mgr manager.Manager, cfg config.Getter) (tide.Provider, tide.Provider, git.ClientFactory, error) {
+	if err := tidegitee.ValidateQueries(cfg().Tide.Queries); err != nil {
+		return nil, nil, nil, err
+	}
+
+	giteeClient, err := o.gitee.GiteeClient(secretAgent, o.dryRun)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting Gitee client: %v", err)
+	}
+
+	gitClient, err := o.gitee.GitClient(secretAgent, o.dryRun)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error getting Git client: %v", err)
+	}
+
+	// Both controllers share one provider, because the status controller
+	// relies on the PRs found by the searches to write the tide status.
+	p := tidegitee.NewProvider(giteeClient, mgr.GetClient(), func() string {
+		return cfg().ProwJobNamespace
+	})
+	return p, p, gitClient, nil
+}
+
 func sync(c *tide.Controller) {
 	if err := c.Sync(); err != nil {
 		logrus.WithError(err).Error("Error syncing.")
@@ -86,6 +86,22 @@ func Test_gatherOptions(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "explicitly set --provider=gitee",
+			args: map[string]string{
+				"--provider": "gitee",
+			},
+			expected: func(o *options) {
+				o.provider = "gitee"
+			},
+		},
+		{
+			name: "unknown --provider",
+			args: map[string]string{
+				"--provider": "gitlab",
+			},
+			err: true,
+		},
 		{
 			name: "--dry-run=true requires --deck-url",
 			args: map[string]string{
@@ -107,10 +123,13 @@ func Test_gatherOptions(t *testing.T) {
 				statusThrottle:    400,
 				maxRecordsPerPool: 1000,
 				github:            flagutil.GitHubOptions{},
+				gitee:             flagutil.GiteeOptions{},
 				kubernetes:        flagutil.KubernetesOptions{DeckURI: "http://whatever"},
+				provider:          "github",
 			}
 			expectedfs := flag.NewFlagSet("fake-flags", flag.PanicOnError)
 			expected.github.AddFlags(expectedfs)
+			expected.gitee.AddFlags(expectedfs)
 			if tc.expected != nil {
 				tc.expected(expected)
 			}
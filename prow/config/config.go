@@ -483,6 +483,97 @@ type Plank struct {
 	// JobURLPrefixConfig is the host and path prefix under which job details
 	// will be viewable. Use `org/repo`, `org` or `*`as key and an url as value
 	JobURLPrefixConfig map[string]string `json:"job_url_prefix_config,omitempty"`
+
+	// Scheduling configures the order in which the triggered jobs are started
+	// when max_concurrency doesn't allow starting all of them.
+	Scheduling PlankScheduling `json:"scheduling,omitempty"`
+
+	// BuildClusters configures the pools of build clusters the jobs can
+	// target and the capacity of the build clusters.
+	BuildClusters PlankBuildClusters `json:"build_clusters,omitempty"`
+}
+
+// PlankBuildClusters configures how plank picks the build cluster of a job.
+type PlankBuildClusters struct {
+	// Pools maps the names usable as the `cluster` of the jobs to the build
+	// clusters the jobs may run on. Plank starts the jobs on the healthy
+	// cluster of the pool with the most free capacity.
+	Pools map[string][]string `json:"pools,omitempty"`
+	// Capacity maps the build clusters to the maximum number of pending and
+	// running pods plank keeps on them. Unset means no limit.
+	Capacity map[string]int `json:"capacity,omitempty"`
+}
+
+// Clusters returns the build clusters the jobs targeting the cluster or the
+// pool may run on.
+func (b PlankBuildClusters) Clusters(cluster string) []string {
+	if pool, ok := b.Pools[cluster]; ok {
+		return pool
+	}
+	return []string{cluster}
+}
+
+// Validate returns an error if a pool contains a cluster which isn't one of
+// the build clusters, or if a pool is named like a build cluster, which the
+// pool would shadow.
+func (b PlankBuildClusters) Validate(clusters sets.String) error {
+	for pool, members := range b.Pools {
+		if clusters.Has(pool) {
+			return fmt.Errorf("plank build cluster pool %s shadows the build cluster %s", pool, pool)
+		}
+		for _, cluster := range members {
+			if !clusters.Has(cluster) {
+				return fmt.Errorf("plank build cluster pool %s contains the unknown build cluster %s", pool, cluster)
+			}
+		}
+	}
+	return nil
+}
+
+// PlankScheduling configures how plank shares max_concurrency between the
+// triggered jobs.
+type PlankScheduling struct {
+	// PriorityClasses maps the job types to their priority, the jobs of a
+	// higher priority are started first. Defaults to 3 for postsubmits, 2 for
+	// presubmits and batches and 1 for periodics.
+	PriorityClasses map[prowapi.ProwJobType]int `json:"priority_classes,omitempty"`
+	// Weights maps `org/repo`, `org` or `*` to the share of the running jobs
+	// the repo or the org gets when the jobs of several of them are waiting.
+	// The repos without a weight share the one of their org. Defaults to 1.
+	Weights map[string]int `json:"weights,omitempty"`
+}
+
+// Priority returns the priority class of the job type.
+func (s PlankScheduling) Priority(jobType prowapi.ProwJobType) int {
+	return s.PriorityClasses[jobType]
+}
+
+// Tenant returns the `org/repo` or the `org` whose share of the running
+// jobs the job counts against, or an empty string for the jobs without refs.
+func (s PlankScheduling) Tenant(spec prowapi.ProwJobSpec) string {
+	refs := spec.Refs
+	if refs == nil && len(spec.ExtraRefs) > 0 {
+		refs = &spec.ExtraRefs[0]
+	}
+	if refs == nil {
+		return ""
+	}
+	orgRepo := fmt.Sprintf("%s/%s", refs.Org, refs.Repo)
+	if _, ok := s.Weights[orgRepo]; ok {
+		return orgRepo
+	}
+	return refs.Org
+}
+
+// Weight returns the weight of the tenant.
+func (s PlankScheduling) Weight(tenant string) int {
+	if weight, ok := s.Weights[tenant]; ok {
+		return weight
+	}
+	if weight, ok := s.Weights["*"]; ok {
+		return weight
+	}
+	return 1
 }
 
 func (p Plank) GetDefaultDecorationConfigs(repo string) *prowapi.DecorationConfig {
@@ -517,6 +608,23 @@ type Gerrit struct {
 	// RateLimit defines how many changes to query per gerrit API call
 	// default is 5
 	RateLimit int `json:"ratelimit,omitempty"`
+	// EventsInterval is how often we fetch the events of the binded gerrit
+	// instances when the adapter runs with --stream-events, default is 5s
+	EventsInterval *metav1.Duration `json:"events_interval,omitempty"`
+	// Submit configures how the gerrit adapter submits the changes when it
+	// runs with --submit.
+	Submit GerritSubmit `json:"submit,omitempty"`
+}
+
+// GerritSubmit is config for submitting the gerrit changes once their
+// required presubmits pass, like tide does for the GitHub PRs.
+type GerritSubmit struct {
+	// Query is added to the query of the open changes of each project to find
+	// the changes to submit, default is "is:submittable", which requires the
+	// submit rules of the project, like the required labels, to pass.
+	Query string `json:"query,omitempty"`
+	// SyncPeriod is how often the changes are submitted, default is 1m.
+	SyncPeriod *metav1.Duration `json:"sync_period,omitempty"`
 }
 
 // JenkinsOperator is config for the jenkins-operator controller.
@@ -1428,6 +1536,46 @@ func parseProwConfig(c *Config) error {
 		c.Plank.PodUnscheduledTimeout = &metav1.Duration{Duration: 24 * time.Hour}
 	}
 
+	if len(c.Plank.Scheduling.PriorityClasses) == 0 {
+		c.Plank.Scheduling.PriorityClasses = map[prowapi.ProwJobType]int{
+			prowapi.PostsubmitJob: 3,
+			prowapi.PresubmitJob:  2,
+			prowapi.BatchJob:      2,
+			prowapi.PeriodicJob:   1,
+		}
+	}
+
+	for t := range c.Plank.Scheduling.PriorityClasses {
+		switch t {
+		case prowapi.PresubmitJob, prowapi.PostsubmitJob, prowapi.PeriodicJob, prowapi.BatchJob:
+		default:
+			return fmt.Errorf("invalid job type in plank priority_classes: %v", t)
+		}
+	}
+
+	for k, weight := range c.Plank.Scheduling.Weights {
+		if weight <= 0 {
+			return fmt.Errorf("plank weights[%s] must be positive, got %d", k, weight)
+		}
+	}
+
+	for pool, clusters := range c.Plank.BuildClusters.Pools {
+		if len(clusters) == 0 {
+			return fmt.Errorf("plank build cluster pool %s has no clusters", pool)
+		}
+		for _, cluster := range clusters {
+			if _, ok := c.Plank.BuildClusters.Pools[cluster]; ok {
+				return fmt.Errorf("plank build cluster pool %s contains the pool %s", pool, cluster)
+			}
+		}
+	}
+
+	for cluster, capacity := range c.Plank.BuildClusters.Capacity {
+		if capacity < 0 {
+			return fmt.Errorf("plank capacity[%s] must not be negative, got %d", cluster, capacity)
+		}
+	}
+
 	if c.Gerrit.TickInterval == nil {
 		c.Gerrit.TickInterval = &metav1.Duration{Duration: time.Minute}
 	}
@@ -1436,6 +1584,18 @@ func parseProwConfig(c *Config) error {
 		c.Gerrit.RateLimit = 5
 	}
 
+	if c.Gerrit.EventsInterval == nil {
+		c.Gerrit.EventsInterval = &metav1.Duration{Duration: 5 * time.Second}
+	}
+
+	if c.Gerrit.Submit.Query == "" {
+		c.Gerrit.Submit.Query = "is:submittable"
+	}
+
+	if c.Gerrit.Submit.SyncPeriod == nil {
+		c.Gerrit.Submit.SyncPeriod = &metav1.Duration{Duration: time.Minute}
+	}
+
 	if len(c.GitHubReporter.JobTypesToReport) == 0 {
 		c.GitHubReporter.JobTypesToReport = append(c.GitHubReporter.JobTypesToReport, prowapi.PresubmitJob, prowapi.PostsubmitJob)
 	}
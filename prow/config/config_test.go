@@ -2466,6 +2466,212 @@ func TestPlankJobURLPrefix(t *testing.T) {
 	}
 }
 
+func TestPlankScheduling(t *testing.T) {
+	scheduling := PlankScheduling{
+		Weights: map[string]int{
+			"*":            2,
+			"kubernetes":   4,
+			"kubernetes/k": 1,
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		spec           prowapi.ProwJobSpec
+		expectedTenant string
+		expectedWeight int
+	}{
+		{
+			name:           "job without refs",
+			spec:           prowapi.ProwJobSpec{Type: prowapi.PeriodicJob},
+			expectedWeight: 2,
+		},
+		{
+			name:           "repo with its own weight",
+			spec:           prowapi.ProwJobSpec{Refs: &prowapi.Refs{Org: "kubernetes", Repo: "k"}},
+			expectedTenant: "kubernetes/k",
+			expectedWeight: 1,
+		},
+		{
+			name:           "repo sharing the weight of its org",
+			spec:           prowapi.ProwJobSpec{Refs: &prowapi.Refs{Org: "kubernetes", Repo: "test-infra"}},
+			expectedTenant: "kubernetes",
+			expectedWeight: 4,
+		},
+		{
+			name:           "periodic with extra refs",
+			spec:           prowapi.ProwJobSpec{ExtraRefs: []prowapi.Refs{{Org: "openeuler", Repo: "community"}}},
+			expectedTenant: "openeuler",
+			expectedWeight: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenant := scheduling.Tenant(tc.spec)
+			if tenant != tc.expectedTenant {
+				t.Errorf("expected tenant %q, got %q", tc.expectedTenant, tenant)
+			}
+			if weight := scheduling.Weight(tenant); weight != tc.expectedWeight {
+				t.Errorf("expected weight %d, got %d", tc.expectedWeight, weight)
+			}
+		})
+	}
+}
+
+func TestPlankSchedulingDefaults(t *testing.T) {
+	testCases := []struct {
+		name        string
+		scheduling  PlankScheduling
+		expected    map[prowapi.ProwJobType]int
+		expectError bool
+	}{
+		{
+			name: "default priority classes",
+			expected: map[prowapi.ProwJobType]int{
+				prowapi.PostsubmitJob: 3,
+				prowapi.PresubmitJob:  2,
+				prowapi.BatchJob:      2,
+				prowapi.PeriodicJob:   1,
+			},
+		},
+		{
+			name: "configured priority classes are kept",
+			scheduling: PlankScheduling{
+				PriorityClasses: map[prowapi.ProwJobType]int{prowapi.PeriodicJob: 5},
+			},
+			expected: map[prowapi.ProwJobType]int{prowapi.PeriodicJob: 5},
+		},
+		{
+			name: "invalid job type",
+			scheduling: PlankScheduling{
+				PriorityClasses: map[prowapi.ProwJobType]int{"nightly": 5},
+			},
+			expectError: true,
+		},
+		{
+			name: "weight must be positive",
+			scheduling: PlankScheduling{
+				Weights: map[string]int{"kubernetes": 0},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{ProwConfig: ProwConfig{Plank: Plank{Scheduling: tc.scheduling}}}
+			err := parseProwConfig(c)
+			if (err != nil) != tc.expectError {
+				t.Fatalf("expected error %t, got %v", tc.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(c.Plank.Scheduling.PriorityClasses, tc.expected) {
+				t.Errorf("expected priority classes %v, got %v", tc.expected, c.Plank.Scheduling.PriorityClasses)
+			}
+		})
+	}
+}
+
+func TestPlankBuildClusters(t *testing.T) {
+	testCases := []struct {
+		name             string
+		buildClusters    PlankBuildClusters
+		cluster          string
+		expectedClusters []string
+		expectError      bool
+	}{
+		{
+			name:             "cluster",
+			cluster:          "default",
+			expectedClusters: []string{"default"},
+		},
+		{
+			name: "pool",
+			buildClusters: PlankBuildClusters{
+				Pools: map[string][]string{"gpu": {"gpu-east", "gpu-west"}},
+			},
+			cluster:          "gpu",
+			expectedClusters: []string{"gpu-east", "gpu-west"},
+		},
+		{
+			name: "empty pool",
+			buildClusters: PlankBuildClusters{
+				Pools: map[string][]string{"gpu": {}},
+			},
+			expectError: true,
+		},
+		{
+			name: "pool of pools",
+			buildClusters: PlankBuildClusters{
+				Pools: map[string][]string{"gpu": {"gpu-east"}, "all": {"gpu", "default"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative capacity",
+			buildClusters: PlankBuildClusters{
+				Capacity: map[string]int{"default": -1},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Config{ProwConfig: ProwConfig{Plank: Plank{BuildClusters: tc.buildClusters}}}
+			err := parseProwConfig(c)
+			if (err != nil) != tc.expectError {
+				t.Fatalf("expected error %t, got %v", tc.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+			if actual := c.Plank.BuildClusters.Clusters(tc.cluster); !reflect.DeepEqual(actual, tc.expectedClusters) {
+				t.Errorf("expected clusters %v, got %v", tc.expectedClusters, actual)
+			}
+		})
+	}
+}
+
+func TestValidatePlankBuildClusters(t *testing.T) {
+	clusters := sets.NewString("default", "gpu-east", "gpu-west")
+	testCases := []struct {
+		name        string
+		pools       map[string][]string
+		expectError bool
+	}{
+		{
+			name: "no pools",
+		},
+		{
+			name:  "pool of build clusters",
+			pools: map[string][]string{"gpu": {"gpu-east", "gpu-west"}},
+		},
+		{
+			name:        "pool with an unknown cluster",
+			pools:       map[string][]string{"gpu": {"gpu-east", "gpu-north"}},
+			expectError: true,
+		},
+		{
+			name:        "pool shadowing a build cluster",
+			pools:       map[string][]string{"default": {"gpu-east", "gpu-west"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := PlankBuildClusters{Pools: tc.pools}.Validate(clusters)
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %t, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
 func TestValidateComponentConfig(t *testing.T) {
 	testCases := []struct {
 		name        string
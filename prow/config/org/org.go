@@ -72,6 +72,12 @@ type Repo struct {
 	Previously []string `json:"previously,omitempty"`
 
 	OnCreate *RepoCreateOptions `json:"on_create,omitempty"`
+
+	// Collaborators maps the logins of the collaborators to their permission
+	// on the repository, they are left alone when unset. Only the Gitee
+	// backend manages them, read, write and admin becoming the pull, push
+	// and admin permissions.
+	Collaborators map[string]github.RepoPermissionLevel `json:"collaborators,omitempty"`
 }
 
 // Config declares org metadata as well as its people and teams.
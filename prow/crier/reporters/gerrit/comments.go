@@ -0,0 +1,305 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/GoogleCloudPlatform/testgrid/metadata/junit"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gcsupload"
+	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+)
+
+const (
+	// maxMessageLength is the maximum length of the failure message of a
+	// test case in an inline comment.
+	maxMessageLength = 1000
+	// maxCachedResults is the number of builds whose JUnit results are
+	// cached.
+	maxCachedResults = 1000
+)
+
+var (
+	// junitRegexp matches the names of the JUnit files in the artifacts,
+	// like the JUnit lens of spyglass does.
+	junitRegexp = regexp.MustCompile(`^junit.*\.xml$`)
+	// findingRegexp matches the golint-style findings in the failure
+	// messages, like "pkg/foo/bar.go:12:3: exported func Bar should have comment".
+	findingRegexp = regexp.MustCompile(`^\s*(?:\./)?([^\s:]+):(\d+)(?::\d+)?:\s*(.+)$`)
+)
+
+// resultReader reads the JUnit results uploaded by a finished job.
+type resultReader interface {
+	JUnit(ctx context.Context, pj *v1.ProwJob) ([]junit.Suites, error)
+}
+
+// gcsResults reads the JUnit files uploaded to GCS by the decorated jobs.
+type gcsResults struct {
+	client *storage.Client
+}
+
+func (r *gcsResults) JUnit(ctx context.Context, pj *v1.ProwJob) ([]junit.Suites, error) {
+	dc := pj.Spec.DecorationConfig
+	if dc == nil || dc.GCSConfiguration == nil || pj.Status.BuildID == "" {
+		return nil, nil
+	}
+	bucket := dc.GCSConfiguration.Bucket
+	if strings.Contains(bucket, "://") {
+		if !strings.HasPrefix(bucket, "gs://") {
+			return nil, fmt.Errorf("unsupported bucket %q", bucket)
+		}
+		bucket = strings.TrimPrefix(bucket, "gs://")
+	}
+
+	spec := downwardapi.NewJobSpec(pj.Spec, pj.Status.BuildID, pj.Name)
+	_, dir, _ := gcsupload.PathsForJob(dc.GCSConfiguration, &spec, "")
+	bkt := r.client.Bucket(bucket)
+	it := bkt.Objects(ctx, &storage.Query{Prefix: path.Join(dir, "artifacts") + "/"})
+
+	var results []junit.Suites
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return results, nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to list the artifacts of %s: %v", pj.Name, err)
+		}
+		if !junitRegexp.MatchString(path.Base(attrs.Name)) {
+			continue
+		}
+
+		reader, err := bkt.Object(attrs.Name).NewReader(ctx)
+		if err != nil {
+			return results, fmt.Errorf("failed to open %s: %v", attrs.Name, err)
+		}
+		buf, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return results, fmt.Errorf("failed to read %s: %v", attrs.Name, err)
+		}
+		suites, err := junit.Parse(buf)
+		if err != nil {
+			logrus.WithError(err).WithField("file", attrs.Name).Warn("Failed to parse the JUnit file.")
+			continue
+		}
+		results = append(results, suites)
+	}
+}
+
+// cachedResults caches the JUnit results of the builds, which are read
+// again by the reports of the other jobs of the revision and don't change
+// once the builds finished.
+type cachedResults struct {
+	results resultReader
+	cache   *lru.Cache
+}
+
+func newCachedResults(results resultReader) (*cachedResults, error) {
+	cache, err := lru.New(maxCachedResults)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedResults{results: results, cache: cache}, nil
+}
+
+func (r *cachedResults) JUnit(ctx context.Context, pj *v1.ProwJob) ([]junit.Suites, error) {
+	if results, ok := r.cache.Get(pj.Name); ok {
+		return results.([]junit.Suites), nil
+	}
+	results, err := r.results.JUnit(ctx, pj)
+	if err != nil {
+		return results, err
+	}
+	r.cache.Add(pj.Name, results)
+	return results, nil
+}
+
+// finding is a failure located at a line of a file.
+type finding struct {
+	job     string
+	path    string
+	line    int
+	message string
+}
+
+// findings returns the failures of the test cases which are located by the
+// "file" and "line" properties of the test case, or by the golint-style
+// lines of the failure message.
+func findings(job string, results []junit.Suites) []finding {
+	var found []finding
+	var walk func(suites []junit.Suite)
+	walk = func(suites []junit.Suite) {
+		for _, suite := range suites {
+			walk(suite.Suites)
+			for _, result := range suite.Results {
+				if result.Failure == nil {
+					continue
+				}
+				if file, line, ok := location(result); ok {
+					message := result.Name + ": " + result.Message(maxMessageLength)
+					found = append(found, finding{job: job, path: file, line: line, message: message})
+					continue
+				}
+				for _, text := range strings.Split(*result.Failure, "\n") {
+					m := findingRegexp.FindStringSubmatch(text)
+					if m == nil {
+						continue
+					}
+					line, err := strconv.Atoi(m[2])
+					if err != nil || line <= 0 {
+						continue
+					}
+					found = append(found, finding{job: job, path: m[1], line: line, message: m[3]})
+				}
+			}
+		}
+	}
+	for _, suites := range results {
+		walk(suites.Suites)
+	}
+	return found
+}
+
+// location returns the file and the line set in the properties of the
+// test case.
+func location(result junit.Result) (string, int, bool) {
+	if result.Properties == nil {
+		return "", 0, false
+	}
+	var file string
+	var line int
+	for _, p := range result.Properties.PropertyList {
+		switch p.Name {
+		case "file":
+			file = strings.TrimPrefix(p.Value, "./")
+		case "line":
+			line, _ = strconv.Atoi(p.Value)
+		}
+	}
+	return file, line, file != "" && line > 0
+}
+
+func commentKey(robot, path string, line int, message string) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s", robot, path, line, message)
+}
+
+// robotComments returns the inline comments of the findings of the failed
+// jobs on the lines changed by the revision, leaving out the ones already
+// posted on it and the ones beyond maxComments, which are counted. The
+// comments already posted on the revision count against maxComments.
+func (c *Client) robotComments(logger *logrus.Entry, pjs []*v1.ProwJob, instance, id, revision string) (map[string][]client.RobotCommentInput, int) {
+	if c.results == nil || c.maxComments <= 0 {
+		return nil, 0
+	}
+
+	runs := map[string]*v1.ProwJob{}
+	var found []finding
+	for _, pj := range pjs {
+		if pj.Status.State != v1.FailureState {
+			continue
+		}
+		results, err := c.results.JUnit(context.Background(), pj)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to read the JUnit results of %s.", pj.Name)
+		}
+		runs[pj.Spec.Job] = pj
+		found = append(found, findings(pj.Spec.Job, results)...)
+	}
+	if len(found) == 0 {
+		return nil, 0
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].job != found[j].job {
+			return found[i].job < found[j].job
+		}
+		if found[i].path != found[j].path {
+			return found[i].path < found[j].path
+		}
+		return found[i].line < found[j].line
+	})
+
+	var files []string
+	for _, f := range found {
+		files = append(files, f.path)
+	}
+	changed, err := c.gc.ChangedLines(instance, id, revision, files)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the changed lines, skipping the inline comments.")
+		return nil, 0
+	}
+	existing, err := c.gc.ListRobotComments(instance, id, revision)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to list the robot comments, skipping the inline comments.")
+		return nil, 0
+	}
+
+	posted := sets.NewString()
+	var total int
+	for file, comments := range existing {
+		for _, comment := range comments {
+			posted.Insert(commentKey(comment.RobotID, file, comment.Line, comment.Message))
+			total++
+		}
+	}
+
+	comments := map[string][]client.RobotCommentInput{}
+	var count, dropped int
+	for _, f := range found {
+		if !changed[f.path].Has(f.line) {
+			continue
+		}
+		key := commentKey(f.job, f.path, f.line, f.message)
+		if posted.Has(key) {
+			continue
+		}
+		posted.Insert(key)
+		if total+count >= c.maxComments {
+			dropped++
+			continue
+		}
+		run := runs[f.job]
+		comment := client.RobotCommentInput{
+			RobotID:    f.job,
+			RobotRunID: run.Status.BuildID,
+			URL:        run.Status.URL,
+		}
+		comment.Line = f.line
+		comment.Message = f.message
+		comments[f.path] = append(comments[f.path], comment)
+		count++
+	}
+	if count == 0 {
+		return nil, dropped
+	}
+	return comments, dropped
+}
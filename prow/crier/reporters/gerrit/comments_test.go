@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata/junit"
+	"github.com/andygrunwald/go-gerrit"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/kube"
+)
+
+const lintJUnit = `<testsuites>
+  <testsuite name="verify">
+    <testcase name="golint">
+      <failure>Found 3 lint errors:
+./pkg/foo/foo.go:12:3: exported func Foo should have comment or be unexported
+pkg/foo/foo.go:20: line is too long
+pkg/bar/bar.go:7:1: don't use underscores in Go names
+not a finding: pkg/foo/foo.go</failure>
+    </testcase>
+    <testcase name="passing"/>
+  </testsuite>
+  <testsuite name="unit">
+    <testsuite name="nested">
+      <testcase name="TestFoo">
+        <properties>
+          <property name="file" value="pkg/foo/foo_test.go"></property>
+          <property name="line" value="42"></property>
+        </properties>
+        <failure>expected 1, got 2</failure>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+type fakeResults map[string]string
+
+func (f fakeResults) JUnit(ctx context.Context, pj *v1.ProwJob) ([]junit.Suites, error) {
+	xml, ok := f[pj.Spec.Job]
+	if !ok {
+		return nil, nil
+	}
+	suites, err := junit.Parse([]byte(xml))
+	if err != nil {
+		return nil, err
+	}
+	return []junit.Suites{suites}, nil
+}
+
+func TestFindings(t *testing.T) {
+	suites, err := junit.Parse([]byte(lintJUnit))
+	if err != nil {
+		t.Fatalf("failed to parse the JUnit: %v", err)
+	}
+
+	expected := []finding{
+		{job: "lint", path: "pkg/foo/foo.go", line: 12, message: "exported func Foo should have comment or be unexported"},
+		{job: "lint", path: "pkg/foo/foo.go", line: 20, message: "line is too long"},
+		{job: "lint", path: "pkg/bar/bar.go", line: 7, message: "don't use underscores in Go names"},
+		{job: "lint", path: "pkg/foo/foo_test.go", line: 42, message: "TestFoo: expected 1, got 2"},
+	}
+	if actual := findings("lint", []junit.Suites{suites}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected findings %v, got %v", expected, actual)
+	}
+}
+
+func TestReportRobotComments(t *testing.T) {
+	newJob := func(name string, state v1.ProwJobState) *v1.ProwJob {
+		return &v1.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					client.GerritRevision: "abc",
+					kube.ProwJobTypeLabel: presubmit,
+				},
+				Annotations: map[string]string{
+					client.GerritID:       "123-abc",
+					client.GerritInstance: "gerrit",
+				},
+			},
+			Spec: v1.ProwJobSpec{
+				Type: v1.PresubmitJob,
+				Job:  name,
+			},
+			Status: v1.ProwJobStatus{
+				State:   state,
+				URL:     "guber/" + name,
+				BuildID: "42",
+			},
+		}
+	}
+
+	changed := map[string]sets.Int{
+		"pkg/foo/foo.go":      sets.NewInt(12, 13, 20),
+		"pkg/foo/foo_test.go": sets.NewInt(42),
+	}
+
+	testcases := []struct {
+		name        string
+		pj          *v1.ProwJob
+		existing    map[string][]client.RobotCommentInfo
+		maxComments int
+
+		expectedComments map[string][]string
+		expectedNote     string
+	}{
+		{
+			name:        "findings on the changed lines are posted",
+			pj:          newJob("lint", v1.FailureState),
+			maxComments: 10,
+			expectedComments: map[string][]string{
+				"pkg/foo/foo.go":      {"12: exported func Foo should have comment or be unexported", "20: line is too long"},
+				"pkg/foo/foo_test.go": {"42: TestFoo: expected 1, got 2"},
+			},
+		},
+		{
+			name:        "comments are disabled",
+			pj:          newJob("lint", v1.FailureState),
+			maxComments: 0,
+		},
+		{
+			name:        "passing jobs are not commented",
+			pj:          newJob("lint", v1.SuccessState),
+			maxComments: 10,
+		},
+		{
+			name: "comments posted by a previous run are left out",
+			pj:   newJob("lint", v1.FailureState),
+			existing: map[string][]client.RobotCommentInfo{
+				"pkg/foo/foo.go": {
+					{
+						CommentInfo: gerrit.CommentInfo{Line: 20, Message: "line is too long"},
+						RobotID:     "lint",
+						RobotRunID:  "41",
+					},
+				},
+			},
+			maxComments: 10,
+			expectedComments: map[string][]string{
+				"pkg/foo/foo.go":      {"12: exported func Foo should have comment or be unexported"},
+				"pkg/foo/foo_test.go": {"42: TestFoo: expected 1, got 2"},
+			},
+		},
+		{
+			name:        "comments beyond the cap are counted",
+			pj:          newJob("lint", v1.FailureState),
+			maxComments: 2,
+			expectedComments: map[string][]string{
+				"pkg/foo/foo.go": {"12: exported func Foo should have comment or be unexported", "20: line is too long"},
+			},
+			expectedNote: "1 more inline comments were not posted.",
+		},
+		{
+			name: "comments posted by a previous run count against the cap",
+			pj:   newJob("lint", v1.FailureState),
+			existing: map[string][]client.RobotCommentInfo{
+				"pkg/foo/foo.go": {
+					{
+						CommentInfo: gerrit.CommentInfo{Line: 20, Message: "line is too long"},
+						RobotID:     "lint",
+						RobotRunID:  "41",
+					},
+				},
+			},
+			maxComments: 2,
+			expectedComments: map[string][]string{
+				"pkg/foo/foo.go": {"12: exported func Foo should have comment or be unexported"},
+			},
+			expectedNote: "1 more inline comments were not posted.",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fgc := &fgc{instance: "gerrit", existing: tc.existing, changed: changed}
+			reporter := &Client{
+				gc:          fgc,
+				lister:      fakeLister{pjs: []*v1.ProwJob{tc.pj}},
+				results:     fakeResults{"lint": lintJUnit},
+				maxComments: tc.maxComments,
+			}
+
+			if _, err := reporter.Report(tc.pj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			actual := map[string][]string{}
+			for file, comments := range fgc.comments {
+				for _, comment := range comments {
+					if comment.RobotID != "lint" || comment.RobotRunID != "42" || comment.URL != "guber/lint" {
+						t.Errorf("expected the comment to come from the run 42 of lint, got %#v", comment)
+					}
+					actual[file] = append(actual[file], fmt.Sprintf("%d: %s", comment.Line, comment.Message))
+				}
+			}
+			if len(actual) == 0 {
+				actual = nil
+			}
+			if !reflect.DeepEqual(actual, tc.expectedComments) {
+				t.Errorf("expected comments %v, got %v", tc.expectedComments, actual)
+			}
+			if tc.expectedNote != "" && !strings.Contains(fgc.reportMessage, tc.expectedNote) {
+				t.Errorf("expected the message to contain %q, got %q", tc.expectedNote, fgc.reportMessage)
+			}
+		})
+	}
+}
+
+type countingResults struct {
+	fakeResults
+	reads int
+}
+
+func (c *countingResults) JUnit(ctx context.Context, pj *v1.ProwJob) ([]junit.Suites, error) {
+	c.reads++
+	return c.fakeResults.JUnit(ctx, pj)
+}
+
+func TestCachedResults(t *testing.T) {
+	counting := &countingResults{fakeResults: fakeResults{"lint": lintJUnit}}
+	results, err := newCachedResults(counting)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := &v1.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "first"}, Spec: v1.ProwJobSpec{Job: "lint"}}
+	second := &v1.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: "second"}, Spec: v1.ProwJobSpec{Job: "lint"}}
+	for _, pj := range []*v1.ProwJob{first, second, first, second} {
+		suites, err := results.JUnit(context.Background(), pj)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(suites) != 1 {
+			t.Errorf("expected the results of %s, got %v", pj.Name, suites)
+		}
+	}
+	if counting.reads != 2 {
+		t.Errorf("expected the results of each build to be read once, read %d times", counting.reads)
+	}
+}
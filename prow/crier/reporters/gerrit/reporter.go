@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"strings"
 
+	"cloud.google.com/go/storage"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	pjlister "k8s.io/test-infra/prow/client/listers/prowjobs/v1"
@@ -61,12 +63,20 @@ var (
 
 type gerritClient interface {
 	SetReview(instance, id, revision, message string, labels map[string]string) error
+	SetReviewWithRobotComments(instance, id, revision, message string, labels map[string]string, comments map[string][]client.RobotCommentInput) error
+	ListRobotComments(instance, id, revision string) (map[string][]client.RobotCommentInfo, error)
+	ChangedLines(instance, id, revision string, files []string) (map[string]sets.Int, error)
 }
 
 // Client is a gerrit reporter client
 type Client struct {
 	gc     gerritClient
 	lister pjlister.ProwJobLister
+	// results reads the JUnit results of the failed jobs, whose findings
+	// on the changed lines are posted as inline robot comments, at most
+	// maxComments of them per revision.
+	results     resultReader
+	maxComments int
 }
 
 // Job is the view of a prowjob scoped for a report
@@ -83,17 +93,28 @@ type JobReport struct {
 	header  string
 }
 
-// NewReporter returns a reporter client
-func NewReporter(cookiefilePath string, projects map[string][]string, lister pjlister.ProwJobLister) (*Client, error) {
+// NewReporter returns a reporter client. The JUnit results of the failed
+// jobs are read from storage to post up to maxInlineComments inline comments
+// per revision, none are posted if storage is nil.
+func NewReporter(cookiefilePath string, projects map[string][]string, lister pjlister.ProwJobLister, storage *storage.Client, maxInlineComments int) (*Client, error) {
 	gc, err := client.NewClient(projects)
 	if err != nil {
 		return nil, err
 	}
 	gc.Start(cookiefilePath)
-	return &Client{
-		gc:     gc,
-		lister: lister,
-	}, nil
+	c := &Client{
+		gc:          gc,
+		lister:      lister,
+		maxComments: maxInlineComments,
+	}
+	if storage != nil {
+		results, err := newCachedResults(&gcsResults{client: storage})
+		if err != nil {
+			return nil, err
+		}
+		c.results = results
+	}
+	return c, nil
 }
 
 // GetName returns the name of the reporter
@@ -215,6 +236,11 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 		logger.Warn("Tried to report empty or aborted jobs.")
 		return nil, nil
 	}
+	comments, dropped := c.robotComments(logger, toReportJobs, gerritInstance, gerritID, gerritRevision)
+	if dropped > 0 {
+		message += fmt.Sprintf("\n%d more inline comments were not posted.\n", dropped)
+	}
+
 	var reviewLabels map[string]string
 	if reportLabel != "" {
 		var vote string
@@ -232,7 +258,7 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 	}
 
 	logger.Infof("Reporting to instance %s on id %s with message %s", gerritInstance, gerritID, message)
-	if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, reviewLabels); err != nil {
+	if err := c.setReview(gerritInstance, gerritID, gerritRevision, message, reviewLabels, comments); err != nil {
 		logger.WithError(err).Errorf("fail to set review with label %q on change ID %s", reportLabel, gerritID)
 
 		if reportLabel == "" {
@@ -240,7 +266,7 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 		}
 		// Retry without voting on a label
 		message := fmt.Sprintf("[NOTICE]: Prow Bot cannot access %s label!\n%s", reportLabel, message)
-		if err := c.gc.SetReview(gerritInstance, gerritID, gerritRevision, message, nil); err != nil {
+		if err := c.setReview(gerritInstance, gerritID, gerritRevision, message, nil, comments); err != nil {
 			logger.WithError(err).Errorf("fail to set plain review on change ID %s", gerritID)
 			return nil, err
 		}
@@ -250,6 +276,13 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 	return toReportJobs, nil
 }
 
+func (c *Client) setReview(instance, id, revision, message string, labels map[string]string, comments map[string][]client.RobotCommentInput) error {
+	if len(comments) == 0 {
+		return c.gc.SetReview(instance, id, revision, message, labels)
+	}
+	return c.gc.SetReviewWithRobotComments(instance, id, revision, message, labels, comments)
+}
+
 func statusIcon(state v1.ProwJobState) string {
 	icon, ok := stateIcon[state]
 	if !ok {
@@ -25,6 +25,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	pjlister "k8s.io/test-infra/prow/client/listers/prowjobs/v1"
 	"k8s.io/test-infra/prow/gerrit/client"
@@ -42,6 +43,9 @@ type fgc struct {
 	reportMessage string
 	reportLabel   map[string]string
 	instance      string
+	comments      map[string][]client.RobotCommentInput
+	existing      map[string][]client.RobotCommentInfo
+	changed       map[string]sets.Int
 }
 
 func (f *fgc) SetReview(instance, id, revision, message string, labels map[string]string) error {
@@ -58,6 +62,22 @@ func (f *fgc) SetReview(instance, id, revision, message string, labels map[strin
 	return nil
 }
 
+func (f *fgc) SetReviewWithRobotComments(instance, id, revision, message string, labels map[string]string, comments map[string][]client.RobotCommentInput) error {
+	if err := f.SetReview(instance, id, revision, message, labels); err != nil {
+		return err
+	}
+	f.comments = comments
+	return nil
+}
+
+func (f *fgc) ListRobotComments(instance, id, revision string) (map[string][]client.RobotCommentInfo, error) {
+	return f.existing, nil
+}
+
+func (f *fgc) ChangedLines(instance, id, revision string, files []string) (map[string]sets.Int, error) {
+	return f.changed, nil
+}
+
 type fakeLister struct {
 	pjs []*v1.ProwJob
 }
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitee implements a reporter interface for gitee
+package gitee
+
+import (
+	"fmt"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/crier/reporters/internal/prlock"
+	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github/report"
+)
+
+const (
+	// GiteeReporterName is the name for gitee reporter
+	GiteeReporterName = "gitee-reporter"
+)
+
+// Client is a gitee reporter client
+type Client struct {
+	gc          giteeClient
+	config      config.Getter
+	reportAgent v1.ProwJobAgent
+	prLocks     *prlock.ShardedLock
+}
+
+// NewReporter returns a reporter client
+func NewReporter(gc giteeClient, cfg config.Getter, reportAgent v1.ProwJobAgent) *Client {
+	c := &Client{
+		gc:          gc,
+		config:      cfg,
+		reportAgent: reportAgent,
+		prLocks:     prlock.NewShardedLock(),
+	}
+	c.prLocks.RunCleanup()
+	return c
+}
+
+// GetName returns the name of the reporter
+func (c *Client) GetName() string {
+	return GiteeReporterName
+}
+
+// ShouldReport returns if this prowjob should be reported by the gitee reporter
+func (c *Client) ShouldReport(pj *v1.ProwJob) bool {
+
+	switch {
+	case !gitee.IsGiteeJob(pj):
+		return false // Only report jobs of repositories hosted on gitee
+	case pj.Labels[client.GerritReportLabel] != "":
+		return false
+	case pj.Spec.Type != v1.PresubmitJob:
+		return false // Gitee has no commit status, so only jobs of pull request can be reported
+	case c.reportAgent != "" && pj.Spec.Agent != c.reportAgent:
+		return false // Only report for specified agent
+	}
+
+	return true
+}
+
+// Report updates the status comment and the failure comment of the pull request.
+func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
+	// The comments are created/updated/deleted per pull request, so
+	// it needs pr-level locking to avoid racing when reporting multiple
+	// jobs in parallel.
+	lock, err := c.prLocks.GetLock(pj)
+	if err != nil {
+		return nil, err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	cfg := c.config()
+	validTypes := cfg.GitHubReporter.JobTypesToReport
+	if !report.ShouldReport(*pj, validTypes) {
+		return []*v1.ProwJob{pj}, nil
+	}
+
+	ghc := &ghclient{giteeClient: c.gc}
+	if err := reportStatus(ghc, *pj); err != nil {
+		return nil, fmt.Errorf("error setting status: %v", err)
+	}
+
+	return []*v1.ProwJob{pj}, report.Report(ghc, cfg.Plank.ReportTemplateForRepo(pj.Spec.Refs), *pj, validTypes)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pjutil"
+)
+
+const (
+	statusTag = "<!-- test status -->"
+)
+
+type giteeClient interface {
+	BotName() (string, error)
+	GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error)
+	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
+	CreatePRComment(org, repo string, number int, comment string) error
+	UpdatePRComment(org, repo string, commentID int, comment string) error
+	DeletePRComment(org, repo string, ID int) error
+}
+
+// ghclient adapts giteeClient to report.GitHubClient.
+type ghclient struct {
+	giteeClient
+}
+
+// CreateStatus does nothing, because Gitee has no commit status API.
+// The statuses are maintained in a comment by reportStatus instead.
+func (c *ghclient) CreateStatus(org, repo, ref string, s github.Status) error {
+	return nil
+}
+
+func (c *ghclient) ListIssueComments(org, repo string, number int) ([]github.IssueComment, error) {
+	var r []github.IssueComment
+
+	v, err := c.ListPRComments(org, repo, number)
+	if err != nil {
+		return r, err
+	}
+
+	for _, i := range v {
+		r = append(r, gitee.ConvertGiteePRComment(i))
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].CreatedAt.Before(r[j].CreatedAt)
+	})
+	return r, nil
+}
+
+func (c *ghclient) CreateComment(org, repo string, number int, comment string) error {
+	return c.CreatePRComment(org, repo, number, comment)
+}
+
+func (c *ghclient) DeleteComment(org, repo string, ID int) error {
+	return c.DeletePRComment(org, repo, ID)
+}
+
+func (c *ghclient) EditComment(org, repo string, ID int, comment string) error {
+	return c.UpdatePRComment(org, repo, ID, comment)
+}
+
+// reportStatus maintains a comment on the pull request which lists the
+// state of every job that ran against the latest commit of it. The jobs of
+// the older commits, which may finish after a push, are not reported.
+func reportStatus(ghc *ghclient, pj v1.ProwJob) error {
+	refs := pj.Spec.Refs
+	number := refs.Pulls[0].Number
+
+	pr, err := ghc.GetGiteePullRequest(refs.Org, refs.Repo, number)
+	if err != nil {
+		return fmt.Errorf("error getting pull request: %v", err)
+	}
+	if pr.Head == nil || pr.Head.Sha != refs.Pulls[0].SHA {
+		logrus.WithFields(pjutil.ProwJobFields(&pj)).Debug("Not reporting the status of a job of an older commit.")
+		return nil
+	}
+
+	botName, err := ghc.BotName()
+	if err != nil {
+		return fmt.Errorf("error getting bot name: %v", err)
+	}
+	ics, err := ghc.ListIssueComments(refs.Org, refs.Repo, number)
+	if err != nil {
+		return fmt.Errorf("error listing comments: %v", err)
+	}
+
+	deletes, entries, updateID := parseStatusComments(botName, ics)
+	for _, id := range deletes {
+		if err := ghc.DeleteComment(refs.Org, refs.Repo, id); err != nil {
+			return fmt.Errorf("error deleting comment: %v", err)
+		}
+	}
+
+	comment := createStatusComment(updateStatusEntries(entries, pj))
+	if updateID == 0 {
+		if err := ghc.CreateComment(refs.Org, refs.Repo, number, comment); err != nil {
+			return fmt.Errorf("error creating comment: %v", err)
+		}
+		return nil
+	}
+	if err := ghc.EditComment(refs.Org, refs.Repo, updateID, comment); err != nil {
+		return fmt.Errorf("error updating comment: %v", err)
+	}
+	return nil
+}
+
+// statusEntry is a row of the table in the status comment.
+type statusEntry struct {
+	context string
+	state   string
+	sha     string
+	link    string
+}
+
+func (e statusEntry) String() string {
+	return strings.Join([]string{e.context, e.state, e.sha, e.link}, " | ")
+}
+
+func newStatusEntry(pj v1.ProwJob) statusEntry {
+	return statusEntry{
+		context: pj.Spec.Context,
+		state:   fmt.Sprintf("**%s**", pj.Status.State),
+		sha:     pj.Spec.Refs.Pulls[0].SHA,
+		link:    fmt.Sprintf("[link](%s)", pj.Status.URL),
+	}
+}
+
+// parseStatusComments returns a list of comments to delete, the entries of
+// the status comment and the ID of the status comment to update. If the ID
+// is 0, a new status comment should be created.
+func parseStatusComments(botName string, ics []github.IssueComment) ([]int, []statusEntry, int) {
+	var deletes []int
+	var entries []statusEntry
+	latestComment := 0
+
+	for _, ic := range ics {
+		if ic.User.Login != botName || !strings.Contains(ic.Body, statusTag) {
+			continue
+		}
+		if latestComment != 0 {
+			deletes = append(deletes, latestComment)
+		}
+		latestComment = ic.ID
+
+		entries = nil
+		var tracking bool
+		for _, line := range strings.Split(ic.Body, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "---"):
+				tracking = true
+			case len(line) == 0:
+				tracking = false
+			case tracking:
+				if f := strings.Split(line, " | "); len(f) == 4 {
+					entries = append(entries, statusEntry{context: f[0], state: f[1], sha: f[2], link: f[3]})
+				}
+			}
+		}
+	}
+	return deletes, entries, latestComment
+}
+
+// updateStatusEntries replaces the entry of the job's context with the
+// current state of the job, which ran against the head commit of the pull
+// request. The entries of the other commits are dropped since they are
+// older.
+func updateStatusEntries(entries []statusEntry, pj v1.ProwJob) []statusEntry {
+	current := newStatusEntry(pj)
+
+	r := []statusEntry{current}
+	for _, e := range entries {
+		if e.sha == current.sha && e.context != current.context {
+			r = append(r, e)
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].context < r[j].context
+	})
+	return r
+}
+
+func createStatusComment(entries []statusEntry) string {
+	lines := []string{
+		"Test name | Status | Commit | Details",
+		"--- | --- | --- | ---",
+	}
+	for _, e := range entries {
+		lines = append(lines, e.String())
+	}
+	lines = append(lines, "", statusTag)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+func newJob(context, sha string, state v1.ProwJobState) v1.ProwJob {
+	return v1.ProwJob{
+		Spec: v1.ProwJobSpec{
+			Type:    v1.PresubmitJob,
+			Report:  true,
+			Context: context,
+			Refs: &v1.Refs{
+				Org:      "o",
+				Repo:     "r",
+				RepoLink: "https://gitee.com/o/r",
+				Pulls:    []v1.Pull{{Number: 1, SHA: sha}},
+			},
+		},
+		Status: v1.ProwJobStatus{
+			State: state,
+			URL:   "https://prow/" + context,
+		},
+	}
+}
+
+func TestStatusCommentRoundTrip(t *testing.T) {
+	entries := []statusEntry{
+		newStatusEntry(newJob("a", "sha1", v1.SuccessState)),
+		newStatusEntry(newJob("b", "sha1", v1.FailureState)),
+	}
+	ics := []github.IssueComment{
+		{ID: 1, User: github.User{Login: "bot"}, Body: createStatusComment(entries)},
+		{ID: 2, User: github.User{Login: "someone"}, Body: createStatusComment(nil)},
+		{ID: 3, User: github.User{Login: "bot"}, Body: "hello"},
+	}
+
+	deletes, got, updateID := parseStatusComments("bot", ics)
+	if len(deletes) != 0 {
+		t.Errorf("expected no comments to delete, got %v", deletes)
+	}
+	if updateID != 1 {
+		t.Errorf("expected to update comment 1, got %d", updateID)
+	}
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("expected entries %v, got %v", entries, got)
+	}
+}
+
+func TestParseStatusCommentsDeletesDuplicates(t *testing.T) {
+	ics := []github.IssueComment{
+		{ID: 1, User: github.User{Login: "bot"}, Body: createStatusComment(nil)},
+		{ID: 2, User: github.User{Login: "bot"}, Body: createStatusComment(nil)},
+	}
+
+	deletes, _, updateID := parseStatusComments("bot", ics)
+	if !reflect.DeepEqual(deletes, []int{1}) {
+		t.Errorf("expected to delete comment 1, got %v", deletes)
+	}
+	if updateID != 2 {
+		t.Errorf("expected to update comment 2, got %d", updateID)
+	}
+}
+
+func TestUpdateStatusEntries(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		entries  []statusEntry
+		pj       v1.ProwJob
+		expected []string
+	}{
+		{
+			name: "adds the entry of a new context",
+			entries: []statusEntry{
+				newStatusEntry(newJob("b", "sha1", v1.SuccessState)),
+			},
+			pj:       newJob("a", "sha1", v1.PendingState),
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "replaces the entry of the same context",
+			entries: []statusEntry{
+				newStatusEntry(newJob("a", "sha1", v1.PendingState)),
+			},
+			pj:       newJob("a", "sha1", v1.FailureState),
+			expected: []string{"a"},
+		},
+		{
+			name: "drops the entries of an older commit",
+			entries: []statusEntry{
+				newStatusEntry(newJob("a", "sha1", v1.SuccessState)),
+				newStatusEntry(newJob("b", "sha1", v1.SuccessState)),
+			},
+			pj:       newJob("a", "sha2", v1.PendingState),
+			expected: []string{"a"},
+		},
+	}
+
+	for _, tc := range testcases {
+		got := updateStatusEntries(tc.entries, tc.pj)
+		var contexts []string
+		for _, e := range got {
+			contexts = append(contexts, e.context)
+		}
+		if !reflect.DeepEqual(contexts, tc.expected) {
+			t.Errorf("%s: expected contexts %v, got %v", tc.name, tc.expected, contexts)
+		}
+		if got[0].context == tc.pj.Spec.Context && got[0].state != "**"+string(tc.pj.Status.State)+"**" {
+			t.Errorf("%s: expected the state of the job, got %s", tc.name, got[0].state)
+		}
+	}
+}
+
+func TestReportStatusSkipsOlderCommits(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.PullRequests[1] = &sdk.PullRequest{Number: 1, Head: &sdk.BranchBasic{Sha: "sha2"}}
+	ghc := &ghclient{giteeClient: fc}
+
+	if err := reportStatus(ghc, newJob("a", "sha2", v1.PendingState)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reportStatus(ghc, newJob("b", "sha1", v1.FailureState)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comments := fc.PRComments[1]
+	if len(comments) != 1 {
+		t.Fatalf("expected a status comment, got %v", comments)
+	}
+	if !strings.Contains(comments[0].Body, "a | **pending** | sha2") {
+		t.Errorf("expected the status of the head commit to be kept, got %q", comments[0].Body)
+	}
+	if strings.Contains(comments[0].Body, "sha1") {
+		t.Errorf("expected the job of the older commit not to be reported, got %q", comments[0].Body)
+	}
+}
@@ -19,16 +19,11 @@ limitations under the License.
 package github
 
 import (
-	"errors"
-	"fmt"
-	"sync"
-	"time"
-
-	"github.com/sirupsen/logrus"
-
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/crier/reporters/internal/prlock"
 	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/gitee"
 	"k8s.io/test-infra/prow/github/report"
 )
 
@@ -42,57 +37,7 @@ type Client struct {
 	gc          report.GitHubClient
 	config      config.Getter
 	reportAgent v1.ProwJobAgent
-	prLocks     *shardedLock
-}
-
-type simplePull struct {
-	org, repo string
-	number    int
-}
-
-type shardedLock struct {
-	mapLock *sync.Mutex
-	locks   map[simplePull]*sync.Mutex
-}
-
-func (s *shardedLock) getLock(key simplePull) *sync.Mutex {
-	s.mapLock.Lock()
-	defer s.mapLock.Unlock()
-	if _, exists := s.locks[key]; !exists {
-		s.locks[key] = &sync.Mutex{}
-	}
-	return s.locks[key]
-}
-
-// cleanup deletes all locks by acquiring first
-// the mapLock and then each individual lock before
-// deleting it. The individual lock must be acquired
-// because otherwise it may be held, we delete it from
-// the map, it gets recreated and acquired and two
-// routines report in parallel for the same job.
-// Note that while this function is running, no new
-// presubmit reporting can happen, as we hold the mapLock.
-func (s *shardedLock) cleanup() {
-	s.mapLock.Lock()
-	defer s.mapLock.Unlock()
-
-	for key, lock := range s.locks {
-		lock.Lock()
-		delete(s.locks, key)
-		lock.Unlock()
-	}
-}
-
-// runCleanup asynchronously runs the cleanup once per hour.
-func (s *shardedLock) runCleanup() {
-	go func() {
-		for range time.Tick(time.Hour) {
-			logrus.Debug("Starting to clean up presubmit locks")
-			startTime := time.Now()
-			s.cleanup()
-			logrus.WithField("duration", time.Since(startTime).String()).Debug("Finished cleaning up presubmit locks")
-		}
-	}()
+	prLocks     *prlock.ShardedLock
 }
 
 // NewReporter returns a reporter client
@@ -101,12 +46,9 @@ func NewReporter(gc report.GitHubClient, cfg config.Getter, reportAgent v1.ProwJ
 		gc:          gc,
 		config:      cfg,
 		reportAgent: reportAgent,
-		prLocks: &shardedLock{
-			mapLock: &sync.Mutex{},
-			locks:   map[simplePull]*sync.Mutex{},
-		},
+		prLocks:     prlock.NewShardedLock(),
 	}
-	c.prLocks.runCleanup()
+	c.prLocks.RunCleanup()
 	return c
 }
 
@@ -121,6 +63,8 @@ func (c *Client) ShouldReport(pj *v1.ProwJob) bool {
 	switch {
 	case pj.Labels[client.GerritReportLabel] != "":
 		return false // TODO(fejta): opt-in to github reporting
+	case gitee.IsGiteeJob(pj):
+		return false // Gitee jobs are reported by gitee reporter
 	case pj.Spec.Type != v1.PresubmitJob && pj.Spec.Type != v1.PostsubmitJob:
 		return false // Report presubmit and postsubmit github jobs for github reporter
 	case c.reportAgent != "" && pj.Spec.Agent != c.reportAgent:
@@ -137,11 +81,10 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 	// needs pr-level locking to avoid racing when reporting multiple
 	// jobs in parallel.
 	if pj.Spec.Type == v1.PresubmitJob {
-		key, err := lockKeyForPJ(pj)
+		lock, err := c.prLocks.GetLock(pj)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get lockkey for job: %v", err)
+			return nil, err
 		}
-		lock := c.prLocks.getLock(*key)
 		lock.Lock()
 		defer lock.Unlock()
 	}
@@ -149,16 +92,3 @@ func (c *Client) Report(pj *v1.ProwJob) ([]*v1.ProwJob, error) {
 	// TODO(krzyzacy): ditch ReportTemplate, and we can drop reference to config.Getter
 	return []*v1.ProwJob{pj}, report.Report(c.gc, c.config().Plank.ReportTemplateForRepo(pj.Spec.Refs), *pj, c.config().GitHubReporter.JobTypesToReport)
 }
-
-func lockKeyForPJ(pj *v1.ProwJob) (*simplePull, error) {
-	if pj.Spec.Type != v1.PresubmitJob {
-		return nil, fmt.Errorf("can only get lock key for presubmit jobs, was %q", pj.Spec.Type)
-	}
-	if pj.Spec.Refs == nil {
-		return nil, errors.New("pj.Spec.Refs is nil")
-	}
-	if n := len(pj.Spec.Refs.Pulls); n != 1 {
-		return nil, fmt.Errorf("prowjob doesn't have one but %d pulls", n)
-	}
-	return &simplePull{org: pj.Spec.Refs.Org, repo: pj.Spec.Refs.Repo, number: pj.Spec.Refs.Pulls[0].Number}, nil
-}
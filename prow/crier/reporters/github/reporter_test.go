@@ -89,6 +89,20 @@ func TestShouldReport(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "github should not report gitee jobs",
+			pj: v1.ProwJob{
+				Spec: v1.ProwJobSpec{
+					Type:   v1.PresubmitJob,
+					Report: true,
+					Refs: &v1.Refs{
+						Org:      "org",
+						Repo:     "repo",
+						RepoLink: "https://gitee.com/org/repo",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -158,15 +172,3 @@ func TestPresumitReportingLocks(t *testing.T) {
 
 	wg.Wait()
 }
-
-func TestShardedLockCleanup(t *testing.T) {
-	t.Parallel()
-	sl := &shardedLock{mapLock: &sync.Mutex{}, locks: map[simplePull]*sync.Mutex{}}
-	key := simplePull{"org", "repo", 1}
-	sl.locks[key] = &sync.Mutex{}
-	sl.cleanup()
-	if _, exists := sl.locks[key]; exists {
-		t.Error("lock didn't get cleaned up")
-	}
-
-}
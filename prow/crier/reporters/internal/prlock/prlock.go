@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prlock provides the pull request level locks the reporters take
+// to avoid racing when they report the jobs of a pull request in parallel.
+package prlock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+type simplePull struct {
+	org, repo string
+	number    int
+}
+
+// ShardedLock holds a lock per pull request.
+type ShardedLock struct {
+	mapLock *sync.Mutex
+	locks   map[simplePull]*sync.Mutex
+}
+
+// NewShardedLock returns a ShardedLock without any lock.
+func NewShardedLock() *ShardedLock {
+	return &ShardedLock{
+		mapLock: &sync.Mutex{},
+		locks:   map[simplePull]*sync.Mutex{},
+	}
+}
+
+// GetLock returns the lock of the pull request the presubmit job runs
+// against.
+func (s *ShardedLock) GetLock(pj *v1.ProwJob) (*sync.Mutex, error) {
+	key, err := lockKeyForPJ(pj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lockkey for job: %v", err)
+	}
+
+	s.mapLock.Lock()
+	defer s.mapLock.Unlock()
+	if _, exists := s.locks[*key]; !exists {
+		s.locks[*key] = &sync.Mutex{}
+	}
+	return s.locks[*key], nil
+}
+
+// cleanup deletes all locks by acquiring first
+// the mapLock and then each individual lock before
+// deleting it. The individual lock must be acquired
+// because otherwise it may be held, we delete it from
+// the map, it gets recreated and acquired and two
+// routines report in parallel for the same job.
+// Note that while this function is running, no new
+// presubmit reporting can happen, as we hold the mapLock.
+func (s *ShardedLock) cleanup() {
+	s.mapLock.Lock()
+	defer s.mapLock.Unlock()
+
+	for key, lock := range s.locks {
+		lock.Lock()
+		delete(s.locks, key)
+		lock.Unlock()
+	}
+}
+
+// RunCleanup asynchronously runs the cleanup once per hour.
+func (s *ShardedLock) RunCleanup() {
+	go func() {
+		for range time.Tick(time.Hour) {
+			logrus.Debug("Starting to clean up presubmit locks")
+			startTime := time.Now()
+			s.cleanup()
+			logrus.WithField("duration", time.Since(startTime).String()).Debug("Finished cleaning up presubmit locks")
+		}
+	}()
+}
+
+func lockKeyForPJ(pj *v1.ProwJob) (*simplePull, error) {
+	if pj.Spec.Type != v1.PresubmitJob {
+		return nil, fmt.Errorf("can only get lock key for presubmit jobs, was %q", pj.Spec.Type)
+	}
+	if pj.Spec.Refs == nil {
+		return nil, errors.New("pj.Spec.Refs is nil")
+	}
+	if n := len(pj.Spec.Refs.Pulls); n != 1 {
+		return nil, fmt.Errorf("prowjob doesn't have one but %d pulls", n)
+	}
+	return &simplePull{org: pj.Spec.Refs.Org, repo: pj.Spec.Refs.Repo, number: pj.Spec.Refs.Pulls[0].Number}, nil
+}
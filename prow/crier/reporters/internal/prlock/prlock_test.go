@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prlock
+
+import (
+	"sync"
+	"testing"
+
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+func TestGetLock(t *testing.T) {
+	t.Parallel()
+	presubmit := func(number int) *v1.ProwJob {
+		return &v1.ProwJob{Spec: v1.ProwJobSpec{
+			Type: v1.PresubmitJob,
+			Refs: &v1.Refs{Org: "org", Repo: "repo", Pulls: []v1.Pull{{Number: number}}},
+		}}
+	}
+
+	sl := NewShardedLock()
+	first, err := sl.GetLock(presubmit(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if same, _ := sl.GetLock(presubmit(1)); same != first {
+		t.Error("expected the same lock for the jobs of a pull request")
+	}
+	if other, _ := sl.GetLock(presubmit(2)); other == first {
+		t.Error("expected another lock for the jobs of another pull request")
+	}
+
+	if _, err := sl.GetLock(&v1.ProwJob{Spec: v1.ProwJobSpec{Type: v1.PeriodicJob}}); err == nil {
+		t.Error("expected an error for a periodic job")
+	}
+}
+
+func TestShardedLockCleanup(t *testing.T) {
+	t.Parallel()
+	sl := NewShardedLock()
+	key := simplePull{"org", "repo", 1}
+	sl.locks[key] = &sync.Mutex{}
+	sl.cleanup()
+	if _, exists := sl.locks[key]; exists {
+		t.Error("lock didn't get cleaned up")
+	}
+}
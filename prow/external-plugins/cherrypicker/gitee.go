@@ -0,0 +1,441 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+type giteeClient interface {
+	AddPRLabel(org, repo string, number int, label string) error
+	AssignPR(owner, repo string, number int, logins []string) error
+	CreatePRComment(org, repo string, number int, comment string) error
+	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (sdk.PullRequest, error)
+	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+	GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error)
+	IsMember(org, login string) (bool, error)
+	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
+}
+
+// GiteeServer implements http.Handler. It validates incoming Gitee webhooks
+// and then dispatches them to the appropriate plugins.
+//
+// Gitee has no API to fork a repo on behalf of the bot, so the cherry-picks
+// are pushed to the fork of the bot which must already exist.
+type GiteeServer struct {
+	tokenGenerator func() []byte
+	botName        string
+	email          string
+
+	gc git.ClientFactory
+	// Used for unit testing
+	push func(newBranch string) error
+	gec  giteeClient
+	log  *logrus.Entry
+
+	// Labels to apply to the cherrypicked PR.
+	labels []string
+	// Use prow to assign users to cherrypicked PRs.
+	prowAssignments bool
+	// Allow anybody to do cherrypicks.
+	allowAll bool
+
+	bare     *http.Client
+	patchURL string
+}
+
+// ServeHTTP validates an incoming webhook and puts it into the event channel.
+func (s *GiteeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	eventType, eventGUID, payload, ok, _ := gitee.ValidateWebhook(w, r, s.tokenGenerator)
+	if !ok {
+		return
+	}
+	fmt.Fprint(w, "Event received. Have a nice day.")
+
+	if err := s.handleEvent(eventType, eventGUID, payload); err != nil {
+		logrus.WithError(err).Error("Error parsing event.")
+	}
+}
+
+func (s *GiteeServer) handleEvent(eventType, eventGUID string, payload []byte) error {
+	l := logrus.WithFields(
+		logrus.Fields{
+			"event-type":     eventType,
+			github.EventGUID: eventGUID,
+		},
+	)
+	switch eventType {
+	case "Note Hook":
+		var e sdk.NoteEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		go func() {
+			if err := s.handleNoteEvent(l, e); err != nil {
+				s.log.WithError(err).WithFields(l.Data).Info("Cherry-pick failed.")
+			}
+		}()
+	case "Merge Request Hook":
+		var e sdk.PullRequestEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		go func() {
+			if err := s.handlePullRequestEvent(l, e); err != nil {
+				s.log.WithError(err).WithFields(l.Data).Info("Cherry-pick failed.")
+			}
+		}()
+	default:
+		logrus.Debugf("skipping event of type %q", eventType)
+	}
+	return nil
+}
+
+func (s *GiteeServer) handleNoteEvent(l *logrus.Entry, e sdk.NoteEvent) error {
+	// Only consider new comments in PRs.
+	if *(e.NoteableType) != "PullRequest" || *(e.Action) != "comment" {
+		return nil
+	}
+
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+	pr := e.PullRequest
+	num := int(pr.Number)
+	ic := github.IssueComment{
+		Body:    e.Comment.Body,
+		User:    github.User{Login: e.Comment.User.Login},
+		HTMLURL: e.Comment.HtmlUrl,
+	}
+
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		github.PrLogField:   num,
+	})
+
+	cherryPickMatches := cherryPickRe.FindAllStringSubmatch(ic.Body, -1)
+	if len(cherryPickMatches) == 0 || len(cherryPickMatches[0]) != 2 {
+		return nil
+	}
+	targetBranch := strings.TrimSpace(cherryPickMatches[0][1])
+
+	if !s.allowAll {
+		// Only org members should be able to do cherry-picks.
+		ok, err := s.gec.IsMember(org, ic.User.Login)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			resp := fmt.Sprintf("only [%s](https://gitee.com/organizations/%s/members) org members may request cherry-picks. You can still do the cherry-pick manually.", org, org)
+			s.log.WithFields(l.Data).Info(resp)
+			return s.createComment(org, repo, num, &ic, resp)
+		}
+	}
+
+	switch pr.State {
+	case "open":
+		resp := fmt.Sprintf("once the present PR merges, I will cherry-pick it on top of %s in a new PR and assign it to you.", targetBranch)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, &ic, resp)
+	case "merged":
+	default:
+		resp := "cannot cherry-pick an unmerged PR"
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, &ic, resp)
+	}
+
+	// TODO: Use a whitelist for allowed base and target branches.
+	if baseBranch := pr.Base.Ref; baseBranch == targetBranch {
+		resp := fmt.Sprintf("base branch (%s) needs to differ from target branch (%s)", baseBranch, targetBranch)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, &ic, resp)
+	}
+
+	s.log.WithFields(l.Data).
+		WithField("requestor", ic.User.Login).
+		WithField("target_branch", targetBranch).
+		Debug("Cherrypick request.")
+	return s.handle(l, ic.User.Login, &ic, org, repo, targetBranch, pr.Title, pr.Body, num)
+}
+
+func (s *GiteeServer) handlePullRequestEvent(l *logrus.Entry, e sdk.PullRequestEvent) error {
+	// Only consider newly merged PRs
+	if *(e.Action) != "merge" {
+		return nil
+	}
+
+	pr := e.PullRequest
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+	baseBranch := pr.Base.Ref
+	num := int(pr.Number)
+
+	l = l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		github.PrLogField:   num,
+	})
+
+	comments, err := s.gec.ListPRComments(org, repo, num)
+	if err != nil {
+		return err
+	}
+
+	// requestor -> target branch -> issue comment
+	requestorToComments := make(map[string]map[string]*github.IssueComment)
+
+	// first look for our special comments
+	for i := range comments {
+		c := gitee.ConvertGiteePRComment(comments[i])
+		cherryPickMatches := cherryPickRe.FindAllStringSubmatch(c.Body, -1)
+		if len(cherryPickMatches) == 0 || len(cherryPickMatches[0]) != 2 {
+			continue
+		}
+		// TODO: Support comments with multiple cherrypick invocations.
+		targetBranch := strings.TrimSpace(cherryPickMatches[0][1])
+		if requestorToComments[c.User.Login] == nil {
+			requestorToComments[c.User.Login] = make(map[string]*github.IssueComment)
+		}
+		requestorToComments[c.User.Login][targetBranch] = &c
+	}
+
+	// now look for our special labels
+	labels, err := s.gec.GetPRLabels(org, repo, num)
+	if err != nil {
+		return err
+	}
+
+	labelPrefix := "cherrypick/"
+	for _, label := range labels {
+		if !strings.HasPrefix(label.Name, labelPrefix) {
+			continue
+		}
+		if requestorToComments[pr.User.Login] == nil {
+			requestorToComments[pr.User.Login] = make(map[string]*github.IssueComment)
+		}
+		requestorToComments[pr.User.Login][label.Name[len(labelPrefix):]] = nil // leave this nil which indicates a label-initiated cherry-pick
+	}
+
+	if len(requestorToComments) == 0 {
+		return nil
+	}
+
+	// Figure out membership.
+	if !s.allowAll {
+		for requestor := range requestorToComments {
+			ok, err := s.gec.IsMember(org, requestor)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				delete(requestorToComments, requestor)
+			}
+		}
+	}
+
+	// Handle multiple comments serially. Make sure to filter out
+	// comments targeting the same branch.
+	handledBranches := make(map[string]bool)
+	for requestor, branches := range requestorToComments {
+		for targetBranch, ic := range branches {
+			if targetBranch == baseBranch {
+				resp := fmt.Sprintf("base branch (%s) needs to differ from target branch (%s)", baseBranch, targetBranch)
+				s.log.WithFields(l.Data).Info(resp)
+				s.createComment(org, repo, num, ic, resp)
+				continue
+			}
+			if handledBranches[targetBranch] {
+				// Branch already handled. Skip.
+				continue
+			}
+			handledBranches[targetBranch] = true
+			s.log.WithFields(l.Data).
+				WithField("requestor", requestor).
+				WithField("target_branch", targetBranch).
+				Debug("Cherrypick request.")
+			err := s.handle(l, requestor, ic, org, repo, targetBranch, pr.Title, pr.Body, num)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *GiteeServer) handle(l *logrus.Entry, requestor string, comment *github.IssueComment, org, repo, targetBranch, title, body string, num int) error {
+	// Clone the repo, checkout the target branch.
+	startClone := time.Now()
+	r, err := s.gc.ClientFor(org, repo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Clean(); err != nil {
+			s.log.WithError(err).WithFields(l.Data).Error("Error cleaning up repo.")
+		}
+	}()
+	if err := r.Checkout(targetBranch); err != nil {
+		resp := fmt.Sprintf("cannot checkout %s: %v", targetBranch, err)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, comment, resp)
+	}
+	s.log.WithFields(l.Data).WithField("duration", time.Since(startClone)).Info("Cloned and checked out target branch.")
+
+	// Fetch the patch from Gitee
+	localPath, err := s.getPatch(org, repo, targetBranch, num)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Config("user.name", s.botName); err != nil {
+		return err
+	}
+	email := s.email
+	if email == "" {
+		email = fmt.Sprintf("%s@localhost", s.botName)
+	}
+	if err := r.Config("user.email", email); err != nil {
+		return err
+	}
+
+	// New branch for the cherry-pick.
+	newBranch := fmt.Sprintf(cherryPickBranchFmt, num, targetBranch)
+	head := fmt.Sprintf("%s:%s", s.botName, newBranch)
+
+	// Check if that branch already exists, which means there is already a PR for that cherry-pick.
+	if r.BranchExists(newBranch) {
+		// Find the PR and link to it.
+		prs, err := s.gec.GetPullRequests(org, repo, "all", "", targetBranch)
+		if err != nil {
+			return err
+		}
+		for _, pr := range prs {
+			if pr.Head != nil && (pr.Head.Ref == newBranch || pr.Head.Ref == head) {
+				resp := fmt.Sprintf("Looks like #%d has already been cherry picked in %s", num, pr.HtmlUrl)
+				s.log.WithFields(l.Data).Info(resp)
+				return s.createComment(org, repo, num, comment, resp)
+			}
+		}
+	}
+
+	// Create the branch for the cherry-pick.
+	if err := r.CheckoutNewBranch(newBranch); err != nil {
+		return err
+	}
+
+	// Apply the patch.
+	if err := r.Am(localPath); err != nil {
+		resp := fmt.Sprintf("#%d failed to apply on top of branch %q:\n```%v\n```", num, targetBranch, err)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, comment, resp)
+	}
+
+	push := r.ForcePush
+	if s.push != nil {
+		push = s.push
+	}
+	// Push the new branch in the bot's fork.
+	if err := push(newBranch); err != nil {
+		resp := fmt.Sprintf("failed to push cherry-picked changes in Gitee: %v", err)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, comment, resp)
+	}
+
+	// Open a PR in Gitee.
+	title = fmt.Sprintf("[%s] %s", targetBranch, title)
+	cherryPickBody := fmt.Sprintf("This is an automated cherry-pick of #%d", num)
+	if s.prowAssignments {
+		cherryPickBody = fmt.Sprintf("%s\n\n/assign %s", cherryPickBody, requestor)
+	}
+	if releaseNote := releaseNoteFromParentPR(body); len(releaseNote) != 0 {
+		cherryPickBody = fmt.Sprintf("%s\n\n%s", cherryPickBody, releaseNote)
+	}
+
+	created, err := s.gec.CreatePullRequest(org, repo, title, cherryPickBody, head, targetBranch, true)
+	if err != nil {
+		resp := fmt.Sprintf("new pull request could not be created: %v", err)
+		s.log.WithFields(l.Data).Info(resp)
+		return s.createComment(org, repo, num, comment, resp)
+	}
+	createdNum := int(created.Number)
+	resp := fmt.Sprintf("new pull request created: #%d", createdNum)
+	s.log.WithFields(l.Data).Info(resp)
+	if err := s.createComment(org, repo, num, comment, resp); err != nil {
+		return err
+	}
+	for _, label := range s.labels {
+		if err := s.gec.AddPRLabel(org, repo, createdNum, label); err != nil {
+			return err
+		}
+	}
+	if !s.prowAssignments {
+		if err := s.gec.AssignPR(org, repo, createdNum, []string{requestor}); err != nil {
+			s.log.WithFields(l.Data).Warningf("Cannot assign to new PR: %v", err)
+			// Ignore returning errors on failure to assign as this is most likely
+			// due to users not being members of the org so that they can't be assigned
+			// in PRs.
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *GiteeServer) createComment(org, repo string, num int, comment *github.IssueComment, resp string) error {
+	if comment != nil {
+		return s.gec.CreatePRComment(org, repo, num, plugins.FormatICResponse(*comment, resp))
+	}
+	return s.gec.CreatePRComment(org, repo, num, fmt.Sprintf("In response to a cherrypick label: %s", resp))
+}
+
+// getPatch downloads the patch of the provided PR, which Gitee serves
+// next to the page of the PR, and creates a local copy of it. It returns
+// its location in the filesystem and any encountered error.
+func (s *GiteeServer) getPatch(org, repo, targetBranch string, num int) (string, error) {
+	resp, err := s.bare.Get(fmt.Sprintf("%s/%s/%s/pulls/%d.patch", s.patchURL, org, repo, num))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download the patch of %s/%s#%d: %s", org, repo, num, resp.Status)
+	}
+
+	localPath := fmt.Sprintf("/tmp/%s_%s_%d_%s.patch", org, repo, num, normalize(targetBranch))
+	out, err := os.Create(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
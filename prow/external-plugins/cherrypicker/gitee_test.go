@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/git/localgit"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+)
+
+func newGiteeServer(t *testing.T, fc *fakegitee.FakeClient, branches ...string) (*GiteeServer, func()) {
+	lg, c, err := localgit.NewV2()
+	if err != nil {
+		t.Fatalf("Making localgit: %v", err)
+	}
+	if err := lg.MakeFakeRepo("foo", "bar"); err != nil {
+		t.Fatalf("Making fake repo: %v", err)
+	}
+	if err := lg.AddCommit("foo", "bar", initialFiles); err != nil {
+		t.Fatalf("Adding initial commit: %v", err)
+	}
+	for _, b := range branches {
+		if err := lg.CheckoutNewBranch("foo", "bar", b); err != nil {
+			t.Fatalf("Checking out pull branch: %v", err)
+		}
+	}
+
+	patchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/foo/bar/pulls/2.patch" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(patch)
+	}))
+
+	s := &GiteeServer{
+		botName: fakegitee.Bot,
+		gc:      c,
+		push:    func(newBranch string) error { return nil },
+		gec:     fc,
+		log:     logrus.StandardLogger().WithField("client", "cherrypicker"),
+
+		prowAssignments: true,
+
+		bare:     patchServer.Client(),
+		patchURL: patchServer.URL,
+	}
+	return s, func() {
+		patchServer.Close()
+		if err := lg.Clean(); err != nil {
+			t.Errorf("Cleaning up localgit: %v", err)
+		}
+		if err := c.Clean(); err != nil {
+			t.Errorf("Cleaning up client: %v", err)
+		}
+	}
+}
+
+func TestGiteeCherryPickNote(t *testing.T) {
+	pr := fakegitee.PR{Org: "foo", Repo: "bar", Number: 2, Author: "developer", Title: "This is a fix for X", Body: body, BaseRef: "master"}
+
+	testCases := []struct {
+		name      string
+		state     string
+		comment   string
+		isMember  bool
+		allowAll  bool
+		expectPRs []sdk.PullRequest
+		expectMsg string
+	}{
+		{
+			name:     "merged PR is cherry-picked",
+			state:    "merged",
+			comment:  "/cherry-pick stage",
+			isMember: true,
+			expectPRs: []sdk.PullRequest{{
+				Number: 1,
+				Title:  "[stage] This is a fix for X",
+				Body:   "This is an automated cherry-pick of #2\n\n/assign wiseguy\n\n```release-note\nUpdate the magic number from 42 to 49\n```",
+				State:  "open",
+				Head:   &sdk.BranchBasic{Ref: fmt.Sprintf(fakegitee.Bot+":"+cherryPickBranchFmt, 2, "stage")},
+				Base:   &sdk.BranchBasic{Ref: "stage"},
+			}},
+			expectMsg: "new pull request created: #1",
+		},
+		{
+			name:      "open PR is cherry-picked once merged",
+			state:     "open",
+			comment:   "/cherrypick stage",
+			isMember:  true,
+			expectMsg: "once the present PR merges, I will cherry-pick it on top of stage in a new PR and assign it to you.",
+		},
+		{
+			name:      "closed PR is not cherry-picked",
+			state:     "closed",
+			comment:   "/cherrypick stage",
+			allowAll:  true,
+			expectMsg: "cannot cherry-pick an unmerged PR",
+		},
+		{
+			name:      "non-member can't cherry-pick",
+			state:     "merged",
+			comment:   "/cherrypick stage",
+			expectMsg: "org members may request cherry-picks",
+		},
+		{
+			name:      "target branch must differ from base branch",
+			state:     "merged",
+			comment:   "/cherrypick master",
+			isMember:  true,
+			expectMsg: "base branch (master) needs to differ from target branch (master)",
+		},
+		{
+			name:     "other comments are ignored",
+			state:    "merged",
+			comment:  "/lgtm",
+			isMember: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			if tc.isMember {
+				fc.OrgMembers["foo"] = []string{"wiseguy"}
+			}
+			s, clean := newGiteeServer(t, fc, "stage")
+			defer clean()
+			s.allowAll = tc.allowAll
+
+			e := fakegitee.NewPRNoteEvent(pr, "wiseguy", tc.comment)
+			e.PullRequest.State = tc.state
+			if err := s.handleNoteEvent(logrus.NewEntry(logrus.StandardLogger()), *e); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(fc.PRsCreated, tc.expectPRs) {
+				t.Errorf("expected the PRs %+v to be created, got %+v", tc.expectPRs, fc.PRsCreated)
+			}
+			switch {
+			case tc.expectMsg == "" && len(fc.PRCommentsAdded) != 0:
+				t.Errorf("unexpected comments: %v", fc.PRCommentsAdded)
+			case tc.expectMsg != "" && (len(fc.PRCommentsAdded) != 1 || !strings.Contains(fc.PRCommentsAdded[0], tc.expectMsg)):
+				t.Errorf("expected a comment containing %q, got %v", tc.expectMsg, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestGiteeCherryPickMerge(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.OrgMembers["foo"] = []string{"approver", "developer"}
+	fc.PRComments[2] = []sdk.PullRequestComments{
+		{Id: 1, Body: "/cherrypick release-1.5", User: &sdk.UserBasic{Login: "approver"}},
+		{Id: 2, Body: "/cherrypick release-1.6", User: &sdk.UserBasic{Login: "outsider"}},
+		{Id: 3, Body: "/cherrypick master", User: &sdk.UserBasic{Login: "approver"}},
+	}
+	fc.PRLabelsExisting = []string{"foo/bar#2:cherrypick/release-1.7"}
+	s, clean := newGiteeServer(t, fc, "release-1.5", "release-1.6", "release-1.7")
+	defer clean()
+
+	pr := fakegitee.PR{Org: "foo", Repo: "bar", Number: 2, Author: "developer", Title: "This is a fix for Y", BaseRef: "master"}
+	if err := s.handlePullRequestEvent(logrus.NewEntry(logrus.StandardLogger()), *fakegitee.NewPullRequestEvent(pr, "update")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.PRsCreated) != 0 || len(fc.PRCommentsAdded) != 0 {
+		t.Fatalf("expected an update to be ignored, got the PRs %v and the comments %v", fc.PRsCreated, fc.PRCommentsAdded)
+	}
+
+	if err := s.handlePullRequestEvent(logrus.NewEntry(logrus.StandardLogger()), *fakegitee.NewPullRequestEvent(pr, "merge")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var bases []string
+	for _, p := range fc.PRsCreated {
+		bases = append(bases, p.Base.Ref)
+	}
+	if expected := map[string]bool{"release-1.5": true, "release-1.7": true}; len(bases) != len(expected) || !expected[bases[0]] || !expected[bases[1]] {
+		t.Errorf("expected cherry-picks to %v, got %v", expected, bases)
+	}
+	var rejected bool
+	for _, c := range fc.PRCommentsAdded {
+		if strings.Contains(c, "needs to differ from target branch (master)") {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Errorf("expected the cherry-pick to the base branch to be rejected, got the comments %v", fc.PRCommentsAdded)
+	}
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
@@ -33,12 +34,19 @@ import (
 	"k8s.io/test-infra/prow/pluginhelp/externalplugins"
 )
 
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
+)
+
 type options struct {
 	port int
 
-	dryRun bool
-	github prowflagutil.GitHubOptions
-	labels prowflagutil.Strings
+	dryRun   bool
+	provider string
+	github   prowflagutil.GitHubOptions
+	gitee    prowflagutil.GiteeOptions
+	labels   prowflagutil.Strings
 
 	webhookSecretFile string
 	prowAssignments   bool
@@ -46,13 +54,25 @@ type options struct {
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.github} {
-		if err := group.Validate(o.dryRun); err != nil {
-			return err
-		}
+	var group flagutil.OptionGroup
+	switch o.provider {
+	case providerGitHub:
+		group = &o.github
+	case providerGitee:
+		group = &o.gitee
+	default:
+		return fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, o.provider)
 	}
 
-	return nil
+	return group.Validate(o.dryRun)
+}
+
+// tokenPath returns the path of the token of the configured provider.
+func (o *options) tokenPath() string {
+	if o.provider == providerGitee {
+		return o.gitee.TokenPath
+	}
+	return o.github.TokenPath
 }
 
 func gatherOptions() options {
@@ -61,10 +81,11 @@ func gatherOptions() options {
 	fs.IntVar(&o.port, "port", 8888, "Port to listen on.")
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
 	fs.Var(&o.labels, "labels", "Labels to apply to the cherrypicked PR.")
-	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
+	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub or Gitee HMAC secret.")
+	fs.StringVar(&o.provider, "provider", providerGitHub, fmt.Sprintf("The code hosting platform of the PRs, %q or %q.", providerGitHub, providerGitee))
 	fs.BoolVar(&o.prowAssignments, "use-prow-assignments", true, "Use prow commands to assign cherrypicked PRs.")
 	fs.BoolVar(&o.allowAll, "allow-all", false, "Allow anybody to use automated cherrypicks by skipping GitHub organization membership checks.")
-	for _, group := range []flagutil.OptionGroup{&o.github} {
+	for _, group := range []flagutil.OptionGroup{&o.github, &o.gitee} {
 		group.AddFlags(fs)
 	}
 	fs.Parse(os.Args[1:])
@@ -83,10 +104,27 @@ func main() {
 	log := logrus.StandardLogger().WithField("plugin", "cherrypick")
 
 	secretAgent := &secret.Agent{}
-	if err := secretAgent.Start([]string{o.github.TokenPath, o.webhookSecretFile}); err != nil {
+	if err := secretAgent.Start([]string{o.tokenPath(), o.webhookSecretFile}); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
 	}
 
+	var server http.Handler
+	if o.provider == providerGitee {
+		server = giteeServer(o, secretAgent, log)
+	} else {
+		server = githubServer(o, secretAgent, log)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", server)
+	externalplugins.ServeExternalPluginHelp(mux, log, HelpProvider)
+	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	defer interrupts.WaitForGracefulShutdown()
+	interrupts.ListenAndServe(httpServer, 5*time.Second)
+}
+
+// githubServer returns the server of the GitHub webhooks.
+func githubServer(o options, secretAgent *secret.Agent, log *logrus.Entry) http.Handler {
 	githubClient, err := o.github.GitHubClient(secretAgent, o.dryRun)
 	if err != nil {
 		logrus.WithError(err).Fatal("Error getting GitHub client.")
@@ -133,11 +171,49 @@ func main() {
 
 		repos: repos,
 	}
+	return server
+}
 
-	mux := http.NewServeMux()
-	mux.Handle("/", server)
-	externalplugins.ServeExternalPluginHelp(mux, log, HelpProvider)
-	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
-	defer interrupts.WaitForGracefulShutdown()
-	interrupts.ListenAndServe(httpServer, 5*time.Second)
+// giteeServer returns the server of the Gitee webhooks.
+func giteeServer(o options, secretAgent *secret.Agent, log *logrus.Entry) http.Handler {
+	giteeClient, err := o.gitee.GiteeClient(secretAgent, o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Gitee client.")
+	}
+	gitClient, err := o.gitee.GitClient(secretAgent, o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Git client.")
+	}
+	interrupts.OnInterrupt(func() {
+		if err := gitClient.Clean(); err != nil {
+			logrus.WithError(err).Error("Could not clean up git client cache.")
+		}
+	})
+
+	email, err := giteeClient.Email()
+	if err != nil {
+		log.WithError(err).Fatal("Error getting bot e-mail.")
+	}
+
+	botName, err := giteeClient.BotName()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting bot name.")
+	}
+
+	return &GiteeServer{
+		tokenGenerator: secretAgent.GetTokenGenerator(o.webhookSecretFile),
+		botName:        botName,
+		email:          email,
+
+		gc:  gitClient,
+		gec: giteeClient,
+		log: log,
+
+		labels:          o.labels.Strings(),
+		prowAssignments: o.prowAssignments,
+		allowAll:        o.allowAll,
+
+		bare:     &http.Client{},
+		patchURL: "https://gitee.com",
+	}
 }
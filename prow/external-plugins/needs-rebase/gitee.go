@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/config/secret"
+	"k8s.io/test-infra/prow/external-plugins/needs-rebase/plugin"
+	"k8s.io/test-infra/prow/gitee"
+	giteeplugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+)
+
+// giteeServer returns the server of the Gitee webhooks and the periodic
+// update of the Gitee PRs.
+func giteeServer(o options, secretAgent *secret.Agent, log *logrus.Entry) (http.Handler, func() error) {
+	pa := giteeplugins.NewConfigAgent()
+	if err := pa.Start(o.pluginConfig, false, nil); err != nil {
+		log.WithError(err).Fatalf("Error loading plugin config from %q.", o.pluginConfig)
+	}
+
+	giteeClient, err := o.gitee.GiteeClient(secretAgent, o.dryRun)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting Gitee client.")
+	}
+
+	server := &GiteeServer{
+		tokenGenerator: secretAgent.GetTokenGenerator(o.webhookSecretFile),
+		gc:             giteeClient,
+		log:            log,
+	}
+	return server, func() error {
+		return plugin.HandleAllGitee(log, giteeClient, pa.Config())
+	}
+}
+
+// GiteeServer implements http.Handler. It validates incoming Gitee webhooks
+// and then dispatches them to the appropriate plugins.
+type GiteeServer struct {
+	tokenGenerator func() []byte
+	gc             gitee.Client
+	log            *logrus.Entry
+}
+
+// ServeHTTP validates an incoming webhook and puts it into the event channel.
+func (s *GiteeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	eventType, eventGUID, payload, ok, _ := gitee.ValidateWebhook(w, r, s.tokenGenerator)
+	if !ok {
+		return
+	}
+	fmt.Fprint(w, "Event received. Have a nice day.")
+
+	if err := s.handleEvent(eventType, eventGUID, payload); err != nil {
+		logrus.WithError(err).Error("Error parsing event.")
+	}
+}
+
+func (s *GiteeServer) handleEvent(eventType, eventGUID string, payload []byte) error {
+	l := s.log.WithFields(
+		logrus.Fields{
+			"event-type":     eventType,
+			github.EventGUID: eventGUID,
+		},
+	)
+	switch eventType {
+	case "Merge Request Hook":
+		var e sdk.PullRequestEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		go func() {
+			if err := plugin.HandleGiteePullRequestEvent(l, s.gc, &e); err != nil {
+				l.WithError(err).Info("Error handling event.")
+			}
+		}()
+	case "Note Hook":
+		var e sdk.NoteEvent
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		go func() {
+			if err := plugin.HandleGiteeNoteEvent(l, s.gc, &e); err != nil {
+				l.WithError(err).Info("Error handling event.")
+			}
+		}()
+	default:
+		s.log.Debugf("received an event of type %q but didn't ask for it", eventType)
+	}
+	return nil
+}
@@ -39,12 +39,19 @@ import (
 	"k8s.io/test-infra/prow/plugins"
 )
 
+const (
+	providerGitHub = "github"
+	providerGitee  = "gitee"
+)
+
 type options struct {
 	port int
 
 	pluginConfig string
 	dryRun       bool
+	provider     string
 	github       prowflagutil.GitHubOptions
+	gitee        prowflagutil.GiteeOptions
 
 	updatePeriod time.Duration
 
@@ -52,13 +59,25 @@ type options struct {
 }
 
 func (o *options) Validate() error {
-	for _, group := range []flagutil.OptionGroup{&o.github} {
-		if err := group.Validate(o.dryRun); err != nil {
-			return err
-		}
+	var group flagutil.OptionGroup
+	switch o.provider {
+	case providerGitHub:
+		group = &o.github
+	case providerGitee:
+		group = &o.gitee
+	default:
+		return fmt.Errorf("--provider must be one of %q and %q, got %q", providerGitHub, providerGitee, o.provider)
 	}
 
-	return nil
+	return group.Validate(o.dryRun)
+}
+
+// tokenPath returns the path of the token of the configured provider.
+func (o *options) tokenPath() string {
+	if o.provider == providerGitee {
+		return o.gitee.TokenPath
+	}
+	return o.github.TokenPath
 }
 
 func gatherOptions() options {
@@ -68,9 +87,10 @@ func gatherOptions() options {
 	fs.StringVar(&o.pluginConfig, "plugin-config", "/etc/plugins/plugins.yaml", "Path to plugin config file.")
 	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
 	fs.DurationVar(&o.updatePeriod, "update-period", time.Hour*24, "Period duration for periodic scans of all PRs.")
-	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
+	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub or Gitee HMAC secret.")
+	fs.StringVar(&o.provider, "provider", providerGitHub, fmt.Sprintf("The code hosting platform of the PRs, %q or %q.", providerGitHub, providerGitee))
 
-	for _, group := range []flagutil.OptionGroup{&o.github} {
+	for _, group := range []flagutil.OptionGroup{&o.github, &o.gitee} {
 		group.AddFlags(fs)
 	}
 	fs.Parse(os.Args[1:])
@@ -89,10 +109,38 @@ func main() {
 	log := logrus.StandardLogger().WithField("plugin", labels.NeedsRebase)
 
 	secretAgent := &secret.Agent{}
-	if err := secretAgent.Start([]string{o.github.TokenPath, o.webhookSecretFile}); err != nil {
+	if err := secretAgent.Start([]string{o.tokenPath(), o.webhookSecretFile}); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
 	}
 
+	var server http.Handler
+	var handleAll func() error
+	if o.provider == providerGitee {
+		server, handleAll = giteeServer(o, secretAgent, log)
+	} else {
+		server, handleAll = githubServer(o, secretAgent, log)
+	}
+
+	defer interrupts.WaitForGracefulShutdown()
+
+	interrupts.TickLiteral(func() {
+		start := time.Now()
+		if err := handleAll(); err != nil {
+			log.WithError(err).Error("Error during periodic update of all PRs.")
+		}
+		log.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Info("Periodic update complete.")
+	}, o.updatePeriod)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", server)
+	externalplugins.ServeExternalPluginHelp(mux, log, plugin.HelpProvider)
+	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	interrupts.ListenAndServe(httpServer, 5*time.Second)
+}
+
+// githubServer returns the server of the GitHub webhooks and the periodic
+// update of the GitHub PRs.
+func githubServer(o options, secretAgent *secret.Agent, log *logrus.Entry) (http.Handler, func() error) {
 	pa := &plugins.ConfigAgent{}
 	if err := pa.Start(o.pluginConfig, false); err != nil {
 		log.WithError(err).Fatalf("Error loading plugin config from %q.", o.pluginConfig)
@@ -110,21 +158,9 @@ func main() {
 		log:            log,
 	}
 
-	defer interrupts.WaitForGracefulShutdown()
-
-	interrupts.TickLiteral(func() {
-		start := time.Now()
-		if err := plugin.HandleAll(log, githubClient, pa.Config()); err != nil {
-			log.WithError(err).Error("Error during periodic update of all PRs.")
-		}
-		log.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Info("Periodic update complete.")
-	}, o.updatePeriod)
-
-	mux := http.NewServeMux()
-	mux.Handle("/", server)
-	externalplugins.ServeExternalPluginHelp(mux, log, plugin.HelpProvider)
-	httpServer := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
-	interrupts.ListenAndServe(httpServer, 5*time.Second)
+	return server, func() error {
+		return plugin.HandleAll(log, githubClient, pa.Config())
+	}
 }
 
 // Server implements http.Handler. It validates incoming GitHub webhooks and
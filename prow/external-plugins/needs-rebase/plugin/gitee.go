@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/gitee"
+	giteeplugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/labels"
+)
+
+type giteeClient interface {
+	BotName() (string, error)
+	GetRepos(org string) ([]sdk.Project, error)
+	GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error)
+	GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error)
+	AddPRLabel(org, repo string, number int, label string) error
+	RemovePRLabel(org, repo string, number int, label string) error
+	CreatePRComment(org, repo string, number int, comment string) error
+	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
+	DeletePRComment(org, repo string, ID int) error
+}
+
+var _ labelCommenter = (*giteeLabelCommenter)(nil)
+
+// giteeLabelCommenter updates the label and the comment of a Gitee PR.
+type giteeLabelCommenter struct {
+	giteeClient
+}
+
+func (c *giteeLabelCommenter) AddLabel(org, repo string, number int, label string) error {
+	return c.AddPRLabel(org, repo, number, label)
+}
+
+func (c *giteeLabelCommenter) RemoveLabel(org, repo string, number int, label string) error {
+	return c.RemovePRLabel(org, repo, number, label)
+}
+
+func (c *giteeLabelCommenter) CreateComment(org, repo string, number int, comment string) error {
+	return c.CreatePRComment(org, repo, number, comment)
+}
+
+func (c *giteeLabelCommenter) DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error {
+	if comments == nil {
+		v, err := c.ListPRComments(org, repo, number)
+		if err != nil {
+			return err
+		}
+		for _, i := range v {
+			comments = append(comments, gitee.ConvertGiteePRComment(i))
+		}
+	}
+	for _, comment := range comments {
+		if !isStale(comment) {
+			continue
+		}
+		if err := c.DeletePRComment(org, repo, comment.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleGiteePullRequestEvent handles a Gitee pull request event and adds or
+// removes a "needs-rebase" label based on whether Gitee considers the PR
+// mergeable.
+func HandleGiteePullRequestEvent(log *logrus.Entry, gc giteeClient, e *sdk.PullRequestEvent) error {
+	switch *(e.Action) {
+	case "open":
+	case "update":
+		// An update event is also sent when the labels, assignees or
+		// testers of a pull request are changed, including by this plugin.
+		if e.ActionDesc == nil {
+			return nil
+		}
+		if desc := *(e.ActionDesc); desc != "source_branch_changed" && desc != "target_branch_changed" {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	return handleGitee(log, gc, e.Repository.Owner.Login, e.Repository.Name, int(e.PullRequest.Number))
+}
+
+// HandleGiteeNoteEvent handles a Gitee comment event and adds or removes a
+// "needs-rebase" label if the comment is on a PR based on whether Gitee
+// considers the PR mergeable.
+func HandleGiteeNoteEvent(log *logrus.Entry, gc giteeClient, e *sdk.NoteEvent) error {
+	if *(e.NoteableType) != "PullRequest" || *(e.Action) != "comment" {
+		return nil
+	}
+
+	return handleGitee(log, gc, e.Repository.Owner.Login, e.Repository.Name, int(e.PullRequest.Number))
+}
+
+// handleGitee fetches the Gitee PR to determine if the "needs-rebase" label
+// needs to be added or removed. The events do not carry the mergeability.
+func handleGitee(log *logrus.Entry, gc giteeClient, org, repo string, number int) error {
+	// Give Gitee a chance to calculate the mergeability after the changes.
+	sleep(time.Second * 5)
+
+	pr, err := gc.GetGiteePullRequest(org, repo, number)
+	if err != nil {
+		return err
+	}
+	if pr.State != "open" {
+		return nil
+	}
+
+	return takeAction(log, &giteeLabelCommenter{gc}, org, repo, number, pr.User.Login, hasGiteeLabel(pr), pr.Mergeable)
+}
+
+// HandleAllGitee checks all orgs and repos that enabled this plugin for open
+// PRs to determine if the "needs-rebase" label needs to be added or removed.
+// It depends on Gitee's mergeable field to decide the need for a rebase.
+func HandleAllGitee(log *logrus.Entry, gc giteeClient, config *giteeplugins.Configurations) error {
+	log.Info("Checking all Gitee PRs.")
+	orgs, repos := config.EnabledReposForExternalPlugin(PluginName)
+	if len(orgs) == 0 && len(repos) == 0 {
+		log.Warnf("No repos have been configured for the %s plugin", PluginName)
+		return nil
+	}
+	for _, org := range orgs {
+		projects, err := gc.GetRepos(org)
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			repos = append(repos, org+"/"+p.Path)
+		}
+	}
+
+	for _, orgRepo := range repos {
+		s := strings.SplitN(orgRepo, "/", 2)
+		if len(s) != 2 {
+			log.Warnf("Invalid repo %q", orgRepo)
+			continue
+		}
+		org, repo := s[0], s[1]
+		prs, err := gc.GetPullRequests(org, repo, "open", "", "")
+		if err != nil {
+			log.WithError(err).Errorf("Error listing the PRs of %s.", orgRepo)
+			continue
+		}
+		log.Infof("Considering %d PRs of %s.", len(prs), orgRepo)
+
+		for _, pr := range prs {
+			num := int(pr.Number)
+			l := log.WithFields(logrus.Fields{
+				"org":  org,
+				"repo": repo,
+				"pr":   num,
+			})
+			err := takeAction(
+				l,
+				&giteeLabelCommenter{gc},
+				org,
+				repo,
+				num,
+				pr.User.Login,
+				hasGiteeLabel(pr),
+				pr.Mergeable,
+			)
+			if err != nil {
+				l.WithError(err).Error("Error handling PR.")
+			}
+		}
+	}
+	return nil
+}
+
+func hasGiteeLabel(pr sdk.PullRequest) bool {
+	for _, l := range pr.Labels {
+		if l.Name == labels.NeedsRebase {
+			return true
+		}
+	}
+	return false
+}
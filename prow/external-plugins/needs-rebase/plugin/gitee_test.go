@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	giteeplugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/labels"
+	"k8s.io/test-infra/prow/plugins"
+)
+
+func newGiteePR(number int, mergeable bool, prLabels ...string) *sdk.PullRequest {
+	pr := &sdk.PullRequest{
+		Number:    int32(number),
+		State:     "open",
+		User:      &sdk.UserBasic{Login: "author"},
+		Mergeable: mergeable,
+	}
+	for _, l := range prLabels {
+		pr.Labels = append(pr.Labels, sdk.Label{Name: l})
+	}
+	return pr
+}
+
+func newGiteeRebaseComment(id int) sdk.PullRequestComments {
+	return sdk.PullRequestComments{
+		Id:   int32(id),
+		Body: plugins.FormatSimpleResponse("author", needsRebaseMessage),
+		User: &sdk.UserBasic{Login: fakegitee.Bot},
+	}
+}
+
+func TestHandleGiteePullRequestEvent(t *testing.T) {
+	oldSleep := sleep
+	sleep = func(time.Duration) { return }
+	defer func() { sleep = oldSleep }()
+
+	testCases := []struct {
+		name       string
+		action     string
+		actionDesc string
+		mergeable  bool
+		labels     []string
+
+		expectedAdded   []string
+		expectedRemoved []string
+		expectComment   bool
+		expectDeletion  bool
+	}{
+		{
+			name:      "mergeable no-op",
+			action:    "open",
+			mergeable: true,
+			labels:    []string{labels.LGTM},
+		},
+		{
+			name:   "unmergeable no-op",
+			action: "open",
+			labels: []string{labels.NeedsRebase},
+		},
+		{
+			name:   "opened unmergeable",
+			action: "open",
+
+			expectedAdded: []string{"org/repo#1:" + labels.NeedsRebase},
+			expectComment: true,
+		},
+		{
+			name:       "source branch changed to mergeable",
+			action:     "update",
+			actionDesc: "source_branch_changed",
+			mergeable:  true,
+			labels:     []string{labels.NeedsRebase},
+
+			expectedRemoved: []string{"org/repo#1:" + labels.NeedsRebase},
+			expectDeletion:  true,
+		},
+		{
+			name:       "target branch changed to unmergeable",
+			action:     "update",
+			actionDesc: "target_branch_changed",
+
+			expectedAdded: []string{"org/repo#1:" + labels.NeedsRebase},
+			expectComment: true,
+		},
+		{
+			name:       "update of the labels is ignored",
+			action:     "update",
+			actionDesc: "update_label",
+		},
+		{
+			name:   "close is ignored",
+			action: "close",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.PullRequests[1] = newGiteePR(1, tc.mergeable, tc.labels...)
+			fc.PRComments[1] = []sdk.PullRequestComments{newGiteeRebaseComment(10)}
+
+			e := fakegitee.NewPullRequestEvent(fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author"}, tc.action)
+			if tc.actionDesc != "" {
+				e.ActionDesc = &tc.actionDesc
+			}
+			if err := HandleGiteePullRequestEvent(logrus.WithField("plugin", PluginName), fc, e); err != nil {
+				t.Fatalf("error handling pull request event: %v", err)
+			}
+
+			if !reflect.DeepEqual(fc.PRLabelsAdded, tc.expectedAdded) {
+				t.Errorf("expected the labels %v to be added, got %v", tc.expectedAdded, fc.PRLabelsAdded)
+			}
+			if !reflect.DeepEqual(fc.PRLabelsRemoved, tc.expectedRemoved) {
+				t.Errorf("expected the labels %v to be removed, got %v", tc.expectedRemoved, fc.PRLabelsRemoved)
+			}
+			if commented := len(fc.PRCommentsAdded) > 0; commented != tc.expectComment {
+				t.Errorf("expected a comment: %t, got the comments %v", tc.expectComment, fc.PRCommentsAdded)
+			}
+			if deleted := len(fc.PRCommentsDeleted) > 0; deleted != tc.expectDeletion {
+				t.Errorf("expected a comment deletion: %t, got the deletions %v", tc.expectDeletion, fc.PRCommentsDeleted)
+			}
+		})
+	}
+}
+
+func TestHandleGiteeNoteEvent(t *testing.T) {
+	oldSleep := sleep
+	sleep = func(time.Duration) { return }
+	defer func() { sleep = oldSleep }()
+
+	fc := fakegitee.NewFakeClient()
+	fc.PullRequests[1] = newGiteePR(1, false)
+
+	e := fakegitee.NewPRNoteEvent(fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author"}, "someone", "/retest")
+	if err := HandleGiteeNoteEvent(logrus.WithField("plugin", PluginName), fc, e); err != nil {
+		t.Fatalf("error handling note event: %v", err)
+	}
+	if expected := []string{"org/repo#1:" + labels.NeedsRebase}; !reflect.DeepEqual(fc.PRLabelsAdded, expected) {
+		t.Errorf("expected the labels %v to be added, got %v", expected, fc.PRLabelsAdded)
+	}
+
+	// The comments on issues are ignored.
+	fc = fakegitee.NewFakeClient()
+	e = fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "someone", "/retest")
+	if err := HandleGiteeNoteEvent(logrus.WithField("plugin", PluginName), fc, e); err != nil {
+		t.Fatalf("error handling note event: %v", err)
+	}
+	if len(fc.PRLabelsAdded) > 0 {
+		t.Errorf("unexpected labels added: %v", fc.PRLabelsAdded)
+	}
+}
+
+func TestHandleAllGitee(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Repos["org"] = []sdk.Project{{Path: "repo"}}
+	fc.PullRequests[1] = newGiteePR(1, false)
+	fc.PullRequests[2] = newGiteePR(2, true, labels.NeedsRebase)
+	fc.PullRequests[3] = newGiteePR(3, true)
+	fc.PRComments[2] = []sdk.PullRequestComments{newGiteeRebaseComment(20)}
+
+	config := &giteeplugins.Configurations{}
+	config.ExternalPlugins = map[string][]plugins.ExternalPlugin{
+		"org": {{Name: PluginName}},
+	}
+	if err := HandleAllGitee(logrus.WithField("plugin", PluginName), fc, config); err != nil {
+		t.Fatalf("Unexpected error handling all PRs: %v", err)
+	}
+
+	if expected := []string{"org/repo#1:" + labels.NeedsRebase}; !reflect.DeepEqual(fc.PRLabelsAdded, expected) {
+		t.Errorf("expected the labels %v to be added, got %v", expected, fc.PRLabelsAdded)
+	}
+	if expected := []string{"org/repo#2:" + labels.NeedsRebase}; !reflect.DeepEqual(fc.PRLabelsRemoved, expected) {
+		t.Errorf("expected the labels %v to be removed, got %v", expected, fc.PRLabelsRemoved)
+	}
+	if expected := []string{"org/repo#20"}; !reflect.DeepEqual(fc.PRCommentsDeleted, expected) {
+		t.Errorf("expected the comments %v to be deleted, got %v", expected, fc.PRCommentsDeleted)
+	}
+}
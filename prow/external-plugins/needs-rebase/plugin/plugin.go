@@ -53,6 +53,15 @@ type githubClient interface {
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
 }
 
+// labelCommenter updates the label and the comment of a PR.
+type labelCommenter interface {
+	CreateComment(org, repo string, number int, comment string) error
+	BotName() (string, error)
+	AddLabel(org, repo string, number int, label string) error
+	RemoveLabel(org, repo string, number int, label string) error
+	DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error
+}
+
 type commentPruner interface {
 	PruneComments(shouldPrune func(github.IssueComment) bool)
 }
@@ -185,7 +194,7 @@ func HandleAll(log *logrus.Entry, ghc githubClient, config *plugins.Configuratio
 // takeAction adds or removes the "needs-rebase" label based on the current
 // state of the PR (hasLabel and mergeable). It also handles adding and
 // removing GitHub comments notifying the PR author that a rebase is needed.
-func takeAction(log *logrus.Entry, ghc githubClient, org, repo string, num int, author string, hasLabel, mergeable bool) error {
+func takeAction(log *logrus.Entry, ghc labelCommenter, org, repo string, num int, author string, hasLabel, mergeable bool) error {
 	if !mergeable && !hasLabel {
 		if err := ghc.AddLabel(org, repo, num, labels.NeedsRebase); err != nil {
 			log.WithError(err).Errorf("Failed to add %q label.", labels.NeedsRebase)
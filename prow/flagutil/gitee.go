@@ -3,6 +3,7 @@ package flagutil
 import (
 	"flag"
 	"fmt"
+	"net/url"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/test-infra/prow/config/secret"
@@ -12,12 +13,20 @@ import (
 
 // GiteeOptions holds options for interacting with Gitee.
 type GiteeOptions struct {
+	endpoint  Strings
 	TokenPath string
+
+	// ThrottleHourlyTokens and ThrottleAllowBurst limit the rate of the
+	// requests sent with the token, they are disabled when zero.
+	ThrottleHourlyTokens int
+	ThrottleAllowBurst   int
 }
 
 // NewGiteeOptions creates a GiteeOptions with default values.
 func NewGiteeOptions() *GiteeOptions {
-	return &GiteeOptions{}
+	return &GiteeOptions{
+		endpoint: NewStrings(gitee.DefaultAPIEndpoint),
+	}
 }
 
 // AddFlags injects Gitee options into the given FlagSet.
@@ -33,15 +42,38 @@ func (o *GiteeOptions) AddFlagsWithoutDefaultGiteeTokenPath(fs *flag.FlagSet) {
 }
 
 func (o *GiteeOptions) addFlags(wantDefaultGiteeTokenPath bool, fs *flag.FlagSet) {
+	o.endpoint = NewStrings(gitee.DefaultAPIEndpoint)
+	fs.Var(&o.endpoint, "gitee-endpoint", "Gitee's API endpoint, repeat the flag to fall back to the next endpoints on connection errors.")
 	defaultGiteeTokenPath := ""
 	if wantDefaultGiteeTokenPath {
 		defaultGiteeTokenPath = "/etc/gitee/oauth"
 	}
 	fs.StringVar(&o.TokenPath, "gitee-token-path", defaultGiteeTokenPath, "Path to the file containing the Gitee OAuth secret.")
+	fs.IntVar(&o.ThrottleHourlyTokens, "gitee-hourly-tokens", 0, "If set to a value larger than zero, enable client-side throttling to limit hourly token consumption. If set, --gitee-allowed-burst must be positive too.")
+	fs.IntVar(&o.ThrottleAllowBurst, "gitee-allowed-burst", 0, "Size of token consumption bursts. If set, --gitee-hourly-tokens must be positive too and set to a higher or equal number.")
 }
 
 // Validate validates Gitee options.
 func (o *GiteeOptions) Validate(dryRun bool) error {
+	for _, uri := range o.endpoint.Strings() {
+		if _, err := url.ParseRequestURI(uri); err != nil {
+			return fmt.Errorf("invalid -gitee-endpoint URI: %q", uri)
+		}
+	}
+
+	return o.parseThrottle()
+}
+
+func (o *GiteeOptions) parseThrottle() error {
+	if o.ThrottleHourlyTokens == 0 && o.ThrottleAllowBurst == 0 {
+		return nil
+	}
+	if o.ThrottleHourlyTokens <= 0 || o.ThrottleAllowBurst <= 0 {
+		return fmt.Errorf("--gitee-hourly-tokens and --gitee-allowed-burst must be both positive or both zero, got %d and %d", o.ThrottleHourlyTokens, o.ThrottleAllowBurst)
+	}
+	if o.ThrottleAllowBurst > o.ThrottleHourlyTokens {
+		return fmt.Errorf("--gitee-allowed-burst must not be larger than --gitee-hourly-tokens, got %d > %d", o.ThrottleAllowBurst, o.ThrottleHourlyTokens)
+	}
 	return nil
 }
 
@@ -52,7 +84,16 @@ func (o *GiteeOptions) GiteeClientWithLogFields(secretAgent *secret.Agent, dryRu
 		return nil, err
 	}
 
-	return gitee.NewClient(generator), nil
+	var client gitee.Client
+	if dryRun {
+		client = gitee.NewDryRunClientWithFields(fields, generator, o.endpoint.Strings()...)
+	} else {
+		client = gitee.NewClientWithFields(fields, generator, o.endpoint.Strings()...)
+	}
+	if o.ThrottleHourlyTokens > 0 && o.ThrottleAllowBurst > 0 {
+		client.Throttle(o.ThrottleHourlyTokens, o.ThrottleAllowBurst)
+	}
+	return client, nil
 }
 
 // GiteeClient returns a Gitee client.
@@ -60,6 +101,12 @@ func (o *GiteeOptions) GiteeClient(secretAgent *secret.Agent, dryRun bool) (clie
 	return o.GiteeClientWithLogFields(secretAgent, dryRun, logrus.Fields{})
 }
 
+// GiteeClientWithAccessToken returns a Gitee client acting on behalf of the user
+// who granted the OAuth access token.
+func (o *GiteeOptions) GiteeClientWithAccessToken(token string) gitee.Client {
+	return gitee.NewClient(func() []byte { return []byte(token) }, o.endpoint.Strings()...)
+}
+
 // GitClient returns a Git client factory.
 func (o *GiteeOptions) GitClient(secretAgent *secret.Agent, dryRun bool) (git.ClientFactory, error) {
 	f, err := token(o.TokenPath, secretAgent)
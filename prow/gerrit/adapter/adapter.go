@@ -41,6 +41,8 @@ type prowJobClient interface {
 
 type gerritClient interface {
 	QueryChanges(lastState client.LastSyncState, rateLimit int) map[string][]client.ChangeInfo
+	GetChange(instance, project, changeID string) (*client.ChangeInfo, error)
+	GetEvents(instance string, since time.Time) ([]client.EventInfo, error)
 	GetBranchRevision(instance, project, branch string) (string, error)
 	SetReview(instance, id, revision, message string, labels map[string]string) error
 	Account(instance string) *gerrit.AccountInfo
@@ -122,8 +124,8 @@ func makeCloneURI(instance, project string) (*url.URL, error) {
 	return u, nil
 }
 
-// listChangedFiles lists (in lexicographic order) the files changed as part of a Gerrit patchset
-func listChangedFiles(changeInfo client.ChangeInfo) config.ChangedFilesProvider {
+// ListChangedFiles lists (in lexicographic order) the files changed as part of a Gerrit patchset
+func ListChangedFiles(changeInfo client.ChangeInfo) config.ChangedFilesProvider {
 	return func() ([]string, error) {
 		var changed []string
 		revision := changeInfo.Revisions[changeInfo.CurrentRevision]
@@ -196,7 +198,7 @@ func (c *Controller) ProcessChange(instance string, change client.ChangeInfo) er
 
 	var jobSpecs []jobSpec
 
-	changedFiles := listChangedFiles(change)
+	changedFiles := ListChangedFiles(change)
 
 	switch change.Status {
 	case client.Merged:
@@ -215,8 +217,7 @@ func (c *Controller) ProcessChange(instance string, change client.ChangeInfo) er
 		}
 	case client.New:
 		// TODO: Do we want to add support for dynamic presubmits?
-		presubmits := c.config().PresubmitsStatic[cloneURI.String()]
-		presubmits = append(presubmits, c.config().PresubmitsStatic[cloneURI.Host+"/"+cloneURI.Path]...)
+		presubmits := presubmitsForCloneURI(c.config(), cloneURI)
 
 		var filters []pjutil.Filter
 		var latestReport *reporter.JobReport
@@ -260,7 +261,7 @@ func (c *Controller) ProcessChange(instance string, change client.ChangeInfo) er
 		if change.Revisions[change.CurrentRevision].Created.Time.After(lastUpdate) {
 			filters = append(filters, pjutil.TestAllFilter())
 		}
-		toTrigger, _, err := pjutil.FilterPresubmits(pjutil.AggregateFilter(filters), listChangedFiles(change), change.Branch, presubmits, logger)
+		toTrigger, _, err := pjutil.FilterPresubmits(pjutil.AggregateFilter(filters), ListChangedFiles(change), change.Branch, presubmits, logger)
 		if err != nil {
 			return fmt.Errorf("failed to filter presubmits: %v", err)
 		}
@@ -272,23 +273,8 @@ func (c *Controller) ProcessChange(instance string, change client.ChangeInfo) er
 		}
 	}
 
-	annotations := map[string]string{
-		client.GerritID:       change.ID,
-		client.GerritInstance: instance,
-	}
-
 	for _, jSpec := range jobSpecs {
-		labels := make(map[string]string)
-		for k, v := range jSpec.labels {
-			labels[k] = v
-		}
-		labels[client.GerritRevision] = change.CurrentRevision
-
-		if _, ok := labels[client.GerritReportLabel]; !ok {
-			labels[client.GerritReportLabel] = client.CodeReview
-		}
-
-		pj := pjutil.NewProwJob(jSpec.spec, labels, annotations)
+		pj := newProwJob(instance, change, jSpec.spec, jSpec.labels)
 		if _, err := c.prowJobClient.Create(&pj); err != nil {
 			logger.WithError(err).Errorf("fail to create prowjob %v", pj)
 		} else {
@@ -311,3 +297,54 @@ func (c *Controller) ProcessChange(instance string, change client.ChangeInfo) er
 
 	return nil
 }
+
+// newProwJob returns the ProwJob of the change with the labels and the
+// annotations the gerrit reporter of crier needs to report back to it.
+func newProwJob(instance string, change client.ChangeInfo, spec prowapi.ProwJobSpec, jobLabels map[string]string) prowapi.ProwJob {
+	labels := make(map[string]string)
+	for k, v := range jobLabels {
+		labels[k] = v
+	}
+	labels[client.GerritRevision] = change.CurrentRevision
+
+	if _, ok := labels[client.GerritReportLabel]; !ok {
+		labels[client.GerritReportLabel] = client.CodeReview
+	}
+
+	annotations := map[string]string{
+		client.GerritID:       change.ID,
+		client.GerritInstance: instance,
+	}
+	return pjutil.NewProwJob(spec, labels, annotations)
+}
+
+func presubmitsForCloneURI(cfg *config.Config, cloneURI *url.URL) []config.Presubmit {
+	var presubmits []config.Presubmit
+	presubmits = append(presubmits, cfg.PresubmitsStatic[cloneURI.String()]...)
+	return append(presubmits, cfg.PresubmitsStatic[cloneURI.Host+"/"+cloneURI.Path]...)
+}
+
+// Presubmits returns the presubmits configured for the project of the
+// gerrit instance.
+func Presubmits(cfg *config.Config, instance, project string) ([]config.Presubmit, error) {
+	cloneURI, err := makeCloneURI(instance, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clone uri: %v", err)
+	}
+	return presubmitsForCloneURI(cfg, cloneURI), nil
+}
+
+// PresubmitJob returns the ProwJob running the presubmit against the current
+// revision of the change on top of baseSHA, which is reported back to the
+// change like the ones triggered by the controller.
+func PresubmitJob(instance string, change client.ChangeInfo, presubmit config.Presubmit, baseSHA string) (prowapi.ProwJob, error) {
+	cloneURI, err := makeCloneURI(instance, change.Project)
+	if err != nil {
+		return prowapi.ProwJob{}, fmt.Errorf("failed to create clone uri: %v", err)
+	}
+	refs, err := createRefs(instance, change, cloneURI, baseSHA)
+	if err != nil {
+		return prowapi.ProwJob{}, fmt.Errorf("failed to get refs: %v", err)
+	}
+	return newProwJob(instance, change, pjutil.PresubmitSpec(presubmit, refs), presubmit.Labels), nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package adapter
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -63,6 +64,14 @@ func (f *fgc) SetReview(instance, id, revision, message string, labels map[strin
 	return nil
 }
 
+func (f *fgc) GetChange(instance, project, changeID string) (*client.ChangeInfo, error) {
+	return nil, errors.New("no change")
+}
+
+func (f *fgc) GetEvents(instance string, since time.Time) ([]client.EventInfo, error) {
+	return nil, nil
+}
+
 func (f *fgc) GetBranchRevision(instance, project, branch string) (string, error) {
 	return "abc", nil
 }
@@ -192,6 +201,49 @@ func TestCreateRefs(t *testing.T) {
 	}
 }
 
+func TestPresubmitJob(t *testing.T) {
+	change := client.ChangeInfo{
+		ID:              "meow%2Fpurr~master~I42",
+		Number:          42,
+		Project:         "meow/purr",
+		CurrentRevision: "123456",
+		Branch:          "master",
+		Revisions: map[string]client.RevisionInfo{
+			"123456": {Ref: "refs/changes/00/1/1"},
+		},
+	}
+	presubmit := config.Presubmit{
+		JobBase: config.JobBase{
+			Name:   "test-foo",
+			Labels: map[string]string{client.GerritReportLabel: "Verified"},
+		},
+	}
+
+	pj, err := PresubmitJob("https://cat-review.example.com", change, presubmit, "abcdef")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pj.Spec.Job != "test-foo" || pj.Spec.Type != prowapi.PresubmitJob {
+		t.Errorf("expected the presubmit test-foo, got the %s job %s", pj.Spec.Type, pj.Spec.Job)
+	}
+	if pj.Spec.Refs == nil || pj.Spec.Refs.BaseSHA != "abcdef" || pj.Spec.Refs.Pulls[0].SHA != "123456" {
+		t.Errorf("expected the refs of revision 123456 on abcdef, got %#v", pj.Spec.Refs)
+	}
+	if label := pj.Labels[client.GerritRevision]; label != "123456" {
+		t.Errorf("expected the revision label 123456, got %q", label)
+	}
+	if label := pj.Labels[client.GerritReportLabel]; label != "Verified" {
+		t.Errorf("expected the report label of the job to be kept, got %q", label)
+	}
+	if pj.Annotations[client.GerritID] != change.ID || pj.Annotations[client.GerritInstance] != "https://cat-review.example.com" {
+		t.Errorf("expected the annotations of the change, got %v", pj.Annotations)
+	}
+
+	if _, err := PresubmitJob("not a url", change, presubmit, "abcdef"); err == nil {
+		t.Error("expected an error for an invalid instance")
+	}
+}
+
 func TestProcessChange(t *testing.T) {
 	var testcases = []struct {
 		name        string
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/gerrit/client"
+)
+
+// EventTracker persists the creation time of the last event processed
+// from each gerrit instance.
+type EventTracker interface {
+	Current() map[string]time.Time
+	Update(instance string, lastEvent time.Time) error
+}
+
+// eventStream is the state of the events of an instance.
+type eventStream struct {
+	// connected is false until the events are fetched, and after they
+	// fail to be fetched.
+	connected bool
+	// seen are the events created at the time of the last processed event,
+	// which are fetched again since gerrit returns the events created since
+	// a time in seconds.
+	seen sets.String
+}
+
+// StreamEvents triggers the jobs from the events of the gerrit instances
+// instead of polling their changes, until the context is done. The events
+// are fetched from the events-log plugin, which records the events of
+// stream-events, every interval. The changes updated while the events
+// can't be fetched, like after a restart, are caught up with Sync.
+func (c *Controller) StreamEvents(ctx context.Context, tracker EventTracker, instances []string, interval func() time.Duration) {
+	streams := map[string]*eventStream{}
+	for _, instance := range instances {
+		streams[instance] = &eventStream{seen: sets.NewString()}
+	}
+
+	for {
+		start := time.Now()
+		c.syncEvents(tracker, streams)
+		logrus.WithField("duration", fmt.Sprintf("%v", time.Since(start))).Debug("Synced events")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval()):
+		}
+	}
+}
+
+// syncEvents processes the new events of each instance.
+func (c *Controller) syncEvents(tracker EventTracker, streams map[string]*eventStream) {
+	catchUp := false
+	events := map[string][]client.EventInfo{}
+	for instance, stream := range streams {
+		since, ok := tracker.Current()[instance]
+		if !ok {
+			// Only the events created from now on are processed the first
+			// time, the earlier ones are caught up by polling.
+			since = time.Now()
+			if err := tracker.Update(instance, since); err != nil {
+				logrus.WithError(err).WithField("instance", instance).Error("Failed to initialize the last event.")
+			}
+		}
+
+		es, err := c.gc.GetEvents(instance, since)
+		if err != nil {
+			logrus.WithError(err).WithField("instance", instance).Error("Failed to get the events, they will be caught up by polling.")
+			stream.connected = false
+			continue
+		}
+		if !stream.connected {
+			logrus.WithField("instance", instance).Info("Connected to the events, catching up by polling.")
+			stream.connected = true
+			catchUp = true
+		}
+		events[instance] = es
+	}
+
+	if catchUp {
+		if err := c.Sync(); err != nil {
+			logrus.WithError(err).Error("Error catching up by polling.")
+		}
+	}
+
+	for instance, es := range events {
+		c.processEvents(instance, es, tracker, streams[instance])
+	}
+}
+
+// processEvents processes the events of an instance in order and records
+// the last one. It stops at the first event failing to be processed, which
+// is retried on the next sync, and disconnects the stream so that the
+// changes are caught up by polling.
+func (c *Controller) processEvents(instance string, events []client.EventInfo, tracker EventTracker, stream *eventStream) {
+	last := tracker.Current()[instance]
+	for _, event := range events {
+		created := time.Unix(int64(event.EventCreatedOn), 0)
+		key := eventKey(event)
+		if created.Before(last) || (created.Equal(last) && stream.seen.Has(key)) {
+			continue
+		}
+
+		if err := c.processEvent(instance, event); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"instance": instance,
+				"type":     event.Type,
+				"change":   event.Change.ID,
+			}).Error("Failed to process the event, catching up by polling.")
+			stream.connected = false
+			break
+		}
+
+		if created.After(last) {
+			last = created
+			stream.seen = sets.NewString()
+		}
+		stream.seen.Insert(key)
+	}
+
+	if err := tracker.Update(instance, last); err != nil {
+		logrus.WithError(err).WithField("instance", instance).Error("Failed to update the last event.")
+	}
+}
+
+func eventKey(event client.EventInfo) string {
+	return fmt.Sprintf("%s/%s/%s/%d", event.Type, event.Change.ID, event.PatchSet.Number, event.EventCreatedOn)
+}
+
+// processEvent triggers the jobs of the change of a new patchset, a new
+// comment or a merge, the way Sync does for the changes it polls.
+func (c *Controller) processEvent(instance string, event client.EventInfo) error {
+	switch event.Type {
+	case client.PatchSetCreated, client.CommentAdded, client.ChangeMerged:
+	default:
+		return nil
+	}
+
+	project := event.Change.Project
+	lastUpdate, ok := c.tracker.Current()[instance][project]
+	if !ok {
+		// Not a monitored project.
+		return nil
+	}
+
+	change, err := c.gc.GetChange(instance, project, event.Change.ID)
+	if err != nil {
+		return err
+	}
+
+	switch change.Status {
+	case client.New:
+	case client.Merged:
+		// The merge may have been processed already by polling.
+		if change.Submitted == nil || !change.Submitted.Time.After(lastUpdate) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if err := c.ProcessChange(instance, *change); err != nil {
+		return err
+	}
+
+	latest := c.tracker.Current().DeepCopy()
+	if latest[instance][project].Before(change.Updated.Time) {
+		latest[instance][project] = change.Updated.Time
+	}
+	return c.tracker.Update(latest)
+}
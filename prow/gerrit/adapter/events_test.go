@@ -0,0 +1,309 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	clienttesting "k8s.io/client-go/testing"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowfake "k8s.io/test-infra/prow/client/clientset/versioned/fake"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gerrit/client"
+)
+
+type fakeEventsClient struct {
+	fgc
+	events  map[string][]client.EventInfo
+	err     error
+	changes map[string]client.ChangeInfo
+	synced  int
+	since   map[string]time.Time
+}
+
+func (f *fakeEventsClient) QueryChanges(lastUpdate client.LastSyncState, rateLimit int) map[string][]client.ChangeInfo {
+	f.synced++
+	return nil
+}
+
+func (f *fakeEventsClient) GetChange(instance, project, changeID string) (*client.ChangeInfo, error) {
+	change, ok := f.changes[changeID]
+	if !ok {
+		return nil, errors.New("no change")
+	}
+	return &change, nil
+}
+
+func (f *fakeEventsClient) GetEvents(instance string, since time.Time) ([]client.EventInfo, error) {
+	f.since[instance] = since
+	return f.events[instance], f.err
+}
+
+type fakeEventTracker struct {
+	lock sync.Mutex
+	val  map[string]time.Time
+}
+
+func (f *fakeEventTracker) Current() map[string]time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	r := map[string]time.Time{}
+	for k, v := range f.val {
+		r[k] = v
+	}
+	return r
+}
+
+func (f *fakeEventTracker) Update(instance string, lastEvent time.Time) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.val[instance] = lastEvent
+	return nil
+}
+
+func newEvent(eventType, project, changeID string, created time.Time) client.EventInfo {
+	return client.EventInfo{
+		Type:           eventType,
+		Change:         client.ChangeInfo{Project: project, ID: changeID},
+		PatchSet:       gerrit.PatchSet{Number: "1"},
+		EventCreatedOn: int(created.Unix()),
+	}
+}
+
+func TestSyncEvents(t *testing.T) {
+	instance := "https://gerrit"
+	lastEvent := timeNow.Truncate(time.Second)
+
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "always-runs"},
+			AlwaysRun: true,
+			Reporter:  config.Reporter{Context: "always-runs"},
+		},
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("could not set regexes: %v", err)
+	}
+	fca := &fca{
+		c: &config.Config{
+			JobConfig: config.JobConfig{
+				PresubmitsStatic: map[string][]config.Presubmit{"gerrit/test-infra": presubmits},
+			},
+		},
+	}
+
+	newChange := func(id string, created time.Time) client.ChangeInfo {
+		return client.ChangeInfo{
+			ID:              id,
+			CurrentRevision: "1",
+			Project:         "test-infra",
+			Status:          client.New,
+			Updated:         makeStamp(created),
+			Revisions: map[string]client.RevisionInfo{
+				"1": {Number: 1, Created: makeStamp(created)},
+			},
+		}
+	}
+
+	gc := &fakeEventsClient{
+		events: map[string][]client.EventInfo{
+			instance: {
+				newEvent(client.PatchSetCreated, "test-infra", "I0", lastEvent.Add(-time.Second)),
+				newEvent(client.PatchSetCreated, "test-infra", "I1", lastEvent.Add(time.Second)),
+				newEvent(client.CommentAdded, "other", "I2", lastEvent.Add(2*time.Second)),
+				newEvent("change-abandoned", "test-infra", "I3", lastEvent.Add(2*time.Second)),
+			},
+		},
+		changes: map[string]client.ChangeInfo{
+			"I0": newChange("I0", lastEvent.Add(-time.Second)),
+			"I1": newChange("I1", lastEvent.Add(time.Second)),
+			"I2": newChange("I2", lastEvent.Add(2*time.Second)),
+			"I3": newChange("I3", lastEvent.Add(2*time.Second)),
+		},
+		since: map[string]time.Time{},
+	}
+	fakeProwJobClient := prowfake.NewSimpleClientset()
+	c := &Controller{
+		config:        fca.Config,
+		prowJobClient: fakeProwJobClient.ProwV1().ProwJobs("prowjobs"),
+		gc:            gc,
+		tracker:       &fakeSync{val: client.LastSyncState{instance: {"test-infra": lastEvent.Add(-time.Minute)}}},
+	}
+	tracker := &fakeEventTracker{val: map[string]time.Time{instance: lastEvent}}
+	streams := map[string]*eventStream{instance: {seen: map[string]sets.Empty{}}}
+
+	triggered := func() []string {
+		var changes []string
+		for _, action := range fakeProwJobClient.Fake.Actions() {
+			if action, ok := action.(clienttesting.CreateActionImpl); ok {
+				if pj, ok := action.Object.(*prowapi.ProwJob); ok {
+					changes = append(changes, pj.Annotations[client.GerritID])
+				}
+			}
+		}
+		sort.Strings(changes)
+		return changes
+	}
+
+	c.syncEvents(tracker, streams)
+
+	if gc.synced != 1 {
+		t.Errorf("expected to catch up by polling once after connecting, polled %d times", gc.synced)
+	}
+	if !gc.since[instance].Equal(lastEvent) {
+		t.Errorf("expected the events since %v, got %v", lastEvent, gc.since[instance])
+	}
+	if expected := []string{"I1"}; !reflect.DeepEqual(triggered(), expected) {
+		t.Errorf("expected jobs triggered for %v, got %v", expected, triggered())
+	}
+	if expected := lastEvent.Add(2 * time.Second); !tracker.Current()[instance].Equal(expected) {
+		t.Errorf("expected the last event at %v, got %v", expected, tracker.Current()[instance])
+	}
+	if expected := lastEvent.Add(time.Second); !c.tracker.Current()[instance]["test-infra"].Equal(expected) {
+		t.Errorf("expected the last sync of the project at %v, got %v", expected, c.tracker.Current()[instance]["test-infra"])
+	}
+
+	// The events of the last second are fetched again, but not processed
+	// again, and the stream stays connected.
+	c.syncEvents(tracker, streams)
+	if gc.synced != 1 {
+		t.Errorf("expected no polling while connected, polled %d times", gc.synced)
+	}
+	if expected := []string{"I1"}; !reflect.DeepEqual(triggered(), expected) {
+		t.Errorf("expected no new job, got jobs for %v", triggered())
+	}
+
+	// A failure disconnects the stream, which catches up once reconnected.
+	gc.err = errors.New("injected error")
+	c.syncEvents(tracker, streams)
+	gc.err = nil
+	c.syncEvents(tracker, streams)
+	if gc.synced != 2 {
+		t.Errorf("expected to catch up by polling after reconnecting, polled %d times", gc.synced)
+	}
+}
+
+func TestProcessEventsFailure(t *testing.T) {
+	instance := "https://gerrit"
+	lastEvent := timeNow.Truncate(time.Second)
+
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "always-runs"},
+			AlwaysRun: true,
+			Reporter:  config.Reporter{Context: "always-runs"},
+		},
+	}
+	if err := config.SetPresubmitRegexes(presubmits); err != nil {
+		t.Fatalf("could not set regexes: %v", err)
+	}
+	fca := &fca{
+		c: &config.Config{
+			JobConfig: config.JobConfig{
+				PresubmitsStatic: map[string][]config.Presubmit{"gerrit/test-infra": presubmits},
+			},
+		},
+	}
+
+	newChange := func(id string, created time.Time) client.ChangeInfo {
+		return client.ChangeInfo{
+			ID:              id,
+			CurrentRevision: "1",
+			Project:         "test-infra",
+			Status:          client.New,
+			Updated:         makeStamp(created),
+			Revisions: map[string]client.RevisionInfo{
+				"1": {Number: 1, Created: makeStamp(created)},
+			},
+		}
+	}
+
+	gc := &fakeEventsClient{
+		events: map[string][]client.EventInfo{
+			instance: {
+				newEvent(client.PatchSetCreated, "test-infra", "I1", lastEvent.Add(time.Second)),
+				newEvent(client.PatchSetCreated, "test-infra", "I2", lastEvent.Add(2*time.Second)),
+				newEvent(client.PatchSetCreated, "test-infra", "I3", lastEvent.Add(3*time.Second)),
+			},
+		},
+		// I2 fails to be fetched.
+		changes: map[string]client.ChangeInfo{
+			"I1": newChange("I1", lastEvent.Add(time.Second)),
+			"I3": newChange("I3", lastEvent.Add(3*time.Second)),
+		},
+		since: map[string]time.Time{},
+	}
+	fakeProwJobClient := prowfake.NewSimpleClientset()
+	c := &Controller{
+		config:        fca.Config,
+		prowJobClient: fakeProwJobClient.ProwV1().ProwJobs("prowjobs"),
+		gc:            gc,
+		tracker:       &fakeSync{val: client.LastSyncState{instance: {"test-infra": lastEvent.Add(-time.Minute)}}},
+	}
+	tracker := &fakeEventTracker{val: map[string]time.Time{instance: lastEvent}}
+	streams := map[string]*eventStream{instance: {seen: map[string]sets.Empty{}}}
+
+	triggered := func() []string {
+		var changes []string
+		for _, action := range fakeProwJobClient.Fake.Actions() {
+			if action, ok := action.(clienttesting.CreateActionImpl); ok {
+				if pj, ok := action.Object.(*prowapi.ProwJob); ok {
+					changes = append(changes, pj.Annotations[client.GerritID])
+				}
+			}
+		}
+		sort.Strings(changes)
+		return changes
+	}
+
+	c.syncEvents(tracker, streams)
+
+	if expected := []string{"I1"}; !reflect.DeepEqual(triggered(), expected) {
+		t.Errorf("expected jobs triggered for %v, got %v", expected, triggered())
+	}
+	if expected := lastEvent.Add(time.Second); !tracker.Current()[instance].Equal(expected) {
+		t.Errorf("expected the last event to stay before the failed one at %v, got %v", expected, tracker.Current()[instance])
+	}
+	if expected := lastEvent.Add(time.Second); !c.tracker.Current()[instance]["test-infra"].Equal(expected) {
+		t.Errorf("expected the last sync of the project to stay at %v, got %v", expected, c.tracker.Current()[instance]["test-infra"])
+	}
+	if streams[instance].connected {
+		t.Error("expected the failure to disconnect the stream")
+	}
+
+	// The failed event and the ones after it are processed on the next
+	// sync, after catching up by polling.
+	gc.changes["I2"] = newChange("I2", lastEvent.Add(2*time.Second))
+	c.syncEvents(tracker, streams)
+	if gc.synced != 2 {
+		t.Errorf("expected to catch up by polling after the failure, polled %d times", gc.synced)
+	}
+	if expected := []string{"I1", "I2", "I3"}; !reflect.DeepEqual(triggered(), expected) {
+		t.Errorf("expected jobs triggered for %v, got %v", expected, triggered())
+	}
+	if expected := lastEvent.Add(3 * time.Second); !tracker.Current()[instance].Equal(expected) {
+		t.Errorf("expected the last event at %v, got %v", expected, tracker.Current()[instance])
+	}
+}
@@ -21,11 +21,13 @@ package client
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/andygrunwald/go-gerrit"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
@@ -45,6 +47,13 @@ const (
 	Merged = "MERGED"
 	// New status indicates a Gerrit change is new (ie pending)
 	New = "NEW"
+
+	// PatchSetCreated is the type of the event sent when a patchset is uploaded
+	PatchSetCreated = "patchset-created"
+	// CommentAdded is the type of the event sent when a change is commented
+	CommentAdded = "comment-added"
+	// ChangeMerged is the type of the event sent when a change is submitted
+	ChangeMerged = "change-merged"
 )
 
 // ProjectsFlag is the flag type for gerrit projects when initializing a gerrit client
@@ -85,12 +94,40 @@ type gerritAccount interface {
 type gerritChange interface {
 	QueryChanges(opt *gerrit.QueryChangeOptions) (*[]gerrit.ChangeInfo, *gerrit.Response, error)
 	SetReview(changeID, revisionID string, input *gerrit.ReviewInput) (*gerrit.ReviewResult, *gerrit.Response, error)
+	SubmitChange(changeID string, input *gerrit.SubmitInput) (*gerrit.ChangeInfo, *gerrit.Response, error)
+	ListFiles(changeID, revisionID string, opt *gerrit.FilesOptions) (map[string]gerrit.FileInfo, *gerrit.Response, error)
+	GetDiff(changeID, revisionID, fileID string, opt *gerrit.DiffOptions) (*gerrit.DiffInfo, *gerrit.Response, error)
+}
+
+type gerritRobotComments interface {
+	ListRobotComments(changeID, revisionID string) (map[string][]gerrit.RobotCommentInfo, error)
+}
+
+// robotCommentsService lists the robot comments, which go-gerrit does not
+// support yet
+type robotCommentsService struct {
+	client *gerrit.Client
+}
+
+// ListRobotComments lists the robot comments of a revision
+// Gerrit API docs: https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#list-robot-comments
+func (s *robotCommentsService) ListRobotComments(changeID, revisionID string) (map[string][]gerrit.RobotCommentInfo, error) {
+	comments := map[string][]gerrit.RobotCommentInfo{}
+	u := fmt.Sprintf("changes/%s/revisions/%s/robotcomments", changeID, revisionID)
+	if _, err := s.client.Call("GET", u, nil, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
 }
 
 type gerritProjects interface {
 	GetBranch(projectName, branchID string) (*gerrit.BranchInfo, *gerrit.Response, error)
 }
 
+type gerritEventsLog interface {
+	GetEvents(options *gerrit.EventsLogOptions) ([]gerrit.EventInfo, *gerrit.Response, [][]byte, error)
+}
+
 // gerritInstanceHandler holds all actual gerrit handlers
 type gerritInstanceHandler struct {
 	instance string
@@ -100,6 +137,8 @@ type gerritInstanceHandler struct {
 	accountService gerritAccount
 	changeService  gerritChange
 	projectService gerritProjects
+	eventsService  gerritEventsLog
+	robotService   gerritRobotComments
 }
 
 // Client holds a instance:handler map
@@ -118,6 +157,15 @@ type RevisionInfo = gerrit.RevisionInfo
 // FileInfo is a gerrit.FileInfo
 type FileInfo = gerrit.FileInfo
 
+// EventInfo is a gerrit.EventInfo
+type EventInfo = gerrit.EventInfo
+
+// RobotCommentInput is a gerrit.RobotCommentInput
+type RobotCommentInput = gerrit.RobotCommentInput
+
+// RobotCommentInfo is a gerrit.RobotCommentInfo
+type RobotCommentInfo = gerrit.RobotCommentInfo
+
 // Map from instance name to repos to lastsync time for that repo
 type LastSyncState map[string]map[string]time.Time
 
@@ -151,6 +199,8 @@ func NewClient(instances map[string][]string) (*Client, error) {
 			accountService: gc.Accounts,
 			changeService:  gc.Changes,
 			projectService: gc.Projects,
+			eventsService:  gc.EventsLog,
+			robotService:   &robotCommentsService{client: gc},
 		}
 	}
 
@@ -220,14 +270,21 @@ func (c *Client) QueryChanges(lastState LastSyncState, rateLimit int) map[string
 
 // SetReview writes a review comment base on the change id + revision
 func (c *Client) SetReview(instance, id, revision, message string, labels map[string]string) error {
+	return c.SetReviewWithRobotComments(instance, id, revision, message, labels, nil)
+}
+
+// SetReviewWithRobotComments writes a review comment together with the inline
+// robot comments of each file base on the change id + revision
+func (c *Client) SetReviewWithRobotComments(instance, id, revision, message string, labels map[string]string, comments map[string][]RobotCommentInput) error {
 	h, ok := c.handlers[instance]
 	if !ok {
 		return fmt.Errorf("not activated gerrit instance: %s", instance)
 	}
 
 	if _, _, err := h.changeService.SetReview(id, revision, &gerrit.ReviewInput{
-		Message: message,
-		Labels:  labels,
+		Message:       message,
+		Labels:        labels,
+		RobotComments: comments,
 	}); err != nil {
 		return fmt.Errorf("cannot comment to gerrit: %v", err)
 	}
@@ -235,6 +292,65 @@ func (c *Client) SetReview(instance, id, revision, message string, labels map[st
 	return nil
 }
 
+// ListRobotComments returns the robot comments of each file of the revision
+func (c *Client) ListRobotComments(instance, id, revision string) (map[string][]RobotCommentInfo, error) {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	comments, err := h.robotService.ListRobotComments(id, revision)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list robot comments of change %s: %v", id, err)
+	}
+	return comments, nil
+}
+
+// ChangedLines returns the lines each of the files added or modified by the
+// revision, the files which are not changed by it are left out
+func (c *Client) ChangedLines(instance, id, revision string, files []string) (map[string]sets.Int, error) {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	changed, _, err := h.changeService.ListFiles(id, revision, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files of change %s: %v", id, err)
+	}
+
+	result := map[string]sets.Int{}
+	for _, file := range files {
+		if _, ok := changed[file]; !ok {
+			continue
+		}
+		if _, ok := result[file]; ok {
+			continue
+		}
+		diff, _, err := h.changeService.GetDiff(id, revision, url.PathEscape(file), nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get diff of %s in change %s: %v", file, id, err)
+		}
+		result[file] = changedLines(diff)
+	}
+	return result, nil
+}
+
+// changedLines returns the lines of the new side of the diff which are not
+// in the old one
+func changedLines(diff *gerrit.DiffInfo) sets.Int {
+	lines := sets.NewInt()
+	line := 1
+	for _, content := range diff.Content {
+		line += len(content.AB) + content.Skip
+		for range content.B {
+			lines.Insert(line)
+			line++
+		}
+	}
+	return lines
+}
+
 // GetBranchRevision returns SHA of HEAD of a branch
 func (c *Client) GetBranchRevision(instance, project, branch string) (string, error) {
 	h, ok := c.handlers[instance]
@@ -250,6 +366,89 @@ func (c *Client) GetBranchRevision(instance, project, branch string) (string, er
 	return res.Revision, nil
 }
 
+// GetChange returns the change of a project by its Change-Id
+func (c *Client) GetChange(instance, project, changeID string) (*ChangeInfo, error) {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	opt := &gerrit.QueryChangeOptions{}
+	opt.Query = append(opt.Query, fmt.Sprintf("project:%s change:%s", project, changeID))
+	opt.AdditionalFields = queryFields
+
+	changes, _, err := h.changeService.QueryChanges(opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gerrit change %s: %v", changeID, err)
+	}
+	if changes == nil || len(*changes) == 0 {
+		return nil, fmt.Errorf("cannot find change %s of project %s", changeID, project)
+	}
+	return &(*changes)[0], nil
+}
+
+// SearchChanges returns all the changes of an instance matching the query,
+// most recently updated first
+func (c *Client) SearchChanges(instance, query string, rateLimit int) ([]ChangeInfo, error) {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	opt := &gerrit.QueryChangeOptions{}
+	opt.Query = append(opt.Query, query)
+	opt.AdditionalFields = queryFields
+	opt.Limit = rateLimit
+
+	var result []ChangeInfo
+	for {
+		opt.Start = len(result)
+		changes, _, err := h.changeService.QueryChanges(opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query gerrit changes %q: %v", query, err)
+		}
+		if changes == nil || len(*changes) == 0 {
+			return result, nil
+		}
+		result = append(result, *changes...)
+	}
+}
+
+// Submit submits the change, which merges its current revision
+func (c *Client) Submit(instance, id string) error {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	if _, _, err := h.changeService.SubmitChange(id, nil); err != nil {
+		return fmt.Errorf("cannot submit gerrit change %s: %v", id, err)
+	}
+	return nil
+}
+
+// GetEvents returns the events created since the given time from the
+// events-log plugin of the instance, which records the events of
+// stream-events. The events which can't be parsed are skipped.
+func (c *Client) GetEvents(instance string, since time.Time) ([]EventInfo, error) {
+	h, ok := c.handlers[instance]
+	if !ok {
+		return nil, fmt.Errorf("not activated gerrit instance: %s", instance)
+	}
+
+	events, _, failures, err := h.eventsService.GetEvents(&gerrit.EventsLogOptions{
+		From:                  since.UTC(),
+		IgnoreUnmarshalErrors: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gerrit events: %v", err)
+	}
+	if len(failures) > 0 {
+		logrus.WithField("instance", instance).Warnf("Skipped %d events which can't be parsed", len(failures))
+	}
+	return events, nil
+}
+
 // Account returns gerrit account for the given instance
 func (c *Client) Account(instance string) *gerrit.AccountInfo {
 	return c.accounts[instance]
@@ -278,6 +477,9 @@ func (h *gerritInstanceHandler) queryAllChanges(lastState map[string]time.Time,
 	return result
 }
 
+// queryFields are the additional fields of the queried changes
+var queryFields = []string{"CURRENT_REVISION", "CURRENT_COMMIT", "CURRENT_FILES", "MESSAGES"}
+
 func parseStamp(value gerrit.Timestamp) time.Time {
 	return value.Time
 }
@@ -287,7 +489,7 @@ func (h *gerritInstanceHandler) queryChangesForProject(project string, lastUpdat
 
 	opt := &gerrit.QueryChangeOptions{}
 	opt.Query = append(opt.Query, "project:"+project)
-	opt.AdditionalFields = []string{"CURRENT_REVISION", "CURRENT_COMMIT", "CURRENT_FILES", "MESSAGES"}
+	opt.AdditionalFields = queryFields
 
 	start := 0
 
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"errors"
 	"reflect"
 	"sort"
 	"strings"
@@ -29,6 +30,9 @@ import (
 type fgc struct {
 	instance string
 	changes  map[string][]gerrit.ChangeInfo
+	files    map[string]gerrit.FileInfo
+	diffs    map[string]*gerrit.DiffInfo
+	review   *gerrit.ReviewInput
 }
 
 func (f *fgc) QueryChanges(opt *gerrit.QueryChangeOptions) (*[]gerrit.ChangeInfo, *gerrit.Response, error) {
@@ -40,17 +44,21 @@ func (f *fgc) QueryChanges(opt *gerrit.QueryChangeOptions) (*[]gerrit.ChangeInfo
 	}
 
 	project := ""
+	changeID := ""
 	for _, query := range opt.Query {
-		for _, q := range strings.Split(query, "+") {
+		for _, q := range strings.FieldsFunc(query, func(r rune) bool { return r == '+' || r == ' ' }) {
 			if strings.HasPrefix(q, "project:") {
 				project = q[8:]
 			}
+			if strings.HasPrefix(q, "change:") {
+				changeID = q[7:]
+			}
 		}
 	}
 
 	for idx, change := range changeInfos {
-		if idx >= opt.Start && len(changes) <= opt.Limit {
-			if project == change.Project {
+		if idx >= opt.Start && (opt.Limit == 0 || len(changes) <= opt.Limit) {
+			if project == change.Project && (changeID == "" || changeID == change.ChangeID) {
 				changes = append(changes, change)
 			}
 		}
@@ -60,9 +68,32 @@ func (f *fgc) QueryChanges(opt *gerrit.QueryChangeOptions) (*[]gerrit.ChangeInfo
 }
 
 func (f *fgc) SetReview(changeID, revisionID string, input *gerrit.ReviewInput) (*gerrit.ReviewResult, *gerrit.Response, error) {
+	f.review = input
 	return nil, nil, nil
 }
 
+func (f *fgc) ListFiles(changeID, revisionID string, opt *gerrit.FilesOptions) (map[string]gerrit.FileInfo, *gerrit.Response, error) {
+	return f.files, nil, nil
+}
+
+func (f *fgc) GetDiff(changeID, revisionID, fileID string, opt *gerrit.DiffOptions) (*gerrit.DiffInfo, *gerrit.Response, error) {
+	diff, ok := f.diffs[fileID]
+	if !ok {
+		return nil, nil, errors.New("file not found")
+	}
+	return diff, nil, nil
+}
+
+func (f *fgc) SubmitChange(changeID string, input *gerrit.SubmitInput) (*gerrit.ChangeInfo, *gerrit.Response, error) {
+	for i, change := range f.changes[f.instance] {
+		if change.ID == changeID {
+			f.changes[f.instance][i].Status = Merged
+			return &f.changes[f.instance][i], nil, nil
+		}
+	}
+	return nil, nil, errors.New("change not found")
+}
+
 func makeStamp(t time.Time) gerrit.Timestamp {
 	return gerrit.Timestamp{Time: t}
 }
@@ -557,3 +588,248 @@ func TestQueryChange(t *testing.T) {
 		}
 	}
 }
+
+type fakeEventsLog struct {
+	since  time.Time
+	events []gerrit.EventInfo
+	err    error
+}
+
+func (f *fakeEventsLog) GetEvents(options *gerrit.EventsLogOptions) ([]gerrit.EventInfo, *gerrit.Response, [][]byte, error) {
+	f.since = options.From
+	return f.events, nil, nil, f.err
+}
+
+func TestGetEvents(t *testing.T) {
+	events := &fakeEventsLog{
+		events: []gerrit.EventInfo{{Type: PatchSetCreated}, {Type: CommentAdded}},
+	}
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {
+				instance:      "foo",
+				eventsService: events,
+			},
+		},
+	}
+
+	since := time.Date(2020, time.May, 15, 3, 2, 1, 0, time.FixedZone("UTC+2", 2*60*60))
+	got, err := client.GetEvents("foo", since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, events.events) {
+		t.Errorf("expected events %v, got %v", events.events, got)
+	}
+	if events.since.Location() != time.UTC || !events.since.Equal(since) {
+		t.Errorf("expected the events since %v in UTC, got %v", since, events.since)
+	}
+
+	if _, err := client.GetEvents("bar", since); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+	events.err = errors.New("injected error")
+	if _, err := client.GetEvents("foo", since); err == nil {
+		t.Error("expected the error of the events-log plugin")
+	}
+}
+
+func TestGetChange(t *testing.T) {
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {
+				instance: "foo",
+				projects: []string{"bar"},
+				changeService: &fgc{
+					instance: "foo",
+					changes: map[string][]gerrit.ChangeInfo{
+						"foo": {
+							{Project: "bar", ChangeID: "I1", CurrentRevision: "1-1"},
+							{Project: "bar", ChangeID: "I2", CurrentRevision: "2-1"},
+							{Project: "baz", ChangeID: "I2", CurrentRevision: "3-1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	change, err := client.GetChange("foo", "bar", "I2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.CurrentRevision != "2-1" {
+		t.Errorf("expected the revision 2-1, got %s", change.CurrentRevision)
+	}
+
+	if _, err := client.GetChange("foo", "bar", "I3"); err == nil {
+		t.Error("expected an error for an unknown change")
+	}
+}
+
+func TestSearchChanges(t *testing.T) {
+	var changes []gerrit.ChangeInfo
+	for i := 0; i < 5; i++ {
+		changes = append(changes, gerrit.ChangeInfo{Project: "bar", Number: i})
+	}
+	changes = append(changes, gerrit.ChangeInfo{Project: "baz", Number: 5})
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {
+				instance: "foo",
+				projects: []string{"bar", "baz"},
+				changeService: &fgc{
+					instance: "foo",
+					changes:  map[string][]gerrit.ChangeInfo{"foo": changes},
+				},
+			},
+		},
+	}
+
+	got, err := client.SearchChanges("foo", "project:bar is:submittable", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, changes[:5]) {
+		t.Errorf("expected changes %v, got %v", changes[:5], got)
+	}
+
+	if _, err := client.SearchChanges("bar", "project:bar", 1); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	fake := &fgc{
+		instance: "foo",
+		changes: map[string][]gerrit.ChangeInfo{
+			"foo": {{Project: "bar", ID: "bar~master~I1", Status: New}},
+		},
+	}
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {instance: "foo", projects: []string{"bar"}, changeService: fake},
+		},
+	}
+
+	if err := client.Submit("foo", "bar~master~I1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := fake.changes["foo"][0].Status; status != Merged {
+		t.Errorf("expected the change to be merged, got status %s", status)
+	}
+	if err := client.Submit("foo", "bar~master~I2"); err == nil {
+		t.Error("expected an error for an unknown change")
+	}
+}
+
+func TestSetReviewWithRobotComments(t *testing.T) {
+	fake := &fgc{instance: "foo"}
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {instance: "foo", changeService: fake},
+		},
+	}
+
+	comments := map[string][]RobotCommentInput{
+		"bar.go": {{CommentInput: gerrit.CommentInput{Line: 3, Message: "oops"}, RobotID: "lint"}},
+	}
+	if err := client.SetReviewWithRobotComments("foo", "I1", "1-1", "message", nil, comments); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.review == nil || fake.review.Message != "message" || !reflect.DeepEqual(fake.review.RobotComments, comments) {
+		t.Errorf("expected the review with the robot comments %v, got %#v", comments, fake.review)
+	}
+	if err := client.SetReviewWithRobotComments("bar", "I1", "1-1", "message", nil, comments); err == nil {
+		t.Error("expected an error for an unknown instance")
+	}
+}
+
+type fakeRobotComments struct {
+	comments map[string][]gerrit.RobotCommentInfo
+}
+
+func (f *fakeRobotComments) ListRobotComments(changeID, revisionID string) (map[string][]gerrit.RobotCommentInfo, error) {
+	if changeID != "I1" {
+		return nil, errors.New("change not found")
+	}
+	return f.comments, nil
+}
+
+func TestListRobotComments(t *testing.T) {
+	robot := &fakeRobotComments{
+		comments: map[string][]gerrit.RobotCommentInfo{
+			"bar.go": {{RobotID: "lint"}},
+		},
+	}
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {instance: "foo", robotService: robot},
+		},
+	}
+
+	comments, err := client.ListRobotComments("foo", "I1", "1-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(comments, robot.comments) {
+		t.Errorf("expected comments %v, got %v", robot.comments, comments)
+	}
+	if _, err := client.ListRobotComments("foo", "I2", "1-1"); err == nil {
+		t.Error("expected an error for an unknown change")
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	fake := &fgc{
+		instance: "foo",
+		files: map[string]gerrit.FileInfo{
+			"/COMMIT_MSG": {},
+			"pkg/bar.go":  {},
+			"new.go":      {},
+		},
+		diffs: map[string]*gerrit.DiffInfo{
+			"pkg%2Fbar.go": {
+				Content: []gerrit.DiffContent{
+					{AB: []string{"package bar", ""}},
+					{A: []string{"var x = 1"}, B: []string{"var x = 2", "var y = 3"}},
+					{Skip: 10},
+					{A: []string{"removed"}},
+					{AB: []string{"func f() {}"}},
+					{B: []string{"func g() {}"}},
+				},
+			},
+			"new.go": {
+				Content: []gerrit.DiffContent{
+					{B: []string{"package new"}},
+				},
+			},
+		},
+	}
+	client := &Client{
+		handlers: map[string]*gerritInstanceHandler{
+			"foo": {instance: "foo", changeService: fake},
+		},
+	}
+
+	lines, err := client.ChangedLines("foo", "I1", "1-1", []string{"pkg/bar.go", "new.go", "unchanged.go", "new.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string][]int{
+		"pkg/bar.go": {3, 4, 16},
+		"new.go":     {1},
+	}
+	actual := map[string][]int{}
+	for file, l := range lines {
+		actual[file] = l.List()
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changed lines %v, got %v", expected, actual)
+	}
+
+	fake.files["missing.go"] = gerrit.FileInfo{}
+	if _, err := client.ChangedLines("foo", "I1", "1-1", []string{"missing.go"}); err == nil {
+		t.Error("expected an error for a diff which can't be read")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ServeHTTP serves the pools of the last sync as JSON.
+func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(c.Pools())
+	if err != nil {
+		logrus.WithError(err).Error("Encoding JSON.")
+		b = []byte("[]")
+	}
+	if _, err = w.Write(b); err != nil {
+		logrus.WithError(err).Error("Writing JSON response.")
+	}
+}
+
+// StatusPage returns the handler of the page showing the pools of the last
+// sync, like the tide page of deck.
+func (c *Controller) StatusPage() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := statusTemplate.Execute(w, c.Pools()); err != nil {
+			logrus.WithError(err).Error("Executing the status template.")
+		}
+	})
+}
+
+// row is a change of a pool with its overall state.
+type row struct {
+	State JobState
+	Change
+}
+
+// rows returns the changes of the pool in the order of their states.
+func rows(pool Pool) []row {
+	var rows []row
+	for _, changes := range []struct {
+		state   JobState
+		changes []Change
+	}{
+		{JobSuccess, pool.SuccessChanges},
+		{JobPending, pool.PendingChanges},
+		{JobStale, pool.StaleChanges},
+		{JobFailure, pool.FailedChanges},
+	} {
+		for _, change := range changes.changes {
+			rows = append(rows, row{State: changes.state, Change: change})
+		}
+	}
+	return rows
+}
+
+// sortedJobs returns the names of the jobs in alphabetical order.
+func sortedJobs(jobs map[string]JobState) []string {
+	var names []string
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var statusTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"rows":       rows,
+	"sortedJobs": sortedJobs,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Gerrit Tide Status</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+.success { color: #2e7d32; }
+.pending { color: #f9a825; }
+.stale, .missing { color: #757575; }
+.failure, .error { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Gerrit Tide Status</h1>
+{{- if not .}}
+<p>No submittable changes.</p>
+{{- end}}
+{{- range .}}
+<h2>{{.Instance}} {{.Project}} {{.Branch}}</h2>
+<p>Tip: <code>{{.BaseSHA}}</code>, action: <strong>{{.Action}}</strong>
+{{- range .Target}} <a href="{{.Link}}">{{.Number}}</a>{{end}}</p>
+{{- if .Error}}
+<p class="error">{{.Error}}</p>
+{{- end}}
+<table>
+<tr><th>State</th><th>Change</th><th>Subject</th><th>Presubmits</th></tr>
+{{- range rows .}}
+<tr>
+<td class="{{.State}}">{{.State}}</td>
+<td><a href="{{.Link}}">{{.Number}}</a></td>
+<td>{{.Subject}}</td>
+<td>
+{{- $jobs := .Jobs}}
+{{- range sortedJobs .Jobs}}
+<span class="{{index $jobs .}}">{{.}}: {{index $jobs .}}</span><br>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,405 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tide contains a controller which submits the gerrit changes once
+// their required presubmits pass on the tip of their branch, like tide does
+// for the GitHub PRs. The changes of a branch are submitted one at a time in
+// the order of their numbers, and the passing changes which were tested on an
+// older tip are re-tested before being submitted.
+package tide
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowv1 "k8s.io/test-infra/prow/client/clientset/versioned/typed/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gerrit/adapter"
+	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/kube"
+)
+
+type prowJobClient interface {
+	Create(*prowapi.ProwJob) (*prowapi.ProwJob, error)
+	List(opts metav1.ListOptions) (*prowapi.ProwJobList, error)
+}
+
+type gerritClient interface {
+	SearchChanges(instance, query string, rateLimit int) ([]client.ChangeInfo, error)
+	GetBranchRevision(instance, project, branch string) (string, error)
+	SetReview(instance, id, revision, message string, labels map[string]string) error
+	Submit(instance, id string) error
+}
+
+// Action represents what the controller did to a pool in the last sync.
+type Action string
+
+// Constants for the actions the controller might take
+const (
+	Wait    Action = "WAIT"
+	Trigger Action = "TRIGGER"
+	Submit  Action = "SUBMIT"
+)
+
+// JobState is the state of a required presubmit of a change.
+type JobState string
+
+// Constants for the states of the required presubmits
+const (
+	// JobSuccess means the presubmit passed on the tip of the branch.
+	JobSuccess JobState = "success"
+	// JobPending means the presubmit is running.
+	JobPending JobState = "pending"
+	// JobFailure means the presubmit failed. Failed presubmits are not
+	// re-tested by the controller.
+	JobFailure JobState = "failure"
+	// JobStale means the presubmit passed on an older tip of the branch.
+	JobStale JobState = "stale"
+	// JobMissing means the presubmit never ran on the current revision.
+	JobMissing JobState = "missing"
+)
+
+// Change is a submittable change with the states of its required presubmits
+// on its current revision.
+type Change struct {
+	Number   int
+	ID       string
+	Subject  string
+	Revision string
+	Link     string
+	Jobs     map[string]JobState
+}
+
+// Pool holds the submittable changes of a branch. There is one for every
+// instance/project/branch combination that has submittable changes.
+type Pool struct {
+	Instance string
+	Project  string
+	Branch   string
+	BaseSHA  string
+
+	// Changes whose required presubmits passed, are running, passed on an
+	// older tip or never ran, and failed.
+	SuccessChanges []Change
+	PendingChanges []Change
+	StaleChanges   []Change
+	FailedChanges  []Change
+
+	// Which action did we last take, and to what target, if any.
+	Action Action
+	Target []Change
+	Error  string
+}
+
+// Controller submits the gerrit changes.
+type Controller struct {
+	config        config.Getter
+	prowJobClient prowJobClient
+	gc            gerritClient
+	projects      map[string][]string
+	dryRun        bool
+
+	lock  sync.Mutex
+	pools []Pool
+}
+
+// NewController returns a new gerrit merge controller
+func NewController(cookiefilePath string, projects map[string][]string, prowJobClient prowv1.ProwJobInterface, cfg config.Getter, dryRun bool) (*Controller, error) {
+	c, err := client.NewClient(projects)
+	if err != nil {
+		return nil, err
+	}
+	c.Start(cookiefilePath)
+
+	return &Controller{
+		config:        cfg,
+		prowJobClient: prowJobClient,
+		gc:            c,
+		projects:      projects,
+		dryRun:        dryRun,
+	}, nil
+}
+
+// Pools returns the pools of the last sync.
+func (c *Controller) Pools() []Pool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.pools
+}
+
+// Sync looks for the submittable changes of the projects, submits the ones
+// whose required presubmits passed on the tip of their branch and re-tests
+// the ones which passed on an older tip.
+func (c *Controller) Sync() error {
+	cfg := c.config()
+
+	jobs, err := c.prowJobClient.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s", kube.ProwJobTypeLabel, prowapi.PresubmitJob, client.GerritRevision),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list prowjobs: %v", err)
+	}
+	jobsByRevision := map[string][]prowapi.ProwJob{}
+	for _, job := range jobs.Items {
+		revision := job.Labels[client.GerritRevision]
+		jobsByRevision[revision] = append(jobsByRevision[revision], job)
+	}
+
+	var pools []Pool
+	var errs []error
+	for instance, projects := range c.projects {
+		for _, project := range projects {
+			log := logrus.WithFields(logrus.Fields{"instance": instance, "project": project})
+
+			presubmits, err := adapter.Presubmits(cfg, instance, project)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			query := fmt.Sprintf("project:%s status:open %s", project, cfg.Gerrit.Submit.Query)
+			changes, err := c.gc.SearchChanges(instance, query, cfg.Gerrit.RateLimit)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			byBranch := map[string][]client.ChangeInfo{}
+			for _, change := range changes {
+				byBranch[change.Branch] = append(byBranch[change.Branch], change)
+			}
+			for branch, changes := range byBranch {
+				pool := c.syncPool(log.WithField("branch", branch), instance, project, branch, changes, presubmits, jobsByRevision)
+				if pool.Error != "" {
+					errs = append(errs, errors.New(pool.Error))
+				}
+				pools = append(pools, pool)
+			}
+		}
+	}
+
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].Instance != pools[j].Instance {
+			return pools[i].Instance < pools[j].Instance
+		}
+		if pools[i].Project != pools[j].Project {
+			return pools[i].Project < pools[j].Project
+		}
+		return pools[i].Branch < pools[j].Branch
+	})
+	c.lock.Lock()
+	c.pools = pools
+	c.lock.Unlock()
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// poolChange pairs a change of a pool with the required presubmits which
+// have to be re-tested before submitting it.
+type poolChange struct {
+	info   client.ChangeInfo
+	change Change
+	retest []config.Presubmit
+}
+
+func (c *Controller) syncPool(log *logrus.Entry, instance, project, branch string, changes []client.ChangeInfo, presubmits []config.Presubmit, jobsByRevision map[string][]prowapi.ProwJob) Pool {
+	pool := Pool{
+		Instance: instance,
+		Project:  project,
+		Branch:   branch,
+		Action:   Wait,
+	}
+
+	baseSHA, err := c.gc.GetBranchRevision(instance, project, branch)
+	if err != nil {
+		pool.Error = fmt.Sprintf("failed to get the tip of %s/%s: %v", project, branch, err)
+		return pool
+	}
+	pool.BaseSHA = baseSHA
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Number < changes[j].Number })
+	var successes, stales []poolChange
+	for _, info := range changes {
+		pc, err := newPoolChange(instance, info, presubmits, jobsByRevision[info.CurrentRevision], baseSHA)
+		if err != nil {
+			log.WithError(err).WithField("change", info.Number).Warn("Failed to get the required presubmits, excluding the change from the pool.")
+			continue
+		}
+
+		switch overallState(pc.change.Jobs) {
+		case JobSuccess:
+			successes = append(successes, pc)
+			pool.SuccessChanges = append(pool.SuccessChanges, pc.change)
+		case JobPending:
+			pool.PendingChanges = append(pool.PendingChanges, pc.change)
+		case JobFailure:
+			pool.FailedChanges = append(pool.FailedChanges, pc.change)
+		default:
+			stales = append(stales, pc)
+			pool.StaleChanges = append(pool.StaleChanges, pc.change)
+		}
+	}
+
+	// Submitting moves the tip of the branch, which makes the results of the
+	// other changes stale, so at most one change is submitted per sync.
+	var target poolChange
+	switch {
+	case len(successes) > 0:
+		target = successes[0]
+		pool.Action = Submit
+		err = c.submit(log, instance, target)
+	case len(pool.PendingChanges) > 0:
+		return pool
+	case len(stales) > 0:
+		target = stales[0]
+		pool.Action = Trigger
+		err = c.retest(log, instance, target, baseSHA)
+	default:
+		return pool
+	}
+	pool.Target = []Change{target.change}
+	if err != nil {
+		pool.Error = err.Error()
+	}
+	return pool
+}
+
+func newPoolChange(instance string, info client.ChangeInfo, presubmits []config.Presubmit, jobs []prowapi.ProwJob, baseSHA string) (poolChange, error) {
+	pc := poolChange{
+		info: info,
+		change: Change{
+			Number:   info.Number,
+			ID:       info.ID,
+			Subject:  info.Subject,
+			Revision: info.CurrentRevision,
+			Link:     fmt.Sprintf("%s/c/%s/+/%d", instance, info.Project, info.Number),
+			Jobs:     map[string]JobState{},
+		},
+	}
+
+	latest := map[string]prowapi.ProwJob{}
+	for _, job := range jobs {
+		if cur, ok := latest[job.Spec.Job]; !ok || cur.CreationTimestamp.Before(&job.CreationTimestamp) {
+			latest[job.Spec.Job] = job
+		}
+	}
+
+	for _, presubmit := range presubmits {
+		if !presubmit.ContextRequired() {
+			continue
+		}
+		shouldRun, err := presubmit.ShouldRun(info.Branch, adapter.ListChangedFiles(info), false, false)
+		if err != nil {
+			return pc, fmt.Errorf("failed to determine if presubmit %q should run: %v", presubmit.Name, err)
+		}
+		if !shouldRun {
+			continue
+		}
+
+		state := jobState(latest, presubmit.Name, baseSHA)
+		if state == JobStale || state == JobMissing {
+			pc.retest = append(pc.retest, presubmit)
+		}
+		pc.change.Jobs[presubmit.Name] = state
+	}
+	return pc, nil
+}
+
+func jobState(latest map[string]prowapi.ProwJob, name, baseSHA string) JobState {
+	job, ok := latest[name]
+	if !ok {
+		return JobMissing
+	}
+	switch job.Status.State {
+	case prowapi.TriggeredState, prowapi.PendingState:
+		return JobPending
+	case prowapi.SuccessState:
+		if job.Spec.Refs == nil || job.Spec.Refs.BaseSHA != baseSHA {
+			return JobStale
+		}
+		return JobSuccess
+	default:
+		return JobFailure
+	}
+}
+
+// overallState rolls up the states of the presubmits of a change, a failure
+// overriding a pending presubmit, which overrides a stale or missing one.
+func overallState(jobs map[string]JobState) JobState {
+	overall := JobSuccess
+	for _, state := range jobs {
+		switch {
+		case state == JobFailure:
+			return JobFailure
+		case state == JobPending:
+			overall = JobPending
+		case state != JobSuccess && overall == JobSuccess:
+			overall = JobStale
+		}
+	}
+	return overall
+}
+
+func (c *Controller) submit(log *logrus.Entry, instance string, target poolChange) error {
+	log = log.WithField("change", target.info.Number)
+	if c.dryRun {
+		log.Info("Dry run, not submitting the change.")
+		return nil
+	}
+	if err := c.gc.Submit(instance, target.info.ID); err != nil {
+		return err
+	}
+	log.Info("Submitted the change.")
+	return nil
+}
+
+func (c *Controller) retest(log *logrus.Entry, instance string, target poolChange, baseSHA string) error {
+	log = log.WithFields(logrus.Fields{"change": target.info.Number, "base-sha": baseSHA})
+
+	var errs []error
+	var triggeredJobs []string
+	for _, presubmit := range target.retest {
+		pj, err := adapter.PresubmitJob(instance, target.info, presubmit, baseSHA)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := c.prowJobClient.Create(&pj); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create prowjob %s: %v", presubmit.Name, err))
+			continue
+		}
+		log.Infof("Triggered Prowjob %s", presubmit.Name)
+		triggeredJobs = append(triggeredJobs, presubmit.Name)
+	}
+
+	if len(triggeredJobs) > 0 && !c.dryRun {
+		message := fmt.Sprintf("Re-testing %d prow jobs on the tip of %s before submitting:", len(triggeredJobs), target.info.Branch)
+		for _, job := range triggeredJobs {
+			message += fmt.Sprintf("\n  * Name: %s", job)
+		}
+		if err := c.gc.SetReview(instance, target.info.ID, target.info.CurrentRevision, message, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
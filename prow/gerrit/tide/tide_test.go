@@ -0,0 +1,363 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowfake "k8s.io/test-infra/prow/client/clientset/versioned/fake"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gerrit/client"
+	"k8s.io/test-infra/prow/kube"
+)
+
+const (
+	instance = "https://gerrit.example.com"
+	tip      = "tip"
+)
+
+type fakeGerrit struct {
+	changes   map[string][]client.ChangeInfo
+	submitted []string
+	reviewed  []string
+}
+
+func (f *fakeGerrit) SearchChanges(instance, query string, rateLimit int) ([]client.ChangeInfo, error) {
+	project := strings.TrimPrefix(strings.Fields(query)[0], "project:")
+	return f.changes[project], nil
+}
+
+func (f *fakeGerrit) GetBranchRevision(instance, project, branch string) (string, error) {
+	return tip, nil
+}
+
+func (f *fakeGerrit) SetReview(instance, id, revision, message string, labels map[string]string) error {
+	f.reviewed = append(f.reviewed, id)
+	return nil
+}
+
+func (f *fakeGerrit) Submit(instance, id string) error {
+	f.submitted = append(f.submitted, id)
+	return nil
+}
+
+func newChange(number int, revision string) client.ChangeInfo {
+	return client.ChangeInfo{
+		ID:              revision,
+		Number:          number,
+		Project:         "foo",
+		Branch:          "master",
+		CurrentRevision: revision,
+		Revisions: map[string]client.RevisionInfo{
+			revision: {Ref: "refs/changes/00/1/1"},
+		},
+	}
+}
+
+func newJob(name, revision, baseSHA string, state prowapi.ProwJobState, age time.Duration) *prowapi.ProwJob {
+	return &prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-" + revision + "-" + string(state),
+			Namespace: "prowjobs",
+			Labels: map[string]string{
+				kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+				client.GerritRevision: revision,
+			},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Spec: prowapi.ProwJobSpec{
+			Type: prowapi.PresubmitJob,
+			Job:  name,
+			Refs: &prowapi.Refs{BaseSHA: baseSHA},
+		},
+		Status: prowapi.ProwJobStatus{State: state},
+	}
+}
+
+func TestSync(t *testing.T) {
+	presubmits := []config.Presubmit{
+		{
+			JobBase:   config.JobBase{Name: "unit"},
+			AlwaysRun: true,
+		},
+		{
+			JobBase:   config.JobBase{Name: "e2e"},
+			AlwaysRun: true,
+		},
+		{
+			JobBase:   config.JobBase{Name: "optional"},
+			AlwaysRun: true,
+			Optional:  true,
+		},
+		{
+			JobBase: config.JobBase{Name: "manual"},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		changes []client.ChangeInfo
+		jobs    []runtime.Object
+		dryRun  bool
+
+		expectedAction    Action
+		expectedTarget    int
+		expectedSubmitted []string
+		expectedRetested  []string
+		expectedStates    map[int]map[string]JobState
+	}{
+		{
+			name:           "no submittable changes",
+			expectedAction: "",
+		},
+		{
+			name:    "passing change is submitted",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", tip, prowapi.SuccessState, time.Minute),
+				newJob("e2e", "a", tip, prowapi.SuccessState, time.Minute),
+				newJob("optional", "a", tip, prowapi.FailureState, time.Minute),
+			},
+			expectedAction:    Submit,
+			expectedTarget:    1,
+			expectedSubmitted: []string{"a"},
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobSuccess, "e2e": JobSuccess},
+			},
+		},
+		{
+			name:    "passing change is not submitted in dry run",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", tip, prowapi.SuccessState, time.Minute),
+				newJob("e2e", "a", tip, prowapi.SuccessState, time.Minute),
+			},
+			dryRun:         true,
+			expectedAction: Submit,
+			expectedTarget: 1,
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobSuccess, "e2e": JobSuccess},
+			},
+		},
+		{
+			name:    "only the smallest passing change is submitted",
+			changes: []client.ChangeInfo{newChange(3, "c"), newChange(2, "b")},
+			jobs: []runtime.Object{
+				newJob("unit", "b", tip, prowapi.SuccessState, time.Minute),
+				newJob("e2e", "b", tip, prowapi.SuccessState, time.Minute),
+				newJob("unit", "c", tip, prowapi.SuccessState, time.Minute),
+				newJob("e2e", "c", tip, prowapi.SuccessState, time.Minute),
+			},
+			expectedAction:    Submit,
+			expectedTarget:    2,
+			expectedSubmitted: []string{"b"},
+			expectedStates: map[int]map[string]JobState{
+				2: {"unit": JobSuccess, "e2e": JobSuccess},
+				3: {"unit": JobSuccess, "e2e": JobSuccess},
+			},
+		},
+		{
+			name:    "the latest job of a presubmit counts",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", tip, prowapi.FailureState, time.Hour),
+				newJob("unit", "a", tip, prowapi.SuccessState, time.Minute),
+				newJob("e2e", "a", tip, prowapi.SuccessState, time.Minute),
+			},
+			expectedAction:    Submit,
+			expectedTarget:    1,
+			expectedSubmitted: []string{"a"},
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobSuccess, "e2e": JobSuccess},
+			},
+		},
+		{
+			name:    "change passing on an older tip is re-tested",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", "old", prowapi.SuccessState, time.Minute),
+				newJob("e2e", "a", tip, prowapi.SuccessState, time.Minute),
+			},
+			expectedAction:   Trigger,
+			expectedTarget:   1,
+			expectedRetested: []string{"unit"},
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobStale, "e2e": JobSuccess},
+			},
+		},
+		{
+			name:    "missing presubmits are run",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", tip, prowapi.SuccessState, time.Minute),
+			},
+			expectedAction:   Trigger,
+			expectedTarget:   1,
+			expectedRetested: []string{"e2e"},
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobSuccess, "e2e": JobMissing},
+			},
+		},
+		{
+			name:    "pending change blocks re-testing",
+			changes: []client.ChangeInfo{newChange(1, "a"), newChange(2, "b")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", tip, prowapi.PendingState, time.Minute),
+				newJob("e2e", "a", tip, prowapi.SuccessState, time.Minute),
+				newJob("unit", "b", "old", prowapi.SuccessState, time.Minute),
+				newJob("e2e", "b", "old", prowapi.SuccessState, time.Minute),
+			},
+			expectedAction: Wait,
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobPending, "e2e": JobSuccess},
+				2: {"unit": JobStale, "e2e": JobStale},
+			},
+		},
+		{
+			name:    "failed change is not re-tested",
+			changes: []client.ChangeInfo{newChange(1, "a")},
+			jobs: []runtime.Object{
+				newJob("unit", "a", "old", prowapi.FailureState, time.Minute),
+			},
+			expectedAction: Wait,
+			expectedStates: map[int]map[string]JobState{
+				1: {"unit": JobFailure, "e2e": JobMissing},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				JobConfig: config.JobConfig{
+					PresubmitsStatic: map[string][]config.Presubmit{
+						instance + "/foo": presubmits,
+					},
+				},
+				ProwConfig: config.ProwConfig{
+					Gerrit: config.Gerrit{
+						RateLimit: 5,
+						Submit:    config.GerritSubmit{Query: "is:submittable"},
+					},
+				},
+			}
+			gc := &fakeGerrit{changes: map[string][]client.ChangeInfo{"foo": tc.changes}}
+			pjc := prowfake.NewSimpleClientset(tc.jobs...).ProwV1().ProwJobs("prowjobs")
+			c := &Controller{
+				config:        func() *config.Config { return cfg },
+				prowJobClient: pjc,
+				gc:            gc,
+				projects:      map[string][]string{instance: {"foo"}},
+				dryRun:        tc.dryRun,
+			}
+
+			if err := c.Sync(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pools := c.Pools()
+			if tc.expectedAction == "" {
+				if len(pools) != 0 {
+					t.Fatalf("expected no pools, got %v", pools)
+				}
+				return
+			}
+			if len(pools) != 1 {
+				t.Fatalf("expected one pool, got %v", pools)
+			}
+			pool := pools[0]
+			if pool.Action != tc.expectedAction {
+				t.Errorf("expected action %s, got %s", tc.expectedAction, pool.Action)
+			}
+			var target int
+			if len(pool.Target) > 0 {
+				target = pool.Target[0].Number
+			}
+			if target != tc.expectedTarget {
+				t.Errorf("expected target %d, got %d", tc.expectedTarget, target)
+			}
+			if !reflect.DeepEqual(gc.submitted, tc.expectedSubmitted) {
+				t.Errorf("expected submitted %v, got %v", tc.expectedSubmitted, gc.submitted)
+			}
+
+			states := map[int]map[string]JobState{}
+			for _, changes := range [][]Change{pool.SuccessChanges, pool.PendingChanges, pool.StaleChanges, pool.FailedChanges} {
+				for _, change := range changes {
+					states[change.Number] = change.Jobs
+				}
+			}
+			if !reflect.DeepEqual(states, tc.expectedStates) {
+				t.Errorf("expected states %v, got %v", tc.expectedStates, states)
+			}
+
+			jobs, err := pjc.List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed to list prowjobs: %v", err)
+			}
+			var retested []string
+			for _, job := range jobs.Items {
+				if len(job.Spec.Refs.Pulls) > 0 {
+					if job.Spec.Refs.BaseSHA != tip {
+						t.Errorf("expected %s to be re-tested on %s, got %s", job.Spec.Job, tip, job.Spec.Refs.BaseSHA)
+					}
+					retested = append(retested, job.Spec.Job)
+				}
+			}
+			if !reflect.DeepEqual(retested, tc.expectedRetested) {
+				t.Errorf("expected re-tested %v, got %v", tc.expectedRetested, retested)
+			}
+			if len(retested) > 0 && len(gc.reviewed) != 1 {
+				t.Errorf("expected a comment on the re-tested change, got %v", gc.reviewed)
+			}
+		})
+	}
+}
+
+func TestStatusPage(t *testing.T) {
+	c := &Controller{
+		pools: []Pool{
+			{
+				Instance: instance,
+				Project:  "foo",
+				Branch:   "master",
+				BaseSHA:  tip,
+				Action:   Trigger,
+				StaleChanges: []Change{
+					{Number: 1, Subject: "<Fix> the bug", Jobs: map[string]JobState{"unit": JobStale}},
+				},
+				Target: []Change{{Number: 1}},
+			},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	c.StatusPage().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	for _, expected := range []string{"foo master", "TRIGGER", "&lt;Fix&gt; the bug", "unit: stale"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the page to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+// EventHandler handles the events of a Queue.
+type EventHandler interface {
+	// Handle runs the plugins of the event and waits for them. It returns
+	// the errors of the plugins which failed, keyed by plugin name, or an
+	// error if the event can't be handled at all.
+	Handle(e *Event) (map[string]error, error)
+}
+
+// Queue is a Dispatcher which persists the events in a pending store
+// before handling them, so that they survive a restart. The plugins which
+// fail to handle an event are retried with an exponential backoff, and
+// the events still failing after the retries are moved to a dead letter
+// store, from which they can be replayed.
+type Queue struct {
+	h       EventHandler
+	pending EventStore
+	dead    EventStore
+
+	maxRetries int
+	backoff    time.Duration
+
+	lock sync.Mutex
+	// running are the IDs of the events being handled.
+	running sets.String
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewQueue returns a Queue which retries the failed plugins maxRetries
+// times, waiting backoff before the first retry and doubling it after.
+func NewQueue(h EventHandler, pending, dead EventStore, maxRetries int, backoff time.Duration) *Queue {
+	return &Queue{
+		h:          h,
+		pending:    pending,
+		dead:       dead,
+		maxRetries: maxRetries,
+		backoff:    backoff,
+		running:    sets.NewString(),
+		stop:       make(chan struct{}),
+	}
+}
+
+func eventID(eventGUID string, payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return fmt.Sprintf("%s-%x", eventGUID, sum[:4])
+}
+
+func eventLog(e *Event) *logrus.Entry {
+	return logrus.WithFields(logrus.Fields{
+		"event-type":     e.Type,
+		github.EventGUID: e.GUID,
+		"event-id":       e.ID,
+	})
+}
+
+// Dispatch persists the event and handles it in the background.
+func (q *Queue) Dispatch(eventType, eventGUID string, payload []byte, h http.Header) error {
+	e := &Event{
+		ID:      eventID(eventGUID, payload),
+		GUID:    eventGUID,
+		Type:    eventType,
+		Payload: payload,
+		Header:  h,
+	}
+	if err := q.pending.Save(e); err != nil {
+		// Handle it anyway, it only won't survive a restart.
+		eventLog(e).WithError(err).Error("Failed to persist the event.")
+	}
+	q.start(e)
+	return nil
+}
+
+// Resume handles the events left in the pending store by a restart or a
+// crash. It is called once at startup.
+func (q *Queue) Resume() error {
+	es, err := q.pending.List()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range es {
+		q.start(e)
+	}
+	logrus.Infof("Resumed %d pending events.", len(es))
+	return nil
+}
+
+// Wait stops retrying the failed plugins and waits for the running ones.
+// The events which still need a retry stay in the pending store and are
+// resumed after the restart.
+func (q *Queue) Wait() {
+	q.stopOnce.Do(func() { close(q.stop) })
+	q.wg.Wait()
+}
+
+// start handles the event in the background, unless it is already being
+// handled.
+func (q *Queue) start(e *Event) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.running.Has(e.ID) {
+		eventLog(e).Info("The event is already being handled, skipping.")
+		return
+	}
+	q.running.Insert(e.ID)
+
+	q.wg.Add(1)
+	go q.process(e)
+}
+
+func (q *Queue) process(e *Event) {
+	defer q.wg.Done()
+	defer func() {
+		q.lock.Lock()
+		q.running.Delete(e.ID)
+		q.lock.Unlock()
+	}()
+
+	l := eventLog(e)
+	for {
+		errs, err := q.h.Handle(e)
+		if err != nil {
+			e.Error = err.Error()
+			l.WithError(err).Error("Failed to handle the event, moving it to the dead letters.")
+			q.bury(e, l)
+			return
+		}
+		if len(errs) == 0 {
+			if err := q.pending.Delete(e.ID); err != nil {
+				l.WithError(err).Warn("Failed to delete the handled event.")
+			}
+			return
+		}
+
+		e.Attempts++
+		e.Plugins = make([]string, 0, len(errs))
+		e.Errors = make(map[string]string, len(errs))
+		for p, err := range errs {
+			e.Plugins = append(e.Plugins, p)
+			e.Errors[p] = err.Error()
+		}
+		sort.Strings(e.Plugins)
+
+		l = l.WithFields(logrus.Fields{"attempts": e.Attempts, "plugins": e.Plugins})
+		if e.Attempts > q.maxRetries {
+			l.Error("Plugins failed to handle the event, moving it to the dead letters.")
+			q.bury(e, l)
+			return
+		}
+		if err := q.pending.Save(e); err != nil {
+			l.WithError(err).Error("Failed to persist the event.")
+		}
+
+		backoff := q.backoff << uint(e.Attempts-1)
+		l.Infof("Plugins failed to handle the event, retrying in %s.", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-q.stop:
+			l.Info("Shutting down, the event will be retried after the restart.")
+			return
+		}
+	}
+}
+
+// bury moves the event to the dead letter store. It stays in the pending
+// store if it can't be moved, so that it is never lost.
+func (q *Queue) bury(e *Event, l *logrus.Entry) {
+	if err := q.dead.Save(e); err != nil {
+		l.WithError(err).Error("Failed to save the dead letter.")
+		return
+	}
+	if err := q.pending.Delete(e.ID); err != nil {
+		l.WithError(err).Warn("Failed to delete the dead letter from the pending events.")
+	}
+}
+
+// Replay moves the dead letters whose ID or GUID is the given one back to
+// the pending store and handles them again, with the plugins which failed.
+// It returns the IDs of the replayed events.
+func (q *Queue) Replay(guid string) ([]string, error) {
+	es, err := q.deadLetters(guid)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range es {
+		e.Attempts = 0
+		e.Errors = nil
+		e.Error = ""
+		if err := q.pending.Save(e); err != nil {
+			return ids, err
+		}
+		if err := q.dead.Delete(e.ID); err != nil {
+			return ids, err
+		}
+
+		eventLog(e).WithField("plugins", e.Plugins).Info("Replaying the dead letter.")
+		q.start(e)
+		ids = append(ids, e.ID)
+	}
+	return ids, nil
+}
+
+func (q *Queue) deadLetters(guid string) ([]*Event, error) {
+	e, err := q.dead.Load(guid)
+	if err == nil {
+		return []*Event{e}, nil
+	}
+	if err != ErrEventNotFound {
+		return nil, err
+	}
+
+	all, err := q.dead.List()
+	if err != nil {
+		return nil, err
+	}
+	var r []*Event
+	for _, e := range all {
+		if e.GUID == guid {
+			r = append(r, e)
+		}
+	}
+	return r, nil
+}
+
+// ServeHTTP serves the admin endpoint of the queue. A GET lists the dead
+// letters, without their payloads and headers. A POST replays the dead
+// letters of the guid parameter, which is either the GUID of the webhooks
+// or the ID of an event.
+func (q *Queue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		es, err := q.dead.List()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list the dead letters: %v", err), http.StatusInternalServerError)
+			return
+		}
+		for _, e := range es {
+			e.Payload = nil
+			e.Header = nil
+		}
+		if es == nil {
+			es = []*Event{}
+		}
+
+		b, err := json.Marshal(es)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to marshal the dead letters: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+
+	case http.MethodPost:
+		guid := r.URL.Query().Get("guid")
+		if guid == "" {
+			http.Error(w, "The guid parameter is required.", http.StatusBadRequest)
+			return
+		}
+
+		ids, err := q.Replay(guid)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to replay %s: %v", guid, err), http.StatusInternalServerError)
+			return
+		}
+		if len(ids) == 0 {
+			http.Error(w, fmt.Sprintf("No dead letter for %s.", guid), http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, "Replaying %s.", strings.Join(ids, ", "))
+
+	default:
+		http.Error(w, "Only GET and POST are supported.", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeHandler struct {
+	lock    sync.Mutex
+	plugins []string
+	// failures is the number of times each plugin fails before succeeding.
+	failures map[string]int
+	calls    [][]string
+}
+
+func (h *fakeHandler) Handle(e *Event) (map[string]error, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	ps := e.Plugins
+	if len(ps) == 0 {
+		ps = h.plugins
+	}
+	h.calls = append(h.calls, ps)
+
+	errs := map[string]error{}
+	for _, p := range ps {
+		if h.failures[p] > 0 {
+			h.failures[p]--
+			errs[p] = errors.New("transient failure")
+		}
+	}
+	return errs, nil
+}
+
+func (h *fakeHandler) getCalls() [][]string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.calls
+}
+
+func newTestQueue(t *testing.T, h EventHandler, maxRetries int, backoff time.Duration) (*Queue, func()) {
+	dir, err := ioutil.TempDir("", "gitee-hook")
+	if err != nil {
+		t.Fatalf("failed to create the temporary directory: %v", err)
+	}
+	pending, err := NewDiskStore(filepath.Join(dir, "pending"))
+	if err != nil {
+		t.Fatalf("failed to create the pending store: %v", err)
+	}
+	dead, err := NewDiskStore(filepath.Join(dir, "dead"))
+	if err != nil {
+		t.Fatalf("failed to create the dead letter store: %v", err)
+	}
+	return NewQueue(h, pending, dead, maxRetries, backoff), func() { os.RemoveAll(dir) }
+}
+
+func waitForIdle(t *testing.T, q *Queue) {
+	for i := 0; i < 500; i++ {
+		q.lock.Lock()
+		n := q.running.Len()
+		q.lock.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the events to be handled")
+}
+
+func listEvents(t *testing.T, s EventStore) []*Event {
+	es, err := s.List()
+	if err != nil {
+		t.Fatalf("failed to list the events: %v", err)
+	}
+	return es
+}
+
+func TestQueueRetriesFailedPlugins(t *testing.T) {
+	h := &fakeHandler{plugins: []string{"a", "b"}, failures: map[string]int{"b": 1}}
+	q, clean := newTestQueue(t, h, 2, time.Millisecond)
+	defer clean()
+
+	if err := q.Dispatch("Note Hook", "1600000000000", []byte("{}"), http.Header{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForIdle(t, q)
+
+	if expected := [][]string{{"a", "b"}, {"b"}}; !reflect.DeepEqual(h.getCalls(), expected) {
+		t.Errorf("expected the calls %v, got %v", expected, h.getCalls())
+	}
+	if es := listEvents(t, q.pending); len(es) != 0 {
+		t.Errorf("expected no pending event, got %d", len(es))
+	}
+	if es := listEvents(t, q.dead); len(es) != 0 {
+		t.Errorf("expected no dead letter, got %d", len(es))
+	}
+}
+
+func TestQueueDeadLettersAndReplay(t *testing.T) {
+	h := &fakeHandler{plugins: []string{"a", "b"}, failures: map[string]int{"b": 2}}
+	q, clean := newTestQueue(t, h, 1, time.Millisecond)
+	defer clean()
+
+	if err := q.Dispatch("Note Hook", "1600000000000", []byte("{}"), http.Header{"X-Gitee-Token": {"signature"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForIdle(t, q)
+
+	if es := listEvents(t, q.pending); len(es) != 0 {
+		t.Errorf("expected no pending event, got %d", len(es))
+	}
+
+	rr := httptest.NewRecorder()
+	q.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected the status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var dead []*Event
+	if err := json.Unmarshal(rr.Body.Bytes(), &dead); err != nil {
+		t.Fatalf("failed to unmarshal the dead letters: %v", err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("expected one dead letter, got %d", len(dead))
+	}
+	if d := dead[0]; d.GUID != "1600000000000" || d.Attempts != 2 || !reflect.DeepEqual(d.Plugins, []string{"b"}) || d.Errors["b"] != "transient failure" || d.Payload != nil || d.Header != nil {
+		t.Errorf("unexpected dead letter: %+v", d)
+	}
+
+	rr = httptest.NewRecorder()
+	q.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/?guid=1700000000000", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected the status 404 for an unknown GUID, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	q.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/?guid=1600000000000", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected the status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	waitForIdle(t, q)
+
+	if expected := [][]string{{"a", "b"}, {"b"}, {"b"}}; !reflect.DeepEqual(h.getCalls(), expected) {
+		t.Errorf("expected the calls %v, got %v", expected, h.getCalls())
+	}
+	if es := listEvents(t, q.dead); len(es) != 0 {
+		t.Errorf("expected no dead letter after the replay, got %d", len(es))
+	}
+	if es := listEvents(t, q.pending); len(es) != 0 {
+		t.Errorf("expected no pending event after the replay, got %d", len(es))
+	}
+}
+
+func TestQueueResume(t *testing.T) {
+	h := &fakeHandler{plugins: []string{"a", "b"}}
+	q, clean := newTestQueue(t, h, 1, time.Millisecond)
+	defer clean()
+
+	e := &Event{ID: "1600000000000-0a1b2c3d", GUID: "1600000000000", Type: "Note Hook", Plugins: []string{"a"}, Attempts: 1}
+	if err := q.pending.Save(e); err != nil {
+		t.Fatalf("failed to save the event: %v", err)
+	}
+
+	if err := q.Resume(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForIdle(t, q)
+
+	if expected := [][]string{{"a"}}; !reflect.DeepEqual(h.getCalls(), expected) {
+		t.Errorf("expected the calls %v, got %v", expected, h.getCalls())
+	}
+	if es := listEvents(t, q.pending); len(es) != 0 {
+		t.Errorf("expected no pending event, got %d", len(es))
+	}
+}
+
+func TestQueueWaitKeepsRetriesPending(t *testing.T) {
+	h := &fakeHandler{plugins: []string{"a", "b"}, failures: map[string]int{"b": 10}}
+	q, clean := newTestQueue(t, h, 3, time.Hour)
+	defer clean()
+
+	if err := q.Dispatch("Note Hook", "1600000000000", []byte("{}"), http.Header{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; len(h.getCalls()) == 0; i++ {
+		if i == 500 {
+			t.Fatal("timed out waiting for the event to be handled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Wait not to wait for the backoff")
+	}
+
+	es := listEvents(t, q.pending)
+	if len(es) != 1 {
+		t.Fatalf("expected the event to stay pending, got %d events", len(es))
+	}
+	if e := es[0]; e.Attempts != 1 || !reflect.DeepEqual(e.Plugins, []string{"b"}) {
+		t.Errorf("unexpected pending event: %+v", e)
+	}
+}
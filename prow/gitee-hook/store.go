@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrEventNotFound is returned by EventStore.Load for an unknown event.
+var ErrEventNotFound = errors.New("event not found")
+
+// Event is a webhook kept in an EventStore until all of its plugins
+// handled it.
+type Event struct {
+	// ID identifies the event in the stores. The GUID of a Gitee webhook is
+	// its timestamp, which two webhooks can share, so the ID adds the hash of
+	// the payload to it.
+	ID      string      `json:"id"`
+	GUID    string      `json:"guid"`
+	Type    string      `json:"type"`
+	Payload []byte      `json:"payload,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+
+	// Plugins are the plugins which still have to handle the event, all the
+	// enabled ones if empty.
+	Plugins []string `json:"plugins,omitempty"`
+	// Attempts is the number of times some plugins failed to handle the event.
+	Attempts int `json:"attempts,omitempty"`
+	// Errors are the last errors of the plugins, keyed by plugin name.
+	Errors map[string]string `json:"errors,omitempty"`
+	// Error is the error which prevented handling the event at all, like an
+	// invalid payload.
+	Error string `json:"error,omitempty"`
+}
+
+// EventStore persists the events of a Queue.
+type EventStore interface {
+	Save(e *Event) error
+	// Load returns ErrEventNotFound if there is no event with the ID.
+	Load(id string) (*Event, error)
+	Delete(id string) error
+	List() ([]*Event, error)
+}
+
+var validEventID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// diskStore keeps each event in a JSON file of a directory.
+type diskStore struct {
+	dir string
+}
+
+// NewDiskStore returns an EventStore which keeps the events in the files
+// of the directory, creating it if needed.
+func NewDiskStore(dir string) (EventStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &diskStore{dir: dir}, nil
+}
+
+func (s *diskStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the event to a temporary file first, so that a crash never
+// leaves a truncated event behind.
+func (s *diskStore) Save(e *Event) error {
+	if !validEventID.MatchString(e.ID) {
+		return fmt.Errorf("invalid event id %q", e.ID)
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(e.ID))
+}
+
+func (s *diskStore) Load(id string) (*Event, error) {
+	if !validEventID.MatchString(id) {
+		return nil, ErrEventNotFound
+	}
+
+	b, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+
+	var e Event
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("invalid event %s: %v", id, err)
+	}
+	return &e, nil
+}
+
+func (s *diskStore) Delete(id string) error {
+	if !validEventID.MatchString(id) {
+		return nil
+	}
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns the events sorted by ID. The unreadable files are skipped,
+// so that one of them doesn't block the others.
+func (s *diskStore) List() ([]*Event, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var r []*Event
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		e, err := s.Load(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			logrus.WithError(err).WithField("file", filepath.Join(s.dir, name)).Warn("Skipping unreadable event.")
+			continue
+		}
+		r = append(r, e)
+	}
+	return r, nil
+}
@@ -57,7 +57,7 @@ func NewApprove(f plugins.GetPluginConfig, gec giteeClient, oc ownersClient) plu
 }
 
 func (a *approve) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
-	c, err := a.pluginConfig()
+	c, err := a.pluginConfig("", "")
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +185,8 @@ func getGiteeOption() prowConfig.GitHubOptions {
 	return prowConfig.GitHubOptions{LinkURLFromConfig: s, LinkURL: linkURL}
 }
 
-func (a *approve) pluginConfig() (*configuration, error) {
-	c := a.getPluginConfig(a.PluginName())
+func (a *approve) pluginConfig(org, repo string) (*configuration, error) {
+	c := a.getPluginConfig(a.PluginName(), org, repo)
 	if c == nil {
 		return nil, fmt.Errorf("can't find the approve's configuration")
 	}
@@ -199,7 +199,7 @@ func (a *approve) pluginConfig() (*configuration, error) {
 }
 
 func (a *approve) approveFor(org, repo string) (*originp.Approve, error) {
-	c, err := a.pluginConfig()
+	c, err := a.pluginConfig(org, repo)
 	if err != nil {
 		return nil, err
 	}
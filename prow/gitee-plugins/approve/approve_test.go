@@ -0,0 +1,146 @@
+package approve
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/labels"
+	"k8s.io/test-infra/prow/repoowners"
+)
+
+type fakeOwnersClient struct {
+	approvers sets.String
+}
+
+func (f *fakeOwnersClient) LoadRepoOwners(org, repo, base string) (repoowners.RepoOwner, error) {
+	return &fakeRepoOwners{approvers: f.approvers}, nil
+}
+
+// fakeRepoOwners has one OWNERS file at the root of the repository.
+type fakeRepoOwners struct {
+	approvers sets.String
+}
+
+func (f *fakeRepoOwners) FindApproverOwnersForFile(path string) string  { return "" }
+func (f *fakeRepoOwners) FindReviewersOwnersForFile(path string) string { return "" }
+func (f *fakeRepoOwners) FindLabelsForFile(path string) sets.String     { return nil }
+func (f *fakeRepoOwners) IsNoParentOwners(path string) bool             { return false }
+func (f *fakeRepoOwners) LeafApprovers(path string) sets.String         { return f.approvers }
+func (f *fakeRepoOwners) Approvers(path string) sets.String             { return f.approvers }
+func (f *fakeRepoOwners) LeafReviewers(path string) sets.String         { return nil }
+func (f *fakeRepoOwners) Reviewers(path string) sets.String             { return nil }
+func (f *fakeRepoOwners) RequiredReviewers(path string) sets.String     { return nil }
+func (f *fakeRepoOwners) TopLevelApprovers() sets.String                { return f.approvers }
+
+func (f *fakeRepoOwners) ParseSimpleConfig(path string) (repoowners.SimpleConfig, error) {
+	return repoowners.SimpleConfig{}, nil
+}
+
+func (f *fakeRepoOwners) ParseFullConfig(path string) (repoowners.FullConfig, error) {
+	return repoowners.FullConfig{}, nil
+}
+
+func TestHandlePullRequestEvent(t *testing.T) {
+	pr := fakegitee.PR{
+		Org:     "org",
+		Repo:    "repo",
+		Number:  3,
+		Author:  "author",
+		BaseRef: "master",
+		HeadSHA: "sha",
+	}
+	approvedLabel := fmt.Sprintf("org/repo#3:%s", labels.Approved)
+
+	testCases := []struct {
+		name     string
+		action   string
+		comments []string
+		hasLabel bool
+
+		expectAdded   bool
+		expectRemoved bool
+		expectNotify  bool
+	}{
+		{
+			name:         "opened PR without approval gets notified",
+			action:       "open",
+			expectNotify: true,
+		},
+		{
+			name:         "updated PR approved by an approver gets the label",
+			action:       "update",
+			comments:     []string{"/approve"},
+			expectAdded:  true,
+			expectNotify: true,
+		},
+		{
+			name:          "approved label is removed when the approval is cancelled",
+			action:        "update",
+			comments:      []string{"/approve", "/approve cancel"},
+			hasLabel:      true,
+			expectRemoved: true,
+			expectNotify:  true,
+		},
+		{
+			name:   "closed PR is skipped",
+			action: "close",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.PullRequestChanges[pr.Number] = []github.PullRequestChange{{Filename: "main.go"}}
+			if tc.hasLabel {
+				fc.PRLabelsExisting = []string{approvedLabel}
+			}
+			created := time.Now().Add(-time.Hour)
+			for i, c := range tc.comments {
+				fc.PRComments[pr.Number] = append(fc.PRComments[pr.Number], sdk.PullRequestComments{
+					Id:        int32(100 + i),
+					Body:      c,
+					User:      &sdk.UserBasic{Login: "approver"},
+					CreatedAt: created.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+				})
+			}
+
+			c := &configuration{}
+			a := NewApprove(
+				func(_, _, _ string) plugins.PluginConfig { return c },
+				fc,
+				&fakeOwnersClient{approvers: sets.NewString("approver")},
+			).(*approve)
+
+			e := fakegitee.NewPullRequestEvent(pr, tc.action)
+			if err := a.handlePullRequestEvent(e, logrus.WithField("plugin", "approve")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if added := sets.NewString(fc.PRLabelsAdded...).Has(approvedLabel); added != tc.expectAdded {
+				t.Errorf("expected approved label added: %t, got labels added: %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if removed := sets.NewString(fc.PRLabelsRemoved...).Has(approvedLabel); removed != tc.expectRemoved {
+				t.Errorf("expected approved label removed: %t, got labels removed: %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+
+			notified := false
+			for _, c := range fc.PRCommentsAdded {
+				if strings.Contains(c, "[APPROVALNOTIFIER]") {
+					notified = true
+				}
+			}
+			if notified != tc.expectNotify {
+				t.Errorf("expected notification: %t, got comments: %v", tc.expectNotify, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
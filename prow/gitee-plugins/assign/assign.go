@@ -51,6 +51,7 @@ func (a *assign) NewPluginConfig() plugins.PluginConfig {
 func (a *assign) RegisterEventHandler(p plugins.Plugins) {
 	name := a.PluginName()
 	p.RegisterNoteEventHandler(name, a.handleNoteEvent)
+	p.RegisterIssueHandler(name, a.handleIssueEvent)
 }
 
 func (a *assign) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
@@ -64,37 +65,46 @@ func (a *assign) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
 		return nil
 	}
 
-	var n int32
-	isPR := true
-	switch *(e.NoteableType) {
-	case "PullRequest":
-		n = e.PullRequest.Number
-	case "Issue":
-		isPR = false
-	default:
+	ce, ref, ok := plugins.NoteToCommentEvent(e)
+	if !ok {
 		log.Debug("not supported note type")
 		return nil
 	}
 
-	ce := github.GenericCommentEvent{
-		Repo: github.Repo{
-			Owner: github.User{Login: e.Repository.Owner.Login},
-			Name:  e.Repository.Name,
-		},
-		Body:    e.Comment.Body,
-		User:    github.User{Login: e.Comment.User.Login},
-		Number:  int(n),
-		HTMLURL: e.Comment.HtmlUrl,
-		IsPR:    false,
+	var assignee string
+	if !ref.IsPR() && e.Issue.Assignee != nil {
+		assignee = e.Issue.Assignee.Login
 	}
+	return a.handle(ce, ref, assignee, log)
+}
+
+func (a *assign) handleIssueEvent(e *sdk.IssueEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleIssueEvent")
+	}()
+
+	ce, ref, ok := plugins.IssueToCommentEvent(e)
+	if !ok {
+		log.Debug("Event is not an opening of an issue, skipping.")
+		return nil
+	}
+
+	var assignee string
+	if e.Issue.Assignee != nil {
+		assignee = e.Issue.Assignee.Login
+	}
+	return a.handle(ce, ref, assignee, log)
+}
 
+func (a *assign) handle(ce *github.GenericCommentEvent, ref plugins.IssueRef, assignee string, log *logrus.Entry) error {
 	var f func(mu github.MissingUsers) string
-	if isPR {
-		f = buildAssignPRFailureComment(a, ce.Repo.Owner.Login, ce.Repo.Name)
+	if ref.IsPR() {
+		f = buildAssignPRFailureComment(a, ref.Org, ref.Repo)
 	} else {
-		f = buildAssignIssueFailureComment(a, ce.Repo.Owner.Login, ce.Repo.Name)
+		f = buildAssignIssueFailureComment(a, ref.Org, ref.Repo)
 	}
-	return origina.HandleAssign(ce, &ghclient{giteeClient: a.gec, e: e}, f, log)
+	return origina.HandleAssign(*ce, newGHClient(a.gec, ref, assignee), f, log)
 }
 
 func buildAssignPRFailureComment(a *assign, org, repo string) func(mu github.MissingUsers) string {
@@ -3,34 +3,43 @@ package assign
 import (
 	"fmt"
 
-	sdk "gitee.com/openeuler/go-gitee/gitee"
 	"k8s.io/test-infra/prow/gitee"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
 	"k8s.io/test-infra/prow/github"
 )
 
 type giteeClient interface {
+	plugins.IssueGiteeClient
 	ListCollaborators(org, repo string) ([]github.User, error)
 	AssignPR(owner, repo string, number int, logins []string) error
 	UnassignPR(owner, repo string, number int, logins []string) error
-	CreatePRComment(owner, repo string, number int, comment string) error
 	AssignGiteeIssue(org, repo string, number string, login string) error
 	UnassignGiteeIssue(org, repo string, number string, login string) error
-	CreateGiteeIssueComment(owner, repo string, number string, comment string) error
 }
 
 var _ githubClient = (*ghclient)(nil)
 
 type ghclient struct {
 	giteeClient
-	e *sdk.NoteEvent
+	ic *plugins.IssueClient
+	// assignee is the current assignee of the issue, if any.
+	assignee string
+}
+
+func newGHClient(gec giteeClient, ref plugins.IssueRef, assignee string) *ghclient {
+	return &ghclient{
+		giteeClient: gec,
+		ic:          plugins.NewIssueClient(gec, ref),
+		assignee:    assignee,
+	}
 }
 
 func (c *ghclient) ispr() bool {
-	return *(c.e.NoteableType) == "PullRequest"
+	return c.ic.Ref.IsPR()
 }
 
 func (c *ghclient) issueNumber() string {
-	return c.e.Issue.Number
+	return c.ic.Ref.IssueNumber
 }
 
 func (c *ghclient) peopleCanAssignPRTo(owner, repo string, number int) (map[string]bool, error) {
@@ -105,18 +114,14 @@ func (c *ghclient) UnassignIssue(owner, repo string, number int, logins []string
 		return fmt.Errorf("can't unassign more one persons from an issue at same time")
 	}
 
-	if c.e.Issue.Assignee != nil && c.e.Issue.Assignee.Login == logins[0] {
+	if c.assignee == logins[0] {
 		return c.UnassignGiteeIssue(owner, repo, c.issueNumber(), logins[0])
 	}
 	return nil
 }
 
 func (c *ghclient) CreateComment(owner, repo string, number int, comment string) error {
-	if c.ispr() {
-		return c.CreatePRComment(owner, repo, number, comment)
-	}
-
-	return c.CreateGiteeIssueComment(owner, repo, c.issueNumber(), comment)
+	return c.ic.CreateComment(owner, repo, number, comment)
 }
 
 func (c *ghclient) RequestReview(org, repo string, number int, logins []string) error {
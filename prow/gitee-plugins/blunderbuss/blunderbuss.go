@@ -0,0 +1,152 @@
+package blunderbuss
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	originb "k8s.io/test-infra/prow/plugins/blunderbuss"
+	"k8s.io/test-infra/prow/repoowners"
+)
+
+type githubClient interface {
+	RequestReview(org, repo string, number int, logins []string) error
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	Query(context.Context, interface{}, map[string]interface{}) error
+}
+
+type ownersClient interface {
+	LoadRepoOwners(org, repo, base string) (repoowners.RepoOwner, error)
+}
+
+type blunderbuss struct {
+	getPluginConfig plugins.GetPluginConfig
+	ghc             githubClient
+	oc              ownersClient
+}
+
+func NewBlunderbuss(f plugins.GetPluginConfig, gec giteeClient, oc ownersClient) plugins.Plugin {
+	return &blunderbuss{
+		getPluginConfig: f,
+		ghc:             &ghclient{giteeClient: gec},
+		oc:              oc,
+	}
+}
+
+func (b *blunderbuss) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	c, err := b.pluginConfig("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	c1 := originp.Configuration{Blunderbuss: c.Blunderbuss}
+
+	return originb.HelpProvider(&c1, enabledRepos)
+}
+
+func (b *blunderbuss) PluginName() string {
+	return originb.PluginName
+}
+
+func (b *blunderbuss) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (b *blunderbuss) RegisterEventHandler(p plugins.Plugins) {
+	name := b.PluginName()
+	p.RegisterPullRequestHandler(name, b.handlePullRequestEvent)
+	p.RegisterNoteEventHandler(name, b.handleNoteEvent)
+}
+
+func (b *blunderbuss) handlePullRequestEvent(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handlePullRequest")
+	}()
+
+	if *(e.Action) != "open" {
+		log.Debug("Pull request event is not an opening of a PR, skipping.")
+		return nil
+	}
+
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+
+	c, err := b.pluginConfig(org, repo)
+	if err != nil {
+		return err
+	}
+
+	pr := &github.PullRequest{
+		Number: int(e.PullRequest.Number),
+		Body:   e.PullRequest.Body,
+		User:   github.User{Login: e.PullRequest.User.Login},
+	}
+	pr.Base.Ref = e.PullRequest.Base.Ref
+
+	return originb.HandlePullRequest(
+		b.ghc,
+		b.oc,
+		log,
+		c.Blunderbuss,
+		github.PullRequestActionOpened,
+		pr,
+		&github.Repo{
+			Owner:    github.User{Login: org},
+			Name:     repo,
+			FullName: org + "/" + repo,
+		},
+	)
+}
+
+func (b *blunderbuss) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleNoteEvent")
+	}()
+
+	ce, _, ok := plugins.NoteToCommentEvent(e)
+	if !ok || !ce.IsPR {
+		log.Debug("Event is not a creation of a comment on a PR, skipping.")
+		return nil
+	}
+
+	c, err := b.pluginConfig(ce.Repo.Owner.Login, ce.Repo.Name)
+	if err != nil {
+		return err
+	}
+
+	return originb.HandleGenericComment(
+		b.ghc,
+		b.oc,
+		log,
+		c.Blunderbuss,
+		ce.Action,
+		ce.IsPR,
+		ce.Number,
+		ce.IssueState,
+		&ce.Repo,
+		ce.Body,
+	)
+}
+
+func (b *blunderbuss) pluginConfig(org, repo string) (*configuration, error) {
+	c := b.getPluginConfig(b.PluginName(), org, repo)
+	if c == nil {
+		return nil, fmt.Errorf("can't find the blunderbuss's configuration")
+	}
+
+	c1, ok := c.(*configuration)
+	if !ok {
+		return nil, fmt.Errorf("can't convert to blunderbuss's configuration")
+	}
+	return c1, nil
+}
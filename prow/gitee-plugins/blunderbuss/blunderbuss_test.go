@@ -0,0 +1,169 @@
+package blunderbuss
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/repoowners"
+)
+
+type fakeOwnersClient struct {
+	reviewers sets.String
+}
+
+func (f *fakeOwnersClient) LoadRepoOwners(org, repo, base string) (repoowners.RepoOwner, error) {
+	return &fakeRepoOwners{reviewers: f.reviewers}, nil
+}
+
+// fakeRepoOwners has one OWNERS file at the root of the repository.
+type fakeRepoOwners struct {
+	reviewers sets.String
+}
+
+func (f *fakeRepoOwners) FindApproverOwnersForFile(path string) string  { return "" }
+func (f *fakeRepoOwners) FindReviewersOwnersForFile(path string) string { return "" }
+func (f *fakeRepoOwners) FindLabelsForFile(path string) sets.String     { return nil }
+func (f *fakeRepoOwners) IsNoParentOwners(path string) bool             { return false }
+func (f *fakeRepoOwners) LeafApprovers(path string) sets.String         { return nil }
+func (f *fakeRepoOwners) Approvers(path string) sets.String             { return nil }
+func (f *fakeRepoOwners) LeafReviewers(path string) sets.String {
+	return sets.NewString(f.reviewers.List()...)
+}
+func (f *fakeRepoOwners) Reviewers(path string) sets.String {
+	return sets.NewString(f.reviewers.List()...)
+}
+func (f *fakeRepoOwners) RequiredReviewers(path string) sets.String { return nil }
+func (f *fakeRepoOwners) TopLevelApprovers() sets.String            { return nil }
+
+func (f *fakeRepoOwners) ParseSimpleConfig(path string) (repoowners.SimpleConfig, error) {
+	return repoowners.SimpleConfig{}, nil
+}
+
+func (f *fakeRepoOwners) ParseFullConfig(path string) (repoowners.FullConfig, error) {
+	return repoowners.FullConfig{}, nil
+}
+
+func newBlunderbuss(fc *fakegitee.FakeClient) *blunderbuss {
+	getConfig := func(_, _, _ string) plugins.PluginConfig {
+		c := &configuration{}
+		c.SetDefault()
+		return c
+	}
+	oc := &fakeOwnersClient{reviewers: sets.NewString("alice", "author", "bob")}
+	return NewBlunderbuss(getConfig, fc, oc).(*blunderbuss)
+}
+
+func newFakeClient() *fakegitee.FakeClient {
+	fc := fakegitee.NewFakeClient()
+	fc.PullRequestChanges[1] = []github.PullRequestChange{{Filename: "main.go"}}
+	fc.PullRequests[1] = &sdk.PullRequest{
+		Number: 1,
+		State:  "open",
+		User:   &sdk.UserBasic{Login: "author"},
+		Head:   &sdk.BranchBasic{Ref: "fix"},
+		Base: &sdk.BranchBasic{
+			Ref:  "master",
+			Repo: &sdk.Project{Owner: &sdk.UserBasic{Login: "org"}},
+		},
+	}
+	return fc
+}
+
+func TestHandlePullRequestEvent(t *testing.T) {
+	reviewers := []string{"org/repo#1:alice", "org/repo#1:bob"}
+
+	testCases := []struct {
+		name     string
+		action   string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "opened PR gets reviewers",
+			action:   "open",
+			expected: reviewers,
+		},
+		{
+			name:   "opened PR which cc reviewers is left alone",
+			action: "open",
+			body:   "/cc @carol",
+		},
+		{
+			name:   "updated PR is ignored",
+			action: "update",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := newFakeClient()
+			pr := fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author", BaseRef: "master", Body: tc.body}
+			e := fakegitee.NewPullRequestEvent(pr, tc.action)
+			if err := newBlunderbuss(fc).handlePullRequestEvent(e, logrus.WithField("plugin", "blunderbuss")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fc.AssigneesAdded, tc.expected) {
+				t.Errorf("expected the reviewers %v, got %v", tc.expected, fc.AssigneesAdded)
+			}
+		})
+	}
+}
+
+func TestHandleNoteEvent(t *testing.T) {
+	pr := fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author", BaseRef: "master"}
+
+	testCases := []struct {
+		name     string
+		event    *sdk.NoteEvent
+		expected []string
+	}{
+		{
+			name:     "auto-cc on a PR requests reviewers",
+			event:    fakegitee.NewPRNoteEvent(pr, "someone", "/auto-cc"),
+			expected: []string{"org/repo#1:alice", "org/repo#1:bob"},
+		},
+		{
+			name:  "other comments are ignored",
+			event: fakegitee.NewPRNoteEvent(pr, "someone", "/lgtm"),
+		},
+		{
+			name:  "auto-cc on an issue is ignored",
+			event: fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "someone", "/auto-cc"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := newFakeClient()
+			if err := newBlunderbuss(fc).handleNoteEvent(tc.event, logrus.WithField("plugin", "blunderbuss")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fc.AssigneesAdded, tc.expected) {
+				t.Errorf("expected the reviewers %v, got %v", tc.expected, fc.AssigneesAdded)
+			}
+		})
+	}
+}
+
+func TestConfiguration(t *testing.T) {
+	c := &configuration{}
+	c.SetDefault()
+	if c.Blunderbuss.ReviewerCount == nil || *c.Blunderbuss.ReviewerCount != defaultReviewerCount {
+		t.Errorf("expected the default reviewer count %d, got %v", defaultReviewerCount, c.Blunderbuss.ReviewerCount)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	c.Blunderbuss.UseStatusAvailability = true
+	if err := c.Validate(); err == nil {
+		t.Error("expected the status availability to be rejected")
+	}
+}
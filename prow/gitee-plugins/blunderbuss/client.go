@@ -0,0 +1,43 @@
+package blunderbuss
+
+import (
+	"context"
+	"errors"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+type giteeClient interface {
+	AssignPR(owner, repo string, number int, logins []string) error
+	GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+}
+
+var _ githubClient = (*ghclient)(nil)
+
+type ghclient struct {
+	giteeClient
+}
+
+// RequestReview assigns the reviewers to the PR, since the assignees of a
+// Gitee PR are its reviewers.
+func (c *ghclient) RequestReview(org, repo string, number int, logins []string) error {
+	return c.AssignPR(org, repo, number, logins)
+}
+
+func (c *ghclient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	v, err := c.GetGiteePullRequest(org, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitee.ConvertGiteePR(&v), nil
+}
+
+// Query is only used to check the status availability of the reviewers,
+// which Gitee doesn't have.
+func (c *ghclient) Query(context.Context, interface{}, map[string]interface{}) error {
+	return errors.New("the status availability of users is not supported by Gitee")
+}
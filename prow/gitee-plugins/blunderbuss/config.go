@@ -0,0 +1,39 @@
+package blunderbuss
+
+import (
+	"errors"
+	"fmt"
+
+	originp "k8s.io/test-infra/prow/plugins"
+)
+
+const defaultReviewerCount = 2
+
+type configuration struct {
+	Blunderbuss originp.Blunderbuss `json:"blunderbuss,omitempty"`
+}
+
+func (c *configuration) Validate() error {
+	b := &c.Blunderbuss
+	if b.ReviewerCount != nil && b.FileWeightCount != nil {
+		return errors.New("cannot use both request_count and file_weight_count in blunderbuss")
+	}
+	if b.ReviewerCount != nil && *b.ReviewerCount < 1 {
+		return fmt.Errorf("invalid request_count: %v (needs to be positive)", *b.ReviewerCount)
+	}
+	if b.FileWeightCount != nil && *b.FileWeightCount < 1 {
+		return fmt.Errorf("invalid file_weight_count: %v (needs to be positive)", *b.FileWeightCount)
+	}
+	if b.UseStatusAvailability {
+		return errors.New("use_status_availability is not supported by Gitee")
+	}
+	return nil
+}
+
+func (c *configuration) SetDefault() {
+	b := &c.Blunderbuss
+	if b.ReviewerCount == nil && b.FileWeightCount == nil {
+		n := defaultReviewerCount
+		b.ReviewerCount = &n
+	}
+}
@@ -2,11 +2,13 @@ package plugins
 
 import (
 	"fmt"
+	"io/ioutil"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
 )
 
 type PluginConfigBuilder func() PluginConfig
@@ -22,14 +24,9 @@ func NewConfigAgent() *ConfigAgent {
 }
 
 func (ca *ConfigAgent) Load(path string, checkUnknownPlugins bool, knownPlugins map[string]HelpProvider) error {
-	pcs := make(map[string]PluginConfig)
-	for n, b := range ca.pcb {
-		pcs[n] = b()
-	}
-
-	c := &Configurations{pluginConfigs: pcs}
+	c := &Configurations{}
 
-	err := load(path, c)
+	err := load(path, c, ca.pcb)
 	if err != nil {
 		return err
 	}
@@ -55,6 +52,22 @@ func (ca *ConfigAgent) Load(path string, checkUnknownPlugins bool, knownPlugins
 	return nil
 }
 
+// CheckUnknownFields returns an error if the configuration at path, or one
+// of its overrides, has a field known neither by the Configurations nor by
+// the configurations of the registered plugins.
+func (ca *ConfigAgent) CheckUnknownFields(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	c := &Configurations{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return err
+	}
+	return c.checkUnknownFields(b, ca.pcb)
+}
+
 func (ca *ConfigAgent) RegisterPluginConfigBuilder(name string, b PluginConfigBuilder) {
 	ca.pcb[name] = b
 }
@@ -17,11 +17,13 @@ limitations under the License.
 package plugins
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/labels"
 	origin "k8s.io/test-infra/prow/plugins"
 	"sigs.k8s.io/yaml"
@@ -41,13 +43,31 @@ type Configurations struct {
 	// note that you're also able to add external plugins.
 	Plugins map[string][]string `json:"plugins,omitempty"`
 
+	// ExternalPlugins is a map of repositories (eg "k/k") to lists of
+	// external plugins. The Gitee events are forwarded to them as they
+	// were received, the events being filtered by the X-Gitee-Event
+	// header, e.g. "Note Hook".
+	ExternalPlugins map[string][]origin.ExternalPlugin `json:"external_plugins,omitempty"`
+
 	// Owners contains configuration related to handling OWNERS files.
 	Owners origin.Owners `json:"owners,omitempty"`
 
+	// Overrides maps an org (eg "openeuler") or a repository (eg
+	// "openeuler/community") to the configuration of the built-in plugins
+	// for it, written like the top-level configuration. The configuration
+	// of a repository is layered global -> org -> repo, a field set in a
+	// more specific layer replacing the one of the less specific layers.
+	Overrides map[string]json.RawMessage `json:"overrides,omitempty"`
+
 	// Built-in plugins specific configuration.
 	pluginConfigs map[string]PluginConfig
+
+	// overriddenConfigs maps the keys of Overrides to the layered
+	// configuration of each built-in plugin.
+	overriddenConfigs map[string]map[string]PluginConfig
 }
 
+// GetPluginConfig returns the global configuration of the plugin.
 func (c *Configurations) GetPluginConfig(name string) PluginConfig {
 	if pc, ok := c.pluginConfigs[name]; ok {
 		return pc
@@ -55,6 +75,20 @@ func (c *Configurations) GetPluginConfig(name string) PluginConfig {
 	return nil
 }
 
+// PluginConfigFor returns the configuration of the plugin for the repo, which
+// is the global configuration with the overrides of the org and of the repo
+// applied. An empty org returns the global configuration.
+func (c *Configurations) PluginConfigFor(name, org, repo string) PluginConfig {
+	if org != "" {
+		for _, k := range []string{org + "/" + repo, org} {
+			if pc, ok := c.overriddenConfigs[k][name]; ok {
+				return pc
+			}
+		}
+	}
+	return c.GetPluginConfig(name)
+}
+
 // MDYAMLEnabled returns a boolean denoting if the passed repo supports YAML OWNERS config headers
 // at the top of markdown (*.md) files. These function like OWNERS files but only apply to the file
 // itself.
@@ -101,11 +135,48 @@ func (c *Configurations) EnabledReposForPlugin(plugin string) (orgs, repos []str
 	return
 }
 
+// EnabledReposForExternalPlugin returns the orgs and repos that have enabled the passed
+// external plugin.
+func (c *Configurations) EnabledReposForExternalPlugin(plugin string) (orgs, repos []string) {
+	for repo, plugins := range c.ExternalPlugins {
+		found := false
+		for _, candidate := range plugins {
+			if candidate.Name == plugin {
+				found = true
+				break
+			}
+		}
+		if found {
+			if strings.Contains(repo, "/") {
+				repos = append(repos, repo)
+			} else {
+				orgs = append(orgs, repo)
+			}
+		}
+	}
+	return
+}
+
+func (c *Configurations) setDefaults() {
+	for repo, plugins := range c.ExternalPlugins {
+		for i, p := range plugins {
+			if p.Endpoint != "" {
+				continue
+			}
+			c.ExternalPlugins[repo][i].Endpoint = fmt.Sprintf("http://%s", p.Name)
+		}
+	}
+}
+
 func (c *Configurations) Validate() error {
-	if len(c.Plugins) == 0 {
+	if len(c.Plugins) == 0 && len(c.ExternalPlugins) == 0 {
 		logrus.Warn("no plugins specified-- check syntax?")
 	}
 
+	if err := validateExternalPlugins(c.ExternalPlugins); err != nil {
+		return err
+	}
+
 	for _, p := range c.pluginConfigs {
 		p.SetDefault()
 
@@ -114,10 +185,149 @@ func (c *Configurations) Validate() error {
 		}
 	}
 
+	for k, pcs := range c.overriddenConfigs {
+		for name, p := range pcs {
+			p.SetDefault()
+
+			if err := p.Validate(); err != nil {
+				return fmt.Errorf("invalid configuration of %s for %s: %v", name, k, err)
+			}
+		}
+	}
+
 	return nil
 }
 
-func load(path string, c *Configurations) error {
+// validateOverrideKey makes sure the key of an override is an org or a
+// repository.
+func validateOverrideKey(k string) error {
+	parts := strings.Split(k, "/")
+	if len(parts) > 2 {
+		return fmt.Errorf("the key %q of the overrides must be an org or an org/repo", k)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("the key %q of the overrides must be an org or an org/repo", k)
+		}
+	}
+	return nil
+}
+
+// buildOverriddenConfigs builds the configurations of the built-in plugins
+// for each key of the overrides by unmarshalling the layers one after the
+// other, so that the fields of a layer replace those of the previous ones.
+func (c *Configurations) buildOverriddenConfigs(global []byte, builders map[string]PluginConfigBuilder) error {
+	c.overriddenConfigs = make(map[string]map[string]PluginConfig, len(c.Overrides))
+	for k, override := range c.Overrides {
+		if err := validateOverrideKey(k); err != nil {
+			return err
+		}
+
+		layers := [][]byte{global}
+		if i := strings.Index(k, "/"); i >= 0 {
+			if org, ok := c.Overrides[k[:i]]; ok {
+				layers = append(layers, org)
+			}
+		}
+		layers = append(layers, override)
+
+		pcs := make(map[string]PluginConfig, len(builders))
+		for name, b := range builders {
+			p := b()
+			for _, l := range layers {
+				if err := yaml.Unmarshal(l, p); err != nil {
+					return fmt.Errorf("invalid overrides for %s: %v", k, err)
+				}
+			}
+			pcs[name] = p
+		}
+		c.overriddenConfigs[k] = pcs
+	}
+	return nil
+}
+
+// checkUnknownFields makes sure every top-level field of the configuration
+// and of its overrides is known by the Configurations or by the
+// configuration of a built-in plugin.
+func (c *Configurations) checkUnknownFields(b []byte, builders map[string]PluginConfigBuilder) error {
+	// Configurations is a candidate of the top-level fields only.
+	known := func(field string, value json.RawMessage, topLevel bool) bool {
+		doc, err := json.Marshal(map[string]json.RawMessage{field: value})
+		if err != nil {
+			return false
+		}
+		if topLevel && yaml.Unmarshal(doc, &Configurations{}, yaml.DisallowUnknownFields) == nil {
+			return true
+		}
+		for _, builder := range builders {
+			if yaml.Unmarshal(doc, builder(), yaml.DisallowUnknownFields) == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	var errors []string
+	check := func(section string, doc []byte, topLevel bool) {
+		var fields map[string]json.RawMessage
+		if err := yaml.Unmarshal(doc, &fields); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", section, err))
+			return
+		}
+		for _, f := range sets.StringKeySet(fields).List() {
+			if !known(f, fields[f], topLevel) {
+				errors = append(errors, fmt.Sprintf("%s: unknown or invalid field %q", section, f))
+			}
+		}
+	}
+
+	check("the top level", b, true)
+	for _, k := range sets.StringKeySet(c.Overrides).List() {
+		check(fmt.Sprintf("the overrides for %s", k), c.Overrides[k], false)
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("invalid plugin configuration:\n\t%v", strings.Join(errors, "\n\t"))
+	}
+	return nil
+}
+
+// validateExternalPlugins makes sure an external plugin is not enabled for
+// both a repository and its org.
+func validateExternalPlugins(pluginMap map[string][]origin.ExternalPlugin) error {
+	var errors []string
+
+	for repo, plugins := range pluginMap {
+		if !strings.Contains(repo, "/") {
+			continue
+		}
+		org := strings.Split(repo, "/")[0]
+
+		orgConfig := sets.NewString()
+		for _, p := range pluginMap[org] {
+			orgConfig.Insert(p.Name)
+		}
+
+		var dupes []string
+		for _, p := range plugins {
+			if orgConfig.Has(p.Name) {
+				dupes = append(dupes, p.Name)
+			}
+		}
+		if len(dupes) > 0 {
+			errors = append(errors, fmt.Sprintf("external plugins %v are duplicated for %s and %s", dupes, repo, org))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("invalid plugin configuration:\n\t%v", strings.Join(errors, "\n\t"))
+	}
+	return nil
+}
+
+// load reads the configuration at path into c and into the configurations
+// built by builders, then layers the overrides over them.
+func load(path string, c *Configurations, builders map[string]PluginConfigBuilder) error {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
@@ -127,12 +337,21 @@ func load(path string, c *Configurations) error {
 		return err
 	}
 
-	for _, p := range c.pluginConfigs {
+	c.pluginConfigs = make(map[string]PluginConfig, len(builders))
+	for name, builder := range builders {
+		p := builder()
 		if err := yaml.Unmarshal(b, p); err != nil {
 			return err
 		}
+		c.pluginConfigs[name] = p
 	}
 
+	if err := c.buildOverriddenConfigs(b, builders); err != nil {
+		return err
+	}
+
+	c.setDefaults()
+
 	if c.Owners.LabelsBlackList == nil {
 		c.Owners.LabelsBlackList = []string{labels.Approved, labels.LGTM}
 	}
@@ -0,0 +1,275 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeSection struct {
+	Labels    []string `json:"labels,omitempty"`
+	Threshold int      `json:"threshold,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+type fakeConfiguration struct {
+	Fake fakeSection `json:"fake,omitempty"`
+}
+
+func (c *fakeConfiguration) Validate() error {
+	if c.Fake.Threshold < 0 {
+		return errors.New("the threshold can't be negative")
+	}
+	return nil
+}
+
+func (c *fakeConfiguration) SetDefault() {
+	if c.Fake.Message == "" {
+		c.Fake.Message = "default"
+	}
+}
+
+func writeConfig(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "gitee-plugins")
+	if err != nil {
+		t.Fatalf("failed to create the temporary directory: %v", err)
+	}
+	path := filepath.Join(dir, "plugins.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write the config: %v", err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func newFakeConfigAgent() *ConfigAgent {
+	ca := NewConfigAgent()
+	ca.RegisterPluginConfigBuilder("fake", func() PluginConfig { return &fakeConfiguration{} })
+	return ca
+}
+
+func TestPluginConfigFor(t *testing.T) {
+	path, clean := writeConfig(t, `
+plugins:
+  org:
+  - fake
+fake:
+  labels:
+  - global
+  threshold: 1
+overrides:
+  org:
+    fake:
+      threshold: 2
+  org/repo:
+    fake:
+      labels:
+      - repo
+      message: custom
+  other/repo:
+    fake:
+      labels: []
+`)
+	defer clean()
+
+	ca := newFakeConfigAgent()
+	if err := ca.Load(path, false, nil); err != nil {
+		t.Fatalf("failed to load the config: %v", err)
+	}
+	c := ca.Config()
+
+	testCases := []struct {
+		name     string
+		org      string
+		repo     string
+		expected fakeSection
+	}{
+		{
+			name:     "global",
+			expected: fakeSection{Labels: []string{"global"}, Threshold: 1, Message: "default"},
+		},
+		{
+			name:     "org without overrides",
+			org:      "another",
+			repo:     "repo",
+			expected: fakeSection{Labels: []string{"global"}, Threshold: 1, Message: "default"},
+		},
+		{
+			name:     "org overrides",
+			org:      "org",
+			repo:     "another",
+			expected: fakeSection{Labels: []string{"global"}, Threshold: 2, Message: "default"},
+		},
+		{
+			name:     "repo overrides are layered over the org overrides",
+			org:      "org",
+			repo:     "repo",
+			expected: fakeSection{Labels: []string{"repo"}, Threshold: 2, Message: "custom"},
+		},
+		{
+			name:     "repo overrides without org overrides",
+			org:      "other",
+			repo:     "repo",
+			expected: fakeSection{Labels: []string{}, Threshold: 1, Message: "default"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pc, ok := c.PluginConfigFor("fake", tc.org, tc.repo).(*fakeConfiguration)
+			if !ok {
+				t.Fatalf("expected a fake configuration, got %v", pc)
+			}
+			if !reflect.DeepEqual(pc.Fake, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, pc.Fake)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidOverrides(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name: "invalid key",
+			config: `
+overrides:
+  org/repo/dir:
+    fake:
+      threshold: 1
+`,
+			expected: `the key "org/repo/dir" of the overrides must be an org or an org/repo`,
+		},
+		{
+			name: "invalid layered configuration",
+			config: `
+fake:
+  threshold: 1
+overrides:
+  org:
+    fake:
+      threshold: -1
+`,
+			expected: "invalid configuration of fake for org: the threshold can't be negative",
+		},
+		{
+			name: "bad type",
+			config: `
+overrides:
+  org:
+    fake:
+      threshold: many
+`,
+			expected: "invalid overrides for org",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, clean := writeConfig(t, tc.config)
+			defer clean()
+
+			err := newFakeConfigAgent().Load(path, false, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected an error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCheckUnknownFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name: "known fields",
+			config: `
+plugins:
+  org:
+  - fake
+external_plugins:
+  org:
+  - name: needs-rebase
+fake:
+  threshold: 1
+overrides:
+  org:
+    fake:
+      threshold: 2
+`,
+		},
+		{
+			name: "unknown top-level field",
+			config: `
+fake:
+  threshold: 1
+fkae:
+  threshold: 2
+`,
+			expected: []string{`the top level: unknown or invalid field "fkae"`},
+		},
+		{
+			name: "unknown field of a plugin",
+			config: `
+fake:
+  treshold: 1
+`,
+			expected: []string{`the top level: unknown or invalid field "fake"`},
+		},
+		{
+			name: "unknown field in the overrides",
+			config: `
+overrides:
+  org/repo:
+    plugins:
+      org/repo:
+      - fake
+`,
+			expected: []string{`the overrides for org/repo: unknown or invalid field "plugins"`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, clean := writeConfig(t, tc.config)
+			defer clean()
+
+			err := newFakeConfigAgent().CheckUnknownFields(path)
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			for _, e := range tc.expected {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected the error to contain %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
@@ -1,24 +1,60 @@
 package plugins
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"gitee.com/openeuler/go-gitee/gitee"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 	hook "k8s.io/test-infra/prow/gitee-hook"
 	"k8s.io/test-infra/prow/github"
+	origin "k8s.io/test-infra/prow/plugins"
 )
 
-func NewDispatcher(c *ConfigAgent, ps Plugins) hook.Dispatcher {
-	return &dispatcher{c: c, ps: ps.(*plugins)}
+// NewDispatcher returns the dispatcher of the events to the plugins, which
+// runs at most workers plugins at once.
+func NewDispatcher(c *ConfigAgent, ps Plugins, workers int) hook.Dispatcher {
+	return newDispatcher(c, ps, workers)
+}
+
+// NewEventHandler returns the handler of the events of the durable queue
+// of gitee-hook. It runs the same plugins as the dispatcher.
+func NewEventHandler(c *ConfigAgent, ps Plugins, workers int) hook.EventHandler {
+	return newDispatcher(c, ps, workers)
+}
+
+// externalPluginTimeout bounds each attempt to dispatch an event to an
+// external plugin, so a stuck plugin can't hold a worker of the pool and
+// delay the next events of the PR or issue forever.
+const externalPluginTimeout = 30 * time.Second
+
+func newDispatcher(c *ConfigAgent, ps Plugins, workers int) *dispatcher {
+	return &dispatcher{
+		c:    c,
+		ps:   ps.(*plugins),
+		hc:   http.Client{Timeout: externalPluginTimeout},
+		pool: newWorkerPool(workers),
+	}
 }
 
 type dispatcher struct {
 	c  *ConfigAgent
 	ps *plugins
 
+	// hc is an http client used for dispatching events
+	// to external plugin services.
+	hc http.Client
+
+	// pool runs the plugins, in order for the events of a PR or an issue.
+	pool *workerPool
+
 	// Tracks running handlers for graceful shutdown
 	wg sync.WaitGroup
 }
@@ -90,7 +126,52 @@ func (d *dispatcher) Wait() {
 	d.wg.Wait() // Handle remaining requests
 }
 
-func (d *dispatcher) Dispatch(eventType, eventGUID string, payload []byte) error {
+func (d *dispatcher) Dispatch(eventType, eventGUID string, payload []byte, h http.Header) error {
+	key, hs, err := d.eventHandlers(eventType, eventGUID, payload, h)
+	if err != nil {
+		return err
+	}
+
+	d.wg.Add(1)
+	d.pool.submit(key, &task{
+		eventType: eventType,
+		handlers:  hs,
+		done:      func(map[string]error) { d.wg.Done() },
+	})
+	return nil
+}
+
+// Handle runs the plugins and the external plugins of the event and waits
+// for them. Only the plugins of e.Plugins are run, unless it is empty. It
+// returns the errors of the plugins which failed, keyed by plugin name.
+func (d *dispatcher) Handle(e *hook.Event) (map[string]error, error) {
+	key, hs, err := d.eventHandlers(e.Type, e.GUID, e.Payload, e.Header)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Plugins) > 0 {
+		selected := sets.NewString(e.Plugins...)
+		for p := range hs {
+			if !selected.Has(p) {
+				delete(hs, p)
+			}
+		}
+	}
+
+	result := make(chan map[string]error, 1)
+	d.pool.submit(key, &task{
+		eventType: e.Type,
+		handlers:  hs,
+		done:      func(errs map[string]error) { result <- errs },
+	})
+	return <-result, nil
+}
+
+// eventHandlers parses the event and returns the functions which run the
+// plugins and the external plugins enabled for it, keyed by plugin name.
+// It also returns the key ordering the event with the others, which is the
+// PR or the issue of the event, or the repository of a push.
+func (d *dispatcher) eventHandlers(eventType, eventGUID string, payload []byte, h http.Header) (string, map[string]func() error, error) {
 	l := logrus.WithFields(
 		logrus.Fields{
 			"event-type":     eventType,
@@ -98,48 +179,172 @@ func (d *dispatcher) Dispatch(eventType, eventGUID string, payload []byte) error
 		},
 	)
 
+	hs := map[string]func() error{}
+	var srcRepo, key string
 	switch eventType {
 	case "Note Hook":
 		var e gitee.NoteEvent
 		if err := json.Unmarshal(payload, &e); err != nil {
-			return err
+			return "", nil, err
+		}
+		srcRepo = fullName(e.Repository.Owner.Login, e.Repository.Name)
+		switch *(e.NoteableType) {
+		case "PullRequest":
+			key = fmt.Sprintf("%s#%d", srcRepo, e.PullRequest.Number)
+		case "Issue":
+			key = fmt.Sprintf("%s#%s", srcRepo, e.Issue.Number)
+		default:
+			key = srcRepo
 		}
-		d.wg.Add(1)
-		go d.handleNoteEvent(&e, l)
+		hs = d.handleNoteEvent(&e, l)
 
 	case "Issue Hook":
 		var ie gitee.IssueEvent
 		if err := json.Unmarshal(payload, &ie); err != nil {
-			return err
+			return "", nil, err
 		}
-		d.wg.Add(1)
-		go d.handleIssueEvent(&ie, l)
+		srcRepo = fullName(ie.Repository.Owner.Login, ie.Repository.Name)
+		key = fmt.Sprintf("%s#%s", srcRepo, ie.Issue.Number)
+		hs = d.handleIssueEvent(&ie, l)
 
 	case "Merge Request Hook":
 		var pr gitee.PullRequestEvent
 		if err := json.Unmarshal(payload, &pr); err != nil {
-			return err
+			return "", nil, err
 		}
-		d.wg.Add(1)
-		go d.handlePullRequestEvent(&pr, l)
+		srcRepo = fullName(pr.Repository.Owner.Login, pr.Repository.Name)
+		key = fmt.Sprintf("%s#%d", srcRepo, pr.PullRequest.Number)
+		hs = d.handlePullRequestEvent(&pr, l)
 
 	case "Push Hook":
 		var pe gitee.PushEvent
 		if err := json.Unmarshal(payload, &pe); err != nil {
-			return err
+			return "", nil, err
 		}
-		d.wg.Add(1)
-		go d.handlePushEvent(&pe, l)
+		srcRepo = fullName(pe.Repository.Owner.Name, pe.Repository.Name)
+		key = srcRepo
+		hs = d.handlePushEvent(&pe, l)
 
 	default:
-		l.Debug("Ignoring unhandled event type")
+		key = eventGUID
+		l.Debug("Ignoring unhandled event type. (Might still be handled by external plugins.)")
+	}
+
+	// Demux events only to external plugins that require this event.
+	for p, f := range d.demuxExternal(l, d.needDemux(eventType, srcRepo), payload, h) {
+		hs[p] = f
+	}
+	return key, hs, nil
+}
+
+func fullName(owner, repo string) string {
+	return fmt.Sprintf("%s/%s", owner, repo)
+}
+
+// needDemux returns whether there are any external plugins that need to
+// get the present event.
+func (d *dispatcher) needDemux(eventType, srcRepo string) []origin.ExternalPlugin {
+	var matching []origin.ExternalPlugin
+	srcOrg := strings.Split(srcRepo, "/")[0]
+
+	for repo, plugins := range d.c.Config().ExternalPlugins {
+		// Make sure the repositories match
+		if repo != srcRepo && repo != srcOrg {
+			continue
+		}
+
+		// Make sure the events match
+		for _, p := range plugins {
+			if len(p.Events) == 0 {
+				matching = append(matching, p)
+			} else {
+				for _, et := range p.Events {
+					if et != eventType {
+						continue
+					}
+					matching = append(matching, p)
+					break
+				}
+			}
+		}
+	}
+	return matching
+}
+
+// demuxExternal returns the functions which dispatch the provided payload
+// to the external plugins. The headers are those of the webhook, so the
+// external plugins can validate the payload with gitee.ValidateWebhook.
+func (d *dispatcher) demuxExternal(l *logrus.Entry, externalPlugins []origin.ExternalPlugin, payload []byte, h http.Header) map[string]func() error {
+	r := map[string]func() error{}
+	if len(externalPlugins) == 0 {
+		return r
+	}
+
+	// The header of the webhook is shared by the plugins of the event.
+	if h == nil {
+		h = http.Header{}
+	} else {
+		h = h.Clone()
+	}
+	h.Set("User-Agent", "ProwHook")
+	for _, p := range externalPlugins {
+		p := p
+		r[p.Name] = func() error {
+			if err := d.dispatch(p.Endpoint, payload, h); err != nil {
+				l.WithError(err).WithField("external-plugin", p.Name).Error("Error dispatching event to external plugin.")
+				return err
+			}
+			l.WithField("external-plugin", p.Name).Info("Dispatched event to external plugin")
+			return nil
+		}
+	}
+	return r
+}
+
+// dispatch sends the provided payload and headers to the provided endpoint.
+func (d *dispatcher) dispatch(endpoint string, payload []byte, h http.Header) error {
+	resp, err := d.do(endpoint, payload, h)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	rb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("response has status %q and body %q", resp.Status, string(rb))
 	}
 	return nil
 }
 
-func (d *dispatcher) handlePullRequestEvent(pr *gitee.PullRequestEvent, l *logrus.Entry) {
-	defer d.wg.Done()
+// do posts the payload to the endpoint, retrying on errors. Every attempt
+// sends a new request, since the body of the previous one is consumed.
+func (d *dispatcher) do(endpoint string, payload []byte, h http.Header) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+	backoff := 100 * time.Millisecond
+	maxRetries := 5
+
+	for retries := 0; retries < maxRetries; retries++ {
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = h.Clone()
+
+		resp, err = d.hc.Do(req)
+		if err == nil {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return resp, err
+}
 
+func (d *dispatcher) handlePullRequestEvent(pr *gitee.PullRequestEvent, l *logrus.Entry) map[string]func() error {
 	l = l.WithFields(logrus.Fields{
 		github.OrgLogField:  pr.Repository.Owner.Login,
 		github.RepoLogField: pr.Repository.Name,
@@ -149,22 +354,21 @@ func (d *dispatcher) handlePullRequestEvent(pr *gitee.PullRequestEvent, l *logru
 	})
 	l.Infof("Pull request %s.", *pr.Action)
 
+	r := map[string]func() error{}
 	for p, h := range d.pullRequestHandlers(pr.Repository.Owner.Login, pr.Repository.Name) {
-		d.wg.Add(1)
-
-		go func(p string, h PullRequestHandler) {
-			defer d.wg.Done()
-
-			if err := h(pr, l); err != nil {
+		p, h := p, h
+		r[p] = func() error {
+			err := h(pr, l)
+			if err != nil {
 				l.WithField("plugin", p).WithError(err).Error("Error handling PullRequestEvent.")
 			}
-		}(p, h)
+			return err
+		}
 	}
+	return r
 }
 
-func (d *dispatcher) handleIssueEvent(i *gitee.IssueEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleIssueEvent(i *gitee.IssueEvent, l *logrus.Entry) map[string]func() error {
 	l = l.WithFields(logrus.Fields{
 		github.OrgLogField:  i.Repository.Owner.Login,
 		github.RepoLogField: i.Repository.Name,
@@ -174,22 +378,21 @@ func (d *dispatcher) handleIssueEvent(i *gitee.IssueEvent, l *logrus.Entry) {
 	})
 	l.Infof("Issue %s.", *i.Action)
 
+	r := map[string]func() error{}
 	for p, h := range d.issueHandlers(i.Repository.Owner.Login, i.Repository.Name) {
-		d.wg.Add(1)
-
-		go func(p string, h IssueHandler) {
-			defer d.wg.Done()
-
-			if err := h(i, l); err != nil {
+		p, h := p, h
+		r[p] = func() error {
+			err := h(i, l)
+			if err != nil {
 				l.WithField("plugin", p).WithError(err).Error("Error handling IssueEvent.")
 			}
-		}(p, h)
+			return err
+		}
 	}
+	return r
 }
 
-func (d *dispatcher) handlePushEvent(pe *gitee.PushEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handlePushEvent(pe *gitee.PushEvent, l *logrus.Entry) map[string]func() error {
 	l = l.WithFields(logrus.Fields{
 		github.OrgLogField:  pe.Repository.Owner.Login,
 		github.RepoLogField: pe.Repository.Name,
@@ -198,22 +401,21 @@ func (d *dispatcher) handlePushEvent(pe *gitee.PushEvent, l *logrus.Entry) {
 	})
 	l.Info("Push event.")
 
+	r := map[string]func() error{}
 	for p, h := range d.pushEventHandlers(pe.Repository.Owner.Name, pe.Repository.Name) {
-		d.wg.Add(1)
-
-		go func(p string, h PushEventHandler) {
-			defer d.wg.Done()
-
-			if err := h(pe, l); err != nil {
+		p, h := p, h
+		r[p] = func() error {
+			err := h(pe, l)
+			if err != nil {
 				l.WithField("plugin", p).WithError(err).Error("Error handling PushEvent.")
 			}
-		}(p, h)
+			return err
+		}
 	}
+	return r
 }
 
-func (d *dispatcher) handleNoteEvent(e *gitee.NoteEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleNoteEvent(e *gitee.NoteEvent, l *logrus.Entry) map[string]func() error {
 	var n interface{}
 	switch *(e.NoteableType) {
 	case "PullRequest":
@@ -232,15 +434,16 @@ func (d *dispatcher) handleNoteEvent(e *gitee.NoteEvent, l *logrus.Entry) {
 	})
 	l.Infof("Note %s.", *e.Action)
 
+	r := map[string]func() error{}
 	for p, h := range d.noteEventHandlers(e.Repository.Owner.Login, e.Repository.Name) {
-		d.wg.Add(1)
-
-		go func(p string, h NoteEventHandler) {
-			defer d.wg.Done()
-
-			if err := h(e, l); err != nil {
+		p, h := p, h
+		r[p] = func() error {
+			err := h(e, l)
+			if err != nil {
 				l.WithField("plugin", p).WithError(err).Error("Error handling NoteEvent.")
 			}
-		}(p, h)
+			return err
+		}
 	}
+	return r
 }
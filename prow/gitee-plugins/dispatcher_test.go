@@ -0,0 +1,212 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	hook "k8s.io/test-infra/prow/gitee-hook"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	origin "k8s.io/test-infra/prow/plugins"
+)
+
+func TestNeedDemux(t *testing.T) {
+	testCases := []struct {
+		name      string
+		eventType string
+		srcRepo   string
+		plugins   map[string][]origin.ExternalPlugin
+
+		expected []string
+	}{
+		{
+			name:      "no external plugins",
+			eventType: "Note Hook",
+			srcRepo:   "openeuler/community",
+		},
+		{
+			name:      "events and repositories are filtered",
+			eventType: "Note Hook",
+			srcRepo:   "openeuler/community",
+			plugins: map[string][]origin.ExternalPlugin{
+				"openeuler/community": {
+					{Name: "sandwich", Events: []string{"Merge Request Hook"}},
+					{Name: "coffee"},
+				},
+				"openeuler/kernel": {
+					{Name: "gumbo", Events: []string{"Note Hook"}},
+				},
+				"openeuler": {
+					{Name: "chicken", Events: []string{"Push Hook"}},
+					{Name: "water"},
+					{Name: "chocolate", Events: []string{"Merge Request Hook", "Note Hook"}},
+				},
+			},
+			expected: []string{"chocolate", "coffee", "water"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &dispatcher{c: &ConfigAgent{c: &Configurations{ExternalPlugins: tc.plugins}}}
+
+			var got []string
+			for _, p := range d.needDemux(tc.eventType, tc.srcRepo) {
+				got = append(got, p.Name)
+			}
+			sort.Strings(got)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected plugins %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("expected plugins %v, got %v", tc.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchForwardsToExternalPlugins(t *testing.T) {
+	var lock sync.Mutex
+	received := map[string]*http.Request{}
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		lock.Lock()
+		defer lock.Unlock()
+		received[r.URL.Path] = r
+		bodies[r.URL.Path] = string(b)
+	}))
+	defer server.Close()
+
+	c := &Configurations{
+		ExternalPlugins: map[string][]origin.ExternalPlugin{
+			"org": {
+				{Name: "notes", Endpoint: server.URL + "/notes", Events: []string{"Note Hook"}},
+				{Name: "pushes", Endpoint: server.URL + "/pushes", Events: []string{"Push Hook"}},
+			},
+		},
+	}
+	d := NewDispatcher(&ConfigAgent{c: c}, NewPluginManager(), 4)
+
+	pr := fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author"}
+	payload, err := json.Marshal(fakegitee.NewPRNoteEvent(pr, "commenter", "/hello"))
+	if err != nil {
+		t.Fatalf("failed to marshal the event: %v", err)
+	}
+	h := http.Header{}
+	h.Set("X-Gitee-Event", "Note Hook")
+	h.Set("X-Gitee-Token", "signature")
+	h.Set("X-Gitee-Timestamp", "1600000000000")
+
+	if err := d.Dispatch("Note Hook", "1600000000000", payload, h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d.Wait()
+
+	if _, ok := received["/pushes"]; ok {
+		t.Error("expected the note event not to be forwarded to the plugin of push events")
+	}
+	r, ok := received["/notes"]
+	if !ok {
+		t.Fatal("expected the note event to be forwarded")
+	}
+	if bodies["/notes"] != string(payload) {
+		t.Errorf("expected the payload to be forwarded unchanged, got %s", bodies["/notes"])
+	}
+	for _, k := range []string{"X-Gitee-Event", "X-Gitee-Token", "X-Gitee-Timestamp"} {
+		if r.Header.Get(k) != h.Get(k) {
+			t.Errorf("expected the header %s to be %q, got %q", k, h.Get(k), r.Header.Get(k))
+		}
+	}
+	if ua := h.Get("User-Agent"); ua != "" {
+		t.Errorf("expected the header of the webhook to be left unchanged, got the user agent %q", ua)
+	}
+}
+
+func TestDispatchRetriesWithThePayload(t *testing.T) {
+	var attempts int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Drop the connection to fail the first attempt.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("failed to hijack the connection: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	d := newDispatcher(&ConfigAgent{c: &Configurations{}}, NewPluginManager(), 1)
+	if err := d.dispatch(server.URL, []byte(`{"action": "comment"}`), http.Header{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if body != `{"action": "comment"}` {
+		t.Errorf("expected the retry to send the whole payload, got %q", body)
+	}
+}
+
+func TestHandleRunsTheGivenPlugins(t *testing.T) {
+	var lock sync.Mutex
+	var ran []string
+	handler := func(name string, err error) NoteEventHandler {
+		return func(_ *sdk.NoteEvent, _ *logrus.Entry) error {
+			lock.Lock()
+			defer lock.Unlock()
+			ran = append(ran, name)
+			return err
+		}
+	}
+
+	pm := NewPluginManager()
+	pm.RegisterNoteEventHandler("failing", handler("failing", errors.New("transient failure")))
+	pm.RegisterNoteEventHandler("passing", handler("passing", nil))
+	pm.RegisterNoteEventHandler("skipped", handler("skipped", nil))
+
+	c := &Configurations{Plugins: map[string][]string{"org": {"failing", "passing", "skipped"}}}
+	h := NewEventHandler(&ConfigAgent{c: c}, pm, 4)
+
+	pr := fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author"}
+	payload, err := json.Marshal(fakegitee.NewPRNoteEvent(pr, "commenter", "/hello"))
+	if err != nil {
+		t.Fatalf("failed to marshal the event: %v", err)
+	}
+
+	errs, err := h.Handle(&hook.Event{Type: "Note Hook", GUID: "1600000000000", Payload: payload, Header: http.Header{}, Plugins: []string{"failing", "passing"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 || errs["failing"] == nil {
+		t.Errorf("expected only the failing plugin to fail, got %v", errs)
+	}
+	sort.Strings(ran)
+	if expected := []string{"failing", "passing"}; !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected the plugins %v to run, got %v", expected, ran)
+	}
+
+	if _, err := h.Handle(&hook.Event{Type: "Note Hook", Payload: []byte("not json")}); err == nil {
+		t.Error("expected an invalid payload to fail")
+	}
+}
@@ -0,0 +1,200 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/pluginhelp"
+	helphook "k8s.io/test-infra/prow/pluginhelp/hook"
+)
+
+// newRepoDetectionLimit is the maximum allowable time before a repo will appear in the help
+// information if it is a new repo that is only referenced via its parent org in the config.
+const newRepoDetectionLimit = time.Hour
+
+type helpGiteeClient interface {
+	GetRepos(org string) ([]sdk.Project, error)
+}
+
+// HelpAgent is a handler that generates and serves the help information of
+// the Gitee plugins in the format of pluginhelp/hook, so deck can show it.
+type HelpAgent struct {
+	log *logrus.Entry
+	ca  *ConfigAgent
+	ps  *plugins
+	gc  helpGiteeClient
+
+	lock       sync.Mutex
+	nextSync   time.Time
+	orgs       sets.String
+	orgToRepos map[string]sets.String
+}
+
+// NewHelpAgent constructs a new HelpAgent.
+func NewHelpAgent(ca *ConfigAgent, ps Plugins, gc helpGiteeClient) *HelpAgent {
+	return &HelpAgent{
+		log: logrus.WithField("client", "plugin-help"),
+		ca:  ca,
+		ps:  ps.(*plugins),
+		gc:  gc,
+	}
+}
+
+// GeneratePluginHelp compiles and returns the help information for all plugins.
+func (ha *HelpAgent) GeneratePluginHelp() *pluginhelp.Help {
+	c := ha.ca.Config()
+	orgToRepos := ha.orgToReposMap(c)
+
+	normalRevMap := reversePluginMap(c.Plugins, orgToRepos)
+	externalRevMap := map[string][]config.OrgRepo{}
+	for repo, exts := range c.ExternalPlugins {
+		for _, ext := range exts {
+			externalRevMap[ext.Name] = append(externalRevMap[ext.Name], expandRepos(repo, orgToRepos)...)
+		}
+	}
+
+	var allPlugins []string
+	pluginHelp := map[string]pluginhelp.PluginHelp{}
+	for name, provider := range ha.ps.HelpProviders() {
+		allPlugins = append(allPlugins, name)
+		if provider == nil {
+			ha.log.Warnf("No help is provided for plugin %q.", name)
+			continue
+		}
+		help, err := provider(normalRevMap[name])
+		if err != nil {
+			ha.log.WithError(err).Errorf("Generating help from normal plugin %q.", name)
+			continue
+		}
+		help.Events = ha.ps.eventsForPlugin(name)
+		pluginHelp[name] = *help
+	}
+
+	allExternalPlugins, externalPluginHelp := helphook.GenerateExternalPluginHelp(ha.log, c.ExternalPlugins, externalRevMap)
+
+	repoPlugins := map[string][]string{
+		"": allPlugins,
+	}
+	for repo, plugins := range c.Plugins {
+		repoPlugins[repo] = plugins
+	}
+	repoExternalPlugins := map[string][]string{
+		"": allExternalPlugins,
+	}
+	for repo, exts := range c.ExternalPlugins {
+		for _, ext := range exts {
+			repoExternalPlugins[repo] = append(repoExternalPlugins[repo], ext.Name)
+		}
+	}
+
+	all := sets.NewString()
+	for repo := range c.Plugins {
+		all.Insert(repo)
+	}
+	for repo := range c.ExternalPlugins {
+		all.Insert(repo)
+	}
+	allRepos := sets.NewString()
+	for repo := range all {
+		for _, r := range expandRepos(repo, orgToRepos) {
+			allRepos.Insert(r.String())
+		}
+	}
+
+	return &pluginhelp.Help{
+		AllRepos:            allRepos.List(),
+		RepoPlugins:         repoPlugins,
+		RepoExternalPlugins: repoExternalPlugins,
+		PluginHelp:          pluginHelp,
+		ExternalPluginHelp:  externalPluginHelp,
+	}
+}
+
+// reversePluginMap inverts the Configurations.Plugins map and expands any org
+// strings to org/repo strings.
+func reversePluginMap(plugins map[string][]string, orgToRepos map[string]sets.String) map[string][]config.OrgRepo {
+	r := map[string][]config.OrgRepo{}
+	for repo, enabledPlugins := range plugins {
+		repos := expandRepos(repo, orgToRepos)
+		for _, plugin := range enabledPlugins {
+			r[plugin] = append(r[plugin], repos...)
+		}
+	}
+	return r
+}
+
+func expandRepos(repo string, orgToRepos map[string]sets.String) []config.OrgRepo {
+	if strings.Contains(repo, "/") {
+		return []config.OrgRepo{*config.NewOrgRepo(repo)}
+	}
+	return config.StringsToOrgRepos(orgToRepos[repo].List())
+}
+
+// orgToReposMap returns the repos of the orgs in the config. They are
+// cached to save the API tokens.
+func (ha *HelpAgent) orgToReposMap(c *Configurations) map[string]sets.String {
+	ha.lock.Lock()
+	defer ha.lock.Unlock()
+
+	orgs := sets.NewString()
+	for repo := range c.Plugins {
+		if !strings.Contains(repo, "/") {
+			orgs.Insert(repo)
+		}
+	}
+	for repo := range c.ExternalPlugins {
+		if !strings.Contains(repo, "/") {
+			orgs.Insert(repo)
+		}
+	}
+
+	if time.Now().Before(ha.nextSync) && orgs.Difference(ha.orgs).Len() == 0 {
+		return ha.orgToRepos
+	}
+
+	orgToRepos := map[string]sets.String{}
+	for _, org := range orgs.List() {
+		repos, err := ha.gc.GetRepos(org)
+		if err != nil {
+			ha.log.WithError(err).Errorf("Getting repos in org: %s.", org)
+			continue
+		}
+		repoSet := sets.NewString()
+		for _, repo := range repos {
+			repoSet.Insert(repo.FullName)
+		}
+		orgToRepos[org] = repoSet
+	}
+
+	ha.orgs = orgs
+	ha.orgToRepos = orgToRepos
+	ha.nextSync = time.Now().Add(newRepoDetectionLimit)
+	return orgToRepos
+}
+
+func (ha *HelpAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		ha.log.Errorf("Invalid request method: %v.", r.Method)
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := json.Marshal(ha.GeneratePluginHelp())
+	if err != nil {
+		ha.log.WithError(err).Error("Error marshaling plugin help.")
+		http.Error(w, fmt.Sprintf("500 Internal server error marshaling plugin help: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, string(b))
+}
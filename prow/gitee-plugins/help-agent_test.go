@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/pluginhelp"
+)
+
+func TestGeneratePluginHelp(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Repos["org"] = []sdk.Project{{FullName: "org/a"}, {FullName: "org/b"}}
+
+	var enabledRepos []config.OrgRepo
+	pm := NewPluginManager()
+	pm.RegisterHelper("hello", func(repos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+		enabledRepos = repos
+		return &pluginhelp.PluginHelp{Description: "hello"}, nil
+	})
+	pm.RegisterNoteEventHandler("hello", func(*sdk.NoteEvent, *logrus.Entry) error { return nil })
+	pm.RegisterPullRequestHandler("hello", func(*sdk.PullRequestEvent, *logrus.Entry) error { return nil })
+
+	ca := &ConfigAgent{c: &Configurations{
+		Plugins: map[string][]string{
+			"org":        {"hello"},
+			"other/repo": {"hello"},
+		},
+	}}
+	help := NewHelpAgent(ca, pm, fc).GeneratePluginHelp()
+
+	if expected := []string{"org/a", "org/b", "other/repo"}; !reflect.DeepEqual(help.AllRepos, expected) {
+		t.Errorf("expected the repos %v, got %v", expected, help.AllRepos)
+	}
+	if expected := []string{"hello"}; !reflect.DeepEqual(help.RepoPlugins[""], expected) {
+		t.Errorf("expected all the plugins %v, got %v", expected, help.RepoPlugins[""])
+	}
+	h, ok := help.PluginHelp["hello"]
+	if !ok {
+		t.Fatal("expected the help of the plugin")
+	}
+	if expected := []string{"Merge Request Hook", "Note Hook"}; !reflect.DeepEqual(h.Events, expected) {
+		t.Errorf("expected the events %v, got %v", expected, h.Events)
+	}
+	if len(enabledRepos) != 3 {
+		t.Errorf("expected the plugin to be enabled for 3 repos, got %v", enabledRepos)
+	}
+}
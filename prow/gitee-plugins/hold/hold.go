@@ -0,0 +1,81 @@
+package hold
+
+import (
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	originh "k8s.io/test-infra/prow/plugins/hold"
+)
+
+type githubClient interface {
+	AddLabel(owner, repo string, number int, label string) error
+	RemoveLabel(owner, repo string, number int, label string) error
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+}
+
+type hold struct {
+	getPluginConfig plugins.GetPluginConfig
+	ghc             githubClient
+}
+
+func NewHold(f plugins.GetPluginConfig, gec giteeClient) plugins.Plugin {
+	return &hold{
+		getPluginConfig: f,
+		ghc:             &ghclient{giteeClient: gec},
+	}
+}
+
+func (h *hold) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	return originh.HelpProvider(&originp.Configuration{}, enabledRepos)
+}
+
+func (h *hold) PluginName() string {
+	return originh.PluginName
+}
+
+func (h *hold) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (h *hold) RegisterEventHandler(p plugins.Plugins) {
+	name := h.PluginName()
+	p.RegisterNoteEventHandler(name, h.handleNoteEvent)
+}
+
+func (h *hold) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleNoteEvent")
+	}()
+
+	if *(e.NoteableType) != "PullRequest" {
+		log.Debug("Event is not a creation of a comment on a PR, skipping.")
+		return nil
+	}
+
+	if *(e.Action) != "comment" {
+		log.Debug("Event is not a creation of a comment on an open PR, skipping.")
+		return nil
+	}
+
+	ce := github.GenericCommentEvent{
+		Repo: github.Repo{
+			Owner: github.User{Login: e.Repository.Owner.Login},
+			Name:  e.Repository.Name,
+		},
+		Action:  github.GenericCommentActionCreated,
+		Body:    e.Comment.Body,
+		User:    github.User{Login: e.Comment.User.Login},
+		Number:  int(e.PullRequest.Number),
+		HTMLURL: e.Comment.HtmlUrl,
+		IsPR:    true,
+	}
+
+	return originh.Handle(h.ghc, log, &ce, github.HasLabel)
+}
@@ -0,0 +1,89 @@
+package hold
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/labels"
+)
+
+func TestHandleNoteEvent(t *testing.T) {
+	pr := fakegitee.PR{
+		Org:    "org",
+		Repo:   "repo",
+		Number: 5,
+		Author: "author",
+	}
+	holdLabel := "org/repo#5:" + labels.Hold
+
+	testCases := []struct {
+		name     string
+		comment  string
+		existing []string
+
+		expectAdded   []string
+		expectRemoved []string
+	}{
+		{
+			name:        "hold a PR",
+			comment:     "/hold",
+			expectAdded: []string{holdLabel},
+		},
+		{
+			name:     "hold a PR already held",
+			comment:  "/hold",
+			existing: []string{holdLabel},
+		},
+		{
+			name:          "cancel the hold",
+			comment:       "/hold cancel",
+			existing:      []string{holdLabel},
+			expectRemoved: []string{holdLabel},
+		},
+		{
+			name:    "cancel the hold of a PR not held",
+			comment: "/hold cancel",
+		},
+		{
+			name:    "unrelated comment",
+			comment: "hold on, I'll push a fix",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.PRLabelsExisting = tc.existing
+
+			h := NewHold(func(_, _, _ string) plugins.PluginConfig { return &configuration{} }, fc).(*hold)
+			e := fakegitee.NewPRNoteEvent(pr, "commenter", tc.comment)
+			if err := h.handleNoteEvent(e, logrus.WithField("plugin", "hold")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !sets.NewString(fc.PRLabelsAdded...).Equal(sets.NewString(tc.expectAdded...)) {
+				t.Errorf("expected labels added %v, got %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if !sets.NewString(fc.PRLabelsRemoved...).Equal(sets.NewString(tc.expectRemoved...)) {
+				t.Errorf("expected labels removed %v, got %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+		})
+	}
+}
+
+func TestHandleNoteEventSkipsIssues(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+
+	h := NewHold(func(_, _, _ string) plugins.PluginConfig { return &configuration{} }, fc).(*hold)
+	e := fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "commenter", "/hold")
+	if err := h.handleNoteEvent(e, logrus.WithField("plugin", "hold")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.PRLabelsAdded) > 0 || len(fc.IssueLabelsAdded) > 0 {
+		t.Errorf("expected no label on an issue, got %v and %v", fc.PRLabelsAdded, fc.IssueLabelsAdded)
+	}
+}
@@ -0,0 +1,218 @@
+package plugins
+
+import (
+	"fmt"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+// IssueRef identifies the PR or the issue a Gitee event is about. The
+// number of a PR is an int, but the one of an issue is a string like I1ABCD
+// which can't be passed to the plugins ported from GitHub. Those plugins are
+// given the number of the PR, or 0 for an issue, and IssueClient routes
+// their calls to the API of the PR or of the issue.
+type IssueRef struct {
+	Org  string
+	Repo string
+	// Number is the number of the PR, 0 for an issue.
+	Number int
+	// IssueNumber is the number of the issue, empty for a PR.
+	IssueNumber string
+}
+
+// IsPR returns true if the reference is a PR.
+func (r IssueRef) IsPR() bool {
+	return r.IssueNumber == ""
+}
+
+func (r IssueRef) String() string {
+	if r.IsPR() {
+		return fmt.Sprintf("%s/%s#%d", r.Org, r.Repo, r.Number)
+	}
+	return fmt.Sprintf("%s/%s#%s", r.Org, r.Repo, r.IssueNumber)
+}
+
+// issueState converts the state of a Gitee PR or issue to the open or
+// closed state of GitHub. A merged PR or a rejected issue is closed, an
+// issue in progress is open.
+func issueState(state string) string {
+	switch state {
+	case "closed", "merged", "rejected":
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// NoteToCommentEvent converts the creation of a comment on a PR or an
+// issue to a GenericCommentEvent. It returns false if the note is on
+// something else, like a commit.
+func NoteToCommentEvent(e *sdk.NoteEvent) (*github.GenericCommentEvent, IssueRef, bool) {
+	ref := IssueRef{Org: e.Repository.Owner.Login, Repo: e.Repository.Name}
+	ce := &github.GenericCommentEvent{
+		Repo: github.Repo{
+			Owner:    github.User{Login: ref.Org},
+			Name:     ref.Repo,
+			FullName: ref.Org + "/" + ref.Repo,
+		},
+		Body:    e.Comment.Body,
+		User:    github.User{Login: e.Comment.User.Login},
+		HTMLURL: e.Comment.HtmlUrl,
+	}
+	if *(e.Action) == "comment" {
+		ce.Action = github.GenericCommentActionCreated
+	}
+
+	switch *(e.NoteableType) {
+	case "PullRequest":
+		ref.Number = int(e.PullRequest.Number)
+		ce.IsPR = true
+		ce.Number = ref.Number
+		ce.IssueState = issueState(e.PullRequest.State)
+		ce.IssueAuthor = github.User{Login: e.PullRequest.User.Login}
+		ce.IssueBody = e.PullRequest.Body
+		ce.IssueHTMLURL = e.PullRequest.HtmlUrl
+	case "Issue":
+		ref.IssueNumber = e.Issue.Number
+		ce.IssueState = issueState(e.Issue.State)
+		ce.IssueAuthor = github.User{Login: e.Issue.User.Login}
+		ce.IssueBody = e.Issue.Body
+		ce.IssueHTMLURL = e.Issue.HtmlUrl
+	default:
+		return nil, ref, false
+	}
+	return ce, ref, true
+}
+
+// IssueToCommentEvent converts the opening of an issue to a
+// GenericCommentEvent whose body is the one of the issue, the way GitHub
+// handles the commands in the body of a new issue. It returns false for
+// the other actions.
+func IssueToCommentEvent(e *sdk.IssueEvent) (*github.GenericCommentEvent, IssueRef, bool) {
+	ref := IssueRef{
+		Org:         e.Repository.Owner.Login,
+		Repo:        e.Repository.Name,
+		IssueNumber: e.Issue.Number,
+	}
+	if *(e.Action) != "open" {
+		return nil, ref, false
+	}
+
+	return &github.GenericCommentEvent{
+		Repo: github.Repo{
+			Owner:    github.User{Login: ref.Org},
+			Name:     ref.Repo,
+			FullName: ref.Org + "/" + ref.Repo,
+		},
+		Action:       github.GenericCommentActionCreated,
+		Body:         e.Issue.Body,
+		User:         github.User{Login: e.Issue.User.Login},
+		HTMLURL:      e.Issue.HtmlUrl,
+		IssueState:   issueState(e.Issue.State),
+		IssueAuthor:  github.User{Login: e.Issue.User.Login},
+		IssueBody:    e.Issue.Body,
+		IssueHTMLURL: e.Issue.HtmlUrl,
+	}, ref, true
+}
+
+// IssueGiteeClient is the part of the Gitee client used by IssueClient.
+type IssueGiteeClient interface {
+	CreatePRComment(org, repo string, number int, comment string) error
+	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+	AddPRLabel(org, repo string, number int, label string) error
+	RemovePRLabel(org, repo string, number int, label string) error
+	ClosePR(org, repo string, number int) error
+	ReopenPR(org, repo string, number int) error
+
+	CreateGiteeIssueComment(org, repo string, number string, comment string) error
+	GetGiteeIssueLabels(org, repo, number string) ([]sdk.Label, error)
+	AddGiteeIssueLabel(org, repo, number, label string) error
+	RemoveGiteeIssueLabel(org, repo, number, label string) error
+	CloseGiteeIssue(org, repo, number string) error
+	ReopenGiteeIssue(org, repo, number string) error
+}
+
+// IssueClient implements the methods of the GitHub client which act on an
+// issue or a PR by its int number, for the PR or the issue of Ref. For a
+// PR, the number given by the caller is used, so that the client keeps
+// working for the other PRs of the repository.
+type IssueClient struct {
+	gc  IssueGiteeClient
+	Ref IssueRef
+}
+
+// NewIssueClient returns the IssueClient of the PR or the issue.
+func NewIssueClient(gc IssueGiteeClient, ref IssueRef) *IssueClient {
+	return &IssueClient{gc: gc, Ref: ref}
+}
+
+// CreateComment comments on the PR or the issue.
+func (c *IssueClient) CreateComment(org, repo string, number int, comment string) error {
+	if c.Ref.IsPR() {
+		return c.gc.CreatePRComment(org, repo, number, comment)
+	}
+	return c.gc.CreateGiteeIssueComment(org, repo, c.Ref.IssueNumber, comment)
+}
+
+// GetIssueLabels returns the labels of the PR or the issue.
+func (c *IssueClient) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	var v []sdk.Label
+	var err error
+	if c.Ref.IsPR() {
+		v, err = c.gc.GetPRLabels(org, repo, number)
+	} else {
+		v, err = c.gc.GetGiteeIssueLabels(org, repo, c.Ref.IssueNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]github.Label, 0, len(v))
+	for _, i := range v {
+		r = append(r, github.Label{Name: i.Name})
+	}
+	return r, nil
+}
+
+// AddLabel adds the label to the PR or the issue.
+func (c *IssueClient) AddLabel(org, repo string, number int, label string) error {
+	if c.Ref.IsPR() {
+		return c.gc.AddPRLabel(org, repo, number, label)
+	}
+	return c.gc.AddGiteeIssueLabel(org, repo, c.Ref.IssueNumber, label)
+}
+
+// RemoveLabel removes the label from the PR or the issue.
+func (c *IssueClient) RemoveLabel(org, repo string, number int, label string) error {
+	if c.Ref.IsPR() {
+		return c.gc.RemovePRLabel(org, repo, number, label)
+	}
+	return c.gc.RemoveGiteeIssueLabel(org, repo, c.Ref.IssueNumber, label)
+}
+
+// ClosePR closes the PR.
+func (c *IssueClient) ClosePR(org, repo string, number int) error {
+	return c.gc.ClosePR(org, repo, number)
+}
+
+// ReopenPR reopens the PR.
+func (c *IssueClient) ReopenPR(org, repo string, number int) error {
+	return c.gc.ReopenPR(org, repo, number)
+}
+
+// CloseIssue closes the issue of Ref.
+func (c *IssueClient) CloseIssue(org, repo string, number int) error {
+	if c.Ref.IsPR() {
+		return fmt.Errorf("%s is not an issue", c.Ref)
+	}
+	return c.gc.CloseGiteeIssue(org, repo, c.Ref.IssueNumber)
+}
+
+// ReopenIssue reopens the issue of Ref.
+func (c *IssueClient) ReopenIssue(org, repo string, number int) error {
+	if c.Ref.IsPR() {
+		return fmt.Errorf("%s is not an issue", c.Ref)
+	}
+	return c.gc.ReopenGiteeIssue(org, repo, c.Ref.IssueNumber)
+}
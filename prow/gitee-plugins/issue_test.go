@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestNoteToCommentEvent(t *testing.T) {
+	mergedPR := fakegitee.NewPRNoteEvent(fakegitee.PR{Org: "org", Repo: "repo", Number: 2, Author: "author"}, "someone", "/close")
+	mergedPR.PullRequest.State = "merged"
+
+	issue := fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "someone", "/close")
+	issue.Issue.State = "progressing"
+
+	testCases := []struct {
+		name  string
+		event *sdk.NoteEvent
+
+		expectedRef   IssueRef
+		expectedIsPR  bool
+		expectedState string
+	}{
+		{
+			name:          "merged PR is closed",
+			event:         mergedPR,
+			expectedRef:   IssueRef{Org: "org", Repo: "repo", Number: 2},
+			expectedIsPR:  true,
+			expectedState: "closed",
+		},
+		{
+			name:          "issue in progress is open",
+			event:         issue,
+			expectedRef:   IssueRef{Org: "org", Repo: "repo", IssueNumber: "I1ABCD"},
+			expectedState: "open",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ce, ref, ok := NoteToCommentEvent(tc.event)
+			if !ok {
+				t.Fatal("expected the note to be converted")
+			}
+			if !reflect.DeepEqual(ref, tc.expectedRef) {
+				t.Errorf("expected the ref %+v, got %+v", tc.expectedRef, ref)
+			}
+			if ce.IsPR != tc.expectedIsPR || ce.Number != tc.expectedRef.Number {
+				t.Errorf("expected IsPR %t and number %d, got %t and %d", tc.expectedIsPR, tc.expectedRef.Number, ce.IsPR, ce.Number)
+			}
+			if ce.IssueState != tc.expectedState {
+				t.Errorf("expected the state %s, got %s", tc.expectedState, ce.IssueState)
+			}
+			if ce.Action != github.GenericCommentActionCreated || ce.Body != "/close" || ce.User.Login != "someone" || ce.IssueAuthor.Login != "author" {
+				t.Errorf("unexpected event: %+v", ce)
+			}
+		})
+	}
+}
+
+func TestIssueToCommentEvent(t *testing.T) {
+	e := fakegitee.NewIssueEvent("org", "repo", "I1ABCD", "author", "open")
+	e.Issue.Body = "/assign"
+
+	ce, ref, ok := IssueToCommentEvent(e)
+	if !ok {
+		t.Fatal("expected the opening of the issue to be converted")
+	}
+	if expected := (IssueRef{Org: "org", Repo: "repo", IssueNumber: "I1ABCD"}); ref != expected {
+		t.Errorf("expected the ref %+v, got %+v", expected, ref)
+	}
+	if ce.IsPR || ce.Body != "/assign" || ce.User.Login != "author" || ce.IssueState != "open" {
+		t.Errorf("unexpected event: %+v", ce)
+	}
+
+	if _, _, ok := IssueToCommentEvent(fakegitee.NewIssueEvent("org", "repo", "I1ABCD", "author", "update")); ok {
+		t.Error("expected the update of the issue to be ignored")
+	}
+}
+
+func TestIssueClient(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.PRLabelsExisting = []string{"org/repo#2:pr-label"}
+	fc.IssueLabelsExisting = []string{"org/repo#I1ABCD:issue-label"}
+
+	pr := NewIssueClient(fc, IssueRef{Org: "org", Repo: "repo", Number: 2})
+	issue := NewIssueClient(fc, IssueRef{Org: "org", Repo: "repo", IssueNumber: "I1ABCD"})
+
+	for _, c := range []*IssueClient{pr, issue} {
+		if err := c.CreateComment("org", "repo", c.Ref.Number, "hello"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.AddLabel("org", "repo", c.Ref.Number, "new"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if expected := []string{"org/repo#2:hello"}; !reflect.DeepEqual(fc.PRCommentsAdded, expected) {
+		t.Errorf("expected the PR comments %v, got %v", expected, fc.PRCommentsAdded)
+	}
+	if expected := []string{"org/repo#I1ABCD:hello"}; !reflect.DeepEqual(fc.IssueCommentsAdded, expected) {
+		t.Errorf("expected the issue comments %v, got %v", expected, fc.IssueCommentsAdded)
+	}
+
+	labels, err := issue.GetIssueLabels("org", "repo", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []github.Label{{Name: "issue-label"}, {Name: "new"}}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected the issue labels %v, got %v", expected, labels)
+	}
+
+	if err := issue.CloseIssue("org", "repo", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"org/repo#I1ABCD"}; !reflect.DeepEqual(fc.IssuesClosed, expected) {
+		t.Errorf("expected the closed issues %v, got %v", expected, fc.IssuesClosed)
+	}
+	if err := pr.CloseIssue("org", "repo", 2); err == nil {
+		t.Error("expected closing a PR as an issue to fail")
+	}
+}
@@ -0,0 +1,56 @@
+package label
+
+import (
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+type giteeClient interface {
+	CreatePRComment(owner, repo string, number int, comment string) error
+	AddPRLabel(owner, repo string, number int, label string) error
+	RemovePRLabel(owner, repo string, number int, label string) error
+	GetRepoLabels(owner, repo string) ([]sdk.Label, error)
+	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+}
+
+var _ githubClient = (*ghclient)(nil)
+
+type ghclient struct {
+	giteeClient
+}
+
+func (c *ghclient) CreateComment(owner, repo string, number int, comment string) error {
+	return c.CreatePRComment(owner, repo, number, comment)
+}
+
+func (c *ghclient) AddLabel(org, repo string, number int, label string) error {
+	return c.AddPRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) RemoveLabel(org, repo string, number int, label string) error {
+	return c.RemovePRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) GetRepoLabels(owner, repo string) ([]github.Label, error) {
+	v, err := c.giteeClient.GetRepoLabels(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return convertLabels(v), nil
+}
+
+func (c *ghclient) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	v, err := c.GetPRLabels(org, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	return convertLabels(v), nil
+}
+
+func convertLabels(v []sdk.Label) []github.Label {
+	r := make([]github.Label, 0, len(v))
+	for _, i := range v {
+		r = append(r, github.Label{Name: i.Name})
+	}
+	return r
+}
@@ -0,0 +1,16 @@
+package label
+
+import (
+	originp "k8s.io/test-infra/prow/plugins"
+)
+
+type configuration struct {
+	Label originp.Label `json:"label,omitempty"`
+}
+
+func (c *configuration) Validate() error {
+	return nil
+}
+
+func (c *configuration) SetDefault() {
+}
@@ -0,0 +1,109 @@
+package label
+
+import (
+	"fmt"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	originl "k8s.io/test-infra/prow/plugins/label"
+)
+
+type githubClient interface {
+	CreateComment(owner, repo string, number int, comment string) error
+	AddLabel(owner, repo string, number int, label string) error
+	RemoveLabel(owner, repo string, number int, label string) error
+	GetRepoLabels(owner, repo string) ([]github.Label, error)
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+}
+
+type label struct {
+	getPluginConfig plugins.GetPluginConfig
+	ghc             githubClient
+}
+
+func NewLabel(f plugins.GetPluginConfig, gec giteeClient) plugins.Plugin {
+	return &label{
+		getPluginConfig: f,
+		ghc:             &ghclient{giteeClient: gec},
+	}
+}
+
+func (l *label) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	c, err := l.pluginConfig("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	c1 := originp.Configuration{Label: c.Label}
+
+	return originl.HelpProvider(&c1, enabledRepos)
+}
+
+func (l *label) PluginName() string {
+	return originl.PluginName
+}
+
+func (l *label) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (l *label) RegisterEventHandler(p plugins.Plugins) {
+	name := l.PluginName()
+	p.RegisterNoteEventHandler(name, l.handleNoteEvent)
+}
+
+func (l *label) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleNoteEvent")
+	}()
+
+	if *(e.NoteableType) != "PullRequest" {
+		log.Debug("Event is not a creation of a comment on a PR, skipping.")
+		return nil
+	}
+
+	if *(e.Action) != "comment" {
+		log.Debug("Event is not a creation of a comment on an open PR, skipping.")
+		return nil
+	}
+
+	c, err := l.pluginConfig(e.Repository.Owner.Login, e.Repository.Name)
+	if err != nil {
+		return err
+	}
+
+	ce := github.GenericCommentEvent{
+		Repo: github.Repo{
+			Owner: github.User{Login: e.Repository.Owner.Login},
+			Name:  e.Repository.Name,
+		},
+		Action:  github.GenericCommentActionCreated,
+		Body:    e.Comment.Body,
+		User:    github.User{Login: e.Comment.User.Login},
+		Number:  int(e.PullRequest.Number),
+		HTMLURL: e.Comment.HtmlUrl,
+		IsPR:    true,
+	}
+
+	return originl.Handle(l.ghc, log, c.Label.AdditionalLabels, &ce)
+}
+
+func (l *label) pluginConfig(org, repo string) (*configuration, error) {
+	c := l.getPluginConfig(l.PluginName(), org, repo)
+	if c == nil {
+		return nil, fmt.Errorf("can't find the label's configuration")
+	}
+
+	c1, ok := c.(*configuration)
+	if !ok {
+		return nil, fmt.Errorf("can't convert to label's configuration")
+	}
+	return c1, nil
+}
@@ -0,0 +1,114 @@
+package label
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	originp "k8s.io/test-infra/prow/plugins"
+)
+
+func TestHandleNoteEvent(t *testing.T) {
+	pr := fakegitee.PR{
+		Org:    "org",
+		Repo:   "repo",
+		Number: 5,
+		Author: "author",
+	}
+
+	testCases := []struct {
+		name             string
+		comment          string
+		existing         []string
+		additionalLabels []string
+
+		expectAdded   []string
+		expectRemoved []string
+		expectComment string
+	}{
+		{
+			name:        "add a kind label",
+			comment:     "/kind bug",
+			expectAdded: []string{"org/repo#5:kind/bug"},
+		},
+		{
+			name:        "add several priority labels",
+			comment:     "/priority high\n/kind feature",
+			expectAdded: []string{"org/repo#5:kind/feature", "org/repo#5:priority/high"},
+		},
+		{
+			name:          "remove a label",
+			comment:       "/remove-kind bug",
+			existing:      []string{"org/repo#5:kind/bug"},
+			expectRemoved: []string{"org/repo#5:kind/bug"},
+		},
+		{
+			name:          "label missing in the repository",
+			comment:       "/kind unknown",
+			expectComment: "the repository doesn't have them",
+		},
+		{
+			name:             "add a configured label",
+			comment:          "/label good-first-issue",
+			additionalLabels: []string{"good-first-issue"},
+			expectAdded:      []string{"org/repo#5:good-first-issue"},
+		},
+		{
+			name:    "generic labels are disabled without configuration",
+			comment: "/label good-first-issue",
+		},
+		{
+			name:    "unrelated comment",
+			comment: "looks fine",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.RepoLabelsExisting = []string{
+				"org/repo:kind/bug", "org/repo:kind/feature", "org/repo:priority/high", "org/repo:good-first-issue",
+			}
+			fc.PRLabelsExisting = tc.existing
+
+			c := &configuration{Label: originp.Label{AdditionalLabels: tc.additionalLabels}}
+			l := NewLabel(func(_, _, _ string) plugins.PluginConfig { return c }, fc).(*label)
+			e := fakegitee.NewPRNoteEvent(pr, "commenter", tc.comment)
+			if err := l.handleNoteEvent(e, logrus.WithField("plugin", "label")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !sets.NewString(fc.PRLabelsAdded...).Equal(sets.NewString(tc.expectAdded...)) {
+				t.Errorf("expected labels added %v, got %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if !sets.NewString(fc.PRLabelsRemoved...).Equal(sets.NewString(tc.expectRemoved...)) {
+				t.Errorf("expected labels removed %v, got %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+			if tc.expectComment == "" {
+				if len(fc.PRCommentsAdded) > 0 {
+					t.Errorf("unexpected comments: %v", fc.PRCommentsAdded)
+				}
+			} else if len(fc.PRCommentsAdded) != 1 || !strings.Contains(fc.PRCommentsAdded[0], tc.expectComment) {
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestHandleNoteEventSkipsIssues(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.RepoLabelsExisting = []string{"org/repo:kind/bug"}
+
+	l := NewLabel(func(_, _, _ string) plugins.PluginConfig { return &configuration{} }, fc).(*label)
+	e := fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "commenter", "/kind bug")
+	if err := l.handleNoteEvent(e, logrus.WithField("plugin", "label")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.PRLabelsAdded) > 0 || len(fc.PRCommentsAdded) > 0 {
+		t.Errorf("expected no action on an issue, got labels %v and comments %v", fc.PRLabelsAdded, fc.PRCommentsAdded)
+	}
+}
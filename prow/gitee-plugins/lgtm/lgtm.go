@@ -53,7 +53,7 @@ func NewLGTM(f plugins.GetPluginConfig, f1 getAllConf, gec giteeClient, oc repoo
 }
 
 func (lg *lgtm) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
-	c, err := lg.pluginConfig()
+	c, err := lg.pluginConfig("", "")
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func (lg *lgtm) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
 		return nil
 	}
 
-	c, err := lg.buildOriginConfig()
+	c, err := lg.buildOriginConfig(e.Repository.Owner.Login, e.Repository.Name)
 	if err != nil {
 		return err
 	}
@@ -142,7 +142,7 @@ func (lg *lgtm) handlePullRequestEvent(e *sdk.PullRequestEvent, log *logrus.Entr
 		return nil
 	}
 
-	c, err := lg.buildOriginConfig()
+	c, err := lg.buildOriginConfig(e.Repository.Owner.Login, e.Repository.Name)
 	if err != nil {
 		return err
 	}
@@ -159,8 +159,8 @@ func (lg *lgtm) handlePullRequestEvent(e *sdk.PullRequestEvent, log *logrus.Entr
 	return originl.HandlePullRequest(log, lg.ghc, c, &pe)
 }
 
-func (lg *lgtm) pluginConfig() (*configuration, error) {
-	c := lg.getPluginConfig(lg.PluginName())
+func (lg *lgtm) pluginConfig(org, repo string) (*configuration, error) {
+	c := lg.getPluginConfig(lg.PluginName(), org, repo)
 	if c == nil {
 		return nil, fmt.Errorf("can't find the lgtm's configuration")
 	}
@@ -172,8 +172,8 @@ func (lg *lgtm) pluginConfig() (*configuration, error) {
 	return c1, nil
 }
 
-func (lg *lgtm) buildOriginConfig() (*originp.Configuration, error) {
-	c, err := lg.pluginConfig()
+func (lg *lgtm) buildOriginConfig(org, repo string) (*originp.Configuration, error) {
+	c, err := lg.pluginConfig(org, repo)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,139 @@
+package lgtm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	originl "k8s.io/test-infra/prow/plugins/lgtm"
+)
+
+func newTestLGTM(fc *fakegitee.FakeClient) *lgtm {
+	c := &configuration{}
+	l := NewLGTM(
+		func(_, _, _ string) plugins.PluginConfig { return c },
+		func() *plugins.Configurations { return &plugins.Configurations{} },
+		fc, nil,
+	)
+	return l.(*lgtm)
+}
+
+func TestHandleNoteEvent(t *testing.T) {
+	pr := fakegitee.PR{
+		Org:       "org",
+		Repo:      "repo",
+		Number:    5,
+		Author:    "author",
+		BaseRef:   "master",
+		HeadSHA:   "sha",
+		Assignees: []string{"reviewer"},
+	}
+	lgtmLabel := fmt.Sprintf("org/repo#5:%s", originl.LGTMLabel)
+
+	testCases := []struct {
+		name          string
+		commenter     string
+		comment       string
+		hasLGTM       bool
+		collaborators []string
+
+		expectAdded    bool
+		expectRemoved  bool
+		expectAssigned bool
+		expectComment  string
+	}{
+		{
+			name:          "collaborator adds lgtm",
+			commenter:     "reviewer",
+			comment:       "/lgtm",
+			collaborators: []string{"reviewer"},
+			expectAdded:   true,
+		},
+		{
+			name:           "collaborator not assigned is assigned and adds lgtm",
+			commenter:      "other",
+			comment:        "/lgtm",
+			collaborators:  []string{"other"},
+			expectAdded:    true,
+			expectAssigned: true,
+		},
+		{
+			name:          "collaborator cancels lgtm",
+			commenter:     "reviewer",
+			comment:       "/lgtm cancel",
+			hasLGTM:       true,
+			collaborators: []string{"reviewer"},
+			expectRemoved: true,
+		},
+		{
+			name:          "non collaborator can't add lgtm",
+			commenter:     "stranger",
+			comment:       "/lgtm",
+			expectComment: "changing LGTM is restricted to collaborators",
+		},
+		{
+			name:          "author can't lgtm own PR",
+			commenter:     "author",
+			comment:       "/lgtm",
+			collaborators: []string{"author"},
+			expectComment: "you cannot LGTM your own PR.",
+		},
+		{
+			name:          "unrelated comment",
+			commenter:     "reviewer",
+			comment:       "looks fine",
+			collaborators: []string{"reviewer"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.Collaborators = tc.collaborators
+			if tc.hasLGTM {
+				fc.PRLabelsExisting = []string{lgtmLabel}
+			}
+
+			e := fakegitee.NewPRNoteEvent(pr, tc.commenter, tc.comment)
+			if err := newTestLGTM(fc).handleNoteEvent(e, logrus.WithField("plugin", "lgtm")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if added := sets.NewString(fc.PRLabelsAdded...).Has(lgtmLabel); added != tc.expectAdded {
+				t.Errorf("expected lgtm label added: %t, got labels added: %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if removed := sets.NewString(fc.PRLabelsRemoved...).Has(lgtmLabel); removed != tc.expectRemoved {
+				t.Errorf("expected lgtm label removed: %t, got labels removed: %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+			if assigned := len(fc.AssigneesAdded) > 0; assigned != tc.expectAssigned {
+				t.Errorf("expected assigned: %t, got assignees added: %v", tc.expectAssigned, fc.AssigneesAdded)
+			}
+
+			if tc.expectComment == "" {
+				if len(fc.PRCommentsAdded) > 0 {
+					t.Errorf("unexpected comments: %v", fc.PRCommentsAdded)
+				}
+			} else if len(fc.PRCommentsAdded) != 1 || !strings.Contains(fc.PRCommentsAdded[0], tc.expectComment) {
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestHandleNoteEventSkipsIssues(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.Collaborators = []string{"reviewer"}
+
+	e := fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", "reviewer", "/lgtm")
+	if err := newTestLGTM(fc).handleNoteEvent(e, logrus.WithField("plugin", "lgtm")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.PRLabelsAdded) > 0 || len(fc.PRCommentsAdded) > 0 {
+		t.Errorf("expected no action on an issue, got labels %v and comments %v", fc.PRLabelsAdded, fc.PRCommentsAdded)
+	}
+}
@@ -0,0 +1,21 @@
+package lifecycle
+
+import (
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+)
+
+type giteeClient interface {
+	plugins.IssueGiteeClient
+	IsCollaborator(owner, repo, login string) (bool, error)
+}
+
+var _ githubClient = (*ghclient)(nil)
+
+type ghclient struct {
+	*plugins.IssueClient
+	gec giteeClient
+}
+
+func (c *ghclient) IsCollaborator(owner, repo, login string) (bool, error) {
+	return c.gec.IsCollaborator(owner, repo, login)
+}
@@ -0,0 +1,11 @@
+package lifecycle
+
+type configuration struct {
+}
+
+func (c *configuration) Validate() error {
+	return nil
+}
+
+func (c *configuration) SetDefault() {
+}
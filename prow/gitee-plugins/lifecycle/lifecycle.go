@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	originl "k8s.io/test-infra/prow/plugins/lifecycle"
+)
+
+type githubClient interface {
+	IsCollaborator(owner, repo, login string) (bool, error)
+	CreateComment(owner, repo string, number int, comment string) error
+	CloseIssue(owner, repo string, number int) error
+	ClosePR(owner, repo string, number int) error
+	ReopenIssue(owner, repo string, number int) error
+	ReopenPR(owner, repo string, number int) error
+	AddLabel(owner, repo string, number int, label string) error
+	RemoveLabel(owner, repo string, number int, label string) error
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+}
+
+type lifecycle struct {
+	getPluginConfig plugins.GetPluginConfig
+	gec             giteeClient
+}
+
+func NewLifecycle(f plugins.GetPluginConfig, gec giteeClient) plugins.Plugin {
+	return &lifecycle{
+		getPluginConfig: f,
+		gec:             gec,
+	}
+}
+
+func (l *lifecycle) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	return originl.HelpProvider(&originp.Configuration{}, enabledRepos)
+}
+
+func (l *lifecycle) PluginName() string {
+	return "lifecycle"
+}
+
+func (l *lifecycle) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (l *lifecycle) RegisterEventHandler(p plugins.Plugins) {
+	name := l.PluginName()
+	p.RegisterNoteEventHandler(name, l.handleNoteEvent)
+	p.RegisterIssueHandler(name, l.handleIssueEvent)
+}
+
+func (l *lifecycle) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleNoteEvent")
+	}()
+
+	ce, ref, ok := plugins.NoteToCommentEvent(e)
+	if !ok {
+		log.Debug("not supported note type")
+		return nil
+	}
+	return l.handle(ce, ref, log)
+}
+
+func (l *lifecycle) handleIssueEvent(e *sdk.IssueEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleIssueEvent")
+	}()
+
+	ce, ref, ok := plugins.IssueToCommentEvent(e)
+	if !ok {
+		log.Debug("Event is not an opening of an issue, skipping.")
+		return nil
+	}
+	return l.handle(ce, ref, log)
+}
+
+func (l *lifecycle) handle(ce *github.GenericCommentEvent, ref plugins.IssueRef, log *logrus.Entry) error {
+	gc := &ghclient{IssueClient: plugins.NewIssueClient(l.gec, ref), gec: l.gec}
+
+	if err := originl.HandleReopen(gc, log, ce); err != nil {
+		return err
+	}
+	if err := originl.HandleClose(gc, log, ce); err != nil {
+		return err
+	}
+	return originl.Handle(gc, log, ce)
+}
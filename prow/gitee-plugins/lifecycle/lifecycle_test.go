@@ -0,0 +1,233 @@
+package lifecycle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/labels"
+)
+
+func newLifecycle(fc *fakegitee.FakeClient) *lifecycle {
+	return NewLifecycle(func(_, _, _ string) plugins.PluginConfig { return &configuration{} }, fc).(*lifecycle)
+}
+
+func TestHandlePRNoteEvent(t *testing.T) {
+	pr := fakegitee.PR{Org: "org", Repo: "repo", Number: 1, Author: "author"}
+
+	testCases := []struct {
+		name      string
+		commenter string
+		comment   string
+		state     string
+		existing  []string
+
+		expectAdded   []string
+		expectRemoved []string
+		expectState   string
+		expectComment string
+	}{
+		{
+			name:          "lifecycle label replaces the other lifecycle labels",
+			commenter:     "someone",
+			comment:       "/lifecycle frozen",
+			existing:      []string{"org/repo#1:" + labels.LifecycleStale},
+			expectAdded:   []string{"org/repo#1:" + labels.LifecycleFrozen},
+			expectRemoved: []string{"org/repo#1:" + labels.LifecycleStale},
+			expectState:   "open",
+		},
+		{
+			name:          "remove lifecycle label",
+			commenter:     "someone",
+			comment:       "/remove-lifecycle stale",
+			existing:      []string{"org/repo#1:" + labels.LifecycleStale},
+			expectRemoved: []string{"org/repo#1:" + labels.LifecycleStale},
+			expectState:   "open",
+		},
+		{
+			name:          "author closes the PR",
+			commenter:     "author",
+			comment:       "/close",
+			expectState:   "closed",
+			expectComment: "Closed this PR.",
+		},
+		{
+			name:          "others can't close an active PR",
+			commenter:     "someone",
+			comment:       "/close",
+			expectState:   "open",
+			expectComment: "You can't close an active issue/PR",
+		},
+		{
+			name:          "collaborator reopens the PR",
+			commenter:     "collaborator",
+			comment:       "/reopen",
+			state:         "closed",
+			expectState:   "open",
+			expectComment: "Reopened this PR.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := tc.state
+			if state == "" {
+				state = "open"
+			}
+			fc := fakegitee.NewFakeClient()
+			fc.Collaborators = []string{"collaborator"}
+			fc.PRLabelsExisting = tc.existing
+			fc.PullRequests[1] = &sdk.PullRequest{Number: 1, State: state}
+
+			e := fakegitee.NewPRNoteEvent(pr, tc.commenter, tc.comment)
+			e.PullRequest.State = state
+			if err := newLifecycle(fc).handleNoteEvent(e, logrus.WithField("plugin", "lifecycle")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(fc.PRLabelsAdded, tc.expectAdded) {
+				t.Errorf("expected the labels %v to be added, got %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if !reflect.DeepEqual(fc.PRLabelsRemoved, tc.expectRemoved) {
+				t.Errorf("expected the labels %v to be removed, got %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+			if s := fc.PullRequests[1].State; s != tc.expectState {
+				t.Errorf("expected the PR to be %s, got %s", tc.expectState, s)
+			}
+			switch {
+			case tc.expectComment == "" && len(fc.PRCommentsAdded) != 0:
+				t.Errorf("unexpected comments: %v", fc.PRCommentsAdded)
+			case tc.expectComment != "" && (len(fc.PRCommentsAdded) != 1 || !strings.Contains(fc.PRCommentsAdded[0], tc.expectComment)):
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestHandleIssueNoteEvent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		commenter string
+		comment   string
+		state     string
+		existing  []string
+
+		expectAdded    []string
+		expectRemoved  []string
+		expectClosed   []string
+		expectReopened []string
+		expectComment  string
+	}{
+		{
+			name:          "lifecycle label replaces the other lifecycle labels",
+			commenter:     "someone",
+			comment:       "/lifecycle rotten",
+			existing:      []string{"org/repo#I1ABCD:" + labels.LifecycleStale},
+			expectAdded:   []string{"org/repo#I1ABCD:" + labels.LifecycleRotten},
+			expectRemoved: []string{"org/repo#I1ABCD:" + labels.LifecycleStale},
+		},
+		{
+			name:          "author closes the issue",
+			commenter:     "author",
+			comment:       "/close",
+			expectClosed:  []string{"org/repo#I1ABCD"},
+			expectComment: "Closing this issue.",
+		},
+		{
+			name:          "anyone closes a rotten issue",
+			commenter:     "someone",
+			comment:       "/close",
+			existing:      []string{"org/repo#I1ABCD:" + labels.LifecycleRotten},
+			expectClosed:  []string{"org/repo#I1ABCD"},
+			expectComment: "Closing this issue.",
+		},
+		{
+			name:          "others can't reopen the issue",
+			commenter:     "someone",
+			comment:       "/reopen",
+			state:         "closed",
+			expectComment: "You can't reopen an issue/PR",
+		},
+		{
+			name:           "rejected issue is reopened by its author",
+			commenter:      "author",
+			comment:        "/reopen",
+			state:          "rejected",
+			expectReopened: []string{"org/repo#I1ABCD"},
+			expectComment:  "Reopened this issue.",
+		},
+		{
+			name:      "reopening an issue in progress does nothing",
+			commenter: "author",
+			comment:   "/reopen",
+			state:     "progressing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			fc.IssueLabelsExisting = tc.existing
+
+			e := fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", tc.commenter, tc.comment)
+			if tc.state != "" {
+				e.Issue.State = tc.state
+			}
+			if err := newLifecycle(fc).handleNoteEvent(e, logrus.WithField("plugin", "lifecycle")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(fc.IssueLabelsAdded, tc.expectAdded) {
+				t.Errorf("expected the labels %v to be added, got %v", tc.expectAdded, fc.IssueLabelsAdded)
+			}
+			if !reflect.DeepEqual(fc.IssueLabelsRemoved, tc.expectRemoved) {
+				t.Errorf("expected the labels %v to be removed, got %v", tc.expectRemoved, fc.IssueLabelsRemoved)
+			}
+			if !reflect.DeepEqual(fc.IssuesClosed, tc.expectClosed) {
+				t.Errorf("expected the issues %v to be closed, got %v", tc.expectClosed, fc.IssuesClosed)
+			}
+			if !reflect.DeepEqual(fc.IssuesReopened, tc.expectReopened) {
+				t.Errorf("expected the issues %v to be reopened, got %v", tc.expectReopened, fc.IssuesReopened)
+			}
+			if len(fc.PRCommentsAdded) != 0 {
+				t.Errorf("unexpected comments on PRs: %v", fc.PRCommentsAdded)
+			}
+			switch {
+			case tc.expectComment == "" && len(fc.IssueCommentsAdded) != 0:
+				t.Errorf("unexpected comments: %v", fc.IssueCommentsAdded)
+			case tc.expectComment != "" && (len(fc.IssueCommentsAdded) != 1 || !strings.Contains(fc.IssueCommentsAdded[0], tc.expectComment)):
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.IssueCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestHandleIssueEvent(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	l := newLifecycle(fc)
+
+	e := fakegitee.NewIssueEvent("org", "repo", "I1ABCD", "author", "open")
+	e.Issue.Body = "It fails.\n/lifecycle frozen"
+	if err := l.handleIssueEvent(e, logrus.WithField("plugin", "lifecycle")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"org/repo#I1ABCD:" + labels.LifecycleFrozen}; !reflect.DeepEqual(fc.IssueLabelsAdded, expected) {
+		t.Errorf("expected the labels %v to be added, got %v", expected, fc.IssueLabelsAdded)
+	}
+
+	// The other actions are ignored.
+	fc.IssueLabelsAdded = nil
+	e = fakegitee.NewIssueEvent("org", "repo", "I1ABCD", "author", "update")
+	e.Issue.Body = "/lifecycle frozen"
+	if err := l.handleIssueEvent(e, logrus.WithField("plugin", "lifecycle")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fc.IssueLabelsAdded) != 0 {
+		t.Errorf("unexpected labels added: %v", fc.IssueLabelsAdded)
+	}
+}
@@ -0,0 +1,31 @@
+package plugins
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queueDepthGauge provides the 'gitee_plugin_queue_depth' gauge that keeps
+// track of the events waiting for a worker by plugin.
+var queueDepthGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "gitee_plugin_queue_depth",
+		Help: "How many events are waiting for a worker by plugin.",
+	},
+	[]string{"plugin"},
+)
+
+// handleDurationHistogram provides the 'gitee_plugin_handle_duration_seconds'
+// histogram that keeps track of the time the plugins take to handle an event.
+var handleDurationHistogram = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "gitee_plugin_handle_duration_seconds",
+		Help:    "How long the plugins took to handle an event by event type and plugin.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 80, 160, 320, 640},
+	},
+	[]string{"event_type", "plugin"},
+)
+
+func init() {
+	prometheus.MustRegister(queueDepthGauge)
+	prometheus.MustRegister(handleDurationHistogram)
+}
@@ -12,4 +12,7 @@ type Plugin interface {
 	HelpProvider(enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error)
 }
 
-type GetPluginConfig func(string) PluginConfig
+// GetPluginConfig returns the configuration of the plugin for the org/repo,
+// see Configurations.PluginConfigFor. An empty org returns the global
+// configuration.
+type GetPluginConfig func(name, org, repo string) PluginConfig
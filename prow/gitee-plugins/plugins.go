@@ -79,3 +79,22 @@ func (p *plugins) RegisterNoteEventHandler(name string, fn NoteEventHandler) {
 func (p *plugins) HelpProviders() map[string]HelpProvider {
 	return p.pluginHelp
 }
+
+// eventsForPlugin returns the Gitee events handled by the plugin, as named
+// by the X-Gitee-Event header.
+func (p *plugins) eventsForPlugin(name string) []string {
+	var events []string
+	if _, ok := p.issueHandlers[name]; ok {
+		events = append(events, "Issue Hook")
+	}
+	if _, ok := p.pullRequestHandlers[name]; ok {
+		events = append(events, "Merge Request Hook")
+	}
+	if _, ok := p.pushEventHandlers[name]; ok {
+		events = append(events, "Push Hook")
+	}
+	if _, ok := p.noteEventHandlers[name]; ok {
+		events = append(events, "Note Hook")
+	}
+	return events
+}
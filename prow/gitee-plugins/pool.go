@@ -0,0 +1,117 @@
+package plugins
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// task is an event to be handled by the plugins.
+type task struct {
+	eventType string
+	// handlers run the plugins of the event, keyed by plugin name.
+	handlers map[string]func() error
+	// done is called with the errors of the plugins which failed, once all
+	// of them ran.
+	done func(map[string]error)
+}
+
+// workerPool runs the plugins of the events, with at most size plugins
+// running at once. The events with the same key, like those of a PR, are
+// handled one after the other in the order they were submitted, so that
+// two /lgtm comments on a PR don't race on its labels. The events with
+// different keys are handled concurrently.
+type workerPool struct {
+	slots chan struct{}
+
+	lock sync.Mutex
+	// queues are the tasks of each key, the first one being run.
+	queues map[string][]*task
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{
+		slots:  make(chan struct{}, size),
+		queues: map[string][]*task{},
+	}
+}
+
+// submit queues the task after the other tasks of the key. It never blocks.
+func (p *workerPool) submit(key string, t *task) {
+	if len(t.handlers) == 0 {
+		if t.done != nil {
+			t.done(map[string]error{})
+		}
+		return
+	}
+
+	for name := range t.handlers {
+		queueDepthGauge.With(prometheus.Labels{"plugin": name}).Inc()
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.queues[key] = append(p.queues[key], t)
+	if len(p.queues[key]) == 1 {
+		go p.drain(key)
+	}
+}
+
+// drain runs the tasks of the key until there is none left.
+func (p *workerPool) drain(key string) {
+	for {
+		p.lock.Lock()
+		t := p.queues[key][0]
+		p.lock.Unlock()
+
+		p.run(t)
+
+		p.lock.Lock()
+		q := p.queues[key][1:]
+		if len(q) == 0 {
+			delete(p.queues, key)
+			p.lock.Unlock()
+			return
+		}
+		p.queues[key] = q
+		p.lock.Unlock()
+	}
+}
+
+// run runs the plugins of the task, each one in a worker, and waits for
+// them.
+func (p *workerPool) run(t *task) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	errs := map[string]error{}
+
+	for name, f := range t.handlers {
+		p.slots <- struct{}{}
+		queueDepthGauge.With(prometheus.Labels{"plugin": name}).Dec()
+
+		wg.Add(1)
+		go func(name string, f func() error) {
+			defer func() {
+				<-p.slots
+				wg.Done()
+			}()
+
+			start := time.Now()
+			err := f()
+			handleDurationHistogram.With(prometheus.Labels{"event_type": t.eventType, "plugin": name}).Observe(time.Since(start).Seconds())
+
+			if err != nil {
+				lock.Lock()
+				errs[name] = err
+				lock.Unlock()
+			}
+		}(name, f)
+	}
+	wg.Wait()
+
+	if t.done != nil {
+		t.done(errs)
+	}
+}
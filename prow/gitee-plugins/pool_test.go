@@ -0,0 +1,104 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolKeepsTheOrderOfAKey(t *testing.T) {
+	p := newWorkerPool(4)
+
+	var lock sync.Mutex
+	handled := map[string][]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"org/repo#1", "org/repo#2"} {
+			i, key := i, key
+			wg.Add(1)
+			p.submit(key, &task{
+				eventType: "Note Hook",
+				handlers: map[string]func() error{
+					"lgtm": func() error {
+						time.Sleep(time.Millisecond)
+						lock.Lock()
+						defer lock.Unlock()
+						handled[key] = append(handled[key], i)
+						return nil
+					},
+				},
+				done: func(map[string]error) { wg.Done() },
+			})
+		}
+	}
+	wg.Wait()
+
+	var expected []int
+	for i := 0; i < 20; i++ {
+		expected = append(expected, i)
+	}
+	for _, key := range []string{"org/repo#1", "org/repo#2"} {
+		if !reflect.DeepEqual(handled[key], expected) {
+			t.Errorf("expected the events of %s to be handled in order, got %v", key, handled[key])
+		}
+	}
+}
+
+func TestWorkerPoolBoundsTheConcurrency(t *testing.T) {
+	p := newWorkerPool(2)
+
+	var lock sync.Mutex
+	running, max := 0, 0
+	handler := func() error {
+		lock.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		p.submit(fmt.Sprintf("org/repo#%d", i), &task{
+			eventType: "Note Hook",
+			handlers:  map[string]func() error{"lgtm": handler, "approve": handler},
+			done:      func(map[string]error) { wg.Done() },
+		})
+	}
+	wg.Wait()
+
+	if max > 2 {
+		t.Errorf("expected at most 2 plugins running at once, got %d", max)
+	}
+}
+
+func TestWorkerPoolReturnsTheErrors(t *testing.T) {
+	p := newWorkerPool(2)
+
+	result := make(chan map[string]error, 1)
+	p.submit("org/repo#1", &task{
+		eventType: "Note Hook",
+		handlers: map[string]func() error{
+			"lgtm":    func() error { return errors.New("transient failure") },
+			"approve": func() error { return nil },
+		},
+		done: func(errs map[string]error) { result <- errs },
+	})
+
+	errs := <-result
+	if len(errs) != 1 || errs["lgtm"] == nil {
+		t.Errorf("expected only lgtm to fail, got %v", errs)
+	}
+}
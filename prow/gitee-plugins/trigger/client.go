@@ -0,0 +1,134 @@
+package trigger
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+type giteeClient interface {
+	BotName() (string, error)
+	IsCollaborator(owner, repo, login string) (bool, error)
+	IsMember(org, login string) (bool, error)
+	GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error)
+	GetRef(org, repo, ref string) (string, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
+	DeletePRComment(org, repo string, ID int) error
+	CreatePRComment(org, repo string, number int, comment string) error
+	AddPRLabel(org, repo string, number int, label string) error
+	RemovePRLabel(org, repo string, number int, label string) error
+}
+
+type prowJobLister interface {
+	List(opts metav1.ListOptions) (*prowapi.ProwJobList, error)
+}
+
+type ghclient struct {
+	giteeClient
+	pjl prowJobLister
+	// number is the PR of the event being handled. It narrows down the
+	// ProwJobs listed to build the combined status.
+	number int
+}
+
+func (c *ghclient) AddLabel(org, repo string, number int, label string) error {
+	return c.AddPRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) RemoveLabel(org, repo string, number int, label string) error {
+	return c.RemovePRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) CreateComment(owner, repo string, number int, comment string) error {
+	return c.CreatePRComment(owner, repo, number, comment)
+}
+
+func (c *ghclient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	v, err := c.GetGiteePullRequest(org, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return gitee.ConvertGiteePR(&v), nil
+}
+
+func (c *ghclient) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	var r []github.Label
+
+	v, err := c.GetPRLabels(org, repo, number)
+	if err != nil {
+		return r, err
+	}
+
+	for _, i := range v {
+		r = append(r, github.Label{Name: i.Name})
+	}
+	return r, nil
+}
+
+func (c *ghclient) ListIssueComments(org, repo string, number int) ([]github.IssueComment, error) {
+	var r []github.IssueComment
+
+	v, err := c.ListPRComments(org, repo, number)
+	if err != nil {
+		return r, err
+	}
+
+	for _, i := range v {
+		r = append(r, gitee.ConvertGiteePRComment(i))
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].CreatedAt.Before(r[j].CreatedAt)
+	})
+
+	return r, nil
+}
+
+func (c *ghclient) DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error {
+	if comments == nil {
+		var err error
+		comments, err = c.ListIssueComments(org, repo, number)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, comment := range comments {
+		if !isStale(comment) {
+			continue
+		}
+		if err := c.DeletePRComment(org, repo, comment.ID); err != nil {
+			return fmt.Errorf("failed to delete stale comment with ID '%d'", comment.ID)
+		}
+	}
+	return nil
+}
+
+// CreateStatus does nothing, because Gitee has no commit status API.
+// The results of jobs are reported to the pull request by crier.
+func (c *ghclient) CreateStatus(owner, repo, ref string, status github.Status) error {
+	return nil
+}
+
+// GetCombinedStatus builds the combined status of a commit from the
+// presubmit ProwJobs of the PR that ran against it.
+func (c *ghclient) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
+	list := func(set labels.Set) ([]prowapi.ProwJob, error) {
+		jobs, err := c.pjl.List(metav1.ListOptions{LabelSelector: labels.SelectorFromSet(set).String()})
+		if err != nil {
+			return nil, err
+		}
+		return jobs.Items, nil
+	}
+	return gitee.CombinedStatus(list, org, repo, c.number, ref)
+}
@@ -0,0 +1,33 @@
+package trigger
+
+import (
+	"fmt"
+
+	originp "k8s.io/test-infra/prow/plugins"
+)
+
+type configuration struct {
+	Triggers []originp.Trigger `json:"triggers,omitempty"`
+}
+
+func (c *configuration) Validate() error {
+	return nil
+}
+
+func (c *configuration) SetDefault() {
+	for i := range c.Triggers {
+		t := &c.Triggers[i]
+		if t.TrustedOrg != "" && t.JoinOrgURL == "" {
+			t.JoinOrgURL = fmt.Sprintf("https://gitee.com/%s", t.TrustedOrg)
+		}
+		t.SetDefaults()
+	}
+}
+
+// TriggerFor finds the Trigger for a repo, if one exists
+// a trigger can be listed for the repo itself or for the
+// owning organization
+func (c *configuration) TriggerFor(org, repo string) originp.Trigger {
+	c1 := originp.Configuration{Triggers: c.Triggers}
+	return c1.TriggerFor(org, repo)
+}
@@ -0,0 +1,237 @@
+package trigger
+
+import (
+	"fmt"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowConfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/v2"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	origint "k8s.io/test-infra/prow/plugins/trigger"
+)
+
+type githubClient interface {
+	AddLabel(org, repo string, number int, label string) error
+	BotName() (string, error)
+	IsCollaborator(org, repo, user string) (bool, error)
+	IsMember(org, user string) (bool, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetRef(org, repo, ref string) (string, error)
+	CreateComment(owner, repo string, number int, comment string) error
+	ListIssueComments(owner, repo string, issue int) ([]github.IssueComment, error)
+	CreateStatus(owner, repo, ref string, status github.Status) error
+	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	RemoveLabel(org, repo string, number int, label string) error
+	DeleteStaleComments(org, repo string, number int, comments []github.IssueComment, isStale func(github.IssueComment) bool) error
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+}
+
+var _ githubClient = (*ghclient)(nil)
+
+type prowJobClient interface {
+	Create(*prowapi.ProwJob) (*prowapi.ProwJob, error)
+	List(opts metav1.ListOptions) (*prowapi.ProwJobList, error)
+	Update(*prowapi.ProwJob) (*prowapi.ProwJob, error)
+}
+
+type getProwConfig func() *prowConfig.Config
+
+type trigger struct {
+	getPluginConfig plugins.GetPluginConfig
+	gec             giteeClient
+	pjc             prowJobClient
+	gitClient       git.ClientFactory
+	getProwConfig   getProwConfig
+}
+
+func NewTrigger(f plugins.GetPluginConfig, f1 getProwConfig, gec giteeClient, pjc prowJobClient, gitClient git.ClientFactory) plugins.Plugin {
+	return &trigger{
+		getPluginConfig: f,
+		gec:             gec,
+		pjc:             pjc,
+		gitClient:       gitClient,
+		getProwConfig:   f1,
+	}
+}
+
+func (t *trigger) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	c, err := t.pluginConfig("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	c1 := originp.Configuration{Triggers: c.Triggers}
+
+	return origint.HelpProvider(&c1, enabledRepos)
+}
+
+func (t *trigger) PluginName() string {
+	return origint.PluginName
+}
+
+func (t *trigger) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (t *trigger) RegisterEventHandler(p plugins.Plugins) {
+	name := t.PluginName()
+	p.RegisterNoteEventHandler(name, t.handleNoteEvent)
+	p.RegisterPullRequestHandler(name, t.handlePullRequestEvent)
+}
+
+func (t *trigger) handleNoteEvent(e *sdk.NoteEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handleNoteEvent")
+	}()
+
+	if *(e.NoteableType) != "PullRequest" {
+		log.Debug("Event is not a creation of a comment on a PR, skipping.")
+		return nil
+	}
+
+	if *(e.Action) != "comment" {
+		log.Debug("Event is not a creation of a comment on an open PR, skipping.")
+		return nil
+	}
+
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+
+	c, err := t.pluginConfig(org, repo)
+	if err != nil {
+		return err
+	}
+
+	pr := e.PullRequest
+
+	gc := github.GenericCommentEvent{
+		IsPR:    true,
+		Action:  github.GenericCommentActionCreated,
+		Body:    e.Comment.Body,
+		HTMLURL: e.Comment.HtmlUrl,
+		Number:  int(pr.Number),
+		Repo: github.Repo{
+			Owner:    github.User{Login: org},
+			Name:     repo,
+			FullName: e.Repository.FullName,
+			HTMLURL:  e.Repository.HtmlUrl,
+		},
+		User:        github.User{Login: e.Comment.User.Login},
+		IssueAuthor: github.User{Login: pr.User.Login},
+		IssueState:  pr.State,
+		GUID:        eventGUID(log),
+	}
+
+	return origint.HandleGenericComment(t.buildOriginClient(log, gc.Number), c.TriggerFor(org, repo), gc)
+}
+
+func (t *trigger) handlePullRequestEvent(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handlePullRequest")
+	}()
+
+	action := convertPullRequestAction(e)
+	if action == "" {
+		log.Debug("Pull request event can't trigger jobs, skipping...")
+		return nil
+	}
+
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+
+	c, err := t.pluginConfig(org, repo)
+	if err != nil {
+		return err
+	}
+
+	pr := e.PullRequest
+
+	var pe github.PullRequestEvent
+	pe.Action = action
+	pe.GUID = eventGUID(log)
+	pe.Number = int(pr.Number)
+	pe.PullRequest.Number = int(pr.Number)
+	pe.PullRequest.HTMLURL = pr.HtmlUrl
+	pe.PullRequest.State = pr.State
+	pe.PullRequest.User.Login = pr.User.Login
+	pe.PullRequest.User.HTMLURL = pr.User.HtmlUrl
+	pe.PullRequest.Head.SHA = pr.Head.Sha
+	pe.PullRequest.Head.Ref = pr.Head.Ref
+	pe.PullRequest.Base.Ref = pr.Base.Ref
+	pe.PullRequest.Base.SHA = pr.Base.Sha
+	pe.PullRequest.Base.Repo.Owner.Login = org
+	pe.PullRequest.Base.Repo.Name = repo
+	pe.PullRequest.Base.Repo.FullName = e.Repository.FullName
+	pe.PullRequest.Base.Repo.HTMLURL = e.Repository.HtmlUrl
+
+	return origint.HandlePR(t.buildOriginClient(log, pe.Number), c.TriggerFor(org, repo), pe)
+}
+
+// buildOriginClient returns the client of the original trigger plugin for
+// the event of the PR.
+func (t *trigger) buildOriginClient(log *logrus.Entry, number int) origint.Client {
+	return origint.Client{
+		GitHubClient:  &ghclient{giteeClient: t.gec, pjl: t.pjc, number: number},
+		ProwJobClient: t.pjc,
+		Config:        t.getProwConfig(),
+		Logger:        log,
+		GitClient:     t.gitClient,
+	}
+}
+
+func (t *trigger) pluginConfig(org, repo string) (*configuration, error) {
+	c := t.getPluginConfig(t.PluginName(), org, repo)
+	if c == nil {
+		return nil, fmt.Errorf("can't find the trigger's configuration")
+	}
+
+	c1, ok := c.(*configuration)
+	if !ok {
+		return nil, fmt.Errorf("can't convert to trigger's configuration")
+	}
+	return c1, nil
+}
+
+// convertPullRequestAction maps the action of a Gitee pull request event
+// to the GitHub one which the original trigger plugin understands. It
+// returns an empty action if the event should not trigger any jobs.
+func convertPullRequestAction(e *sdk.PullRequestEvent) github.PullRequestEventAction {
+	switch *(e.Action) {
+	case "open":
+		return github.PullRequestActionOpened
+	case "update":
+		// An update event is also sent when the labels, assignees or
+		// testers of a pull request are changed. Only the changes of
+		// the source or target branch need to run the jobs again.
+		if e.ActionDesc == nil {
+			return ""
+		}
+		switch *(e.ActionDesc) {
+		case "source_branch_changed", "target_branch_changed":
+			return github.PullRequestActionSynchronize
+		}
+	case "close":
+		return github.PullRequestActionClosed
+	}
+	return ""
+}
+
+// eventGUID returns the guid of the event being handled, which the
+// dispatcher records in the log fields.
+func eventGUID(log *logrus.Entry) string {
+	if v, ok := log.Data[github.EventGUID].(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clienttesting "k8s.io/client-go/testing"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/client/clientset/versioned/fake"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/labels"
+	originp "k8s.io/test-infra/prow/plugins"
+)
+
+const (
+	trustedUser   = "trusted-member"
+	untrustedUser = "stranger"
+)
+
+var testPR = fakegitee.PR{
+	Org:     "org",
+	Repo:    "repo",
+	Number:  5,
+	BaseRef: "master",
+	HeadRef: "feature",
+	HeadSHA: "sha",
+}
+
+func newTestTrigger(t *testing.T, fc *fakegitee.FakeClient, pjs ...prowapi.ProwJob) (plugins.Plugin, *fake.Clientset) {
+	c := &configuration{Triggers: []originp.Trigger{{Repos: []string{"org"}, TrustedOrg: "org"}}}
+	c.SetDefault()
+
+	pc := &prowConfig.Config{}
+	presubmits := map[string][]prowConfig.Presubmit{
+		"org/repo": {
+			{
+				JobBase:      prowConfig.JobBase{Name: "job"},
+				AlwaysRun:    true,
+				Reporter:     prowConfig.Reporter{Context: "pull-job"},
+				Trigger:      `(?m)^/test (?:.*? )?job(?: .*?)?$`,
+				RerunCommand: "/test job",
+			},
+			{
+				JobBase:      prowConfig.JobBase{Name: "jib"},
+				Reporter:     prowConfig.Reporter{Context: "pull-jib"},
+				Trigger:      `(?m)^/test (?:.*? )?jib(?: .*?)?$`,
+				RerunCommand: "/test jib",
+			},
+		},
+	}
+	if err := pc.SetPresubmits(presubmits); err != nil {
+		t.Fatalf("failed to set presubmits: %v", err)
+	}
+
+	var objects []runtime.Object
+	for i := range pjs {
+		objects = append(objects, &pjs[i])
+	}
+	cs := fake.NewSimpleClientset(objects...)
+
+	tr := NewTrigger(
+		func(_, _, _ string) plugins.PluginConfig { return c },
+		func() *prowConfig.Config { return pc },
+		fc, cs.ProwV1().ProwJobs("prowjobs"), nil,
+	)
+	return tr, cs
+}
+
+func newFakeClient(pr fakegitee.PR) *fakegitee.FakeClient {
+	fc := fakegitee.NewFakeClient()
+	fc.OrgMembers = map[string][]string{"org": {trustedUser}}
+	fc.PullRequests[pr.Number] = &sdk.PullRequest{
+		Number:  int32(pr.Number),
+		State:   "open",
+		HtmlUrl: "https://gitee.com/org/repo/pulls/" + strconv.Itoa(pr.Number),
+		User:    &sdk.UserBasic{Login: pr.Author},
+		Head:    &sdk.BranchBasic{Ref: pr.HeadRef, Sha: pr.HeadSHA},
+		Base: &sdk.BranchBasic{
+			Ref:  pr.BaseRef,
+			Repo: &sdk.Project{Path: pr.Repo, FullName: pr.Org + "/" + pr.Repo, Owner: &sdk.UserBasic{Login: pr.Org}},
+		},
+	}
+	return fc
+}
+
+// startedContexts returns the contexts of the ProwJobs created.
+func startedContexts(cs *fake.Clientset) sets.String {
+	started := sets.NewString()
+	for _, action := range cs.Fake.Actions() {
+		if create, ok := action.(clienttesting.CreateActionImpl); ok {
+			if pj, ok := create.Object.(*prowapi.ProwJob); ok {
+				started.Insert(pj.Spec.Context)
+			}
+		}
+	}
+	return started
+}
+
+func failedJob(name, context string, number int, sha string) prowapi.ProwJob {
+	return prowapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "prowjobs",
+			Labels: map[string]string{
+				kube.OrgLabel:         "org",
+				kube.RepoLabel:        "repo",
+				kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+				kube.PullLabel:        strconv.Itoa(number),
+			},
+		},
+		Spec: prowapi.ProwJobSpec{
+			Type:    prowapi.PresubmitJob,
+			Context: context,
+			Refs: &prowapi.Refs{
+				Org:   "org",
+				Repo:  "repo",
+				Pulls: []prowapi.Pull{{Number: number, SHA: sha}},
+			},
+		},
+		Status: prowapi.ProwJobStatus{State: prowapi.FailureState},
+	}
+}
+
+func TestHandlePullRequestEvent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		author     string
+		action     string
+		actionDesc string
+
+		expectStarted []string
+		expectLabel   string
+		expectComment string
+	}{
+		{
+			name:          "trusted user opens a PR",
+			author:        trustedUser,
+			action:        "open",
+			expectStarted: []string{"pull-job"},
+		},
+		{
+			name:          "untrusted user opens a PR",
+			author:        untrustedUser,
+			action:        "open",
+			expectLabel:   labels.NeedsOkToTest,
+			expectComment: "Thanks for your PR.",
+		},
+		{
+			name:          "trusted user pushes to the source branch",
+			author:        trustedUser,
+			action:        "update",
+			actionDesc:    "source_branch_changed",
+			expectStarted: []string{"pull-job"},
+		},
+		{
+			name:       "labels of the PR are changed",
+			author:     trustedUser,
+			action:     "update",
+			actionDesc: "update_label",
+		},
+		{
+			name:   "update without a description",
+			author: trustedUser,
+			action: "update",
+		},
+		{
+			name:   "PR is closed",
+			author: trustedUser,
+			action: "close",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := testPR
+			pr.Author = tc.author
+			fc := newFakeClient(pr)
+			tr, cs := newTestTrigger(t, fc)
+
+			e := fakegitee.NewPullRequestEvent(pr, tc.action)
+			if tc.actionDesc != "" {
+				e.ActionDesc = &tc.actionDesc
+			}
+			if err := tr.(*trigger).handlePullRequestEvent(e, logrus.WithField("plugin", "trigger")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if started := startedContexts(cs); !started.Equal(sets.NewString(tc.expectStarted...)) {
+				t.Errorf("expected the jobs %v to start, got %v", tc.expectStarted, started.List())
+			}
+			added := sets.NewString(fc.PRLabelsAdded...)
+			if tc.expectLabel != "" && !added.Has("org/repo#5:"+tc.expectLabel) {
+				t.Errorf("expected the label %s to be added, got %v", tc.expectLabel, fc.PRLabelsAdded)
+			} else if tc.expectLabel == "" && added.Len() > 0 {
+				t.Errorf("expected no labels to be added, got %v", fc.PRLabelsAdded)
+			}
+			if tc.expectComment == "" {
+				if len(fc.PRCommentsAdded) > 0 {
+					t.Errorf("unexpected comments: %v", fc.PRCommentsAdded)
+				}
+			} else if len(fc.PRCommentsAdded) != 1 || !strings.Contains(fc.PRCommentsAdded[0], tc.expectComment) {
+				t.Errorf("expected a comment containing %q, got %v", tc.expectComment, fc.PRCommentsAdded)
+			}
+		})
+	}
+}
+
+func TestHandleNoteEvent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		commenter string
+		comment   string
+		issue     bool
+		pjs       []prowapi.ProwJob
+
+		expectStarted []string
+	}{
+		{
+			name:          "trusted user runs all the jobs",
+			commenter:     trustedUser,
+			comment:       "/test all",
+			expectStarted: []string{"pull-job"},
+		},
+		{
+			name:          "trusted user runs a job",
+			commenter:     trustedUser,
+			comment:       "/test jib",
+			expectStarted: []string{"pull-jib"},
+		},
+		{
+			name:      "untrusted user can't run the jobs",
+			commenter: untrustedUser,
+			comment:   "/test all",
+		},
+		{
+			name:      "unrelated comment",
+			commenter: trustedUser,
+			comment:   "looks good",
+		},
+		{
+			name:      "comment on an issue",
+			commenter: trustedUser,
+			comment:   "/test all",
+			issue:     true,
+		},
+		{
+			name:      "retest reruns the failed jobs of the PR",
+			commenter: trustedUser,
+			comment:   "/retest",
+			pjs: []prowapi.ProwJob{
+				failedJob("failed-jib", "pull-jib", 5, "sha"),
+			},
+			expectStarted: []string{"pull-job", "pull-jib"},
+		},
+		{
+			name:      "retest ignores the failed jobs of other PRs",
+			commenter: trustedUser,
+			comment:   "/retest",
+			pjs: []prowapi.ProwJob{
+				failedJob("other-pr", "pull-jib", 6, "sha"),
+			},
+			expectStarted: []string{"pull-job"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := testPR
+			pr.Author = "author"
+			fc := newFakeClient(pr)
+			tr, cs := newTestTrigger(t, fc, tc.pjs...)
+
+			e := fakegitee.NewPRNoteEvent(pr, tc.commenter, tc.comment)
+			if tc.issue {
+				e = fakegitee.NewIssueNoteEvent("org", "repo", "I1ABCD", "author", tc.commenter, tc.comment)
+			}
+			if err := tr.(*trigger).handleNoteEvent(e, logrus.WithField("plugin", "trigger")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if started := startedContexts(cs); !started.Equal(sets.NewString(tc.expectStarted...)) {
+				t.Errorf("expected the jobs %v to start, got %v", tc.expectStarted, started.List())
+			}
+		})
+	}
+}
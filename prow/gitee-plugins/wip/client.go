@@ -0,0 +1,40 @@
+package wip
+
+import (
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+type giteeClient interface {
+	AddPRLabel(owner, repo string, number int, label string) error
+	RemovePRLabel(owner, repo string, number int, label string) error
+	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+}
+
+var _ githubClient = (*ghclient)(nil)
+
+type ghclient struct {
+	giteeClient
+}
+
+func (c *ghclient) AddLabel(org, repo string, number int, label string) error {
+	return c.AddPRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) RemoveLabel(org, repo string, number int, label string) error {
+	return c.RemovePRLabel(org, repo, number, label)
+}
+
+func (c *ghclient) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	var r []github.Label
+
+	v, err := c.GetPRLabels(org, repo, number)
+	if err != nil {
+		return r, err
+	}
+
+	for _, i := range v {
+		r = append(r, github.Label{Name: i.Name})
+	}
+	return r, nil
+}
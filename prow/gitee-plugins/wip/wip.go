@@ -0,0 +1,82 @@
+package wip
+
+import (
+	"fmt"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	prowConfig "k8s.io/test-infra/prow/config"
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/labels"
+	"k8s.io/test-infra/prow/pluginhelp"
+	originp "k8s.io/test-infra/prow/plugins"
+	originw "k8s.io/test-infra/prow/plugins/wip"
+)
+
+type githubClient interface {
+	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+	AddLabel(owner, repo string, number int, label string) error
+	RemoveLabel(owner, repo string, number int, label string) error
+}
+
+type wip struct {
+	getPluginConfig plugins.GetPluginConfig
+	ghc             githubClient
+}
+
+func NewWIP(f plugins.GetPluginConfig, gec giteeClient) plugins.Plugin {
+	return &wip{
+		getPluginConfig: f,
+		ghc:             &ghclient{giteeClient: gec},
+	}
+}
+
+func (w *wip) HelpProvider(enabledRepos []prowConfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	return originw.HelpProvider(&originp.Configuration{}, enabledRepos)
+}
+
+func (w *wip) PluginName() string {
+	return originw.PluginName
+}
+
+func (w *wip) NewPluginConfig() plugins.PluginConfig {
+	return &configuration{}
+}
+
+func (w *wip) RegisterEventHandler(p plugins.Plugins) {
+	name := w.PluginName()
+	p.RegisterPullRequestHandler(name, w.handlePullRequestEvent)
+}
+
+func (w *wip) handlePullRequestEvent(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	funcStart := time.Now()
+	defer func() {
+		log.WithField("duration", time.Since(funcStart).String()).Debug("Completed handlePullRequest")
+	}()
+
+	if *(e.State) != "open" {
+		log.Debug("Pull request state is not open, skipping...")
+		return nil
+	}
+
+	// Gitee reports the changes of the title and of the draft state as updates.
+	if action := *(e.Action); action != "open" && action != "update" {
+		log.Debug("Pull request event is neither open nor update, skipping...")
+		return nil
+	}
+
+	pr := e.PullRequest
+	org := e.Repository.Owner.Login
+	repo := e.Repository.Name
+	number := int(pr.Number)
+
+	currentLabels, err := w.ghc.GetIssueLabels(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("could not get labels for PR %s/%s:%d in WIP plugin: %v", org, repo, number, err)
+	}
+	hasLabel := github.HasLabel(labels.WorkInProgress, currentLabels)
+
+	return originw.Handle(w.ghc, log, originw.NewEvent(org, repo, number, pr.Title, pr.Draft, hasLabel))
+}
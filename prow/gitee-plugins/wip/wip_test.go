@@ -0,0 +1,93 @@
+package wip
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	plugins "k8s.io/test-infra/prow/gitee-plugins"
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/labels"
+)
+
+func TestHandlePullRequestEvent(t *testing.T) {
+	wipLabel := "org/repo#5:" + labels.WorkInProgress
+
+	testCases := []struct {
+		name     string
+		title    string
+		draft    bool
+		action   string
+		hasLabel bool
+
+		expectAdded   bool
+		expectRemoved bool
+	}{
+		{
+			name:        "WIP title prefix adds the label",
+			title:       "[WIP] fix the tests",
+			action:      "open",
+			expectAdded: true,
+		},
+		{
+			name:        "draft PR adds the label",
+			title:       "fix the tests",
+			draft:       true,
+			action:      "open",
+			expectAdded: true,
+		},
+		{
+			name:          "ready PR loses the label",
+			title:         "fix the tests",
+			action:        "update",
+			hasLabel:      true,
+			expectRemoved: true,
+		},
+		{
+			name:     "WIP PR keeps the label",
+			title:    "WIP: fix the tests",
+			action:   "update",
+			hasLabel: true,
+		},
+		{
+			name:   "ready PR without label is left alone",
+			title:  "fix the tests",
+			action: "open",
+		},
+		{
+			name:   "closed PR is ignored",
+			title:  "WIP: fix the tests",
+			action: "close",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := fakegitee.NewFakeClient()
+			if tc.hasLabel {
+				fc.PRLabelsExisting = []string{wipLabel}
+			}
+			pr := fakegitee.PR{
+				Org:    "org",
+				Repo:   "repo",
+				Number: 5,
+				Author: "author",
+				Title:  tc.title,
+				Draft:  tc.draft,
+			}
+
+			w := NewWIP(func(_, _, _ string) plugins.PluginConfig { return &configuration{} }, fc).(*wip)
+			e := fakegitee.NewPullRequestEvent(pr, tc.action)
+			if err := w.handlePullRequestEvent(e, logrus.WithField("plugin", "wip")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if added := len(fc.PRLabelsAdded) == 1 && fc.PRLabelsAdded[0] == wipLabel; added != tc.expectAdded {
+				t.Errorf("expected wip label added: %t, got labels added: %v", tc.expectAdded, fc.PRLabelsAdded)
+			}
+			if removed := len(fc.PRLabelsRemoved) == 1 && fc.PRLabelsRemoved[0] == wipLabel; removed != tc.expectRemoved {
+				t.Errorf("expected wip label removed: %t, got labels removed: %v", tc.expectRemoved, fc.PRLabelsRemoved)
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package gitee
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 
 	sdk "gitee.com/openeuler/go-gitee/gitee"
 	"github.com/antihax/optional"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"k8s.io/test-infra/prow/github"
 )
@@ -17,22 +20,104 @@ var _ Client = (*client)(nil)
 type client struct {
 	ac *sdk.APIClient
 
+	logger    *logrus.Entry
+	transport *transport
+	// If dry is true, the mutating calls are logged and skipped.
+	dry bool
+
 	mut      sync.Mutex // protects botName and email
 	userData *sdk.User
 }
 
-func NewClient(getToken func() []byte) Client {
+// DefaultAPIEndpoint is the base path of the Gitee API.
+const DefaultAPIEndpoint = "https://gitee.com/api/v5"
+
+// NewClient creates a new fully operational Gitee client.
+// 'bases' is a variadic slice of endpoints to use in order of preference.
+// An endpoint is used when all preceding endpoints have returned a conn err.
+// This should be used when using the ghproxy cache in front of Gitee to allow
+// this client to bypass the cache if it is temporarily unavailable.
+func NewClient(getToken func() []byte, bases ...string) Client {
+	return NewClientWithFields(logrus.Fields{}, getToken, bases...)
+}
+
+// NewClientWithFields creates a new fully operational Gitee client. The
+// fields are added to every log line of the client.
+func NewClientWithFields(fields logrus.Fields, getToken func() []byte, bases ...string) Client {
+	return newClient(fields, getToken, false, bases...)
+}
+
+// NewDryRunClient creates a new client that will not perform mutating actions
+// such as creating comments or adding labels. It does still query Gitee and
+// logs the actions it would have taken.
+func NewDryRunClient(getToken func() []byte, bases ...string) Client {
+	return NewDryRunClientWithFields(logrus.Fields{}, getToken, bases...)
+}
+
+// NewDryRunClientWithFields creates a new client that will not perform
+// mutating actions. The fields are added to every log line of the client.
+func NewDryRunClientWithFields(fields logrus.Fields, getToken func() []byte, bases ...string) Client {
+	return newClient(fields, getToken, true, bases...)
+}
+
+func newClient(fields logrus.Fields, getToken func() []byte, dry bool, bases ...string) *client {
 	token := string(getToken())
 
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 
+	logger := logrus.WithFields(fields).WithField("client", "gitee")
+
+	var endpoints []*url.URL
+	for _, b := range bases {
+		u, err := url.Parse(strings.TrimSuffix(b, "/"))
+		if err != nil {
+			logger.WithError(err).Errorf("Ignoring the invalid endpoint %q.", b)
+			continue
+		}
+		endpoints = append(endpoints, u)
+	}
+
+	t := newTransport(http.DefaultTransport, logger, endpoints...)
+	// The oauth2 client sends its requests with the client of the context,
+	// so the requests carry the token when they reach the transport.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: t})
+
 	conf := sdk.NewConfiguration()
-	conf.HTTPClient = oauth2.NewClient(context.Background(), ts)
+	conf.HTTPClient = oauth2.NewClient(ctx, ts)
+	if len(endpoints) > 0 {
+		conf.BasePath = endpoints[0].String()
+	}
 
-	c := sdk.NewAPIClient(conf)
-	return &client{ac: c}
+	return &client{
+		ac:        sdk.NewAPIClient(conf),
+		logger:    logger,
+		transport: t,
+		dry:       dry,
+	}
+}
+
+// Throttle client to a rate of at most hourlyTokens requests per hour,
+// allowing burst tokens.
+func (c *client) Throttle(hourlyTokens, burst int) {
+	c.logger.WithFields(logrus.Fields{"hourly-tokens": hourlyTokens, "burst": burst}).Info("Throttle")
+	c.transport.throttle.Set(hourlyTokens, burst)
+}
+
+// skip logs the call of a mutating method and reports whether the call has
+// to be skipped, which is the case for a dry-run client.
+func (c *client) skip(methodName string, args ...interface{}) bool {
+	if !c.dry {
+		return false
+	}
+
+	var as []string
+	for _, arg := range args {
+		as = append(as, fmt.Sprintf("%v", arg))
+	}
+	c.logger.Infof("Dry run, skipping %s(%s)", methodName, strings.Join(as, ", "))
+	return true
 }
 
 // BotName returns the login of the authenticated identity.
@@ -72,6 +157,10 @@ func (c *client) BotUser() (*github.User, error) {
 }
 
 func (c *client) CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (sdk.PullRequest, error) {
+	if c.skip("CreatePullRequest", org, repo, title, head, base) {
+		return sdk.PullRequest{}, nil
+	}
+
 	opts := sdk.CreatePullRequestParam{
 		Title:             title,
 		Head:              head,
@@ -92,11 +181,32 @@ func (c *client) GetPullRequests(org, repo, state, head, base string) ([]sdk.Pul
 		Head:  optional.NewString(head),
 		Base:  optional.NewString(base),
 	}
-	prs, _, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPulls(context.Background(), org, repo, &opts)
-	return prs, err
+
+	var r []sdk.PullRequest
+	p := int32(1)
+	for {
+		opts.Page = optional.NewInt32(p)
+		prs, _, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPulls(context.Background(), org, repo, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(prs) == 0 {
+			break
+		}
+
+		p += 1
+		r = append(r, prs...)
+	}
+
+	return r, nil
 }
 
 func (c *client) UpdatePullRequest(org, repo string, number int32, title, body, state, labels string) (sdk.PullRequest, error) {
+	if c.skip("UpdatePullRequest", org, repo, number, title, state, labels) {
+		return sdk.PullRequest{}, nil
+	}
+
 	opts := sdk.PullRequestUpdateParam{
 		Title:  title,
 		Body:   body,
@@ -107,6 +217,39 @@ func (c *client) UpdatePullRequest(org, repo string, number int32, title, body,
 	return pr, err
 }
 
+func (c *client) MergePR(owner, repo string, number int, opt sdk.PullRequestMergePutParam) error {
+	if c.skip("MergePR", owner, repo, number, opt.MergeMethod) {
+		return nil
+	}
+
+	_, err := c.ac.PullRequestsApi.PutV5ReposOwnerRepoPullsNumberMerge(
+		context.Background(), owner, repo, int32(number), opt)
+	return err
+}
+
+func (c *client) GetRepos(org string) ([]sdk.Project, error) {
+	var r []sdk.Project
+
+	p := int32(1)
+	opt := sdk.GetV5OrgsOrgReposOpts{}
+	for {
+		opt.Page = optional.NewInt32(p)
+		ps, _, err := c.ac.RepositoriesApi.GetV5OrgsOrgRepos(context.Background(), org, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ps) == 0 {
+			break
+		}
+
+		p += 1
+		r = append(r, ps...)
+	}
+
+	return r, nil
+}
+
 func (c *client) GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error) {
 	pr, _, err := c.ac.PullRequestsApi.GetV5ReposOwnerRepoPullsNumber(
 		context.Background(), org, repo, int32(number), nil)
@@ -130,17 +273,27 @@ func (c *client) getUserData() error {
 }
 
 func (c *client) ListCollaborators(org, repo string) ([]github.User, error) {
-	cs, _, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepoCollaborators(context.Background(), org, repo, nil)
-	if err != nil {
-		return nil, err
-	}
 	var r []github.User
-	for _, i := range cs {
-		c := github.User{
-			Login: i.Login,
+
+	p := int32(1)
+	opt := sdk.GetV5ReposOwnerRepoCollaboratorsOpts{}
+	for {
+		opt.Page = optional.NewInt32(p)
+		cs, _, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepoCollaborators(context.Background(), org, repo, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cs) == 0 {
+			break
+		}
+
+		p += 1
+		for _, i := range cs {
+			r = append(r, github.User{Login: i.Login})
 		}
-		r = append(r, c)
 	}
+
 	return r, nil
 }
 
@@ -194,6 +347,45 @@ func (c *client) GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
 	return r, nil
 }
 
+// GetRepoLabels returns the labels defined in the repository.
+func (c *client) GetRepoLabels(owner, repo string) ([]sdk.Label, error) {
+	labels, _, err := c.ac.LabelsApi.GetV5ReposOwnerRepoLabels(context.Background(), owner, repo, nil)
+	return labels, err
+}
+
+// AddRepoLabel creates the label in the repository, color being rrggbb.
+func (c *client) AddRepoLabel(org, repo, name, color string) error {
+	if c.skip("AddRepoLabel", org, repo, name, color) {
+		return nil
+	}
+
+	opt := sdk.LabelPostParam{Name: name, Color: color}
+	_, _, err := c.ac.LabelsApi.PostV5ReposOwnerRepoLabels(context.Background(), org, repo, opt)
+	return err
+}
+
+// UpdateRepoLabel renames and recolors the label of the repository.
+func (c *client) UpdateRepoLabel(org, repo, currentName, newName, color string) error {
+	if c.skip("UpdateRepoLabel", org, repo, currentName, newName, color) {
+		return nil
+	}
+
+	opt := sdk.LabelPatchParam{Name: newName, Color: color}
+	_, _, err := c.ac.LabelsApi.PatchV5ReposOwnerRepoLabelsOriginalName(
+		context.Background(), org, repo, currentName, opt)
+	return err
+}
+
+// DeleteRepoLabel deletes the label of the repository.
+func (c *client) DeleteRepoLabel(org, repo, label string) error {
+	if c.skip("DeleteRepoLabel", org, repo, label) {
+		return nil
+	}
+
+	_, err := c.ac.LabelsApi.DeleteV5ReposOwnerRepoLabelsName(context.Background(), org, repo, label, nil)
+	return err
+}
+
 func (c *client) ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error) {
 	var r []sdk.PullRequestComments
 
@@ -219,19 +411,42 @@ func (c *client) ListPRComments(org, repo string, number int) ([]sdk.PullRequest
 }
 
 func (c *client) DeletePRComment(org, repo string, ID int) error {
+	if c.skip("DeletePRComment", org, repo, ID) {
+		return nil
+	}
+
 	_, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsCommentsId(
 		context.Background(), org, repo, int32(ID), nil)
 	return err
 }
 
 func (c *client) CreatePRComment(org, repo string, number int, comment string) error {
+	if c.skip("CreatePRComment", org, repo, number, comment) {
+		return nil
+	}
+
 	opt := sdk.PullRequestCommentPostParam{Body: comment}
 	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
 		context.Background(), org, repo, int32(number), opt)
 	return err
 }
 
+func (c *client) UpdatePRComment(org, repo string, commentID int, comment string) error {
+	if c.skip("UpdatePRComment", org, repo, commentID, comment) {
+		return nil
+	}
+
+	opt := sdk.PullRequestCommentPatchParam{Body: comment}
+	_, _, err := c.ac.PullRequestsApi.PatchV5ReposOwnerRepoPullsCommentsId(
+		context.Background(), org, repo, int32(commentID), opt)
+	return err
+}
+
 func (c *client) AddPRLabel(org, repo string, number int, label string) error {
+	if c.skip("AddPRLabel", org, repo, number, label) {
+		return nil
+	}
+
 	opt := sdk.PullRequestLabelPostParam{Body: []string{label}}
 	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberLabels(
 		context.Background(), org, repo, int32(number), opt)
@@ -239,12 +454,41 @@ func (c *client) AddPRLabel(org, repo string, number int, label string) error {
 }
 
 func (c *client) RemovePRLabel(org, repo string, number int, label string) error {
+	if c.skip("RemovePRLabel", org, repo, number, label) {
+		return nil
+	}
+
 	_, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsLabel(
 		context.Background(), org, repo, int32(number), label, nil)
 	return err
 }
 
+// ClosePR closes the PR.
+func (c *client) ClosePR(org, repo string, number int) error {
+	return c.updatePRState(org, repo, number, "closed")
+}
+
+// ReopenPR reopens the closed PR.
+func (c *client) ReopenPR(org, repo string, number int) error {
+	return c.updatePRState(org, repo, number, "open")
+}
+
+func (c *client) updatePRState(org, repo string, number int, state string) error {
+	if c.skip("UpdatePRState", org, repo, number, state) {
+		return nil
+	}
+
+	opt := sdk.PullRequestUpdateParam{State: state}
+	_, _, err := c.ac.PullRequestsApi.PatchV5ReposOwnerRepoPullsNumber(
+		context.Background(), org, repo, int32(number), opt)
+	return err
+}
+
 func (c *client) AssignPR(org, repo string, number int, logins []string) error {
+	if c.skip("AssignPR", org, repo, number, logins) {
+		return nil
+	}
+
 	opt := sdk.PullRequestAssigneePostParam{Assignees: strings.Join(logins, ",")}
 
 	_, _, err := c.ac.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberAssignees(
@@ -253,12 +497,20 @@ func (c *client) AssignPR(org, repo string, number int, logins []string) error {
 }
 
 func (c *client) UnassignPR(org, repo string, number int, logins []string) error {
+	if c.skip("UnassignPR", org, repo, number, logins) {
+		return nil
+	}
+
 	_, _, err := c.ac.PullRequestsApi.DeleteV5ReposOwnerRepoPullsNumberAssignees(
 		context.Background(), org, repo, int32(number), strings.Join(logins, ","), nil)
 	return err
 }
 
 func (c *client) AssignGiteeIssue(org, repo string, number string, login string) error {
+	if c.skip("AssignGiteeIssue", org, repo, number, login) {
+		return nil
+	}
+
 	opt := sdk.IssueUpdateParam{
 		Repo:     repo,
 		Assignee: login,
@@ -279,12 +531,113 @@ func (c *client) UnassignGiteeIssue(org, repo string, number string, login strin
 }
 
 func (c *client) CreateGiteeIssueComment(org, repo string, number string, comment string) error {
+	if c.skip("CreateGiteeIssueComment", org, repo, number, comment) {
+		return nil
+	}
+
 	opt := sdk.IssueCommentPostParam{Body: comment}
 	_, _, err := c.ac.IssuesApi.PostV5ReposOwnerRepoIssuesNumberComments(
 		context.Background(), org, repo, number, opt)
 	return err
 }
 
+// ListIssues returns the issues of the repository in the state, which
+// carry all the comma separated labels.
+func (c *client) ListIssues(org, repo, state, labels string) ([]sdk.Issue, error) {
+	opts := sdk.GetV5ReposOwnerRepoIssuesOpts{
+		State:  optional.NewString(state),
+		Labels: optional.NewString(labels),
+	}
+
+	var r []sdk.Issue
+	p := int32(1)
+	for {
+		opts.Page = optional.NewInt32(p)
+		is, _, err := c.ac.IssuesApi.GetV5ReposOwnerRepoIssues(context.Background(), org, repo, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(is) == 0 {
+			break
+		}
+
+		p += 1
+		r = append(r, is...)
+	}
+
+	return r, nil
+}
+
+// UpdateIssueLabels replaces the labels of the issue.
+func (c *client) UpdateIssueLabels(org, repo, number string, labels []string) error {
+	if c.skip("UpdateIssueLabels", org, repo, number, labels) {
+		return nil
+	}
+
+	opt := sdk.IssueUpdateParam{
+		Repo:   repo,
+		Labels: strings.Join(labels, ","),
+	}
+	_, _, err := c.ac.IssuesApi.PatchV5ReposOwnerIssuesNumber(
+		context.Background(), org, number, opt)
+	return err
+}
+
+// GetGiteeIssueLabels returns the labels of the issue.
+func (c *client) GetGiteeIssueLabels(org, repo, number string) ([]sdk.Label, error) {
+	labels, _, err := c.ac.LabelsApi.GetV5ReposOwnerRepoIssuesNumberLabels(
+		context.Background(), org, repo, number, nil)
+	return labels, err
+}
+
+// AddGiteeIssueLabel adds the label to the issue.
+func (c *client) AddGiteeIssueLabel(org, repo, number, label string) error {
+	if c.skip("AddGiteeIssueLabel", org, repo, number, label) {
+		return nil
+	}
+
+	opt := sdk.PullRequestLabelPostParam{Body: []string{label}}
+	_, _, err := c.ac.LabelsApi.PostV5ReposOwnerRepoIssuesNumberLabels(
+		context.Background(), org, repo, number, opt)
+	return err
+}
+
+// RemoveGiteeIssueLabel removes the label from the issue.
+func (c *client) RemoveGiteeIssueLabel(org, repo, number, label string) error {
+	if c.skip("RemoveGiteeIssueLabel", org, repo, number, label) {
+		return nil
+	}
+
+	_, err := c.ac.LabelsApi.DeleteV5ReposOwnerRepoIssuesNumberLabelsName(
+		context.Background(), org, repo, number, label, nil)
+	return err
+}
+
+// CloseGiteeIssue closes the issue.
+func (c *client) CloseGiteeIssue(org, repo, number string) error {
+	return c.updateGiteeIssueState(org, repo, number, "closed")
+}
+
+// ReopenGiteeIssue reopens the closed issue.
+func (c *client) ReopenGiteeIssue(org, repo, number string) error {
+	return c.updateGiteeIssueState(org, repo, number, "open")
+}
+
+func (c *client) updateGiteeIssueState(org, repo, number, state string) error {
+	if c.skip("UpdateGiteeIssueState", org, repo, number, state) {
+		return nil
+	}
+
+	opt := sdk.IssueUpdateParam{
+		Repo:  repo,
+		State: state,
+	}
+	_, _, err := c.ac.IssuesApi.PatchV5ReposOwnerIssuesNumber(
+		context.Background(), org, number, opt)
+	return err
+}
+
 func (c *client) IsCollaborator(owner, repo, login string) (bool, error) {
 	v, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepoCollaboratorsUsername(
 		context.Background(), owner, repo, login, nil)
@@ -297,6 +650,208 @@ func (c *client) IsCollaborator(owner, repo, login string) (bool, error) {
 	return true, nil
 }
 
+func (c *client) IsMember(org, login string) (bool, error) {
+	_, v, err := c.ac.OrganizationsApi.GetV5OrgsOrgMembershipsUsername(
+		context.Background(), org, login, nil)
+	if err != nil {
+		if v != nil && v.StatusCode == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ListOrgMembers returns the members of the org with the role, which is
+// one of all, admin and member.
+func (c *client) ListOrgMembers(org, role string) ([]sdk.UserBasic, error) {
+	var r []sdk.UserBasic
+
+	p := int32(1)
+	opt := sdk.GetV5OrgsOrgMembersOpts{Role: optional.NewString(role)}
+	for {
+		opt.Page = optional.NewInt32(p)
+		ms, _, err := c.ac.OrganizationsApi.GetV5OrgsOrgMembers(context.Background(), org, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ms) == 0 {
+			break
+		}
+
+		p += 1
+		r = append(r, ms...)
+	}
+
+	return r, nil
+}
+
+// UpdateOrgMembership adds the user to the org, or changes their role if
+// they are already a member.
+func (c *client) UpdateOrgMembership(org, login string, admin bool) error {
+	role := github.RoleMember
+	if admin {
+		role = github.RoleAdmin
+	}
+	if c.skip("UpdateOrgMembership", org, login, role) {
+		return nil
+	}
+
+	opt := sdk.OrgMembershipPutParam{Role: role}
+	_, _, err := c.ac.OrganizationsApi.PutV5OrgsOrgMembershipsUsername(
+		context.Background(), org, login, opt)
+	return err
+}
+
+// RemoveOrgMembership removes the user from the org.
+func (c *client) RemoveOrgMembership(org, login string) error {
+	if c.skip("RemoveOrgMembership", org, login) {
+		return nil
+	}
+
+	_, err := c.ac.OrganizationsApi.DeleteV5OrgsOrgMembershipsUsername(
+		context.Background(), org, login, nil)
+	return err
+}
+
+// CreateRepo creates a repository in the org.
+func (c *client) CreateRepo(org string, repo sdk.RepositoryPostParam) (sdk.Project, error) {
+	if c.skip("CreateRepo", org, repo.Path) {
+		return sdk.Project{
+			Name:        repo.Name,
+			Path:        repo.Path,
+			FullName:    org + "/" + repo.Path,
+			Description: repo.Description,
+			Homepage:    repo.Homepage,
+			Private:     repo.Private,
+		}, nil
+	}
+
+	p, _, err := c.ac.RepositoriesApi.PostV5OrgsOrgRepos(context.Background(), org, repo)
+	return p, err
+}
+
+// UpdateRepo edits the repository. The name of the patch is required by
+// Gitee, set it to the current name to keep it.
+func (c *client) UpdateRepo(org, repo string, patch sdk.RepoPatchParam) (sdk.Project, error) {
+	if c.skip("UpdateRepo", org, repo) {
+		return sdk.Project{}, nil
+	}
+
+	p, _, err := c.ac.RepositoriesApi.PatchV5ReposOwnerRepo(context.Background(), org, repo, patch)
+	return p, err
+}
+
+// GetRepoCollaboratorPermission returns the permission of the user on the
+// repository, which is one of pull, push and admin.
+func (c *client) GetRepoCollaboratorPermission(org, repo, login string) (string, error) {
+	v, _, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepoCollaboratorsUsernamePermission(
+		context.Background(), org, repo, login, nil)
+	if err != nil {
+		return "", err
+	}
+	return v.Permission, nil
+}
+
+// AddRepoCollaborator adds the user as a collaborator of the repository,
+// or changes their permission if they already are one.
+func (c *client) AddRepoCollaborator(org, repo, login, permission string) error {
+	if c.skip("AddRepoCollaborator", org, repo, login, permission) {
+		return nil
+	}
+
+	opt := sdk.ProjectMemberPutParam{Permission: permission}
+	_, _, err := c.ac.RepositoriesApi.PutV5ReposOwnerRepoCollaboratorsUsername(
+		context.Background(), org, repo, login, opt)
+	return err
+}
+
+// RemoveRepoCollaborator removes the user from the collaborators of the
+// repository.
+func (c *client) RemoveRepoCollaborator(org, repo, login string) error {
+	if c.skip("RemoveRepoCollaborator", org, repo, login) {
+		return nil
+	}
+
+	_, err := c.ac.RepositoriesApi.DeleteV5ReposOwnerRepoCollaboratorsUsername(
+		context.Background(), org, repo, login, nil)
+	return err
+}
+
+// GetRepo returns the repository.
+func (c *client) GetRepo(org, repo string) (sdk.Project, error) {
+	p, _, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepo(context.Background(), org, repo, nil)
+	return p, err
+}
+
+// GetBranches returns the branches of the repository, or only the protected
+// ones if onlyProtected is set.
+func (c *client) GetBranches(org, repo string, onlyProtected bool) ([]sdk.Branch, error) {
+	bs, _, err := c.ac.RepositoriesApi.GetV5ReposOwnerRepoBranches(context.Background(), org, repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !onlyProtected {
+		return bs, nil
+	}
+
+	var r []sdk.Branch
+	for _, b := range bs {
+		if b.Protected {
+			r = append(r, b)
+		}
+	}
+	return r, nil
+}
+
+// ProtectBranch makes the branch a protected branch.
+func (c *client) ProtectBranch(org, repo, branch string) error {
+	if c.skip("ProtectBranch", org, repo, branch) {
+		return nil
+	}
+
+	_, _, err := c.ac.RepositoriesApi.PutV5ReposOwnerRepoBranchesBranchProtection(
+		context.Background(), org, repo, branch, nil)
+	return err
+}
+
+// UnprotectBranch makes the branch a regular branch again.
+func (c *client) UnprotectBranch(org, repo, branch string) error {
+	if c.skip("UnprotectBranch", org, repo, branch) {
+		return nil
+	}
+
+	_, err := c.ac.RepositoriesApi.DeleteV5ReposOwnerRepoBranchesBranchProtection(
+		context.Background(), org, repo, branch, nil)
+	return err
+}
+
+// UpdateBranchSetting sets who can push to and merge into the protected
+// branch. Pusher and merger are either "admin", "none" or the logins of the
+// users separated by ";".
+func (c *client) UpdateBranchSetting(org, repo, branch string, setting sdk.BranchProtectionPutParam) error {
+	if c.skip("UpdateBranchSetting", org, repo, branch, setting.Pusher, setting.Merger) {
+		return nil
+	}
+
+	setting.Wildcard = branch
+	_, _, err := c.ac.RepositoriesApi.PutV5ReposOwnerRepoBranchesWildcardSetting(
+		context.Background(), org, repo, branch, setting)
+	return err
+}
+
+// UpdateRepoReviewer changes the code review settings of the repository,
+// which apply to the PRs of all its branches.
+func (c *client) UpdateRepoReviewer(org, repo string, reviewer sdk.ProjectReviewerPutParam) error {
+	if c.skip("UpdateRepoReviewer", org, repo, reviewer.AssigneesNumber) {
+		return nil
+	}
+
+	_, err := c.ac.RepositoriesApi.PutV5ReposOwnerRepoReviewer(context.Background(), org, repo, reviewer)
+	return err
+}
+
 func (c *client) GetSingleCommit(org, repo, SHA string) (github.SingleCommit, error) {
 	var r github.SingleCommit
 
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDryRunClient(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		dry      bool
+		expected int
+	}{
+		{
+			name:     "dry-run client doesn't send the mutating requests",
+			dry:      true,
+			expected: 1,
+		},
+		{
+			name:     "client sends all the requests",
+			expected: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = nil
+
+			c := newClient(logrus.Fields{}, func() []byte { return []byte("token") }, tc.dry)
+			c.ac.ChangeBasePath(server.URL)
+
+			if _, err := c.ListPRComments("org", "repo", 1); err != nil {
+				t.Fatalf("unexpected error listing comments: %v", err)
+			}
+			// The responses don't matter, only the requests sent are checked.
+			c.CreatePRComment("org", "repo", 1, "comment")
+			c.AddPRLabel("org", "repo", 1, "label")
+			c.AssignPR("org", "repo", 1, []string{"user"})
+
+			if len(requests) != tc.expected {
+				t.Errorf("expected %d requests, got %d: %v", tc.expected, len(requests), requests)
+			}
+		})
+	}
+}
+
+func TestListCollaboratorsPaginates(t *testing.T) {
+	pages := map[string]string{
+		"1": `[{"login": "alice"}, {"login": "bob"}]`,
+		"2": `[{"login": "carol"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if page, ok := pages[r.URL.Query().Get("page")]; ok {
+			w.Write([]byte(page))
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	c := newClient(logrus.Fields{}, func() []byte { return []byte("token") }, false)
+	c.ac.ChangeBasePath(server.URL)
+
+	users, err := c.ListCollaborators("org", "repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var logins []string
+	for _, u := range users {
+		logins = append(logins, u.Login)
+	}
+	if expected := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(logins, expected) {
+		t.Errorf("expected the collaborators %v of all the pages, got %v", expected, logins)
+	}
+}
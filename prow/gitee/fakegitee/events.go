@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakegitee
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+)
+
+// The fixtures below are built from the JSON payloads of the Gitee webhooks,
+// the same way gitee-hook decodes the events it receives.
+
+// PR describes the pull request of an event fixture.
+type PR struct {
+	Org, Repo string
+	Number    int
+	Author    string
+	Title     string
+	Body      string
+	BaseRef   string
+	HeadRef   string
+	HeadSHA   string
+	Assignees []string
+	Labels    []string
+	Draft     bool
+}
+
+func (pr PR) payload() map[string]interface{} {
+	assignees := []map[string]interface{}{}
+	for _, a := range pr.Assignees {
+		assignees = append(assignees, user(a))
+	}
+	labels := []map[string]interface{}{}
+	for _, l := range pr.Labels {
+		labels = append(labels, map[string]interface{}{"name": l})
+	}
+
+	return map[string]interface{}{
+		"number":    pr.Number,
+		"state":     "open",
+		"title":     pr.Title,
+		"body":      pr.Body,
+		"draft":     pr.Draft,
+		"html_url":  fmt.Sprintf("https://gitee.com/%s/%s/pulls/%d", pr.Org, pr.Repo, pr.Number),
+		"user":      user(pr.Author),
+		"assignees": assignees,
+		"labels":    labels,
+		"head": map[string]interface{}{
+			"ref":  pr.HeadRef,
+			"sha":  pr.HeadSHA,
+			"user": user(pr.Author),
+			"repo": repository(pr.Author, pr.Repo),
+		},
+		"base": map[string]interface{}{
+			"ref":  pr.BaseRef,
+			"user": user(pr.Org),
+			"repo": repository(pr.Org, pr.Repo),
+		},
+	}
+}
+
+func user(login string) map[string]interface{} {
+	return map[string]interface{}{
+		"login":    login,
+		"name":     login,
+		"username": login,
+	}
+}
+
+func repository(org, repo string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      repo,
+		"path":      repo,
+		"full_name": org + "/" + repo,
+		"html_url":  fmt.Sprintf("https://gitee.com/%s/%s", org, repo),
+		"owner":     user(org),
+	}
+}
+
+// NewPRNoteEvent returns the event of a comment on a PR.
+func NewPRNoteEvent(pr PR, commenter, comment string) *sdk.NoteEvent {
+	e := &sdk.NoteEvent{}
+	decode(map[string]interface{}{
+		"action":        "comment",
+		"noteable_type": "PullRequest",
+		"comment": map[string]interface{}{
+			"id":       1,
+			"body":     comment,
+			"user":     user(commenter),
+			"html_url": fmt.Sprintf("https://gitee.com/%s/%s/pulls/%d#note_1", pr.Org, pr.Repo, pr.Number),
+		},
+		"repository":   repository(pr.Org, pr.Repo),
+		"pull_request": pr.payload(),
+		"sender":       user(commenter),
+	}, e)
+	return e
+}
+
+// NewIssueNoteEvent returns the event of a comment on an issue. The number is
+// the issue id like I1ABCD.
+func NewIssueNoteEvent(org, repo, number, author, commenter, comment string) *sdk.NoteEvent {
+	e := &sdk.NoteEvent{}
+	decode(map[string]interface{}{
+		"action":        "comment",
+		"noteable_type": "Issue",
+		"comment": map[string]interface{}{
+			"id":       1,
+			"body":     comment,
+			"user":     user(commenter),
+			"html_url": fmt.Sprintf("https://gitee.com/%s/%s/issues/%s#note_1", org, repo, number),
+		},
+		"repository": repository(org, repo),
+		"issue":      issue(org, repo, number, author),
+		"sender":     user(commenter),
+	}, e)
+	return e
+}
+
+// NewPullRequestEvent returns the event of the action on a PR, the action
+// being one of "open", "update" and "close".
+func NewPullRequestEvent(pr PR, action string) *sdk.PullRequestEvent {
+	state := "open"
+	if action == "close" {
+		state = "closed"
+	}
+
+	e := &sdk.PullRequestEvent{}
+	decode(map[string]interface{}{
+		"action":       action,
+		"state":        state,
+		"repository":   repository(pr.Org, pr.Repo),
+		"pull_request": pr.payload(),
+		"sender":       user(pr.Author),
+	}, e)
+	return e
+}
+
+// NewIssueEvent returns the event of the action on an issue, the action
+// being one of "open", "update" and "close".
+func NewIssueEvent(org, repo, number, author, action string) *sdk.IssueEvent {
+	e := &sdk.IssueEvent{}
+	decode(map[string]interface{}{
+		"action":     action,
+		"repository": repository(org, repo),
+		"issue":      issue(org, repo, number, author),
+		"sender":     user(author),
+	}, e)
+	return e
+}
+
+func issue(org, repo, number, author string) map[string]interface{} {
+	return map[string]interface{}{
+		"number":   number,
+		"state":    "open",
+		"html_url": fmt.Sprintf("https://gitee.com/%s/%s/issues/%s", org, repo, number),
+		"user":     user(author),
+		"labels":   []interface{}{},
+	}
+}
+
+// decode converts the payload to the event the way it is done for the
+// requests of the webhooks. Failures are programming errors of the fixtures.
+func decode(payload map[string]interface{}, e interface{}) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(b, e); err != nil {
+		panic(fmt.Sprintf("failed to decode the fixture: %v", err))
+	}
+}
@@ -0,0 +1,669 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakegitee provides an in-memory gitee.Client for tests.
+package fakegitee
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+const botName = "gitee-robot"
+
+const (
+	// Bot is the exported botName
+	Bot = botName
+	// TestRef is the ref returned when calling GetRef
+	TestRef = "abcde"
+)
+
+var _ gitee.Client = (*FakeClient)(nil)
+
+// FakeClient is like client, but fake.
+type FakeClient struct {
+	PullRequests       map[int]*sdk.PullRequest
+	PullRequestChanges map[int][]github.PullRequestChange
+	PRComments         map[int][]sdk.PullRequestComments
+	PRCommentID        int
+	Collaborators      []string
+	OrgMembers         map[string][]string
+	Commits            map[string]github.SingleCommit
+	// org -> repositories
+	Repos map[string][]sdk.Project
+	// org -> admins, who are org members too
+	OrgAdmins map[string][]string
+
+	// org/repo:label
+	RepoLabelsExisting []string
+
+	// org/repo:login -> pull, push or admin
+	RepoCollaborators map[string]string
+
+	// org/repo#number:label
+	PRLabelsAdded    []string
+	PRLabelsExisting []string
+	PRLabelsRemoved  []string
+
+	// org/repo#number:body
+	PRCommentsAdded []string
+	// org/repo#commentid:body
+	PRCommentsEdited []string
+	// org/repo#commentid
+	PRCommentsDeleted []string
+
+	// org/repo#number:assignee
+	AssigneesAdded   []string
+	AssigneesRemoved []string
+
+	// org/repo#number:body, the number being the issue id like I1ABCD
+	IssueCommentsAdded []string
+	// org/repo#number:assignee, the assignee being blank if unassigned
+	IssueAssignees []string
+	// org/repo -> issues
+	Issues map[string][]sdk.Issue
+	// org/repo#number:label, the number being the issue id like I1ABCD
+	IssueLabelsAdded    []string
+	IssueLabelsExisting []string
+	IssueLabelsRemoved  []string
+	// org/repo#number, the number being the issue id like I1ABCD
+	IssuesClosed   []string
+	IssuesReopened []string
+
+	// org/repo -> branches
+	Branches map[string][]sdk.Branch
+	// org/repo=branch -> who can push and merge
+	BranchSettings map[string]sdk.BranchProtectionPutParam
+	// org/repo -> code review settings
+	RepoReviewers map[string]sdk.ProjectReviewerPutParam
+
+	// org/repo#number:merge_method
+	PRsMerged []string
+	// PRs created by CreatePullRequest
+	PRsCreated []sdk.PullRequest
+}
+
+// NewFakeClient returns a FakeClient with the maps initialized.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		PullRequests:       map[int]*sdk.PullRequest{},
+		PullRequestChanges: map[int][]github.PullRequestChange{},
+		PRComments:         map[int][]sdk.PullRequestComments{},
+		OrgMembers:         map[string][]string{},
+		OrgAdmins:          map[string][]string{},
+		RepoCollaborators:  map[string]string{},
+		Issues:             map[string][]sdk.Issue{},
+		Branches:           map[string][]sdk.Branch{},
+		BranchSettings:     map[string]sdk.BranchProtectionPutParam{},
+		RepoReviewers:      map[string]sdk.ProjectReviewerPutParam{},
+		Commits:            map[string]github.SingleCommit{},
+		Repos:              map[string][]sdk.Project{},
+	}
+}
+
+// BotName returns authenticated login.
+func (f *FakeClient) BotName() (string, error) {
+	return botName, nil
+}
+
+// BotUser returns the authenticated user.
+func (f *FakeClient) BotUser() (*github.User, error) {
+	return &github.User{Login: botName}, nil
+}
+
+// Email returns the email of the authenticated user.
+func (f *FakeClient) Email() (string, error) {
+	return botName + "@example.com", nil
+}
+
+// Throttle does nothing.
+func (f *FakeClient) Throttle(hourlyTokens, burst int) {}
+
+// CreatePullRequest creates a PR and records it.
+func (f *FakeClient) CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (sdk.PullRequest, error) {
+	pr := sdk.PullRequest{
+		Number: int32(len(f.PullRequests) + len(f.PRsCreated) + 1),
+		Title:  title,
+		Body:   body,
+		State:  "open",
+		Head:   &sdk.BranchBasic{Ref: head},
+		Base:   &sdk.BranchBasic{Ref: base},
+	}
+	f.PRsCreated = append(f.PRsCreated, pr)
+	return pr, nil
+}
+
+// GetPullRequests returns the PRs in the state.
+func (f *FakeClient) GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error) {
+	var r []sdk.PullRequest
+	for _, pr := range f.PullRequests {
+		if state != "" && state != "all" && pr.State != state {
+			continue
+		}
+		if head != "" && (pr.Head == nil || pr.Head.Ref != head) {
+			continue
+		}
+		if base != "" && (pr.Base == nil || pr.Base.Ref != base) {
+			continue
+		}
+		r = append(r, *pr)
+	}
+	return r, nil
+}
+
+// UpdatePullRequest updates the title, body and state of the PR.
+func (f *FakeClient) UpdatePullRequest(org, repo string, number int32, title, body, state, labels string) (sdk.PullRequest, error) {
+	pr, exists := f.PullRequests[int(number)]
+	if !exists {
+		return sdk.PullRequest{}, fmt.Errorf("pull request number %d does not exist", number)
+	}
+	if title != "" {
+		pr.Title = title
+	}
+	if body != "" {
+		pr.Body = body
+	}
+	if state != "" {
+		pr.State = state
+	}
+	return *pr, nil
+}
+
+// MergePR records the merge of the PR.
+func (f *FakeClient) MergePR(owner, repo string, number int, opt sdk.PullRequestMergePutParam) error {
+	if _, exists := f.PullRequests[number]; !exists {
+		return fmt.Errorf("pull request number %d does not exist", number)
+	}
+	f.PRsMerged = append(f.PRsMerged, fmt.Sprintf("%s/%s#%d:%s", owner, repo, number, opt.MergeMethod))
+	return nil
+}
+
+// GetRepos returns the repositories of the org.
+func (f *FakeClient) GetRepos(org string) ([]sdk.Project, error) {
+	return f.Repos[org], nil
+}
+
+// GetGiteePullRequest returns details about the PR.
+func (f *FakeClient) GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error) {
+	val, exists := f.PullRequests[number]
+	if !exists {
+		return sdk.PullRequest{}, fmt.Errorf("pull request number %d does not exist", number)
+	}
+	return *val, nil
+}
+
+// ListCollaborators lists the collaborators, including those of the repo
+// in RepoCollaborators.
+func (f *FakeClient) ListCollaborators(org, repo string) ([]github.User, error) {
+	var r []github.User
+	for _, c := range f.Collaborators {
+		r = append(r, github.User{Login: c})
+	}
+	prefix := org + "/" + repo + ":"
+	for k := range f.RepoCollaborators {
+		if strings.HasPrefix(k, prefix) {
+			r = append(r, github.User{Login: strings.TrimPrefix(k, prefix)})
+		}
+	}
+	return r, nil
+}
+
+// IsCollaborator returns true if the user is a collaborator of the repo.
+func (f *FakeClient) IsCollaborator(owner, repo, login string) (bool, error) {
+	return sets.NewString(f.Collaborators...).Has(login), nil
+}
+
+// IsMember returns true if user is in org.
+func (f *FakeClient) IsMember(org, login string) (bool, error) {
+	return sets.NewString(f.OrgMembers[org]...).Has(login), nil
+}
+
+// ListOrgMembers returns the members of the org with the role.
+func (f *FakeClient) ListOrgMembers(org, role string) ([]sdk.UserBasic, error) {
+	admins := sets.NewString(f.OrgAdmins[org]...)
+	var r []sdk.UserBasic
+	for _, m := range sets.NewString(f.OrgMembers[org]...).Union(admins).List() {
+		if (role == github.RoleAdmin && !admins.Has(m)) || (role == github.RoleMember && admins.Has(m)) {
+			continue
+		}
+		r = append(r, sdk.UserBasic{Login: m})
+	}
+	return r, nil
+}
+
+// UpdateOrgMembership adds the user to the org with the role.
+func (f *FakeClient) UpdateOrgMembership(org, login string, admin bool) error {
+	f.OrgMembers[org] = sets.NewString(f.OrgMembers[org]...).Insert(login).List()
+	admins := sets.NewString(f.OrgAdmins[org]...)
+	if admin {
+		admins.Insert(login)
+	} else {
+		admins.Delete(login)
+	}
+	f.OrgAdmins[org] = admins.List()
+	return nil
+}
+
+// RemoveOrgMembership removes the user from the org.
+func (f *FakeClient) RemoveOrgMembership(org, login string) error {
+	f.OrgMembers[org] = sets.NewString(f.OrgMembers[org]...).Delete(login).List()
+	f.OrgAdmins[org] = sets.NewString(f.OrgAdmins[org]...).Delete(login).List()
+	return nil
+}
+
+// CreateRepo adds the repository to the org.
+func (f *FakeClient) CreateRepo(org string, repo sdk.RepositoryPostParam) (sdk.Project, error) {
+	for _, p := range f.Repos[org] {
+		if p.Path == repo.Path {
+			return sdk.Project{}, fmt.Errorf("repo %s/%s already exists", org, repo.Path)
+		}
+	}
+	p := sdk.Project{
+		Name:          repo.Name,
+		Path:          repo.Path,
+		FullName:      org + "/" + repo.Path,
+		Description:   repo.Description,
+		Homepage:      repo.Homepage,
+		Private:       repo.Private,
+		DefaultBranch: "master",
+	}
+	f.Repos[org] = append(f.Repos[org], p)
+	return p, nil
+}
+
+// UpdateRepo applies the patch to the repository.
+func (f *FakeClient) UpdateRepo(org, repo string, patch sdk.RepoPatchParam) (sdk.Project, error) {
+	for i, p := range f.Repos[org] {
+		if p.Path != repo {
+			continue
+		}
+		p.Name = patch.Name
+		p.Description = patch.Description
+		p.Homepage = patch.Homepage
+		p.Private = patch.Private
+		if patch.DefaultBranch != "" {
+			p.DefaultBranch = patch.DefaultBranch
+		}
+		f.Repos[org][i] = p
+		return p, nil
+	}
+	return sdk.Project{}, fmt.Errorf("repo %s/%s does not exist", org, repo)
+}
+
+// GetRepoCollaboratorPermission returns the permission of the collaborator.
+func (f *FakeClient) GetRepoCollaboratorPermission(org, repo, login string) (string, error) {
+	p, ok := f.RepoCollaborators[fmt.Sprintf("%s/%s:%s", org, repo, login)]
+	if !ok {
+		return "", fmt.Errorf("%s is not a collaborator of %s/%s", login, org, repo)
+	}
+	return p, nil
+}
+
+// AddRepoCollaborator sets the permission of the collaborator.
+func (f *FakeClient) AddRepoCollaborator(org, repo, login, permission string) error {
+	f.RepoCollaborators[fmt.Sprintf("%s/%s:%s", org, repo, login)] = permission
+	return nil
+}
+
+// RemoveRepoCollaborator removes the collaborator.
+func (f *FakeClient) RemoveRepoCollaborator(org, repo, login string) error {
+	delete(f.RepoCollaborators, fmt.Sprintf("%s/%s:%s", org, repo, login))
+	return nil
+}
+
+// GetRepo returns the repository of the org.
+func (f *FakeClient) GetRepo(org, repo string) (sdk.Project, error) {
+	for _, p := range f.Repos[org] {
+		if p.Path == repo {
+			return p, nil
+		}
+	}
+	return sdk.Project{}, fmt.Errorf("repo %s/%s does not exist", org, repo)
+}
+
+// GetBranches returns the branches of the repository.
+func (f *FakeClient) GetBranches(org, repo string, onlyProtected bool) ([]sdk.Branch, error) {
+	var r []sdk.Branch
+	for _, b := range f.Branches[org+"/"+repo] {
+		if !onlyProtected || b.Protected {
+			r = append(r, b)
+		}
+	}
+	return r, nil
+}
+
+// ProtectBranch marks the branch as protected.
+func (f *FakeClient) ProtectBranch(org, repo, branch string) error {
+	return f.setBranchProtected(org, repo, branch, true)
+}
+
+// UnprotectBranch marks the branch as unprotected and drops its settings.
+func (f *FakeClient) UnprotectBranch(org, repo, branch string) error {
+	delete(f.BranchSettings, fmt.Sprintf("%s/%s=%s", org, repo, branch))
+	return f.setBranchProtected(org, repo, branch, false)
+}
+
+func (f *FakeClient) setBranchProtected(org, repo, branch string, protected bool) error {
+	bs := f.Branches[org+"/"+repo]
+	for i := range bs {
+		if bs[i].Name == branch {
+			bs[i].Protected = protected
+			return nil
+		}
+	}
+	return fmt.Errorf("branch %s does not exist in %s/%s", branch, org, repo)
+}
+
+// UpdateBranchSetting records who can push to and merge into the branch.
+func (f *FakeClient) UpdateBranchSetting(org, repo, branch string, setting sdk.BranchProtectionPutParam) error {
+	setting.Wildcard = branch
+	f.BranchSettings[fmt.Sprintf("%s/%s=%s", org, repo, branch)] = setting
+	return nil
+}
+
+// UpdateRepoReviewer records the code review settings of the repository.
+func (f *FakeClient) UpdateRepoReviewer(org, repo string, reviewer sdk.ProjectReviewerPutParam) error {
+	f.RepoReviewers[org+"/"+repo] = reviewer
+	return nil
+}
+
+// GetRef returns the hash of a ref.
+func (f *FakeClient) GetRef(org, repo, ref string) (string, error) {
+	return TestRef, nil
+}
+
+// GetSingleCommit returns a single commit.
+func (f *FakeClient) GetSingleCommit(org, repo, SHA string) (github.SingleCommit, error) {
+	return f.Commits[SHA], nil
+}
+
+// GetPullRequestChanges returns the file modifications in a PR.
+func (f *FakeClient) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	return f.PullRequestChanges[number], nil
+}
+
+// GetPRLabels returns the labels on the PR.
+func (f *FakeClient) GetPRLabels(org, repo string, number int) ([]sdk.Label, error) {
+	re := regexp.MustCompile(fmt.Sprintf(`^%s/%s#%d:(.*)$`, org, repo, number))
+	la := []sdk.Label{}
+	allLabels := sets.NewString(f.PRLabelsExisting...)
+	allLabels.Insert(f.PRLabelsAdded...)
+	allLabels.Delete(f.PRLabelsRemoved...)
+	for _, l := range allLabels.List() {
+		groups := re.FindStringSubmatch(l)
+		if groups != nil {
+			la = append(la, sdk.Label{Name: groups[1]})
+		}
+	}
+	return la, nil
+}
+
+// GetRepoLabels returns the labels of the repository.
+func (f *FakeClient) GetRepoLabels(owner, repo string) ([]sdk.Label, error) {
+	prefix := owner + "/" + repo + ":"
+	var la []sdk.Label
+	for _, l := range f.RepoLabelsExisting {
+		if strings.HasPrefix(l, prefix) {
+			la = append(la, sdk.Label{Name: strings.TrimPrefix(l, prefix)})
+		}
+	}
+	return la, nil
+}
+
+// AddRepoLabel adds the label to the repository.
+func (f *FakeClient) AddRepoLabel(org, repo, name, color string) error {
+	labelString := fmt.Sprintf("%s/%s:%s", org, repo, name)
+	if sets.NewString(f.RepoLabelsExisting...).Has(labelString) {
+		return fmt.Errorf("label %s already exists in %s/%s", name, org, repo)
+	}
+	f.RepoLabelsExisting = append(f.RepoLabelsExisting, labelString)
+	return nil
+}
+
+// UpdateRepoLabel renames the label of the repository.
+func (f *FakeClient) UpdateRepoLabel(org, repo, currentName, newName, color string) error {
+	current := fmt.Sprintf("%s/%s:%s", org, repo, currentName)
+	for i, l := range f.RepoLabelsExisting {
+		if l == current {
+			f.RepoLabelsExisting[i] = fmt.Sprintf("%s/%s:%s", org, repo, newName)
+			return nil
+		}
+	}
+	return fmt.Errorf("label %s does not exist in %s/%s", currentName, org, repo)
+}
+
+// DeleteRepoLabel removes the label from the repository.
+func (f *FakeClient) DeleteRepoLabel(org, repo, label string) error {
+	f.RepoLabelsExisting = sets.NewString(f.RepoLabelsExisting...).Delete(fmt.Sprintf("%s/%s:%s", org, repo, label)).List()
+	return nil
+}
+
+// AddPRLabel adds a label to the PR.
+func (f *FakeClient) AddPRLabel(org, repo string, number int, label string) error {
+	labelString := fmt.Sprintf("%s/%s#%d:%s", org, repo, number, label)
+	if sets.NewString(f.PRLabelsAdded...).Has(labelString) {
+		return fmt.Errorf("cannot add %v to %s/%s/#%d", label, org, repo, number)
+	}
+	f.PRLabelsAdded = append(f.PRLabelsAdded, labelString)
+	return nil
+}
+
+// RemovePRLabel removes a label from the PR.
+func (f *FakeClient) RemovePRLabel(org, repo string, number int, label string) error {
+	labelString := fmt.Sprintf("%s/%s#%d:%s", org, repo, number, label)
+	if sets.NewString(f.PRLabelsRemoved...).Has(labelString) {
+		return fmt.Errorf("cannot remove %v from %s/%s/#%d", label, org, repo, number)
+	}
+	f.PRLabelsRemoved = append(f.PRLabelsRemoved, labelString)
+	return nil
+}
+
+// ClosePR closes the PR.
+func (f *FakeClient) ClosePR(org, repo string, number int) error {
+	return f.setPRState(number, "closed")
+}
+
+// ReopenPR reopens the PR.
+func (f *FakeClient) ReopenPR(org, repo string, number int) error {
+	return f.setPRState(number, "open")
+}
+
+func (f *FakeClient) setPRState(number int, state string) error {
+	pr, exists := f.PullRequests[number]
+	if !exists {
+		return fmt.Errorf("pull request number %d does not exist", number)
+	}
+	pr.State = state
+	return nil
+}
+
+// ListPRComments returns the comments on the PR.
+func (f *FakeClient) ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error) {
+	return append([]sdk.PullRequestComments{}, f.PRComments[number]...), nil
+}
+
+// CreatePRComment adds a comment to the PR.
+func (f *FakeClient) CreatePRComment(org, repo string, number int, comment string) error {
+	f.PRCommentsAdded = append(f.PRCommentsAdded, fmt.Sprintf("%s/%s#%d:%s", org, repo, number, comment))
+
+	now := time.Now().Format(time.RFC3339)
+	f.PRComments[number] = append(f.PRComments[number], sdk.PullRequestComments{
+		Id:        int32(f.PRCommentID),
+		Body:      comment,
+		User:      &sdk.UserBasic{Login: botName},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	f.PRCommentID++
+	return nil
+}
+
+// UpdatePRComment edits the body of a comment.
+func (f *FakeClient) UpdatePRComment(org, repo string, commentID int, comment string) error {
+	for num, cs := range f.PRComments {
+		for i := range cs {
+			if int(cs[i].Id) == commentID {
+				f.PRComments[num][i].Body = comment
+				f.PRCommentsEdited = append(f.PRCommentsEdited, fmt.Sprintf("%s/%s#%d:%s", org, repo, commentID, comment))
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("could not find pull request comment %d", commentID)
+}
+
+// DeletePRComment deletes a comment.
+func (f *FakeClient) DeletePRComment(org, repo string, ID int) error {
+	f.PRCommentsDeleted = append(f.PRCommentsDeleted, fmt.Sprintf("%s/%s#%d", org, repo, ID))
+	for num, cs := range f.PRComments {
+		for i := range cs {
+			if int(cs[i].Id) == ID {
+				f.PRComments[num] = append(cs[:i], cs[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("could not find pull request comment %d", ID)
+}
+
+// AssignPR adds assignees to the PR.
+func (f *FakeClient) AssignPR(owner, repo string, number int, logins []string) error {
+	for _, l := range logins {
+		f.AssigneesAdded = append(f.AssigneesAdded, fmt.Sprintf("%s/%s#%d:%s", owner, repo, number, l))
+	}
+	return nil
+}
+
+// UnassignPR removes assignees from the PR.
+func (f *FakeClient) UnassignPR(owner, repo string, number int, logins []string) error {
+	for _, l := range logins {
+		f.AssigneesRemoved = append(f.AssigneesRemoved, fmt.Sprintf("%s/%s#%d:%s", owner, repo, number, l))
+	}
+	return nil
+}
+
+// AssignGiteeIssue sets the assignee of the issue.
+func (f *FakeClient) AssignGiteeIssue(org, repo string, number string, login string) error {
+	f.IssueAssignees = append(f.IssueAssignees, fmt.Sprintf("%s/%s#%s:%s", org, repo, number, login))
+	return nil
+}
+
+// UnassignGiteeIssue clears the assignee of the issue.
+func (f *FakeClient) UnassignGiteeIssue(org, repo string, number string, login string) error {
+	return f.AssignGiteeIssue(org, repo, number, "")
+}
+
+// CreateGiteeIssueComment adds a comment to the issue.
+func (f *FakeClient) CreateGiteeIssueComment(org, repo string, number string, comment string) error {
+	f.IssueCommentsAdded = append(f.IssueCommentsAdded, fmt.Sprintf("%s/%s#%s:%s", org, repo, number, comment))
+	return nil
+}
+
+// ListIssues returns the issues of the repository in the state which carry
+// all the comma separated labels.
+func (f *FakeClient) ListIssues(org, repo, state, labels string) ([]sdk.Issue, error) {
+	var want []string
+	if labels != "" {
+		want = strings.Split(labels, ",")
+	}
+
+	var r []sdk.Issue
+	for _, i := range f.Issues[org+"/"+repo] {
+		if state != "" && state != "all" && i.State != state {
+			continue
+		}
+		have := sets.NewString()
+		for _, l := range i.Labels {
+			have.Insert(l.Name)
+		}
+		if have.HasAll(want...) {
+			r = append(r, i)
+		}
+	}
+	return r, nil
+}
+
+// UpdateIssueLabels replaces the labels of the issue.
+func (f *FakeClient) UpdateIssueLabels(org, repo, number string, labels []string) error {
+	issues := f.Issues[org+"/"+repo]
+	for i := range issues {
+		if issues[i].Number != number {
+			continue
+		}
+		var ls []sdk.Label
+		for _, l := range labels {
+			ls = append(ls, sdk.Label{Name: l})
+		}
+		issues[i].Labels = ls
+		return nil
+	}
+	return fmt.Errorf("issue %s does not exist in %s/%s", number, org, repo)
+}
+
+// GetGiteeIssueLabels returns the labels on the issue.
+func (f *FakeClient) GetGiteeIssueLabels(org, repo, number string) ([]sdk.Label, error) {
+	prefix := fmt.Sprintf("%s/%s#%s:", org, repo, number)
+	la := []sdk.Label{}
+	allLabels := sets.NewString(f.IssueLabelsExisting...)
+	allLabels.Insert(f.IssueLabelsAdded...)
+	allLabels.Delete(f.IssueLabelsRemoved...)
+	for _, l := range allLabels.List() {
+		if strings.HasPrefix(l, prefix) {
+			la = append(la, sdk.Label{Name: strings.TrimPrefix(l, prefix)})
+		}
+	}
+	return la, nil
+}
+
+// AddGiteeIssueLabel adds a label to the issue.
+func (f *FakeClient) AddGiteeIssueLabel(org, repo, number, label string) error {
+	labelString := fmt.Sprintf("%s/%s#%s:%s", org, repo, number, label)
+	if sets.NewString(f.IssueLabelsAdded...).Has(labelString) {
+		return fmt.Errorf("cannot add %v to %s/%s/#%s", label, org, repo, number)
+	}
+	f.IssueLabelsAdded = append(f.IssueLabelsAdded, labelString)
+	return nil
+}
+
+// RemoveGiteeIssueLabel removes a label from the issue.
+func (f *FakeClient) RemoveGiteeIssueLabel(org, repo, number, label string) error {
+	labelString := fmt.Sprintf("%s/%s#%s:%s", org, repo, number, label)
+	if sets.NewString(f.IssueLabelsRemoved...).Has(labelString) {
+		return fmt.Errorf("cannot remove %v from %s/%s/#%s", label, org, repo, number)
+	}
+	f.IssueLabelsRemoved = append(f.IssueLabelsRemoved, labelString)
+	return nil
+}
+
+// CloseGiteeIssue records the close of the issue.
+func (f *FakeClient) CloseGiteeIssue(org, repo, number string) error {
+	f.IssuesClosed = append(f.IssuesClosed, fmt.Sprintf("%s/%s#%s", org, repo, number))
+	return nil
+}
+
+// ReopenGiteeIssue records the reopen of the issue.
+func (f *FakeClient) ReopenGiteeIssue(org, repo, number string) error {
+	f.IssuesReopened = append(f.IssuesReopened, fmt.Sprintf("%s/%s#%s", org, repo, number))
+	return nil
+}
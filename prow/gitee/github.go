@@ -26,5 +26,18 @@ func ConvertGiteePR(v *sdk.PullRequest) *github.PullRequest {
 	r.Head.SHA = v.Head.Sha
 	r.Head.Ref = v.Head.Ref
 	r.Base.Ref = v.Base.Ref
+	r.Base.SHA = v.Base.Sha
+	r.Base.Repo.Owner.Login = v.Base.Repo.Owner.Login
+	r.Base.Repo.Name = v.Base.Repo.Path
+	r.Base.Repo.FullName = v.Base.Repo.FullName
+	r.Base.Repo.HTMLURL = v.Base.Repo.HtmlUrl
+	r.Number = int(v.Number)
+	r.State = v.State
+	r.Title = v.Title
+	r.Body = v.Body
+	r.HTMLURL = v.HtmlUrl
+	r.User.Login = v.User.Login
+	r.User.HTMLURL = v.User.HtmlUrl
+	r.Mergable = &v.Mergeable
 	return &r
 }
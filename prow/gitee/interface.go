@@ -9,27 +9,65 @@ import (
 type Client interface {
 	github.UserClient
 
+	// Throttle limits the rate of the requests to at most hourlyTokens
+	// per hour, allowing burst tokens. Non-positive values disable it.
+	Throttle(hourlyTokens, burst int)
+
 	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (sdk.PullRequest, error)
 	GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error)
 	UpdatePullRequest(org, repo string, number int32, title, body, state, labels string) (sdk.PullRequest, error)
+	MergePR(owner, repo string, number int, opt sdk.PullRequestMergePutParam) error
+	GetRepos(org string) ([]sdk.Project, error)
 
 	ListCollaborators(org, repo string) ([]github.User, error)
 	GetRef(org, repo, ref string) (string, error)
 	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
 	GetPRLabels(org, repo string, number int) ([]sdk.Label, error)
+	GetRepoLabels(owner, repo string) ([]sdk.Label, error)
+	AddRepoLabel(org, repo, name, color string) error
+	UpdateRepoLabel(org, repo, currentName, newName, color string) error
+	DeleteRepoLabel(org, repo, label string) error
 	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
 	DeletePRComment(org, repo string, ID int) error
 	CreatePRComment(org, repo string, number int, comment string) error
+	UpdatePRComment(org, repo string, commentID int, comment string) error
 	AddPRLabel(org, repo string, number int, label string) error
 	RemovePRLabel(org, repo string, number int, label string) error
+	ClosePR(org, repo string, number int) error
+	ReopenPR(org, repo string, number int) error
 
 	AssignPR(owner, repo string, number int, logins []string) error
 	UnassignPR(owner, repo string, number int, logins []string) error
 	AssignGiteeIssue(org, repo string, number string, login string) error
 	UnassignGiteeIssue(org, repo string, number string, login string) error
 	CreateGiteeIssueComment(org, repo string, number string, comment string) error
+	ListIssues(org, repo, state, labels string) ([]sdk.Issue, error)
+	UpdateIssueLabels(org, repo, number string, labels []string) error
+	GetGiteeIssueLabels(org, repo, number string) ([]sdk.Label, error)
+	AddGiteeIssueLabel(org, repo, number, label string) error
+	RemoveGiteeIssueLabel(org, repo, number, label string) error
+	CloseGiteeIssue(org, repo, number string) error
+	ReopenGiteeIssue(org, repo, number string) error
 
 	IsCollaborator(owner, repo, login string) (bool, error)
+	IsMember(org, login string) (bool, error)
+	ListOrgMembers(org, role string) ([]sdk.UserBasic, error)
+	UpdateOrgMembership(org, login string, admin bool) error
+	RemoveOrgMembership(org, login string) error
+
+	CreateRepo(org string, repo sdk.RepositoryPostParam) (sdk.Project, error)
+	UpdateRepo(org, repo string, patch sdk.RepoPatchParam) (sdk.Project, error)
+	GetRepoCollaboratorPermission(org, repo, login string) (string, error)
+	AddRepoCollaborator(org, repo, login, permission string) error
+	RemoveRepoCollaborator(org, repo, login string) error
+
+	GetRepo(org, repo string) (sdk.Project, error)
+	GetBranches(org, repo string, onlyProtected bool) ([]sdk.Branch, error)
+	ProtectBranch(org, repo, branch string) error
+	UnprotectBranch(org, repo, branch string) error
+	UpdateBranchSetting(org, repo, branch string, setting sdk.BranchProtectionPutParam) error
+	UpdateRepoReviewer(org, repo string, reviewer sdk.ProjectReviewerPutParam) error
+
 	GetGiteePullRequest(org, repo string, number int) (sdk.PullRequest, error)
 	GetSingleCommit(org, repo, SHA string) (github.SingleCommit, error)
 }
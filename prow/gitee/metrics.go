@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/test-infra/ghproxy/ghmetrics"
+)
+
+// requestRetriesCounter provides the 'gitee_request_retries' counter that
+// keeps track of the retried Gitee requests by API path and reason.
+var requestRetriesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gitee_request_retries",
+		Help: "How many Gitee requests were retried by API path and reason.",
+	},
+	[]string{"path", "reason"},
+)
+
+// webhookRejectedCounter provides the 'gitee_webhook_rejected' counter that
+// keeps track of the webhooks rejected for their signature or their timestamp.
+var webhookRejectedCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "gitee_webhook_rejected",
+		Help: "How many Gitee webhooks were rejected by reason.",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	prometheus.MustRegister(requestRetriesCounter)
+	prometheus.MustRegister(webhookRejectedCounter)
+}
+
+// collectRequestMetrics publishes the duration of a request by API path with
+// the labels ghproxy uses for the requests it sends to Gitee.
+func collectRequestMetrics(tokenHash, path, statusCode, userAgent string, roundTripTime float64) {
+	ghmetrics.CollectGiteeRequestMetrics(tokenHash, path, statusCode, userAgent, roundTripTime)
+}
+
+// collectRetryMetrics publishes a retried request to `gitee_request_retries`
+// on prometheus.
+func collectRetryMetrics(path, reason string) {
+	requestRetriesCounter.With(prometheus.Labels{"path": ghmetrics.SimplifyGiteePath(path), "reason": reason}).Inc()
+}
+
+// collectWebhookRejectedMetrics publishes a rejected webhook to
+// `gitee_webhook_rejected` on prometheus.
+func collectWebhookRejectedMetrics(reason string) {
+	webhookRejectedCounter.With(prometheus.Labels{"reason": reason}).Inc()
+}
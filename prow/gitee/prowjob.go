@@ -0,0 +1,88 @@
+package gitee
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/kube"
+)
+
+// HostURL is the url of Gitee web site.
+const HostURL = "https://gitee.com"
+
+// IsGiteeJob returns whether the ProwJob runs against a repository hosted
+// on Gitee, which is determined by the link of the repository.
+func IsGiteeJob(pj *prowapi.ProwJob) bool {
+	refs := pj.Spec.Refs
+	return refs != nil && strings.HasPrefix(refs.RepoLink, HostURL+"/")
+}
+
+// ProwJobLister lists the ProwJobs matching the labels.
+type ProwJobLister func(set labels.Set) ([]prowapi.ProwJob, error)
+
+// CombinedStatus builds the combined status of a commit of the PR from the
+// presubmit ProwJobs that ran against it, because Gitee has no commit status
+// API. Only the newest job of each context is taken into account. The jobs
+// of all the PRs of the repo are listed if number is 0.
+func CombinedStatus(list ProwJobLister, org, repo string, number int, ref string) (*github.CombinedStatus, error) {
+	r := &github.CombinedStatus{SHA: ref}
+
+	set := labels.Set{
+		kube.OrgLabel:         org,
+		kube.RepoLabel:        repo,
+		kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+	}
+	if number > 0 {
+		set[kube.PullLabel] = strconv.Itoa(number)
+	}
+	jobs, err := list(set)
+	if err != nil {
+		return r, fmt.Errorf("failed to list prowjobs for %s/%s@%s: %v", org, repo, ref, err)
+	}
+
+	latest := map[string]prowapi.ProwJob{}
+	for _, job := range jobs {
+		refs := job.Spec.Refs
+		if refs == nil || len(refs.Pulls) == 0 || refs.Pulls[0].SHA != ref {
+			continue
+		}
+
+		ctx := job.Spec.Context
+		if v, ok := latest[ctx]; ok && !v.Status.StartTime.Before(&job.Status.StartTime) {
+			continue
+		}
+		latest[ctx] = job
+	}
+
+	for ctx, job := range latest {
+		r.Statuses = append(r.Statuses, github.Status{
+			State:       stateToStatus(job.Status.State),
+			TargetURL:   job.Status.URL,
+			Description: job.Status.Description,
+			Context:     ctx,
+		})
+	}
+	sort.Slice(r.Statuses, func(i, j int) bool {
+		return r.Statuses[i].Context < r.Statuses[j].Context
+	})
+	return r, nil
+}
+
+func stateToStatus(state prowapi.ProwJobState) string {
+	switch state {
+	case prowapi.TriggeredState, prowapi.PendingState:
+		return github.StatusPending
+	case prowapi.SuccessState:
+		return github.StatusSuccess
+	case prowapi.ErrorState:
+		return github.StatusError
+	default:
+		return github.StatusFailure
+	}
+}
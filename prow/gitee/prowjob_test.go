@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/kube"
+)
+
+func TestCombinedStatus(t *testing.T) {
+	now := time.Now()
+	newJob := func(context, sha string, state prowapi.ProwJobState, age time.Duration) prowapi.ProwJob {
+		return prowapi.ProwJob{
+			Spec: prowapi.ProwJobSpec{
+				Type:    prowapi.PresubmitJob,
+				Context: context,
+				Refs:    &prowapi.Refs{Org: "org", Repo: "repo", Pulls: []prowapi.Pull{{Number: 5, SHA: sha}}},
+			},
+			Status: prowapi.ProwJobStatus{
+				State:     state,
+				StartTime: metav1.NewTime(now.Add(-age)),
+				URL:       "https://prow/" + context,
+			},
+		}
+	}
+
+	var listed labels.Set
+	list := func(set labels.Set) ([]prowapi.ProwJob, error) {
+		listed = set
+		return []prowapi.ProwJob{
+			newJob("unit", "sha", prowapi.FailureState, 2*time.Hour),
+			newJob("unit", "sha", prowapi.SuccessState, time.Hour),
+			newJob("lint", "sha", prowapi.PendingState, time.Hour),
+			newJob("e2e", "old", prowapi.FailureState, time.Hour),
+		}, nil
+	}
+
+	status, err := CombinedStatus(list, "org", "repo", 5, "sha")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedSet := labels.Set{
+		kube.OrgLabel:         "org",
+		kube.RepoLabel:        "repo",
+		kube.ProwJobTypeLabel: string(prowapi.PresubmitJob),
+		kube.PullLabel:        "5",
+	}
+	if !reflect.DeepEqual(listed, expectedSet) {
+		t.Errorf("expected the jobs matching %v to be listed, got %v", expectedSet, listed)
+	}
+	expected := []github.Status{
+		{State: github.StatusPending, TargetURL: "https://prow/lint", Context: "lint"},
+		{State: github.StatusSuccess, TargetURL: "https://prow/unit", Context: "unit"},
+	}
+	if status.SHA != "sha" || !reflect.DeepEqual(status.Statuses, expected) {
+		t.Errorf("expected the statuses %v, got %v", expected, status.Statuses)
+	}
+
+	if _, err := CombinedStatus(list, "org", "repo", 0, "sha"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := listed[kube.PullLabel]; ok {
+		t.Errorf("expected the jobs of all the PRs to be listed without a PR, got %v", listed)
+	}
+}
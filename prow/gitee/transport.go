@@ -0,0 +1,292 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxRetries   = 8
+	defaultInitialDelay = 2 * time.Second
+	defaultMaxSleepTime = 2 * time.Minute
+)
+
+// throttler sets a ceiling on the rate of Gitee requests.
+// Configure with Client.Throttle()
+type throttler struct {
+	lock     sync.RWMutex
+	ticker   *time.Ticker
+	throttle chan time.Time
+	// stop is closed when the throttle is reconfigured, releasing the
+	// requests waiting for a token of the previous configuration.
+	stop chan struct{}
+	slow int32 // Helps log once when requests start/stop being throttled
+}
+
+// Wait blocks until a token is available. It returns immediately if the
+// throttle is disabled.
+func (t *throttler) Wait(log *logrus.Entry) {
+	t.lock.RLock()
+	throttle, stop := t.throttle, t.stop
+	t.lock.RUnlock()
+	if throttle == nil {
+		return
+	}
+
+	log = log.WithField("throttled", true)
+	select {
+	case <-throttle:
+		// If we were throttled and the channel is now somewhat (25%+) full, note this
+		if len(throttle) > cap(throttle)/4 && atomic.CompareAndSwapInt32(&t.slow, 1, 0) {
+			log.Debug("Unthrottled")
+		}
+		return
+	default: // Do not wait if nothing is available right now
+	}
+	// If this is the first time we are waiting, note this
+	if slow := atomic.SwapInt32(&t.slow, 1); slow == 0 {
+		log.Debug("Throttled")
+	}
+	select {
+	case <-throttle:
+	case <-stop:
+		log.Debug("Throttle reconfigured")
+	}
+}
+
+// Set limits the rate to at most hourlyTokens requests per hour, allowing
+// burst tokens. A non-positive value disables the throttle.
+func (t *throttler) Set(hourlyTokens, burst int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.ticker != nil {
+		t.ticker.Stop()
+		close(t.stop)
+		t.ticker, t.throttle, t.stop = nil, nil, nil
+	}
+	if hourlyTokens <= 0 || burst <= 0 { // Disable throttle
+		return
+	}
+
+	rate := time.Hour / time.Duration(hourlyTokens)
+	ticker := time.NewTicker(rate)
+	throttle := make(chan time.Time, burst)
+	stop := make(chan struct{})
+	for i := 0; i < burst; i++ { // Fill up the channel
+		throttle <- time.Now()
+	}
+	go func() {
+		// Refill the channel
+		for {
+			select {
+			case t := <-ticker.C:
+				select {
+				case throttle <- t:
+				default:
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	t.ticker, t.throttle, t.stop = ticker, throttle, stop
+}
+
+// transport sends the requests of the SDK to Gitee. It waits for the
+// throttle, retries the requests which failed because of a connection
+// problem, a server error or a rate limit, and records the request metrics.
+type transport struct {
+	delegate http.RoundTripper
+	logger   *logrus.Entry
+	throttle throttler
+
+	// bases are the API endpoints to use in order of preference, the SDK
+	// sends the requests to the first one. An endpoint is used when all
+	// preceding endpoints have returned a connection error.
+	bases []*url.URL
+
+	maxRetries   int
+	initialDelay time.Duration
+	maxSleepTime time.Duration
+	sleep        func(time.Duration)
+}
+
+func newTransport(delegate http.RoundTripper, logger *logrus.Entry, bases ...*url.URL) *transport {
+	return &transport{
+		delegate:     delegate,
+		logger:       logger,
+		bases:        bases,
+		maxRetries:   defaultMaxRetries,
+		initialDelay: defaultInitialDelay,
+		maxSleepTime: defaultMaxSleepTime,
+		sleep:        time.Sleep,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tokenHash := hashAuthHeader(req.Header.Get("Authorization"))
+	userAgent := req.Header.Get("User-Agent")
+	log := t.logger.WithFields(logrus.Fields{"method": req.Method, "path": req.URL.Path})
+
+	var hostIndex int
+	var resp *http.Response
+	var err error
+	backoff := t.initialDelay
+	for retries := 0; ; retries++ {
+		r, rerr := t.request(req, retries, hostIndex)
+		if rerr != nil {
+			return nil, rerr
+		}
+
+		t.throttle.Wait(log)
+		start := time.Now()
+		resp, err = t.delegate.RoundTrip(r)
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		collectRequestMetrics(tokenHash, r.URL.Path, status, userAgent, time.Since(start).Seconds())
+
+		if retries+1 >= t.maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var reason string
+		sleepTime := backoff
+		rateLimited := false
+		switch {
+		case err != nil:
+			// Connection problem. Try a different host.
+			reason = "connection"
+			if len(t.bases) > 1 {
+				hostIndex = (hostIndex + 1) % len(t.bases)
+				log = log.WithField("endpoint", t.bases[hostIndex].String())
+			}
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden:
+			d, limited, perr := rateLimitWait(resp.Header)
+			if perr != nil {
+				log.WithError(perr).Warn("Failed to parse the rate limit headers.")
+				return resp, nil
+			}
+			if !limited && resp.StatusCode == http.StatusForbidden {
+				// A plain 403 is a permission problem, retrying won't help.
+				return resp, nil
+			}
+			if limited {
+				sleepTime, rateLimited = d, true
+			}
+			reason = "ratelimit"
+		case resp.StatusCode >= 500:
+			reason = "5xx"
+		default:
+			// Normal, happy case.
+			return resp, nil
+		}
+
+		if sleepTime >= t.maxSleepTime {
+			log.WithField("backoff", sleepTime.String()).Warnf("Not retrying, the wait exceeds the max sleep time %v.", t.maxSleepTime)
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		collectRetryMetrics(r.URL.Path, reason)
+		log.WithField("backoff", sleepTime.String()).WithField("reason", reason).Debug("Retrying request")
+		t.sleep(sleepTime)
+		if !rateLimited {
+			backoff *= 2
+		}
+	}
+}
+
+// request returns the request to send for the attempt. The retries are sent
+// with a fresh body, to the endpoint at hostIndex.
+func (t *transport) request(req *http.Request, retries, hostIndex int) (*http.Request, error) {
+	if retries == 0 {
+		return req, nil
+	}
+
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("cannot retry %s %s, the body can't be read again", req.Method, req.URL.Path)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	if hostIndex > 0 {
+		// The SDK sends the requests to the first endpoint, replace it.
+		base := t.bases[hostIndex]
+		r.URL.Scheme = base.Scheme
+		r.URL.Host = base.Host
+		r.URL.Path = base.Path + strings.TrimPrefix(req.URL.Path, t.bases[0].Path)
+		r.Host = ""
+	}
+	return r, nil
+}
+
+// rateLimitWait returns how long to wait before sending a request again
+// according to the headers of a rate limited response. The bool reports
+// whether the headers say the request was rate limited at all.
+func rateLimitWait(h http.Header) (time.Duration, bool, error) {
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		// The X-RateLimit-Reset header tells us the time at which we can
+		// request again. Sleep an extra second to be safe.
+		raw := h.Get("X-RateLimit-Reset")
+		t, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to parse rate limit reset unix time %q: %v", raw, err)
+		}
+		return time.Until(time.Unix(int64(t), 0)) + time.Second, true, nil
+	}
+
+	if raw := h.Get("Retry-After"); raw != "" && raw != "0" {
+		t, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to parse rate limit wait time %q: %v", raw, err)
+		}
+		return time.Duration(t+1) * time.Second, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// hashAuthHeader returns a hash of the authorization header which can be
+// used as a label for the token.
+func hashAuthHeader(h string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(h))
+	return fmt.Sprintf("%x", hasher.Sum(nil)) // use %x to make this a utf-8 string for use as a label
+}
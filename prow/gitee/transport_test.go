@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeResponse struct {
+	status int
+	header map[string]string
+}
+
+func TestTransportRetries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		responses []fakeResponse
+
+		expectedStatus int
+		expectedSleeps []time.Duration
+	}{
+		{
+			name:           "success is not retried",
+			responses:      []fakeResponse{{status: 200}},
+			expectedStatus: 200,
+		},
+		{
+			name:           "5xx are retried with exponential backoff",
+			responses:      []fakeResponse{{status: 502}, {status: 500}, {status: 200}},
+			expectedStatus: 200,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:           "retries stop after the max retries",
+			responses:      []fakeResponse{{status: 500}, {status: 500}, {status: 500}, {status: 500}},
+			expectedStatus: 500,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name: "429 waits for Retry-After",
+			responses: []fakeResponse{
+				{status: 429, header: map[string]string{"Retry-After": "5"}},
+				{status: 201},
+			},
+			expectedStatus: 201,
+			expectedSleeps: []time.Duration{6 * time.Second},
+		},
+		{
+			name:           "429 without Retry-After backs off",
+			responses:      []fakeResponse{{status: 429}, {status: 200}},
+			expectedStatus: 200,
+			expectedSleeps: []time.Duration{time.Second},
+		},
+		{
+			name: "403 with Retry-After is an abuse rate limit",
+			responses: []fakeResponse{
+				{status: 403, header: map[string]string{"Retry-After": "1"}},
+				{status: 200},
+			},
+			expectedStatus: 200,
+			expectedSleeps: []time.Duration{2 * time.Second},
+		},
+		{
+			name:           "plain 403 is not retried",
+			responses:      []fakeResponse{{status: 403}, {status: 200}},
+			expectedStatus: 403,
+		},
+		{
+			name: "wait exceeding the max sleep time is not retried",
+			responses: []fakeResponse{
+				{status: 429, header: map[string]string{"Retry-After": "3600"}},
+				{status: 200},
+			},
+			expectedStatus: 429,
+		},
+		{
+			name:           "404 is not retried",
+			responses:      []fakeResponse{{status: 404}, {status: 200}},
+			expectedStatus: 404,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+
+				resp := tc.responses[len(bodies)-1]
+				for k, v := range resp.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(resp.status)
+			}))
+			defer server.Close()
+
+			var sleeps []time.Duration
+			tr := newTransport(http.DefaultTransport, logrus.WithField("client", "gitee"))
+			tr.maxRetries = 3
+			tr.initialDelay = time.Second
+			tr.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v5/repos/org/repo/pulls/1/comments", bytes.NewBufferString("body"))
+			if err != nil {
+				t.Fatalf("failed to create the request: %v", err)
+			}
+			resp, err := tr.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if !reflect.DeepEqual(sleeps, tc.expectedSleeps) {
+				t.Errorf("expected sleeps %v, got %v", tc.expectedSleeps, sleeps)
+			}
+			for i, b := range bodies {
+				if b != "body" {
+					t.Errorf("expected the body of request %d to be resent, got %q", i, b)
+				}
+			}
+		})
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	var th throttler
+	log := logrus.WithField("client", "gitee")
+
+	waited := func() bool {
+		done := make(chan struct{})
+		go func() {
+			th.Wait(log)
+			close(done)
+		}()
+		select {
+		case <-done:
+			return false
+		case <-time.After(100 * time.Millisecond):
+			return true
+		}
+	}
+
+	if waited() {
+		t.Fatal("expected no wait without throttle")
+	}
+
+	th.Set(1, 2)
+	for i := 0; i < 2; i++ {
+		if waited() {
+			t.Fatalf("expected no wait for the token %d of the burst", i)
+		}
+	}
+	blocked := make(chan struct{})
+	go func() {
+		th.Wait(log)
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("expected to wait once the burst is consumed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	th.Set(0, 0)
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Error("expected the waiting request to be released once the throttle is disabled")
+	}
+	if waited() {
+		t.Error("expected no wait once the throttle is disabled")
+	}
+}
+
+func TestTransportFallsBackToNextEndpoint(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	proxy, _ := url.Parse(down.URL + "/api/v5")
+	upstream, _ := url.Parse(server.URL + "/gitee/api/v5")
+
+	var sleeps []time.Duration
+	tr := newTransport(http.DefaultTransport, logrus.WithField("client", "gitee"), proxy, upstream)
+	tr.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	req, err := http.NewRequest(http.MethodGet, proxy.String()+"/user", nil)
+	if err != nil {
+		t.Fatalf("failed to create the request: %v", err)
+	}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if expected := []string{"/gitee/api/v5/user"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected the requests %v, got %v", expected, paths)
+	}
+	if len(sleeps) != 1 {
+		t.Errorf("expected one retry, got the sleeps %v", sleeps)
+	}
+}
@@ -20,19 +20,68 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
 )
 
+// hmacSecret contains a hmac token and its expiration time.
+type hmacSecret struct {
+	Value  string    `json:"value"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// hmacsForRepo contains all hmac tokens configured for a repo, org or globally.
+type hmacsForRepo []hmacSecret
+
 // ValidateWebhook ensures that the provided request conforms to the
 // format of a Gitee webhook and the payload can be validated with
 // the provided hmac secret. It returns the event type, the event guid,
 // the payload of the request, whether the webhook is valid or not,
-// and finally the resultant HTTP status code
+// and finally the resultant HTTP status code. It doesn't check whether
+// the request is fresh, use a WebhookValidator for that.
 func ValidateWebhook(w http.ResponseWriter, r *http.Request, tokenGenerator func() []byte) (string, string, []byte, bool, int) {
+	return NewWebhookValidator(tokenGenerator, 0).Validate(w, r)
+}
+
+// WebhookValidator validates the Gitee webhooks like ValidateWebhook. When
+// it has a window, it also rejects the requests whose X-Gitee-Timestamp is
+// further than the window from now, and the requests whose signature was
+// already seen. Gitee signs only the timestamp, not the payload, so each
+// signature is accepted once, whatever the payload sent with it, and a
+// captured request can't be replayed nor reused with another payload.
+type WebhookValidator struct {
+	tokenGenerator func() []byte
+	window         time.Duration
+	seen           *signatureCache
+	now            func() time.Time
+}
+
+// NewWebhookValidator returns a WebhookValidator checking the signatures
+// with the secrets of tokenGenerator. A non-positive window disables the
+// timestamp and replay checks.
+func NewWebhookValidator(tokenGenerator func() []byte, window time.Duration) *WebhookValidator {
+	return &WebhookValidator{
+		tokenGenerator: tokenGenerator,
+		window:         window,
+		seen:           &signatureCache{signatures: map[string]time.Time{}},
+		now:            time.Now,
+	}
+}
+
+// Validate returns the event type, the event guid, the payload of the
+// request, whether the webhook is valid or not, and the resultant HTTP
+// status code.
+func (v *WebhookValidator) Validate(w http.ResponseWriter, r *http.Request) (string, string, []byte, bool, int) {
 	defer r.Body.Close()
 
 	// Header checks: It must be a POST with an event type and a signature.
@@ -60,6 +109,23 @@ func ValidateWebhook(w http.ResponseWriter, r *http.Request, tokenGenerator func
 		responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Hook only accepts content-type: application/json")
 		return "", "", nil, false, http.StatusBadRequest
 	}
+
+	// The timestamp is in milliseconds.
+	var sent time.Time
+	if v.window > 0 {
+		ms, err := strconv.ParseInt(eventGUID, 10, 64)
+		if err != nil {
+			responseHTTPError(w, http.StatusBadRequest, "400 Bad Request: Invalid X-Gitee-Timestamp Header")
+			return "", "", nil, false, http.StatusBadRequest
+		}
+		sent = time.Unix(0, ms*int64(time.Millisecond))
+		if d := v.now().Sub(sent); d > v.window || d < -v.window {
+			collectWebhookRejectedMetrics("stale")
+			responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Stale X-Gitee-Timestamp")
+			return "", "", nil, false, http.StatusForbidden
+		}
+	}
+
 	payload, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		responseHTTPError(w, http.StatusInternalServerError, "500 Internal Server Error: Failed to read request body")
@@ -67,14 +133,51 @@ func ValidateWebhook(w http.ResponseWriter, r *http.Request, tokenGenerator func
 	}
 	// Validate the payload with our HMAC secret.
 	f := func(key string) string { return payloadSignature(eventGUID, key) }
-	if !validatePayload(sig, tokenGenerator, f) {
+	if !validatePayload(payload, sig, v.tokenGenerator, f) {
+		collectWebhookRejectedMetrics("signature")
 		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Invalid X-Gitee-Token")
 		return "", "", nil, false, http.StatusForbidden
 	}
 
+	if v.window > 0 && !v.seen.add(sig, v.now(), sent.Add(v.window)) {
+		collectWebhookRejectedMetrics("replay")
+		responseHTTPError(w, http.StatusForbidden, "403 Forbidden: Replayed X-Gitee-Token")
+		return "", "", nil, false, http.StatusForbidden
+	}
+
 	return eventType, eventGUID, payload, true, http.StatusOK
 }
 
+// signatureCache records the signatures of the valid requests until their
+// timestamps get stale.
+type signatureCache struct {
+	lock       sync.Mutex
+	signatures map[string]time.Time
+	nextPrune  time.Time
+}
+
+// add records the signature until expiry. It returns false if the signature
+// was already recorded.
+func (c *signatureCache) add(sig string, now, expiry time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if now.After(c.nextPrune) {
+		for s, e := range c.signatures {
+			if now.After(e) {
+				delete(c.signatures, s)
+			}
+		}
+		c.nextPrune = now.Add(time.Minute)
+	}
+
+	if e, ok := c.signatures[sig]; ok && !now.After(e) {
+		return false
+	}
+	c.signatures[sig] = expiry
+	return true
+}
+
 func payloadSignature(timestamp, key string) string {
 	mac := hmac.New(sha256.New, []byte(key))
 
@@ -94,13 +197,56 @@ func responseHTTPError(w http.ResponseWriter, statusCode int, response string) {
 	http.Error(w, response, statusCode)
 }
 
-func extractHmacs(tokenGenerator func() []byte) ([][]byte, error) {
+// extractHmacs returns all *valid* HMAC tokens for given repository/organization.
+// It considers only the tokens at the most specific level configured for the given repo.
+// The secret is either a single token or a YAML map of repos, orgs and "*" to tokens
+// with an expiry, the format of the GitHub hook, so the tokens can be rotated.
+func extractHmacs(repo string, tokenGenerator func() []byte) ([][]byte, error) {
 	t := tokenGenerator()
-	return [][]byte{t}, nil
+	repoToTokenMap := map[string]hmacsForRepo{}
+
+	if err := yaml.Unmarshal(t, &repoToTokenMap); err != nil {
+		// The whole file is a single token.
+		return [][]byte{t}, nil
+	}
+
+	orgName := strings.Split(repo, "/")[0]
+
+	if val, ok := repoToTokenMap[repo]; ok {
+		return extractValidTokens(val), nil
+	}
+	if val, ok := repoToTokenMap[orgName]; ok {
+		return extractValidTokens(val), nil
+	}
+	if val, ok := repoToTokenMap["*"]; ok {
+		return extractValidTokens(val), nil
+	}
+	return nil, errors.New("invalid content in secret file, global token doesn't exist")
+}
+
+// extractValidTokens return valid tokens for any given level of tree. Validity is determined based on time till they are valid.
+func extractValidTokens(allTokens hmacsForRepo) [][]byte {
+	var validTokens [][]byte
+	for _, token := range allTokens {
+		if token.Expiry.After(time.Now()) {
+			validTokens = append(validTokens, []byte(token.Value))
+		}
+	}
+	return validTokens
 }
 
-func validatePayload(sig string, tokenGenerator func() []byte, ps func(string) string) bool {
-	hmacs, err := extractHmacs(tokenGenerator)
+func validatePayload(payload []byte, sig string, tokenGenerator func() []byte, ps func(string) string) bool {
+	var event struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		logrus.WithError(err).Info("validatePayload couldn't unmarshal the gitee event payload")
+		return false
+	}
+
+	hmacs, err := extractHmacs(event.Repository.FullName, tokenGenerator)
 	if err != nil {
 		logrus.WithError(err).Error("couldn't unmarshal the hmac secret")
 		return false
@@ -108,7 +254,7 @@ func validatePayload(sig string, tokenGenerator func() []byte, ps func(string) s
 
 	// If we have a match with any valid hmac, we can validate successfully.
 	for _, key := range hmacs {
-		if sig == ps(string(key)) {
+		if hmac.Equal([]byte(sig), []byte(ps(string(key)))) {
 			return true
 		}
 	}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var secret = `
+'*':
+  - value: abc
+    expiry: 9999-10-02T15:00:00Z
+  - value: expired
+    expiry: 2018-10-02T15:00:00Z
+org:
+  - value: org-old
+    expiry: 9999-10-02T15:00:00Z
+  - value: org-new
+    expiry: 9999-10-02T15:00:00Z
+`
+
+func TestValidatePayload(t *testing.T) {
+	testCases := []struct {
+		name    string
+		secret  string
+		payload string
+		key     string
+		valid   bool
+	}{
+		{
+			name:    "single token",
+			secret:  "abc",
+			payload: `{"repository": {"full_name": "org/repo"}}`,
+			key:     "abc",
+			valid:   true,
+		},
+		{
+			name:    "global token",
+			secret:  secret,
+			payload: `{"repository": {"full_name": "other/repo"}}`,
+			key:     "abc",
+			valid:   true,
+		},
+		{
+			name:    "expired global token",
+			secret:  secret,
+			payload: `{"repository": {"full_name": "other/repo"}}`,
+			key:     "expired",
+		},
+		{
+			name:    "both org tokens are valid during the rotation",
+			secret:  secret,
+			payload: `{"repository": {"full_name": "org/repo"}}`,
+			key:     "org-new",
+			valid:   true,
+		},
+		{
+			name:    "global token is not used for an org with tokens",
+			secret:  secret,
+			payload: `{"repository": {"full_name": "org/repo"}}`,
+			key:     "abc",
+		},
+		{
+			name:    "invalid payload",
+			secret:  secret,
+			payload: `not json`,
+			key:     "abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sig := payloadSignature("1600000000000", tc.key)
+			ps := func(key string) string { return payloadSignature("1600000000000", key) }
+			tg := func() []byte { return []byte(tc.secret) }
+			if valid := validatePayload([]byte(tc.payload), sig, tg, ps); valid != tc.valid {
+				t.Errorf("expected valid: %t, got %t", tc.valid, valid)
+			}
+		})
+	}
+}
+
+func TestWebhookValidatorRejectsStaleAndReplayedRequests(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	v := NewWebhookValidator(func() []byte { return []byte("abc") }, 5*time.Minute)
+	v.now = func() time.Time { return now }
+
+	const payload = `{"repository": {"full_name": "org/repo"}}`
+	send := func(sent time.Time, payload string) int {
+		timestamp := strconv.FormatInt(sent.UnixNano()/int64(time.Millisecond), 10)
+		r := httptest.NewRequest(http.MethodPost, "/gitee-hook", bytes.NewBufferString(payload))
+		r.Header.Set("X-Gitee-Event", "Note Hook")
+		r.Header.Set("X-Gitee-Timestamp", timestamp)
+		r.Header.Set("X-Gitee-Token", payloadSignature(timestamp, "abc"))
+		r.Header.Set("content-type", "application/json")
+
+		_, _, _, _, status := v.Validate(httptest.NewRecorder(), r)
+		return status
+	}
+
+	if status := send(now.Add(-time.Minute), payload); status != http.StatusOK {
+		t.Errorf("expected a fresh request to be accepted, got %d", status)
+	}
+	if status := send(now.Add(-time.Minute), payload); status != http.StatusForbidden {
+		t.Errorf("expected a replayed request to be rejected, got %d", status)
+	}
+	if status := send(now.Add(-time.Minute), `{"action": "comment", "repository": {"full_name": "org/repo"}}`); status != http.StatusForbidden {
+		t.Errorf("expected a forged payload reusing a signature to be rejected, got %d", status)
+	}
+	if status := send(now.Add(-10*time.Minute), payload); status != http.StatusForbidden {
+		t.Errorf("expected a stale request to be rejected, got %d", status)
+	}
+	if status := send(now.Add(10*time.Minute), payload); status != http.StatusForbidden {
+		t.Errorf("expected a request from the future to be rejected, got %d", status)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if status := send(now, payload); status != http.StatusOK {
+		t.Errorf("expected a new request to be accepted, got %d", status)
+	}
+	if len(v.seen.signatures) != 1 {
+		t.Errorf("expected the stale signatures to be pruned, got %v", v.seen.signatures)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package giteeoauth logs users in with Gitee OAuth, reusing the flow of
+// githuboauth with cookies of its own.
+package giteeoauth
+
+import (
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/githuboauth"
+)
+
+const (
+	// LoginSession is the cookie with the login of the Gitee user, for the front-end.
+	LoginSession = "gitee_login"
+	// TokenSession is the session with the access token of the Gitee user.
+	TokenSession = "gitee-access-token-session"
+	// TokenKey is the key of the access token in the TokenSession.
+	TokenKey = "access-token"
+
+	oauthSessionCookie = "gitee-oauth-session"
+)
+
+// Endpoint is the OAuth 2.0 endpoint of gitee.com, which expects the client
+// credentials in the parameters of the token request.
+var Endpoint = oauth2.Endpoint{
+	AuthURL:   "https://gitee.com/oauth/authorize",
+	TokenURL:  "https://gitee.com/oauth/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// NewAgent returns an OAuth Agent for Gitee. Its cookies differ from the
+// GitHub ones, so a Gitee login is never taken for a GitHub login.
+func NewAgent(config *githuboauth.Config, logger *logrus.Entry) *githuboauth.Agent {
+	return githuboauth.NewAgentWithCookies(config, githuboauth.Cookies{
+		Login:        LoginSession,
+		TokenSession: TokenSession,
+		OAuthSession: oauthSessionCookie,
+	}, logger)
+}
+
+// NewAuthenticatedUserIdentifier returns an identifier of the Gitee users
+// from their access token.
+func NewAuthenticatedUserIdentifier(options *flagutil.GiteeOptions) githuboauth.AuthenticatedUserIdentifier {
+	return &authenticatedUserIdentifier{clientFactory: options.GiteeClientWithAccessToken}
+}
+
+type authenticatedUserIdentifier struct {
+	clientFactory func(accessToken string) gitee.Client
+}
+
+func (a *authenticatedUserIdentifier) LoginForRequester(requester, token string) (string, error) {
+	return a.clientFactory(token).BotName()
+}
@@ -121,18 +121,43 @@ func (cli client) WithFinalRedirectURL(path string) (OAuthClient, error) {
 	), nil
 }
 
+// Cookies holds the names of the cookies in which an Agent keeps the login of the user,
+// the session with their access token and the session of the OAuth flow. Agents of
+// different OAuth servers must not share them, or the login of a user on one server
+// would be taken for the same login on the other.
+type Cookies struct {
+	Login        string
+	TokenSession string
+	OAuthSession string
+}
+
+// gitHubCookies are the cookies of the GitHub login, the front-end reads the login one.
+var gitHubCookies = Cookies{
+	Login:        loginSession,
+	TokenSession: tokenSession,
+	OAuthSession: oauthSessionCookie,
+}
+
 // Agent represents an agent that takes care GitHub authentication process such as handles
 // login request from users or handles redirection from GitHub OAuth server.
 type Agent struct {
-	gc     *Config
-	logger *logrus.Entry
+	gc      *Config
+	cookies Cookies
+	logger  *logrus.Entry
 }
 
 // NewAgent returns a new GitHub OAuth Agent.
 func NewAgent(config *Config, logger *logrus.Entry) *Agent {
+	return NewAgentWithCookies(config, gitHubCookies, logger)
+}
+
+// NewAgentWithCookies returns a new OAuth Agent which keeps its sessions in the given
+// cookies, for OAuth servers other than GitHub.
+func NewAgentWithCookies(config *Config, cookies Cookies, logger *logrus.Entry) *Agent {
 	return &Agent{
-		gc:     config,
-		logger: logger,
+		gc:      config,
+		cookies: cookies,
+		logger:  logger,
 	}
 }
 
@@ -143,7 +168,7 @@ func (ga *Agent) HandleLogin(client OAuthClient, secure bool) http.HandlerFunc {
 		destPage := r.URL.Query().Get("dest")
 		stateToken := xsrftoken.Generate(ga.gc.ClientSecret, "", "")
 		state := hex.EncodeToString([]byte(stateToken))
-		oauthSession, err := ga.gc.CookieStore.New(r, oauthSessionCookie)
+		oauthSession, err := ga.gc.CookieStore.New(r, ga.cookies.OAuthSession)
 		oauthSession.Options.Secure = secure
 		oauthSession.Options.HttpOnly = true
 		if err != nil {
@@ -168,7 +193,7 @@ func (ga *Agent) HandleLogin(client OAuthClient, secure bool) http.HandlerFunc {
 
 // GetLogin returns the username of the already authenticated GitHub user.
 func (ga *Agent) GetLogin(r *http.Request, identifier AuthenticatedUserIdentifier) (string, error) {
-	session, err := ga.gc.CookieStore.Get(r, tokenSession)
+	session, err := ga.gc.CookieStore.Get(r, ga.cookies.TokenSession)
 	if err != nil {
 		return "", err
 	}
@@ -187,7 +212,7 @@ func (ga *Agent) GetLogin(r *http.Request, identifier AuthenticatedUserIdentifie
 // redirect back to the front page.
 func (ga *Agent) HandleLogout(client OAuthClient) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		accessTokenSession, err := ga.gc.CookieStore.Get(r, tokenSession)
+		accessTokenSession, err := ga.gc.CookieStore.Get(r, ga.cookies.TokenSession)
 		if err != nil {
 			ga.serverError(w, "get cookie", err)
 			return
@@ -198,7 +223,7 @@ func (ga *Agent) HandleLogout(client OAuthClient) http.HandlerFunc {
 			ga.serverError(w, "Save invalidated session on log out", err)
 			return
 		}
-		loginCookie, err := r.Cookie(loginSession)
+		loginCookie, err := r.Cookie(ga.cookies.Login)
 		if err == nil {
 			loginCookie.MaxAge = -1
 			loginCookie.Expires = time.Now().Add(-time.Hour * 24)
@@ -232,7 +257,7 @@ func (ga *Agent) HandleRedirect(client OAuthClient, identifier AuthenticatedUser
 			return
 		}
 
-		oauthSession, err := ga.gc.CookieStore.Get(r, oauthSessionCookie)
+		oauthSession, err := ga.gc.CookieStore.Get(r, ga.cookies.OAuthSession)
 		if err != nil {
 			ga.serverError(w, "Get cookie", err)
 			return
@@ -269,7 +294,7 @@ func (ga *Agent) HandleRedirect(client OAuthClient, identifier AuthenticatedUser
 		}
 
 		// New session that stores the token.
-		session, err := ga.gc.CookieStore.New(r, tokenSession)
+		session, err := ga.gc.CookieStore.New(r, ga.cookies.TokenSession)
 		session.Options.Secure = secure
 		session.Options.HttpOnly = true
 		if err != nil {
@@ -288,7 +313,7 @@ func (ga *Agent) HandleRedirect(client OAuthClient, identifier AuthenticatedUser
 			return
 		}
 		http.SetCookie(w, &http.Cookie{
-			Name:    loginSession,
+			Name:    ga.cookies.Login,
 			Value:   user,
 			Path:    "/",
 			Expires: time.Now().Add(time.Hour * 24 * 30),
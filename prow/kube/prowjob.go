@@ -50,4 +50,9 @@ const (
 	// PullLabel is added in resources created by prow and
 	// carries the PR number associated with the job, eg 321.
 	PullLabel = "prow.k8s.io/refs.pull"
+	// ClusterPoolAnnotation is added by plank on the ProwJobs
+	// targeting a pool of build clusters and carries the name of
+	// the pool, since plank replaces the cluster of the job with
+	// the build cluster it starts the job on.
+	ClusterPoolAnnotation = "prow.k8s.io/cluster-pool"
 )
@@ -0,0 +1,193 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/kube"
+)
+
+// maxListFailures is the number of consecutive syncs failing to list the
+// pods of a cluster after which the jobs of a pool which didn't run yet on
+// it are rescheduled.
+const maxListFailures = 3
+
+// buildClusters is the state of the build clusters seen by the last sync.
+type buildClusters struct {
+	// unhealthy are the clusters whose pods couldn't be listed.
+	unhealthy sets.String
+	// failures is the number of consecutive syncs which couldn't list the
+	// pods of each cluster.
+	failures map[string]int
+	// pods is the number of pending and running pods in each cluster.
+	pods map[string]int
+	// waiting maps the pods which were never seen running to their cluster.
+	waiting map[string]string
+}
+
+// newBuildClusters returns the empty state of the build clusters filled by
+// a sync.
+func newBuildClusters() buildClusters {
+	return buildClusters{
+		unhealthy: sets.NewString(),
+		failures:  map[string]int{},
+		pods:      map[string]int{},
+		waiting:   map[string]string{},
+	}
+}
+
+// listFailed records that the pods of the cluster couldn't be listed,
+// keeping what the previous sync knew about them.
+func (b *buildClusters) listFailed(cluster string, previous buildClusters) {
+	b.unhealthy.Insert(cluster)
+	b.failures[cluster] = previous.failures[cluster] + 1
+	for pod, c := range previous.waiting {
+		if c == cluster {
+			b.waiting[pod] = c
+		}
+	}
+}
+
+// listed records a pod of the cluster.
+func (b *buildClusters) listed(cluster string, pod corev1.Pod) {
+	switch pod.Status.Phase {
+	case corev1.PodPending, "":
+		b.pods[cluster]++
+		b.waiting[pod.Name] = cluster
+	case corev1.PodRunning:
+		b.pods[cluster]++
+	}
+}
+
+// buildClustersState returns the state of the build clusters seen by the
+// last sync.
+func (c *Controller) buildClustersState() buildClusters {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.clusters
+}
+
+// setBuildClusters records the state of the build clusters, logging the
+// ones whose health changed.
+func (c *Controller) setBuildClusters(clusters buildClusters) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for cluster := range clusters.unhealthy.Difference(c.clusters.unhealthy) {
+		c.log.WithField("cluster", cluster).Warn("Build cluster is unhealthy.")
+	}
+	for cluster := range c.clusters.unhealthy.Difference(clusters.unhealthy) {
+		c.log.WithField("cluster", cluster).Info("Build cluster is healthy again.")
+	}
+	c.clusters = clusters
+}
+
+// isHealthy returns whether the pods of the cluster could be listed by the
+// last sync.
+func (c *Controller) isHealthy(cluster string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return !c.clusters.unhealthy.Has(cluster)
+}
+
+// canReschedule returns whether the job may move to another cluster of its
+// pool, which is the case once the pods of its cluster couldn't be listed
+// maxListFailures times in a row and if its pod was never seen running, so
+// that it doesn't run twice.
+func (c *Controller) canReschedule(pj *prowapi.ProwJob) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	cluster := pj.ClusterAlias()
+	return c.clusters.failures[cluster] >= maxListFailures && c.clusters.waiting[pj.Name] == cluster
+}
+
+// pickCluster returns the healthy build cluster with the most free capacity
+// among the ones the job may run on, and reserves a pod on it. The members
+// of a pool without a build client are skipped. It returns false if none
+// can take the job now.
+func (c *Controller) pickCluster(pj *prowapi.ProwJob) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	buildClusters := c.config().Plank.BuildClusters
+	_, pool := buildClusters.Pools[pj.ClusterAlias()]
+	var picked string
+	var best int
+	for _, cluster := range buildClusters.Clusters(pj.ClusterAlias()) {
+		if c.clusters.unhealthy.Has(cluster) {
+			continue
+		}
+		if _, ok := c.buildClients[cluster]; pool && !ok {
+			continue
+		}
+		free := math.MaxInt32 - c.clusters.pods[cluster]
+		if capacity, ok := buildClusters.Capacity[cluster]; ok && capacity > 0 {
+			free = capacity - c.clusters.pods[cluster]
+		}
+		if free > best {
+			picked, best = cluster, free
+		}
+	}
+	if picked == "" {
+		return "", false
+	}
+
+	if c.clusters.pods == nil {
+		c.clusters.pods = map[string]int{}
+	}
+	c.clusters.pods[picked]++
+	if c.clusters.waiting == nil {
+		c.clusters.waiting = map[string]string{}
+	}
+	c.clusters.waiting[pj.Name] = picked
+	return picked, true
+}
+
+// validateBuildClusters returns an error if the pools of build clusters
+// contain unknown clusters or shadow one.
+func (c *Controller) validateBuildClusters() error {
+	clusters := sets.NewString()
+	for cluster := range c.buildClients {
+		clusters.Insert(cluster)
+	}
+	return c.config().Plank.BuildClusters.Validate(clusters)
+}
+
+// isStrayPod returns whether the pod was left on the cluster by a job which
+// has been rescheduled to another cluster of its pool.
+func isStrayPod(pod corev1.Pod, cluster string, pools map[string]prowapi.ProwJob) bool {
+	pj, ok := pools[pod.Name]
+	return ok && pj.ClusterAlias() != cluster
+}
+
+// poolJobs returns the active jobs targeting a pool of build clusters by
+// name.
+func poolJobs(pjs []prowapi.ProwJob) map[string]prowapi.ProwJob {
+	pools := map[string]prowapi.ProwJob{}
+	for _, pj := range pjs {
+		if pj.Complete() || pj.Annotations[kube.ClusterPoolAnnotation] == "" {
+			continue
+		}
+		pools[pj.Name] = pj
+	}
+	return pools
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/kube"
+	"k8s.io/test-infra/prow/pjutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type listErroringClient struct {
+	ctrlruntimeclient.Client
+	err error
+}
+
+func (c *listErroringClient) List(ctx context.Context, list runtime.Object, opts ...ctrlruntimeclient.ListOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestPickCluster(t *testing.T) {
+	cfg := config.PlankBuildClusters{
+		Pools: map[string][]string{
+			"gpu":    {"gpu-east", "gpu-west"},
+			"legacy": {"gpu-east", "gpu-north"},
+		},
+		Capacity: map[string]int{
+			"gpu-east": 4,
+			"gpu-west": 2,
+			"default":  1,
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		cluster   string
+		unhealthy []string
+		pods      map[string]int
+
+		expected    string
+		expectedOK  bool
+		expectedPod map[string]int
+	}{
+		{
+			name:        "cluster with capacity",
+			cluster:     "default",
+			expected:    "default",
+			expectedOK:  true,
+			expectedPod: map[string]int{"default": 1},
+		},
+		{
+			name:        "full cluster",
+			cluster:     "default",
+			pods:        map[string]int{"default": 1},
+			expectedPod: map[string]int{"default": 1},
+		},
+		{
+			name:        "unhealthy cluster",
+			cluster:     "default",
+			unhealthy:   []string{"default"},
+			expectedPod: map[string]int{},
+		},
+		{
+			name:        "cluster without a capacity",
+			cluster:     "trusted",
+			pods:        map[string]int{"trusted": 100},
+			expected:    "trusted",
+			expectedOK:  true,
+			expectedPod: map[string]int{"trusted": 101},
+		},
+		{
+			name:        "pool cluster with the most free capacity",
+			cluster:     "gpu",
+			pods:        map[string]int{"gpu-east": 3},
+			expected:    "gpu-west",
+			expectedOK:  true,
+			expectedPod: map[string]int{"gpu-east": 3, "gpu-west": 1},
+		},
+		{
+			name:        "unhealthy pool cluster is skipped",
+			cluster:     "gpu",
+			unhealthy:   []string{"gpu-east"},
+			expected:    "gpu-west",
+			expectedOK:  true,
+			expectedPod: map[string]int{"gpu-west": 1},
+		},
+		{
+			name:        "pool cluster without a build client is skipped",
+			cluster:     "legacy",
+			pods:        map[string]int{"gpu-east": 3},
+			expected:    "gpu-east",
+			expectedOK:  true,
+			expectedPod: map[string]int{"gpu-east": 4},
+		},
+		{
+			name:        "full pool",
+			cluster:     "gpu",
+			pods:        map[string]int{"gpu-east": 4, "gpu-west": 2},
+			expectedPod: map[string]int{"gpu-east": 4, "gpu-west": 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fca := newFakeConfigAgent(t, 0)
+			fca.c.Plank.BuildClusters = cfg
+			pods := tc.pods
+			if pods == nil {
+				pods = map[string]int{}
+			}
+			c := Controller{
+				log:    logrus.NewEntry(logrus.StandardLogger()),
+				config: fca.Config,
+				buildClients: map[string]ctrlruntimeclient.Client{
+					"default":  nil,
+					"gpu-east": nil,
+					"gpu-west": nil,
+				},
+				clusters: buildClusters{
+					unhealthy: sets.NewString(tc.unhealthy...),
+					pods:      pods,
+				},
+			}
+
+			pj := prowapi.ProwJob{Spec: prowapi.ProwJobSpec{Cluster: tc.cluster}}
+			cluster, ok := c.pickCluster(&pj)
+			if cluster != tc.expected || ok != tc.expectedOK {
+				t.Errorf("expected cluster %q (%t), got %q (%t)", tc.expected, tc.expectedOK, cluster, ok)
+			}
+			if len(c.clusters.pods) != len(tc.expectedPod) {
+				t.Errorf("expected pods %v, got %v", tc.expectedPod, c.clusters.pods)
+			}
+			for cluster, num := range tc.expectedPod {
+				if c.clusters.pods[cluster] != num {
+					t.Errorf("expected pods %v, got %v", tc.expectedPod, c.clusters.pods)
+				}
+			}
+		})
+	}
+}
+
+// newFailoverController returns a controller running a periodic job on the
+// pool of the build clusters gpu-east and gpu-west.
+func newFailoverController(t *testing.T, totURL string) (*Controller, ctrlruntimeclient.Client, *listErroringClient, *listErroringClient) {
+	per := config.Periodic{
+		JobBase: config.JobBase{
+			Name:    "ci-periodic-job",
+			Agent:   "kubernetes",
+			Cluster: "gpu",
+			Spec:    &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+		},
+	}
+
+	pj := pjutil.NewProwJob(pjutil.PeriodicSpec(per), nil, nil)
+	pj.Namespace = "prowjobs"
+	fakeProwJobClient := fakectrlruntimeclient.NewFakeClient(&pj)
+	east := &listErroringClient{Client: fakectrlruntimeclient.NewFakeClient()}
+	west := &listErroringClient{Client: fakectrlruntimeclient.NewFakeClient()}
+	fca := newFakeConfigAgent(t, 0)
+	fca.c.Plank.BuildClusters = config.PlankBuildClusters{
+		Pools:    map[string][]string{"gpu": {"gpu-east", "gpu-west"}},
+		Capacity: map[string]int{"gpu-east": 2, "gpu-west": 1},
+	}
+	c := &Controller{
+		prowJobClient: fakeProwJobClient,
+		ghc:           &fghc{},
+		buildClients: map[string]ctrlruntimeclient.Client{
+			"gpu-east": east,
+			"gpu-west": west,
+		},
+		log:         logrus.NewEntry(logrus.StandardLogger()),
+		config:      fca.Config,
+		totURL:      totURL,
+		pendingJobs: make(map[string]int),
+		clock:       clock.RealClock{},
+		skipReport:  true,
+	}
+	return c, fakeProwJobClient, east, west
+}
+
+// TestClusterFailover walks through a job of a pool moving to another
+// cluster when its cluster stays unhealthy before its pod runs.
+func TestClusterFailover(t *testing.T) {
+	totServ := httptest.NewServer(http.HandlerFunc(handleTot))
+	defer totServ.Close()
+	c, fakeProwJobClient, east, west := newFailoverController(t, totServ.URL)
+
+	getJob := func() prowapi.ProwJob {
+		pjs := &prowapi.ProwJobList{}
+		if err := fakeProwJobClient.List(context.Background(), pjs); err != nil {
+			t.Fatalf("could not list prowJobs from the client: %v", err)
+		}
+		if len(pjs.Items) != 1 {
+			t.Fatalf("saw %d prowjobs, not 1", len(pjs.Items))
+		}
+		return pjs.Items[0]
+	}
+	countPods := func(client ctrlruntimeclient.Client) int {
+		pods := &v1.PodList{}
+		if err := client.List(context.Background(), pods); err != nil {
+			t.Fatalf("could not list pods from the client: %v", err)
+		}
+		return len(pods.Items)
+	}
+
+	if err := c.Sync(); err != nil {
+		t.Fatalf("Error on first sync: %v", err)
+	}
+	job := getJob()
+	if job.Status.State != prowapi.PendingState || job.Spec.Cluster != "gpu-east" || job.Annotations[kube.ClusterPoolAnnotation] != "gpu" {
+		t.Fatalf("expected the job to start on gpu-east, got %s on %s", job.Status.State, job.Spec.Cluster)
+	}
+	if num := countPods(east.Client); num != 1 {
+		t.Fatalf("expected a pod on gpu-east, got %d", num)
+	}
+
+	east.err = errors.New("connection refused")
+	for i := 1; i < maxListFailures; i++ {
+		if err := c.Sync(); err == nil {
+			t.Fatal("expected the sync to fail to list the pods of gpu-east")
+		}
+		if job := getJob(); job.Status.State != prowapi.PendingState || job.Spec.Cluster != "gpu-east" {
+			t.Fatalf("expected the job to wait for gpu-east after %d failures, got %s on %s", i, job.Status.State, job.Spec.Cluster)
+		}
+	}
+	if err := c.Sync(); err == nil {
+		t.Fatal("expected the sync to fail to list the pods of gpu-east")
+	}
+	job = getJob()
+	if job.Status.State != prowapi.TriggeredState || job.Spec.Cluster != "gpu" || job.Status.PendingTime != nil {
+		t.Fatalf("expected the job to be rescheduled on gpu, got %s on %s", job.Status.State, job.Spec.Cluster)
+	}
+
+	if err := c.Sync(); err == nil {
+		t.Fatal("expected the sync to fail to list the pods of gpu-east")
+	}
+	job = getJob()
+	if job.Status.State != prowapi.PendingState || job.Spec.Cluster != "gpu-west" {
+		t.Fatalf("expected the job to start on gpu-west, got %s on %s", job.Status.State, job.Spec.Cluster)
+	}
+	if num := countPods(west.Client); num != 1 {
+		t.Fatalf("expected a pod on gpu-west, got %d", num)
+	}
+
+	east.err = nil
+	if err := c.Sync(); err != nil {
+		t.Fatalf("Error on the sync after gpu-east recovered: %v", err)
+	}
+	if num := countPods(east.Client); num != 0 {
+		t.Errorf("expected the stray pod on gpu-east to be deleted, got %d pods", num)
+	}
+	if job := getJob(); job.Status.State != prowapi.PendingState || job.Spec.Cluster != "gpu-west" {
+		t.Errorf("expected the job to keep running on gpu-west, got %s on %s", job.Status.State, job.Spec.Cluster)
+	}
+}
+
+// TestRunningJobIsNotRescheduled checks that a job of a pool whose pod was
+// seen running waits for its cluster to recover instead of running twice.
+func TestRunningJobIsNotRescheduled(t *testing.T) {
+	totServ := httptest.NewServer(http.HandlerFunc(handleTot))
+	defer totServ.Close()
+	c, fakeProwJobClient, east, west := newFailoverController(t, totServ.URL)
+
+	if err := c.Sync(); err != nil {
+		t.Fatalf("Error on first sync: %v", err)
+	}
+	pods := &v1.PodList{}
+	if err := east.Client.List(context.Background(), pods); err != nil || len(pods.Items) != 1 {
+		t.Fatalf("expected a pod on gpu-east, got %d (%v)", len(pods.Items), err)
+	}
+	pod := pods.Items[0]
+	pod.Status.Phase = v1.PodRunning
+	if err := east.Client.Update(context.Background(), &pod); err != nil {
+		t.Fatalf("could not update the pod: %v", err)
+	}
+	if err := c.Sync(); err != nil {
+		t.Fatalf("Error on second sync: %v", err)
+	}
+
+	east.err = errors.New("connection refused")
+	for i := 0; i <= maxListFailures; i++ {
+		if err := c.Sync(); err == nil {
+			t.Fatal("expected the sync to fail to list the pods of gpu-east")
+		}
+	}
+	pjs := &prowapi.ProwJobList{}
+	if err := fakeProwJobClient.List(context.Background(), pjs); err != nil {
+		t.Fatalf("could not list prowJobs from the client: %v", err)
+	}
+	if job := pjs.Items[0]; job.Status.State != prowapi.PendingState || job.Spec.Cluster != "gpu-east" {
+		t.Errorf("expected the running job to wait for gpu-east, got %s on %s", job.Status.State, job.Spec.Cluster)
+	}
+	pods = &v1.PodList{}
+	if err := west.Client.List(context.Background(), pods); err != nil || len(pods.Items) != 0 {
+		t.Errorf("expected no pod on gpu-west, got %d (%v)", len(pods.Items), err)
+	}
+}
@@ -80,6 +80,11 @@ type Controller struct {
 	// pendingJobs is a short-lived cache that helps in limiting
 	// the maximum concurrency of jobs.
 	pendingJobs map[string]int
+	// queue holds the positions of the triggered jobs which have to wait,
+	// it is computed by schedule on every resync.
+	queue map[string]int
+	// clusters is the state of the build clusters seen by the last sync.
+	clusters buildClusters
 
 	// If `lock` is acquired as well, `lock` must be acquired before locking
 	// pjLock
@@ -98,7 +103,7 @@ func NewController(pjClient ctrlruntimeclient.Client, buildClients map[string]ct
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
-	return &Controller{
+	c := &Controller{
 		prowJobClient: pjClient,
 		buildClients:  buildClients,
 		ghc:           ghc,
@@ -109,7 +114,11 @@ func NewController(pjClient ctrlruntimeclient.Client, buildClients map[string]ct
 		selector:      selector,
 		skipReport:    skipReport,
 		clock:         clock.RealClock{},
-	}, nil
+	}
+	if err := c.validateBuildClusters(); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // canExecuteConcurrently checks whether the provided ProwJob can
@@ -118,6 +127,11 @@ func (c *Controller) canExecuteConcurrently(pj *prowapi.ProwJob) bool {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	if position, queued := c.queue[pj.Name]; queued {
+		c.log.WithFields(pjutil.ProwJobFields(pj)).Debugf("Not starting %s, it is at position %d in the queue.", pj.Spec.Job, position)
+		return false
+	}
+
 	if max := c.config().Plank.MaxConcurrency; max > 0 {
 		var running int
 		for _, num := range c.pendingJobs {
@@ -174,6 +188,22 @@ func (c *Controller) incrementNumPendingJobs(job string) {
 	c.pendingJobs[job]++
 }
 
+// decrementNumPendingJobs decrements the amount of
+// pending ProwJobs for the given job identifier
+func (c *Controller) decrementNumPendingJobs(job string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pendingJobs[job]--
+}
+
+// queuePosition returns the position of the job in the queue, or 0 if it
+// doesn't have to wait for the other jobs.
+func (c *Controller) queuePosition(name string) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.queue[name]
+}
+
 // setPreviousReportState sets the github key for PrevReportStates
 // to current state. This is a work-around for plank -> crier
 // migration to become seamless.
@@ -231,7 +261,14 @@ func (c *Controller) Sync() error {
 		selector = strings.Join([]string{c.selector, selector}, ",")
 	}
 
+	if err := c.validateBuildClusters(); err != nil {
+		c.log.WithError(err).Error("Invalid build clusters.")
+	}
+
 	pm := map[string]corev1.Pod{}
+	previous := c.buildClustersState()
+	clusters := newBuildClusters()
+	pools := poolJobs(pjs.Items)
 	for alias, client := range c.buildClients {
 		listOpts := &ctrlruntimeclient.ListOptions{
 			Namespace: c.config().PodNamespace,
@@ -242,12 +279,23 @@ func (c *Controller) Sync() error {
 		c.log.WithField("selector", selector).Debug("List Pods.")
 		if err != nil {
 			syncErrs = append(syncErrs, fmt.Errorf("error listing pods in cluster %q: %v", alias, err))
+			clusters.listFailed(alias, previous)
 			continue
 		}
 		for _, pod := range pods.Items {
+			if isStrayPod(pod, alias, pools) {
+				// The job was rescheduled while the cluster was unhealthy.
+				c.log.WithField("name", pod.ObjectMeta.Name).WithField("cluster", alias).Info("Delete stray Pod.")
+				if err := client.Delete(c.ctx, pod.DeepCopy()); err != nil && !kerrors.IsNotFound(err) {
+					syncErrs = append(syncErrs, fmt.Errorf("error deleting stray pod %s in cluster %q: %v", pod.Name, alias, err))
+				}
+				continue
+			}
 			pm[pod.ObjectMeta.Name] = pod
+			clusters.listed(alias, pod)
 		}
 	}
+	c.setBuildClusters(clusters)
 	// TODO: Replace the following filtering with a field selector once CRDs support field selectors.
 	// https://github.com/kubernetes/kubernetes/issues/53459
 	var k8sJobs []prowapi.ProwJob
@@ -282,6 +330,10 @@ func (c *Controller) Sync() error {
 	maxSyncRoutines := c.config().Plank.MaxGoroutines
 	c.log.Debugf("Handling %d pending prowjobs", len(pendingCh))
 	syncProwJobs(c.log, c.syncPendingJob, maxSyncRoutines, pendingCh, reportCh, errCh, pm)
+	// Decide which triggered jobs start now that we know how many are running.
+	c.lock.Lock()
+	c.queue = schedule(c.config().Plank, k8sJobs, pm, c.pendingJobs)
+	c.lock.Unlock()
 	c.log.Debugf("Handling %d triggered prowjobs", len(triggeredCh))
 	syncProwJobs(c.log, c.syncTriggeredJob, maxSyncRoutines, triggeredCh, reportCh, errCh, pm)
 	c.log.Debugf("Handling %d aborted prowjobs", len(abortedCh))
@@ -380,6 +432,25 @@ func (c *Controller) syncPendingJob(pj prowapi.ProwJob, pm map[string]corev1.Pod
 	prevPJ := *pj.DeepCopy()
 
 	pod, podExists := pm[pj.ObjectMeta.Name]
+	if !podExists && !c.isHealthy(pj.ClusterAlias()) {
+		pool := pj.Annotations[kube.ClusterPoolAnnotation]
+		if pool == "" || !c.canReschedule(&pj) {
+			// The pod can't be seen, wait for the cluster to recover.
+			c.incrementNumPendingJobs(pj.Spec.Job)
+			return nil
+		}
+		// Send the job back to the queue so that it starts on another
+		// cluster of its pool.
+		c.log.WithFields(pjutil.ProwJobFields(&pj)).WithField("cluster", pj.ClusterAlias()).Info("Build cluster is unhealthy, rescheduling the job.")
+		pj.Status.Description = fmt.Sprintf("Rescheduled, build cluster %s is unhealthy.", pj.ClusterAlias())
+		pj.Spec.Cluster = pool
+		pj.Status.State = prowapi.TriggeredState
+		pj.Status.PendingTime = nil
+		pj.Status.PodName = ""
+		pj.Status.BuildID = ""
+		pj.Status.URL = ""
+		return c.prowJobClient.Patch(c.ctx, pj.DeepCopy(), ctrlruntimeclient.MergeFrom(&prevPJ))
+	}
 	if !podExists {
 		c.incrementNumPendingJobs(pj.Spec.Job)
 		// Pod is missing. This can happen in case the previous pod was deleted manually or by
@@ -545,8 +616,29 @@ func (c *Controller) syncTriggeredJob(pj prowapi.ProwJob, pm map[string]corev1.P
 	if !podExists {
 		// Do not start more jobs than specified.
 		if !c.canExecuteConcurrently(&pj) {
+			// Let deck show where the job is in the queue.
+			position := c.queuePosition(pj.Name)
+			if !queuePositionChanged(pj.Status.QueuePosition, position) {
+				return nil
+			}
+			pj.Status.QueuePosition = position
+			return c.prowJobClient.Patch(c.ctx, pj.DeepCopy(), ctrlruntimeclient.MergeFrom(&prevPJ))
+		}
+		// Do not start the job on an unhealthy or a full cluster.
+		cluster, ok := c.pickCluster(&pj)
+		if !ok {
+			c.decrementNumPendingJobs(pj.Spec.Job)
+			c.log.WithFields(pjutil.ProwJobFields(&pj)).Debugf("Not starting %s, no build cluster of %s can take it.", pj.Spec.Job, pj.ClusterAlias())
 			return nil
 		}
+		if cluster != pj.ClusterAlias() {
+			pj = *pj.DeepCopy()
+			if pj.Annotations == nil {
+				pj.Annotations = map[string]string{}
+			}
+			pj.Annotations[kube.ClusterPoolAnnotation] = pj.ClusterAlias()
+			pj.Spec.Cluster = cluster
+		}
 		// We haven't started the pod yet. Do so.
 		var err error
 		id, pn, err = c.startPod(pj)
@@ -571,6 +663,7 @@ func (c *Controller) syncTriggeredJob(pj prowapi.ProwJob, pm map[string]corev1.P
 		pj.Status.PendingTime = &now
 		pj.Status.State = prowapi.PendingState
 		pj.Status.PodName = pn
+		pj.Status.QueuePosition = 0
 		pj.Status.Description = "Job triggered."
 		pj.Status.URL = pjutil.JobURL(c.config().Plank, pj, c.log)
 	}
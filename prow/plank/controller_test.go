@@ -352,11 +352,13 @@ func TestSyncTriggeredJobs(t *testing.T) {
 
 		pj             prowapi.ProwJob
 		pendingJobs    map[string]int
+		queue          map[string]int
 		maxConcurrency int
 		pods           map[string][]v1.Pod
 		podErr         error
 
 		expectedState         prowapi.ProwJobState
+		expectedQueuePosition int
 		expectedPodHasName    bool
 		expectedNumPods       map[string]int
 		expectedComplete      bool
@@ -395,6 +397,84 @@ func TestSyncTriggeredJobs(t *testing.T) {
 			},
 			expectedURL: "blabla/pending",
 		},
+		{
+			name: "job waiting in the queue",
+			pj: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "blabla",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					Job:     "boop",
+					Type:    prowapi.PeriodicJob,
+					PodSpec: &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:         prowapi.TriggeredState,
+					QueuePosition: 5,
+				},
+			},
+			queue:                 map[string]int{"blabla": 2},
+			maxConcurrency:        1,
+			pendingJobs:           map[string]int{"other": 1},
+			pods:                  map[string][]v1.Pod{"default": {}},
+			expectedState:         prowapi.TriggeredState,
+			expectedQueuePosition: 2,
+			expectedNumPods:       map[string]int{"default": 0},
+		},
+		{
+			name: "job far in the queue moving by a few positions",
+			pj: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "blabla",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					Job:     "boop",
+					Type:    prowapi.PeriodicJob,
+					PodSpec: &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:         prowapi.TriggeredState,
+					QueuePosition: 50,
+				},
+			},
+			queue:                 map[string]int{"blabla": 48},
+			maxConcurrency:        1,
+			pendingJobs:           map[string]int{"other": 1},
+			pods:                  map[string][]v1.Pod{"default": {}},
+			expectedState:         prowapi.TriggeredState,
+			expectedQueuePosition: 50,
+			expectedNumPods:       map[string]int{"default": 0},
+		},
+		{
+			name: "job leaving the queue",
+			pj: prowapi.ProwJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "blabla",
+					Namespace: "prowjobs",
+				},
+				Spec: prowapi.ProwJobSpec{
+					Job:     "boop",
+					Type:    prowapi.PeriodicJob,
+					PodSpec: &v1.PodSpec{Containers: []v1.Container{{Name: "test-name", Env: []v1.EnvVar{}}}},
+				},
+				Status: prowapi.ProwJobStatus{
+					State:         prowapi.TriggeredState,
+					QueuePosition: 1,
+				},
+			},
+			pods:                map[string][]v1.Pod{"default": {}},
+			expectedState:       prowapi.PendingState,
+			expectedPendingTime: &pendingTime,
+			expectedPodHasName:  true,
+			expectedNumPods:     map[string]int{"default": 1},
+			expectedReport:      true,
+			expectPrevReportState: map[string]prowapi.ProwJobState{
+				reporter.GitHubReporterName: prowapi.PendingState,
+			},
+			expectedURL: "blabla/pending",
+		},
 		{
 			name: "pod with a max concurrency of 1",
 			pj: prowapi.ProwJob{
@@ -752,6 +832,7 @@ func TestSyncTriggeredJobs(t *testing.T) {
 		if tc.pendingJobs != nil {
 			c.pendingJobs = tc.pendingJobs
 		}
+		c.queue = tc.queue
 
 		reports := make(chan prowapi.ProwJob, 100)
 		if err := c.syncTriggeredJob(tc.pj, pm, reports); (err != nil) != tc.expectError {
@@ -783,6 +864,9 @@ func TestSyncTriggeredJobs(t *testing.T) {
 		if actual.Status.State != tc.expectedState {
 			t.Errorf("for case %q got state %v", tc.name, actual.Status.State)
 		}
+		if actual.Status.QueuePosition != tc.expectedQueuePosition {
+			t.Errorf("for case %q got queue position %d, expected %d", tc.name, actual.Status.QueuePosition, tc.expectedQueuePosition)
+		}
 		if !reflect.DeepEqual(actual.Status.PendingTime, tc.expectedPendingTime) {
 			t.Errorf("for case %q got pending time %v, expected %v", tc.name, actual.Status.PendingTime, tc.expectedPendingTime)
 		}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+)
+
+// schedule decides which of the triggered jobs without a pod can start
+// within the max_concurrency of plank. The jobs of the highest priority
+// class start first, the orgs and repos get the running jobs in proportion
+// to their weights, and the jobs of an org or repo start in the order they
+// were created. It returns the queue positions, starting at 1, of the jobs
+// which have to wait, including the ones waiting for their own
+// max_concurrency.
+func schedule(plank config.Plank, pjs []prowapi.ProwJob, pm map[string]corev1.Pod, pendingJobs map[string]int) map[string]int {
+	if plank.MaxConcurrency <= 0 {
+		return nil
+	}
+	scheduling := plank.Scheduling
+
+	free := plank.MaxConcurrency
+	for _, num := range pendingJobs {
+		free -= num
+	}
+
+	sorted := make([]prowapi.ProwJob, len(pjs))
+	copy(sorted, pjs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+	})
+
+	// running is the number of running jobs of each tenant, the triggered
+	// jobs are counted as they are scheduled.
+	running := map[string]int{}
+	// queues holds the triggered jobs of each tenant by priority class.
+	queues := map[int]map[string][]prowapi.ProwJob{}
+	// blocked are the jobs which can't start because of their own
+	// max_concurrency, like canExecuteConcurrently decides.
+	blocked := map[string]bool{}
+	olderMatchingPJs := map[string]int{}
+	for _, pj := range sorted {
+		tenant := scheduling.Tenant(pj.Spec)
+		switch pj.Status.State {
+		case prowapi.PendingState:
+			running[tenant]++
+		case prowapi.TriggeredState:
+			older := olderMatchingPJs[pj.Spec.Job]
+			olderMatchingPJs[pj.Spec.Job]++
+			if _, exists := pm[pj.Name]; exists {
+				continue
+			}
+			if max := pj.Spec.MaxConcurrency; max > 0 && pendingJobs[pj.Spec.Job]+older >= max {
+				blocked[pj.Name] = true
+			}
+			priority := scheduling.Priority(pj.Spec.Type)
+			if queues[priority] == nil {
+				queues[priority] = map[string][]prowapi.ProwJob{}
+			}
+			queues[priority][tenant] = append(queues[priority][tenant], pj)
+		}
+	}
+
+	var priorities []int
+	for priority := range queues {
+		priorities = append(priorities, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	positions := map[string]int{}
+	for _, priority := range priorities {
+		tenantQueues := queues[priority]
+		for len(tenantQueues) > 0 {
+			tenant := nextTenant(scheduling, tenantQueues, running)
+			pj := tenantQueues[tenant][0]
+			if len(tenantQueues[tenant]) == 1 {
+				delete(tenantQueues, tenant)
+			} else {
+				tenantQueues[tenant] = tenantQueues[tenant][1:]
+			}
+
+			if blocked[pj.Name] {
+				positions[pj.Name] = len(positions) + 1
+				continue
+			}
+			running[tenant]++
+			if free > 0 {
+				free--
+				continue
+			}
+			positions[pj.Name] = len(positions) + 1
+		}
+	}
+	return positions
+}
+
+// exactQueuePositions is the number of positions at the head of the queue
+// which are always kept up to date on the jobs.
+const exactQueuePositions = 10
+
+// queuePositionChanged returns whether the queue position of a job moved
+// enough to be patched. The positions at the head of the queue are patched
+// on every change, the ones further back once they moved by a tenth, so
+// that a resync doesn't patch every waiting job when the queue moves by one.
+func queuePositionChanged(previous, current int) bool {
+	if previous == current {
+		return false
+	}
+	if previous == 0 || current == 0 || current <= exactQueuePositions {
+		return true
+	}
+	diff := current - previous
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*10 >= previous
+}
+
+// nextTenant returns the tenant with the fewest running jobs for its weight,
+// preferring the one whose next job is the oldest.
+func nextTenant(scheduling config.PlankScheduling, queues map[string][]prowapi.ProwJob, running map[string]int) string {
+	var tenants []string
+	for tenant := range queues {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	var next string
+	for i, tenant := range tenants {
+		if i == 0 {
+			next = tenant
+			continue
+		}
+		// Compare running[tenant]/weight[tenant] to running[next]/weight[next].
+		lhs := running[tenant] * scheduling.Weight(next)
+		rhs := running[next] * scheduling.Weight(tenant)
+		if lhs < rhs {
+			next = tenant
+			continue
+		}
+		if lhs == rhs && queues[tenant][0].CreationTimestamp.Before(&queues[next][0].CreationTimestamp) {
+			next = tenant
+		}
+	}
+	return next
+}
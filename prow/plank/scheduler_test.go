@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plank
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/config"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Now()
+	newJob := func(name, job, org string, jobType prowapi.ProwJobType, state prowapi.ProwJobState, age int) prowapi.ProwJob {
+		pj := prowapi.ProwJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-time.Duration(age) * time.Minute)),
+			},
+			Spec: prowapi.ProwJobSpec{
+				Job:  job,
+				Type: jobType,
+			},
+			Status: prowapi.ProwJobStatus{State: state},
+		}
+		if org != "" {
+			pj.Spec.Refs = &prowapi.Refs{Org: org, Repo: "repo"}
+		}
+		return pj
+	}
+	priorityClasses := map[prowapi.ProwJobType]int{
+		prowapi.PostsubmitJob: 3,
+		prowapi.PresubmitJob:  2,
+		prowapi.PeriodicJob:   1,
+	}
+
+	testCases := []struct {
+		name           string
+		maxConcurrency int
+		weights        map[string]int
+		pjs            []prowapi.ProwJob
+		pods           []string
+		pendingJobs    map[string]int
+		expected       map[string]int
+	}{
+		{
+			name: "no queue without max_concurrency",
+			pjs: []prowapi.ProwJob{
+				newJob("a", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("b", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+		},
+		{
+			name:           "jobs fitting in max_concurrency start",
+			maxConcurrency: 3,
+			pjs: []prowapi.ProwJob{
+				newJob("a", "job", "org", prowapi.PresubmitJob, prowapi.PendingState, 3),
+				newJob("b", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("c", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+			pendingJobs: map[string]int{"job": 1},
+			expected:    map[string]int{},
+		},
+		{
+			name:           "higher priority classes start first",
+			maxConcurrency: 1,
+			pjs: []prowapi.ProwJob{
+				newJob("periodic", "p", "", prowapi.PeriodicJob, prowapi.TriggeredState, 3),
+				newJob("presubmit", "pre", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("postsubmit", "post", "org", prowapi.PostsubmitJob, prowapi.TriggeredState, 1),
+			},
+			expected: map[string]int{"presubmit": 1, "periodic": 2},
+		},
+		{
+			name:           "orgs share the running jobs",
+			maxConcurrency: 4,
+			pjs: []prowapi.ProwJob{
+				newJob("flood-1", "job", "flood", prowapi.PresubmitJob, prowapi.TriggeredState, 9),
+				newJob("flood-2", "job", "flood", prowapi.PresubmitJob, prowapi.TriggeredState, 8),
+				newJob("flood-3", "job", "flood", prowapi.PresubmitJob, prowapi.TriggeredState, 7),
+				newJob("flood-4", "job", "flood", prowapi.PresubmitJob, prowapi.TriggeredState, 6),
+				newJob("small-1", "other", "small", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("small-2", "other", "small", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+			expected: map[string]int{"flood-3": 1, "flood-4": 2},
+		},
+		{
+			name:           "running jobs count against the share of their org",
+			maxConcurrency: 3,
+			pjs: []prowapi.ProwJob{
+				newJob("flood-0", "job", "flood", prowapi.PresubmitJob, prowapi.PendingState, 11),
+				newJob("flood-1", "job", "flood", prowapi.PresubmitJob, prowapi.PendingState, 10),
+				newJob("flood-2", "job", "flood", prowapi.PresubmitJob, prowapi.TriggeredState, 9),
+				newJob("small-1", "other", "small", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+			pendingJobs: map[string]int{"job": 2},
+			expected:    map[string]int{"flood-2": 1},
+		},
+		{
+			name:           "weights give bigger shares",
+			maxConcurrency: 3,
+			weights:        map[string]int{"big": 2},
+			pjs: []prowapi.ProwJob{
+				newJob("small-1", "other", "small", prowapi.PresubmitJob, prowapi.TriggeredState, 9),
+				newJob("small-2", "other", "small", prowapi.PresubmitJob, prowapi.TriggeredState, 8),
+				newJob("big-1", "job", "big", prowapi.PresubmitJob, prowapi.TriggeredState, 3),
+				newJob("big-2", "job", "big", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("big-3", "job", "big", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+			expected: map[string]int{"small-2": 1, "big-3": 2},
+		},
+		{
+			name:           "jobs waiting for their own max_concurrency don't take a slot",
+			maxConcurrency: 2,
+			pjs: func() []prowapi.ProwJob {
+				first := newJob("serial-1", "serial", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 9)
+				first.Spec.MaxConcurrency = 1
+				second := newJob("serial-2", "serial", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 8)
+				second.Spec.MaxConcurrency = 1
+				return []prowapi.ProwJob{
+					first,
+					second,
+					newJob("other", "other", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+				}
+			}(),
+			expected: map[string]int{"serial-2": 1},
+		},
+		{
+			name:           "triggered jobs with a pod are left out",
+			maxConcurrency: 1,
+			pjs: []prowapi.ProwJob{
+				newJob("a", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 2),
+				newJob("b", "job", "org", prowapi.PresubmitJob, prowapi.TriggeredState, 1),
+			},
+			pods:     []string{"a"},
+			expected: map[string]int{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plank := config.Plank{
+				Controller: config.Controller{MaxConcurrency: tc.maxConcurrency},
+				Scheduling: config.PlankScheduling{
+					PriorityClasses: priorityClasses,
+					Weights:         tc.weights,
+				},
+			}
+			pm := map[string]v1.Pod{}
+			for _, name := range tc.pods {
+				pm[name] = v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			}
+			pendingJobs := tc.pendingJobs
+			if pendingJobs == nil {
+				pendingJobs = map[string]int{}
+			}
+
+			if actual := schedule(plank, tc.pjs, pm, pendingJobs); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected queue %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQueuePositionChanged(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous int
+		current  int
+		expected bool
+	}{
+		{name: "unchanged", previous: 3, current: 3},
+		{name: "job enters the queue", previous: 0, current: 120, expected: true},
+		{name: "job leaves the queue", previous: 120, current: 0, expected: true},
+		{name: "head of the queue", previous: 11, current: 10, expected: true},
+		{name: "small move far in the queue", previous: 120, current: 119},
+		{name: "move by a tenth far in the queue", previous: 120, current: 108, expected: true},
+		{name: "job pushed back far in the queue", previous: 20, current: 22, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := queuePositionChanged(tc.previous, tc.current); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
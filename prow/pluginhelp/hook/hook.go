@@ -96,8 +96,16 @@ func (ha *HelpAgent) generateNormalPluginHelp(config *plugins.Configuration, rev
 }
 
 func (ha *HelpAgent) generateExternalPluginHelp(config *plugins.Configuration, revMap map[string][]prowconfig.OrgRepo) (allPlugins []string, pluginHelp map[string]pluginhelp.PluginHelp) {
+	return GenerateExternalPluginHelp(ha.log, config.ExternalPlugins, revMap)
+}
+
+// GenerateExternalPluginHelp asks the external plugins configured for the
+// repos for their help. revMap maps the plugin names to the repos they are
+// enabled on. Plugins that don't answer within a second are left out of the
+// help but are listed in allPlugins.
+func GenerateExternalPluginHelp(log *logrus.Entry, externalPlugins map[string][]plugins.ExternalPlugin, revMap map[string][]prowconfig.OrgRepo) (allPlugins []string, pluginHelp map[string]pluginhelp.PluginHelp) {
 	externals := map[string]plugins.ExternalPlugin{}
-	for _, exts := range config.ExternalPlugins {
+	for _, exts := range externalPlugins {
 		for _, ext := range exts {
 			externals[ext.Name] = ext
 		}
@@ -111,9 +119,9 @@ func (ha *HelpAgent) generateExternalPluginHelp(config *plugins.Configuration, r
 	for _, ext := range externals {
 		allPlugins = append(allPlugins, ext.Name)
 		go func(ext plugins.ExternalPlugin) {
-			help, err := externalHelpProvider(ha.log, ext.Endpoint)(revMap[ext.Name])
+			help, err := externalHelpProvider(log, ext.Endpoint)(revMap[ext.Name])
 			if err != nil {
-				ha.log.WithError(err).Errorf("Getting help from external plugin %q.", ext.Name)
+				log.WithError(err).Errorf("Getting help from external plugin %q.", ext.Name)
 				help = nil
 			} else {
 				help.Events = ext.Events
@@ -0,0 +1,7 @@
+package blunderbuss
+
+var (
+	HandlePullRequest    = handlePullRequest
+	HandleGenericComment = handleGenericComment
+	HelpProvider         = helpProvider
+)
@@ -0,0 +1,6 @@
+package hold
+
+var (
+	Handle       = handle
+	HelpProvider = helpProvider
+)
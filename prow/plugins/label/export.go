@@ -0,0 +1,8 @@
+package label
+
+const PluginName = pluginName
+
+var (
+	Handle       = handle
+	HelpProvider = helpProvider
+)
@@ -0,0 +1,8 @@
+package lifecycle
+
+var (
+	Handle       = handle
+	HandleClose  = handleClose
+	HandleReopen = handleReopen
+	HelpProvider = help
+)
@@ -0,0 +1,7 @@
+package trigger
+
+var (
+	HandlePR             = handlePR
+	HandleGenericComment = handleGenericComment
+	HelpProvider         = helpProvider
+)
@@ -0,0 +1,21 @@
+package wip
+
+// Event is the pull request state the wip plugin works on.
+type Event = event
+
+// NewEvent returns the Event of a pull request.
+func NewEvent(org, repo string, number int, title string, draft, hasLabel bool) *Event {
+	return &Event{
+		org:      org,
+		repo:     repo,
+		number:   number,
+		title:    title,
+		draft:    draft,
+		hasLabel: hasLabel,
+	}
+}
+
+var (
+	Handle       = handle
+	HelpProvider = helpProvider
+)
@@ -0,0 +1,229 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/gorilla/sessions"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/giteeoauth"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/githuboauth"
+)
+
+type giteeClient interface {
+	BotName() (string, error)
+	GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error)
+}
+
+// GiteeDashboardAgent serves the open pull requests of the user logged in
+// with Gitee. Gitee cannot search pull requests by author, so the agent lists
+// the open pull requests of the Gitee repos and keeps the ones of the user.
+type GiteeDashboardAgent struct {
+	repos         func() []string
+	goac          *githuboauth.Config
+	clientFactory func(accessToken string) giteeClient
+
+	log *logrus.Entry
+}
+
+// NewGiteeDashboardAgent creates a new user dashboard agent for Gitee. The
+// repos are read on every request, so they follow the config changes.
+func NewGiteeDashboardAgent(repos func() []string, config *githuboauth.Config, gitee *flagutil.GiteeOptions, log *logrus.Entry) *GiteeDashboardAgent {
+	return &GiteeDashboardAgent{
+		repos: repos,
+		goac:  config,
+		clientFactory: func(accessToken string) giteeClient {
+			return gitee.GiteeClientWithAccessToken(accessToken)
+		},
+		log: log,
+	}
+}
+
+// HasSession returns true if the request carries the session of a Gitee login.
+func (da *GiteeDashboardAgent) HasSession(r *http.Request) bool {
+	_, err := r.Cookie(giteeoauth.TokenSession)
+	return err == nil
+}
+
+func invalidateGiteeSession(w http.ResponseWriter, r *http.Request, session *sessions.Session) error {
+	// Invalidate gitee login session
+	http.SetCookie(w, &http.Cookie{
+		Name:    giteeoauth.LoginSession,
+		Path:    "/",
+		Expires: time.Now().Add(-time.Hour * 24),
+		MaxAge:  -1,
+		Secure:  true,
+	})
+
+	// Invalidate access token session
+	session.Options.MaxAge = -1
+	return session.Save(r, w)
+}
+
+// HandlePrStatus returns a http handler function that handles request to /pr-status
+// endpoint for the users logged in with Gitee. The statuses of the head commits
+// are not served, Gitee does not report them.
+func (da *GiteeDashboardAgent) HandlePrStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serverError := func(action string, err error) {
+			da.log.WithError(err).Errorf("Error %s.", action)
+			msg := fmt.Sprintf("500 Internal server error %s: %v", action, err)
+			http.Error(w, msg, http.StatusInternalServerError)
+		}
+
+		data := UserData{
+			Login: false,
+		}
+
+		session, err := da.goac.CookieStore.Get(r, giteeoauth.TokenSession)
+		if err != nil {
+			da.log.WithError(err).Info("Failed to get existing session, invalidating Gitee login session")
+			if err := invalidateGiteeSession(w, r, session); err != nil {
+				serverError("Failed to invalidate Gitee session", err)
+				return
+			}
+		}
+
+		token, ok := session.Values[giteeoauth.TokenKey].(*oauth2.Token)
+		if ok && token.Valid() {
+			gc := da.clientFactory(token.AccessToken)
+			login, err := gc.BotName()
+			if err != nil {
+				if !strings.Contains(err.Error(), "401") {
+					serverError("Error with getting user login", err)
+					return
+				}
+				da.log.Info("Failed to access Gitee with existing access token, invalidating Gitee login session")
+				if err := invalidateGiteeSession(w, r, session); err != nil {
+					serverError("Failed to invalidate Gitee session", err)
+					return
+				}
+			} else {
+				data.Login = true
+				http.SetCookie(w, &http.Cookie{
+					Name:    giteeoauth.LoginSession,
+					Value:   login,
+					Path:    "/",
+					Expires: time.Now().Add(time.Hour * 24 * 30),
+					Secure:  true,
+				})
+				session.Values[loginKey] = login
+				if err := session.Save(r, w); err != nil {
+					serverError("Save oauth session", err)
+					return
+				}
+
+				pullRequests, err := da.QueryPullRequests(gc, login)
+				if err != nil {
+					serverError("Error with querying user data.", err)
+					return
+				}
+				for _, pr := range pullRequests {
+					data.PullRequestsWithContexts = append(data.PullRequestsWithContexts, PullRequestWithContexts{
+						PullRequest: pr,
+					})
+				}
+			}
+		}
+
+		marshaledData, err := json.Marshal(data)
+		if err != nil {
+			da.log.WithError(err).Error("Error with marshalling user data.")
+		}
+
+		if v := r.URL.Query().Get("var"); v != "" {
+			fmt.Fprintf(w, "var %s = ", v)
+			w.Write(marshaledData)
+			io.WriteString(w, ";")
+		} else {
+			w.Write(marshaledData)
+		}
+	}
+}
+
+// QueryPullRequests returns the open pull requests of the Gitee repos
+// authored by the user. The repos whose pull requests can't be listed, for
+// example because they are private, are skipped.
+func (da *GiteeDashboardAgent) QueryPullRequests(gc giteeClient, login string) ([]PullRequest, error) {
+	var r []PullRequest
+	for _, v := range da.repos() {
+		orgRepo := config.NewOrgRepo(v)
+		prs, err := gc.GetPullRequests(orgRepo.Org, orgRepo.Repo, "open", "", "")
+		if err != nil {
+			da.log.WithError(err).WithField("repo", v).Warn("Failed to list the pull requests.")
+			continue
+		}
+		for i := range prs {
+			pr := &prs[i]
+			if pr.User == nil || github.NormLogin(pr.User.Login) != github.NormLogin(login) {
+				continue
+			}
+			r = append(r, convertGiteePR(orgRepo.Org, orgRepo.Repo, pr))
+		}
+	}
+	return r, nil
+}
+
+func convertGiteePR(org, repo string, v *sdk.PullRequest) PullRequest {
+	var r PullRequest
+
+	r.Number = githubql.Int(v.Number)
+	r.Title = githubql.String(v.Title)
+	r.Author.Login = githubql.String(v.User.Login)
+	if v.Base != nil {
+		r.BaseRef.Name = githubql.String(v.Base.Ref)
+		r.BaseRef.Prefix = "refs/heads/"
+	}
+	if v.Head != nil {
+		r.HeadRefOID = githubql.String(v.Head.Sha)
+	}
+	r.Repository.Name = githubql.String(repo)
+	r.Repository.NameWithOwner = githubql.String(org + "/" + repo)
+	r.Repository.Owner.Login = githubql.String(org)
+
+	for _, l := range v.Labels {
+		r.Labels.Nodes = append(r.Labels.Nodes, struct {
+			Label Label `graphql:"... on Label"`
+		}{
+			Label: Label{Name: githubql.String(l.Name)},
+		})
+	}
+
+	if v.Milestone != nil {
+		r.Milestone.Title = githubql.String(v.Milestone.Title)
+	}
+
+	if v.Mergeable {
+		r.Mergeable = githubql.MergeableStateMergeable
+	} else {
+		r.Mergeable = githubql.MergeableStateConflicting
+	}
+	return r
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prstatus
+
+import (
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/gorilla/sessions"
+	githubql "github.com/shurcooL/githubv4"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/test-infra/prow/gitee/fakegitee"
+	"k8s.io/test-infra/prow/giteeoauth"
+	"k8s.io/test-infra/prow/githuboauth"
+)
+
+func TestGiteeHandlePrStatus(t *testing.T) {
+	mockCookieStore := sessions.NewCookieStore([]byte("secret-key"))
+	fc := fakegitee.NewFakeClient()
+	fc.PullRequests[1] = &sdk.PullRequest{
+		Number:    1,
+		Title:     "mine",
+		State:     "open",
+		User:      &sdk.UserBasic{Login: fakegitee.Bot},
+		Base:      &sdk.BranchBasic{Ref: "master"},
+		Head:      &sdk.BranchBasic{Ref: "feature", Sha: "abcdef"},
+		Labels:    []sdk.Label{{Name: "lgtm"}},
+		Mergeable: true,
+	}
+	fc.PullRequests[2] = &sdk.PullRequest{
+		Number: 2,
+		Title:  "someone else's",
+		State:  "open",
+		User:   &sdk.UserBasic{Login: "someone"},
+	}
+	agent := &GiteeDashboardAgent{
+		repos:         func() []string { return []string{"org/repo"} },
+		goac:          &githuboauth.Config{CookieStore: mockCookieStore},
+		clientFactory: func(string) giteeClient { return fc },
+		log:           logrus.WithField("unit-test", "gitee-dashboard-agent"),
+	}
+
+	expected := PullRequest{Number: 1, Title: "mine", HeadRefOID: "abcdef", Mergeable: githubql.MergeableStateMergeable}
+	expected.Author.Login = fakegitee.Bot
+	expected.BaseRef.Name = "master"
+	expected.BaseRef.Prefix = "refs/heads/"
+	expected.Repository.Name = "repo"
+	expected.Repository.NameWithOwner = "org/repo"
+	expected.Repository.Owner.Login = "org"
+	expected.Labels.Nodes = append(expected.Labels.Nodes, struct {
+		Label Label `graphql:"... on Label"`
+	}{Label: Label{Name: "lgtm"}})
+
+	testCases := []struct {
+		name         string
+		login        bool
+		expectedData UserData
+	}{
+		{
+			name:         "not logged in",
+			expectedData: UserData{Login: false},
+		},
+		{
+			name:  "logged in",
+			login: true,
+			expectedData: UserData{
+				Login:                    true,
+				PullRequestsWithContexts: []PullRequestWithContexts{{PullRequest: expected}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/pr-data.js", nil)
+			if tc.login {
+				mockSession, err := sessions.GetRegistry(request).Get(mockCookieStore, giteeoauth.TokenSession)
+				if err != nil {
+					t.Fatalf("Error with creating mock session: %v", err)
+				}
+				gob.Register(oauth2.Token{})
+				mockSession.Values[giteeoauth.TokenKey] = &oauth2.Token{AccessToken: "secret-token", Expiry: time.Now().Add(time.Hour)}
+			}
+
+			agent.HandlePrStatus().ServeHTTP(rr, request)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Bad status code: %d", rr.Code)
+			}
+			body, err := ioutil.ReadAll(rr.Result().Body)
+			if err != nil {
+				t.Fatalf("Error with reading response body: %v", err)
+			}
+			var dataReturned UserData
+			if err := yaml.Unmarshal(body, &dataReturned); err != nil {
+				t.Fatalf("Error with unmarshaling response: %v", err)
+			}
+			if !reflect.DeepEqual(dataReturned, tc.expectedData) {
+				t.Errorf("Invalid user data. Got %+v, expected %+v.", dataReturned, tc.expectedData)
+			}
+		})
+	}
+}
+
+type failingRepoClient struct {
+	giteeClient
+	repo string
+}
+
+func (c *failingRepoClient) GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error) {
+	if org+"/"+repo == c.repo {
+		return nil, errors.New("404 Not Found")
+	}
+	return c.giteeClient.GetPullRequests(org, repo, state, head, base)
+}
+
+func TestGiteeQueryPullRequestsSkipsFailingRepos(t *testing.T) {
+	fc := fakegitee.NewFakeClient()
+	fc.PullRequests[1] = &sdk.PullRequest{
+		Number: 1,
+		State:  "open",
+		User:   &sdk.UserBasic{Login: "author"},
+	}
+	agent := &GiteeDashboardAgent{
+		repos: func() []string { return []string{"org/private", "org/repo"} },
+		log:   logrus.WithField("unit-test", "gitee-dashboard-agent"),
+	}
+
+	prs, err := agent.QueryPullRequests(&failingRepoClient{giteeClient: fc, repo: "org/private"}, "author")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(prs) != 1 || string(prs[0].Repository.NameWithOwner) != "org/repo" {
+		t.Errorf("Expected the pull request of org/repo, got %+v", prs)
+	}
+}
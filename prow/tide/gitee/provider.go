@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gitee implements the tide provider for the repositories on Gitee.
+package gitee
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/tide"
+	"k8s.io/test-infra/prow/tide/blockers"
+)
+
+type giteeClient interface {
+	BotName() (string, error)
+	GetRef(org, repo, ref string) (string, error)
+	GetRepos(org string) ([]sdk.Project, error)
+	GetPullRequests(org, repo, state, head, base string) ([]sdk.PullRequest, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	MergePR(owner, repo string, number int, opt sdk.PullRequestMergePutParam) error
+	ListPRComments(org, repo string, number int) ([]sdk.PullRequestComments, error)
+	CreatePRComment(org, repo string, number int, comment string) error
+	UpdatePRComment(org, repo string, commentID int, comment string) error
+}
+
+var _ tide.Provider = (*provider)(nil)
+
+// prExpiry is how long a PR is remembered after the last search finding it.
+// The status controller writes the tide status of the PRs right after its
+// search, so the PRs closed or pushed to since are forgotten safely.
+const prExpiry = time.Hour
+
+type prRecord struct {
+	number int
+	seen   time.Time
+}
+
+type provider struct {
+	gc          giteeClient
+	pjc         ctrlruntimeclient.Reader
+	pjNamespace func() string
+
+	// mut protects prs and statuses.
+	mut sync.Mutex
+	// prs maps the head commits of the PRs found by the searches to the PR
+	// numbers, which are needed to write the tide status comment.
+	prs map[string]prRecord
+	// statuses caches the tide status set on the head commits of the PRs.
+	// It is pruned together with prs.
+	statuses map[string]github.Status
+}
+
+// NewProvider returns a tide.Provider which works against Gitee. The contexts
+// of the PRs are computed from the ProwJobs listed by pjc in the namespace
+// returned by pjNamespace, since Gitee has no commit status API.
+func NewProvider(gc gitee.Client, pjc ctrlruntimeclient.Reader, pjNamespace func() string) tide.Provider {
+	return &provider{
+		gc:          gc,
+		pjc:         pjc,
+		pjNamespace: pjNamespace,
+		prs:         map[string]prRecord{},
+		statuses:    map[string]github.Status{},
+	}
+}
+
+// RepoLink returns the web link of the repository on Gitee.
+func (p *provider) RepoLink(org, repo string) string {
+	return fmt.Sprintf("%s/%s/%s", gitee.HostURL, org, repo)
+}
+
+func (p *provider) GetRef(org, repo, ref string) (string, error) {
+	return p.gc.GetRef(org, repo, ref)
+}
+
+func (p *provider) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	return p.gc.GetPullRequestChanges(org, repo, number)
+}
+
+// GetRepo returns the merge methods of the repository. Gitee allows all of
+// them for every repository.
+func (p *provider) GetRepo(owner, name string) (github.FullRepo, error) {
+	r := github.FullRepo{
+		AllowMergeCommit: true,
+		AllowSquashMerge: true,
+		AllowRebaseMerge: true,
+	}
+	r.Owner.Login = owner
+	r.Name = name
+	r.FullName = owner + "/" + name
+	r.HTMLURL = p.RepoLink(owner, name)
+	return r, nil
+}
+
+func (p *provider) Merge(org, repo string, number int, details github.MergeDetails) error {
+	opt := sdk.PullRequestMergePutParam{
+		MergeMethod: details.MergeMethod,
+		Title:       details.CommitTitle,
+		Description: details.CommitMessage,
+	}
+	if opt.MergeMethod == "" {
+		opt.MergeMethod = string(github.MergeMerge)
+	}
+	if err := p.gc.MergePR(org, repo, number, opt); err != nil {
+		return fmt.Errorf("failed to merge %s/%s#%d: %v", org, repo, number, err)
+	}
+	return nil
+}
+
+// FindBlockers returns no blockers, because the issues on Gitee can't be
+// searched across the orgs and repos by label.
+func (p *provider) FindBlockers(log *logrus.Entry, label, orgRepoQuery string) (blockers.Blockers, error) {
+	log.WithField("label", label).Warn("Merge blockers are not supported for Gitee, ignoring them.")
+	return blockers.Blockers{}, nil
+}
+
+// Search lists the open PRs of the repositories in the query and returns the
+// ones matching the query which were updated between start and end.
+func (p *provider) Search(log *logrus.Entry, q string, start, end time.Time) ([]tide.PullRequest, error) {
+	log = log.WithField("query", q)
+	query, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := p.listRepos(query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	defer p.prune(now.Add(-prExpiry))
+
+	var ret []tide.PullRequest
+	for _, repo := range repos {
+		prs, err := p.gc.GetPullRequests(repo.Org, repo.Repo, "open", "", "")
+		if err != nil {
+			// Keep the PRs found so far like the GitHub search does.
+			return ret, fmt.Errorf("failed to list the PRs of %s: %v", repo.String(), err)
+		}
+
+		for i := range prs {
+			pr := &prs[i]
+			if !query.matches(pr) {
+				continue
+			}
+
+			updated, err := time.Parse(time.RFC3339, pr.UpdatedAt)
+			if err != nil {
+				log.WithError(err).Warnf("Failed to parse the update time of %s#%d.", repo.String(), pr.Number)
+			}
+			if (!start.IsZero() && updated.Before(start)) || updated.After(end) {
+				continue
+			}
+
+			ret = append(ret, convertPR(repo.Org, repo.Repo, pr, updated))
+			p.recordPR(repo.Org, repo.Repo, pr.Head.Sha, int(pr.Number), now)
+		}
+	}
+
+	sortByUpdatedAt(ret)
+	log.Debugf("Found %d PRs in %d repositories.", len(ret), len(repos))
+	return ret, nil
+}
+
+func (p *provider) recordPR(org, repo, sha string, number int, seen time.Time) {
+	p.mut.Lock()
+	p.prs[commitKey(org, repo, sha)] = prRecord{number: number, seen: seen}
+	p.mut.Unlock()
+}
+
+// prune forgets the commits of the PRs which were not found since the time,
+// together with their tide status.
+func (p *provider) prune(since time.Time) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	for k, v := range p.prs {
+		if v.seen.Before(since) {
+			delete(p.prs, k)
+		}
+	}
+	for k := range p.statuses {
+		if _, ok := p.prs[k]; !ok {
+			delete(p.statuses, k)
+		}
+	}
+}
+
+func (p *provider) prNumber(org, repo, sha string) (int, bool) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	v, ok := p.prs[commitKey(org, repo, sha)]
+	return v.number, ok
+}
+
+func commitKey(org, repo, sha string) string {
+	return fmt.Sprintf("%s/%s@%s", org, repo, sha)
+}
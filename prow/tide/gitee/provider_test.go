@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	p := &provider{
+		prs:      map[string]prRecord{},
+		statuses: map[string]github.Status{},
+	}
+	p.recordPR("org", "repo", "old", 1, now.Add(-2*prExpiry))
+	p.recordPR("org", "repo", "new", 1, now)
+	p.statuses[commitKey("org", "repo", "old")] = github.Status{State: github.StatusPending}
+	p.statuses[commitKey("org", "repo", "new")] = github.Status{State: github.StatusSuccess}
+
+	p.prune(now.Add(-prExpiry))
+
+	if _, ok := p.prNumber("org", "repo", "old"); ok {
+		t.Error("expected the commit not found for a long time to be forgotten")
+	}
+	if n, ok := p.prNumber("org", "repo", "new"); !ok || n != 1 {
+		t.Errorf("expected the recent commit to belong to PR 1, got %d (%t)", n, ok)
+	}
+	if _, ok := p.statuses[commitKey("org", "repo", "old")]; ok {
+		t.Error("expected the status of the forgotten commit to be pruned")
+	}
+	if _, ok := p.statuses[commitKey("org", "repo", "new")]; !ok {
+		t.Error("expected the status of the recent commit to be kept")
+	}
+}
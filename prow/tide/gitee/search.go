@@ -0,0 +1,237 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	githubql "github.com/shurcooL/githubv4"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/tide"
+)
+
+// query is the parsed form of the GitHub search syntax generated by
+// config.TideQuery.Query.
+type query struct {
+	orgs             []string
+	repos            []string
+	excludedRepos    sets.String
+	author           string
+	milestone        string
+	labels           []string
+	missingLabels    []string
+	includedBranches sets.String
+	excludedBranches sets.String
+}
+
+// parseQuery parses the query. It fails on review:approved, because Gitee
+// doesn't tell whether the reviewers of a PR approved it.
+func parseQuery(q string) (query, error) {
+	r := query{
+		excludedRepos:    sets.NewString(),
+		includedBranches: sets.NewString(),
+		excludedBranches: sets.NewString(),
+	}
+
+	for _, tok := range splitQuery(q) {
+		i := strings.Index(tok, ":")
+		if i < 0 {
+			continue
+		}
+		k, v := tok[:i], strings.Trim(tok[i+1:], "\"")
+
+		switch k {
+		case "org":
+			r.orgs = append(r.orgs, v)
+		case "repo":
+			r.repos = append(r.repos, v)
+		case "-repo":
+			r.excludedRepos.Insert(v)
+		case "author":
+			r.author = v
+		case "milestone":
+			r.milestone = v
+		case "label":
+			r.labels = append(r.labels, v)
+		case "-label":
+			r.missingLabels = append(r.missingLabels, v)
+		case "base":
+			r.includedBranches.Insert(v)
+		case "-base":
+			r.excludedBranches.Insert(v)
+		case "review":
+			return r, fmt.Errorf("review:%s is not supported for Gitee", v)
+		}
+	}
+	return r, nil
+}
+
+// ValidateQueries returns an error if one of the tide queries can't be
+// searched on Gitee.
+func ValidateQueries(queries config.TideQueries) error {
+	for i, tq := range queries {
+		if _, err := parseQuery(tq.Query()); err != nil {
+			return fmt.Errorf("tide query (index %d) is invalid: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// splitQuery splits the query by the spaces which are not quoted.
+func splitQuery(q string) []string {
+	var toks []string
+	var b strings.Builder
+	quoted := false
+	for _, c := range q {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			b.WriteRune(c)
+		case c == ' ' && !quoted:
+			if b.Len() > 0 {
+				toks = append(toks, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 {
+		toks = append(toks, b.String())
+	}
+	return toks
+}
+
+// matches returns whether the PR satisfies the conditions of the query other
+// than the repository and the update time.
+func (q *query) matches(pr *sdk.PullRequest) bool {
+	if q.author != "" && !strings.EqualFold(q.author, pr.User.Login) {
+		return false
+	}
+
+	if q.milestone != "" && (pr.Milestone == nil || pr.Milestone.Title != q.milestone) {
+		return false
+	}
+
+	base := pr.Base.Ref
+	if q.includedBranches.Len() > 0 && !q.includedBranches.Has(base) {
+		return false
+	}
+	if q.excludedBranches.Has(base) {
+		return false
+	}
+
+	labels := sets.NewString()
+	for _, l := range pr.Labels {
+		labels.Insert(l.Name)
+	}
+	for _, l := range q.labels {
+		// A label of the query can list alternatives separated by commas.
+		if !labels.HasAny(strings.Split(l, ",")...) {
+			return false
+		}
+	}
+	for _, l := range q.missingLabels {
+		if labels.Has(l) {
+			return false
+		}
+	}
+	return true
+}
+
+// listRepos returns the repositories in the query, including the repositories
+// of its orgs which are not excluded.
+func (p *provider) listRepos(q query) ([]config.OrgRepo, error) {
+	seen := sets.NewString()
+	var r []config.OrgRepo
+
+	add := func(org, repo string) {
+		s := org + "/" + repo
+		if q.excludedRepos.Has(s) || seen.Has(s) {
+			return
+		}
+		seen.Insert(s)
+		r = append(r, config.OrgRepo{Org: org, Repo: repo})
+	}
+
+	for _, org := range q.orgs {
+		repos, err := p.gc.GetRepos(org)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the repositories of %s: %v", org, err)
+		}
+		for _, repo := range repos {
+			add(org, repo.Path)
+		}
+	}
+
+	for _, s := range q.repos {
+		orgRepo := config.NewOrgRepo(s)
+		add(orgRepo.Org, orgRepo.Repo)
+	}
+	return r, nil
+}
+
+func convertPR(org, repo string, v *sdk.PullRequest, updated time.Time) tide.PullRequest {
+	var r tide.PullRequest
+
+	r.Number = githubql.Int(v.Number)
+	r.Author.Login = githubql.String(v.User.Login)
+	r.BaseRef.Name = githubql.String(v.Base.Ref)
+	r.BaseRef.Prefix = "refs/heads/"
+	r.HeadRefName = githubql.String(v.Head.Ref)
+	r.HeadRefOID = githubql.String(v.Head.Sha)
+	r.Repository.Name = githubql.String(repo)
+	r.Repository.NameWithOwner = githubql.String(org + "/" + repo)
+	r.Repository.Owner.Login = githubql.String(org)
+	r.Body = githubql.String(v.Body)
+	r.Title = githubql.String(v.Title)
+	r.UpdatedAt = githubql.DateTime{Time: updated}
+
+	if v.Mergeable {
+		r.Mergeable = githubql.MergeableStateMergeable
+	} else {
+		r.Mergeable = githubql.MergeableStateConflicting
+	}
+
+	for _, l := range v.Labels {
+		r.Labels.Nodes = append(r.Labels.Nodes, struct{ Name githubql.String }{
+			Name: githubql.String(l.Name),
+		})
+	}
+
+	if v.Milestone != nil && v.Milestone.Title != "" {
+		r.Milestone = &struct{ Title githubql.String }{
+			Title: githubql.String(v.Milestone.Title),
+		}
+	}
+
+	// The contexts of the head commit are left empty on purpose, tide gets
+	// them through GetCombinedStatus.
+	return r
+}
+
+func sortByUpdatedAt(prs []tide.PullRequest) {
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].UpdatedAt.Before(prs[j].UpdatedAt.Time)
+	})
+}
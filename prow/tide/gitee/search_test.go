@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/test-infra/prow/config"
+)
+
+func TestParseQuery(t *testing.T) {
+	tq := config.TideQuery{
+		Orgs:             []string{"org"},
+		Repos:            []string{"other/repo"},
+		ExcludedRepos:    []string{"org/excluded"},
+		Author:           "bot",
+		Labels:           []string{"lgtm", "approved"},
+		MissingLabels:    []string{"do-not-merge/hold"},
+		Milestone:        "v1.0 beta",
+		IncludedBranches: []string{"master"},
+		ExcludedBranches: []string{"release"},
+	}
+
+	expected := query{
+		orgs:             []string{"org"},
+		repos:            []string{"other/repo"},
+		excludedRepos:    sets.NewString("org/excluded"),
+		author:           "bot",
+		milestone:        "v1.0 beta",
+		labels:           []string{"lgtm", "approved"},
+		missingLabels:    []string{"do-not-merge/hold"},
+		includedBranches: sets.NewString("master"),
+		excludedBranches: sets.NewString("release"),
+	}
+
+	q, err := parseQuery(tq.Query())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(q, expected) {
+		t.Errorf("expected query %+v, got %+v", expected, q)
+	}
+}
+
+func TestValidateQueries(t *testing.T) {
+	queries := config.TideQueries{
+		{Repos: []string{"org/repo"}, Labels: []string{"lgtm"}},
+	}
+	if err := ValidateQueries(queries); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	queries = append(queries, config.TideQuery{Repos: []string{"org/repo"}, ReviewApprovedRequired: true})
+	if err := ValidateQueries(queries); err == nil {
+		t.Error("expected an error for review:approved")
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	newPR := func(base string, labels ...string) *sdk.PullRequest {
+		pr := &sdk.PullRequest{}
+		pr.Base = &sdk.BranchBasic{Ref: base}
+		pr.User = &sdk.UserBasic{Login: "author"}
+		for _, l := range labels {
+			pr.Labels = append(pr.Labels, sdk.Label{Name: l})
+		}
+		return pr
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		pr       *sdk.PullRequest
+		expected bool
+	}{
+		{
+			name:     "all the labels present",
+			query:    `is:pr state:open label:"lgtm" label:"approved"`,
+			pr:       newPR("master", "lgtm", "approved"),
+			expected: true,
+		},
+		{
+			name:  "a label missing",
+			query: `is:pr state:open label:"lgtm" label:"approved"`,
+			pr:    newPR("master", "lgtm"),
+		},
+		{
+			name:     "one of the alternative labels present",
+			query:    `is:pr state:open label:"lgtm,approved"`,
+			pr:       newPR("master", "approved"),
+			expected: true,
+		},
+		{
+			name:  "a forbidden label present",
+			query: `is:pr state:open label:"lgtm" -label:"do-not-merge/hold"`,
+			pr:    newPR("master", "lgtm", "do-not-merge/hold"),
+		},
+		{
+			name:  "base branch not included",
+			query: `is:pr state:open base:"master"`,
+			pr:    newPR("release"),
+		},
+		{
+			name:  "base branch excluded",
+			query: `is:pr state:open -base:"release"`,
+			pr:    newPR("release"),
+		},
+		{
+			name:  "another author",
+			query: `is:pr state:open author:"bot"`,
+			pr:    newPR("master"),
+		},
+		{
+			name:  "milestone missing",
+			query: `is:pr state:open milestone:"v1.0"`,
+			pr:    newPR("master"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r := q.matches(tc.pr); r != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitee
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/gitee"
+	"k8s.io/test-infra/prow/github"
+)
+
+const (
+	tideStatusTag = "<!-- tide status -->"
+)
+
+// GetCombinedStatus returns the statuses of the latest presubmit ProwJobs run
+// against the commit, together with the tide status set on it.
+func (p *provider) GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error) {
+	list := func(set labels.Set) ([]prowapi.ProwJob, error) {
+		jobs := &prowapi.ProwJobList{}
+		err := p.pjc.List(
+			context.Background(),
+			jobs,
+			ctrlruntimeclient.InNamespace(p.pjNamespace()),
+			ctrlruntimeclient.MatchingLabels(set),
+		)
+		return jobs.Items, err
+	}
+	// The PR of the commit is known once tide searched it.
+	number, _ := p.prNumber(org, repo, ref)
+	r, err := gitee.CombinedStatus(list, org, repo, number, ref)
+	if err != nil {
+		return r, err
+	}
+
+	p.mut.Lock()
+	if s, ok := p.statuses[commitKey(org, repo, ref)]; ok {
+		r.Statuses = append(r.Statuses, s)
+	}
+	p.mut.Unlock()
+
+	sort.Slice(r.Statuses, func(i, j int) bool {
+		return r.Statuses[i].Context < r.Statuses[j].Context
+	})
+	return r, nil
+}
+
+// CreateStatus writes the tide status of the PR to a comment on it, since
+// Gitee has no commit status API. The comment is updated in place whenever
+// the status changes.
+func (p *provider) CreateStatus(org, repo, ref string, s github.Status) error {
+	number, ok := p.prNumber(org, repo, ref)
+	if !ok {
+		return fmt.Errorf("can't find the PR of %s/%s@%s", org, repo, ref)
+	}
+
+	if err := p.writeStatusComment(org, repo, number, ref, s); err != nil {
+		return err
+	}
+
+	p.mut.Lock()
+	p.statuses[commitKey(org, repo, ref)] = s
+	p.mut.Unlock()
+	return nil
+}
+
+func (p *provider) writeStatusComment(org, repo string, number int, ref string, s github.Status) error {
+	botName, err := p.gc.BotName()
+	if err != nil {
+		return err
+	}
+
+	comments, err := p.gc.ListPRComments(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments of %s/%s#%d: %v", org, repo, number, err)
+	}
+
+	body := statusComment(ref, s)
+	for _, v := range comments {
+		c := gitee.ConvertGiteePRComment(v)
+		if c.User.Login != botName || !strings.Contains(c.Body, tideStatusTag) {
+			continue
+		}
+		if c.Body == body {
+			return nil
+		}
+		return p.gc.UpdatePRComment(org, repo, c.ID, body)
+	}
+	return p.gc.CreatePRComment(org, repo, number, body)
+}
+
+func statusComment(ref string, s github.Status) string {
+	desc := s.Description
+	if s.TargetURL != "" {
+		desc = fmt.Sprintf("[%s](%s)", desc, s.TargetURL)
+	}
+
+	return fmt.Sprintf(
+		"%s\n**%s**: %s\n\n%s (commit %s)",
+		tideStatusTag, s.Context, strings.ToUpper(s.State), desc, ref,
+	)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tide
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/tide/blockers"
+)
+
+// Provider abstracts the code hosting platform tide works against. It
+// queries the PRs of the pools, looks up the contexts of their head
+// commits and merges them.
+type Provider interface {
+	CreateStatus(org, repo, ref string, s github.Status) error
+	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetRef(org, repo, ref string) (string, error)
+	GetRepo(owner, name string) (github.FullRepo, error)
+	Merge(org, repo string, number int, details github.MergeDetails) error
+
+	// Search returns the PRs matching the query which were updated between
+	// start and end, sorted by the update time. The query is written in the
+	// GitHub search syntax as config.TideQuery generates it.
+	Search(log *logrus.Entry, q string, start, end time.Time) ([]PullRequest, error)
+	// FindBlockers returns the issues with the label in the orgs and repos
+	// of the query which block merging.
+	FindBlockers(log *logrus.Entry, label, orgRepoQuery string) (blockers.Blockers, error)
+	// RepoLink returns the web link of the repository. An empty link means
+	// the repository is on GitHub.
+	RepoLink(org, repo string) string
+}
+
+// NewGitHubProvider returns a Provider which works against GitHub.
+func NewGitHubProvider(ghc github.Client) Provider {
+	return &githubProvider{githubClient: ghc}
+}
+
+type githubProvider struct {
+	githubClient
+}
+
+func (p *githubProvider) Search(log *logrus.Entry, q string, start, end time.Time) ([]PullRequest, error) {
+	return search(p.Query, log, q, start, end)
+}
+
+func (p *githubProvider) FindBlockers(log *logrus.Entry, label, orgRepoQuery string) (blockers.Blockers, error) {
+	return blockers.FindAll(p.githubClient, log, label, orgRepoQuery)
+}
+
+func (p *githubProvider) RepoLink(org, repo string) string {
+	return ""
+}
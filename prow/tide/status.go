@@ -65,7 +65,7 @@ type statusController struct {
 	pjClient ctrlruntimeclient.Client
 	logger   *logrus.Entry
 	config   config.Getter
-	ghc      githubClient
+	ghc      Provider
 	gc       git.ClientFactory
 
 	mergeChecker *mergeChecker
@@ -555,7 +555,7 @@ func (sc *statusController) search() []PullRequest {
 		sc.PreviousQuery = query
 	}
 
-	prs, err := search(sc.ghc.Query, sc.logger, query, sc.LatestPR.Time, now)
+	prs, err := sc.ghc.Search(sc.logger, query, sc.LatestPR.Time, now)
 	log.WithField("duration", time.Since(now).String()).Debugf("Found %d open PRs.", len(prs))
 	if err != nil {
 		log := log.WithError(err)
@@ -582,7 +582,7 @@ func (sc *statusController) search() []PullRequest {
 
 // newBaseSHAGetter is a refGetter that will look up the baseSHA from GitHub if necessary
 // and if it did so, store in in the baseSHA map
-func newBaseSHAGetter(baseSHAs map[string]string, ghc githubClient, org, repo, branch string) config.RefGetter {
+func newBaseSHAGetter(baseSHAs map[string]string, ghc Provider, org, repo, branch string) config.RefGetter {
 	return func() (string, error) {
 		if sha, exists := baseSHAs[poolKey(org, repo, branch)]; exists {
 			return sha, nil
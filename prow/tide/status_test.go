@@ -946,7 +946,7 @@ func TestNewBaseSHAGetter(t *testing.T) {
 	testCases := []struct {
 		name     string
 		baseSHAs map[string]string
-		ghc      githubClient
+		ghc      Provider
 
 		expectedSHA string
 		expectErr   bool
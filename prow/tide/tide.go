@@ -73,7 +73,7 @@ type Controller struct {
 	ctx           context.Context
 	logger        *logrus.Entry
 	config        config.Getter
-	ghc           githubClient
+	ghc           Provider
 	prowJobClient ctrlruntimeclient.Client
 	gc            git.ClientFactory
 
@@ -120,6 +120,9 @@ type Pool struct {
 	Org    string
 	Repo   string
 	Branch string
+	// RepoLink is the web link of the repository, which is empty if the
+	// repository is on GitHub.
+	RepoLink string
 
 	// PRs with passing tests, pending tests, and missing or failed tests.
 	// Note that these results are rolled up. If all tests for a PR are passing
@@ -227,7 +230,7 @@ type manager interface {
 }
 
 // NewController makes a Controller out of the given clients.
-func NewController(ghcSync, ghcStatus github.Client, mgr manager, cfg config.Getter, gc git.ClientFactory, maxRecordsPerPool int, opener io.Opener, historyURI, statusURI string, logger *logrus.Entry) (*Controller, error) {
+func NewController(ghcSync, ghcStatus Provider, mgr manager, cfg config.Getter, gc git.ClientFactory, maxRecordsPerPool int, opener io.Opener, historyURI, statusURI string, logger *logrus.Entry) (*Controller, error) {
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
@@ -246,7 +249,7 @@ func NewController(ghcSync, ghcStatus github.Client, mgr manager, cfg config.Get
 	return newSyncController(logger, ghcSync, mgr, cfg, gc, sc, hist, mergeChecker)
 }
 
-func newStatusController(logger *logrus.Entry, ghc githubClient, mgr manager, gc git.ClientFactory, cfg config.Getter, opener io.Opener, statusURI string, mergeChecker *mergeChecker) (*statusController, error) {
+func newStatusController(logger *logrus.Entry, ghc Provider, mgr manager, gc git.ClientFactory, cfg config.Getter, opener io.Opener, statusURI string, mergeChecker *mergeChecker) (*statusController, error) {
 	if err := mgr.GetFieldIndexer().IndexField(&prowapi.ProwJob{}, indexNamePassingJobs, indexFuncPassingJobs); err != nil {
 		return nil, fmt.Errorf("failed to add index for passing jobs to cache: %v", err)
 	}
@@ -266,7 +269,7 @@ func newStatusController(logger *logrus.Entry, ghc githubClient, mgr manager, gc
 
 func newSyncController(
 	logger *logrus.Entry,
-	ghcSync githubClient,
+	ghcSync Provider,
 	mgr manager,
 	cfg config.Getter,
 	gc git.ClientFactory,
@@ -354,7 +357,7 @@ func (c *Controller) Sync() error {
 	prs := make(map[string]PullRequest)
 	for _, query := range c.config().Tide.Queries {
 		q := query.Query()
-		results, err := search(c.ghc.Query, c.logger, q, time.Time{}, time.Now())
+		results, err := c.ghc.Search(c.logger, q, time.Time{}, time.Now())
 		if err != nil && len(results) == 0 {
 			return fmt.Errorf("query %q, err: %v", q, err)
 		}
@@ -380,7 +383,7 @@ func (c *Controller) Sync() error {
 				orgs = append(orgs, org)
 			}
 			orgRepoQuery := orgRepoQueryString(orgs, repos.UnsortedList(), orgExcepts)
-			blocks, err = blockers.FindAll(c.ghc, c.logger, label, orgRepoQuery)
+			blocks, err = c.ghc.FindBlockers(c.logger, label, orgRepoQuery)
 			if err != nil {
 				return err
 			}
@@ -521,7 +524,7 @@ func (c *Controller) initSubpoolData(sp *subpool) error {
 // filtered subpool.
 // If the subpool becomes empty 'nil' is returned to indicate that the subpool
 // should be deleted.
-func filterSubpool(ghc githubClient, mergeAllowed func(*PullRequest) (string, error), sp *subpool) *subpool {
+func filterSubpool(ghc Provider, mergeAllowed func(*PullRequest) (string, error), sp *subpool) *subpool {
 	var toKeep []PullRequest
 	for _, pr := range sp.prs {
 		if !filterPR(ghc, mergeAllowed, sp, &pr) {
@@ -544,7 +547,7 @@ func filterSubpool(ghc githubClient, mergeAllowed func(*PullRequest) (string, er
 //   status is preventing merge. Required ProwJob statuses are allowed to be
 //   'pending' because this prevents kicking PRs from the pool when Tide is
 //   retesting them.)
-func filterPR(ghc githubClient, mergeAllowed func(*PullRequest) (string, error), sp *subpool, pr *PullRequest) bool {
+func filterPR(ghc Provider, mergeAllowed func(*PullRequest) (string, error), sp *subpool, pr *PullRequest) bool {
 	log := sp.log.WithFields(pr.logFields())
 	// Skip PRs that are known to be unmergeable.
 	if reason, err := mergeAllowed(pr); err != nil {
@@ -589,13 +592,13 @@ func filterPR(ghc githubClient, mergeAllowed func(*PullRequest) (string, error),
 // It caches results and should be cleared periodically with clearCache()
 type mergeChecker struct {
 	config config.Getter
-	ghc    githubClient
+	ghc    Provider
 
 	sync.Mutex
 	cache map[config.OrgRepo]map[github.PullRequestMergeType]bool
 }
 
-func newMergeChecker(cfg config.Getter, ghc githubClient) *mergeChecker {
+func newMergeChecker(cfg config.Getter, ghc Provider) *mergeChecker {
 	m := &mergeChecker{
 		config: cfg,
 		ghc:    ghc,
@@ -716,7 +719,7 @@ func toSimpleState(s prowapi.ProwJobState) simpleState {
 
 // isPassingTests returns whether or not all contexts set on the PR except for
 // the tide pool context are passing.
-func isPassingTests(log *logrus.Entry, ghc githubClient, pr PullRequest, cc contextChecker) bool {
+func isPassingTests(log *logrus.Entry, ghc Provider, pr PullRequest, cc contextChecker) bool {
 	log = log.WithFields(pr.logFields())
 	contexts, err := headContexts(log, ghc, &pr)
 	if err != nil {
@@ -754,7 +757,7 @@ func unsuccessfulContexts(contexts []Context, cc contextChecker, log *logrus.Ent
 	return failed
 }
 
-func pickSmallestPassingNumber(log *logrus.Entry, ghc githubClient, prs []PullRequest, cc map[int]contextChecker) (bool, PullRequest) {
+func pickSmallestPassingNumber(log *logrus.Entry, ghc Provider, prs []PullRequest, cc map[int]contextChecker) (bool, PullRequest) {
 	smallestNumber := -1
 	var smallestPR PullRequest
 	for _, pr := range prs {
@@ -1178,10 +1181,11 @@ func tryMerge(mergeFunc func() error) (bool, error) {
 
 func (c *Controller) trigger(sp subpool, presubmits []config.Presubmit, prs []PullRequest) error {
 	refs := prowapi.Refs{
-		Org:     sp.org,
-		Repo:    sp.repo,
-		BaseRef: sp.branch,
-		BaseSHA: sp.sha,
+		Org:      sp.org,
+		Repo:     sp.repo,
+		RepoLink: c.ghc.RepoLink(sp.org, sp.repo),
+		BaseRef:  sp.branch,
+		BaseSHA:  sp.sha,
 	}
 	for _, pr := range prs {
 		refs.Pulls = append(
@@ -1260,7 +1264,7 @@ func (c *Controller) takeAction(sp subpool, batchPending, successes, pendings, m
 // changedFilesAgent queries and caches the names of files changed by PRs.
 // Cache entries expire if they are not used during a sync loop.
 type changedFilesAgent struct {
-	ghc         githubClient
+	ghc         Provider
 	changeCache map[changeCacheKey][]string
 	// nextChangeCache caches file change info that is relevant this sync for use next sync.
 	// This becomes the new changeCache when prune() is called at the end of each sync.
@@ -1473,9 +1477,10 @@ func (c *Controller) syncSubpool(sp subpool, blocks []blockers.Blocker) (Pool, e
 	tideMetrics.pooledPRs.WithLabelValues(sp.org, sp.repo, sp.branch).Set(float64(len(sp.prs)))
 	tideMetrics.updateTime.WithLabelValues(sp.org, sp.repo, sp.branch).Set(float64(time.Now().Unix()))
 	return Pool{
-			Org:    sp.org,
-			Repo:   sp.repo,
-			Branch: sp.branch,
+			Org:      sp.org,
+			Repo:     sp.repo,
+			Branch:   sp.branch,
+			RepoLink: c.ghc.RepoLink(sp.org, sp.repo),
 
 			SuccessPRs: successes,
 			PendingPRs: pendings,
@@ -1691,7 +1696,7 @@ func (pr *PullRequest) logFields() logrus.Fields {
 // We list multiple commits with the query to increase our chance of success,
 // but if we don't find the head commit we have to ask GitHub for it
 // specifically (this costs an API token).
-func headContexts(log *logrus.Entry, ghc githubClient, pr *PullRequest) ([]Context, error) {
+func headContexts(log *logrus.Entry, ghc Provider, pr *PullRequest) ([]Context, error) {
 	for _, node := range pr.Commits.Nodes {
 		if node.Commit.OID == pr.HeadRefOID {
 			return node.Commit.Status.Contexts, nil
@@ -47,6 +47,7 @@ import (
 	"k8s.io/test-infra/prow/git/localgit"
 	"k8s.io/test-infra/prow/git/v2"
 	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/tide/blockers"
 	"k8s.io/test-infra/prow/tide/history"
 )
 
@@ -560,6 +561,18 @@ func (f *fgc) Query(ctx context.Context, q interface{}, vars map[string]interfac
 	return nil
 }
 
+func (f *fgc) Search(log *logrus.Entry, q string, start, end time.Time) ([]PullRequest, error) {
+	return search(f.Query, log, q, start, end)
+}
+
+func (f *fgc) FindBlockers(log *logrus.Entry, label, orgRepoQuery string) (blockers.Blockers, error) {
+	return blockers.FindAll(f, log, label, orgRepoQuery)
+}
+
+func (f *fgc) RepoLink(org, repo string) string {
+	return ""
+}
+
 func (f *fgc) Merge(org, repo string, number int, details github.MergeDetails) error {
 	if err, ok := f.mergeErrs[number]; ok {
 		return err